@@ -2,21 +2,19 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/cors"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 
-	"modernfi-treasury-app/internal/database"
-	"modernfi-treasury-app/internal/handlers"
+	"modernfi-treasury-app/internal/config"
+	"modernfi-treasury-app/internal/middleware"
+	"modernfi-treasury-app/internal/router"
 	"modernfi-treasury-app/internal/services"
 )
 
@@ -27,127 +25,119 @@ const (
 	serverWriteTimeout = 15 * time.Second
 	serverIdleTimeout  = 60 * time.Second
 	shutdownTimeout    = 30 * time.Second
-
-	// CORS configuration
-	corsMaxAge = 300
 )
 
 func main() {
+	// Emit structured JSON logs; packages log through the default slog logger,
+	// and context-aware calls made while serving a request are tagged with its request_id
+	slog.SetDefault(slog.New(middleware.NewRequestIDLogHandler(slog.NewJSONHandler(os.Stdout, nil))))
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+		slog.Info("No .env file found")
 	}
 
+	// Load and validate all configuration up front so a bad setting fails fast
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+
+	// Record the settings actually in effect, secrets redacted, so operators can confirm what is live
+	slog.Info("Effective configuration",
+		"addr", serverPort,
+		"read_timeout", serverReadTimeout,
+		"write_timeout", serverWriteTimeout,
+		"idle_timeout", serverIdleTimeout,
+		"shutdown_timeout", shutdownTimeout,
+		"config", cfg,
+	)
+
 	// Database connection
 	ctx := context.Background()
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("DATABASE_URL environment variable not set")
-	}
 
 	// Create connection pool
-	config, err := pgxpool.ParseConfig(dbURL)
+	poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("Unable to parse DATABASE_URL: %v", err)
+		fatal("Unable to parse DATABASE_URL", err)
 	}
 
-	config.MaxConns = 25
-	config.MinConns = 5
+	poolConfig.MaxConns = cfg.DBMaxConns
+	poolConfig.MinConns = cfg.DBMinConns
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		log.Fatalf("Unable to connect to database: %v", err)
+		fatal("Unable to connect to database", err)
 	}
 	defer pool.Close()
 
 	// Test connection
 	if err := pool.Ping(ctx); err != nil {
-		log.Fatalf("Unable to ping database: %v", err)
+		fatal("Unable to ping database", err)
 	}
-	log.Println("Database connection established")
-
-	// Initialize sqlc queries
-	queries := database.New(pool)
-
-	// Initialize handlers
-	userHandler := handlers.NewUserHandler(queries)
+	slog.Info("Database connection established")
 
 	// Initialize TreasuryService
 	treasuryService := services.NewTreasuryService()
+	treasuryService.SetSlowFetchThreshold(cfg.SlowFetchThreshold)
+	if err := treasuryService.SetPlausibleYieldBand(cfg.YieldMinPlausible, cfg.YieldMaxPlausible); err != nil {
+		fatal("Invalid plausible yield band", err)
+	}
+	if err := treasuryService.SetCacheDuration(cfg.YieldCacheDuration); err != nil {
+		fatal("Invalid YIELD_CACHE_DURATION", err)
+	}
+	// Yields come from treasury.gov unless YIELD_SOURCE names a directory; YIELD_FALLBACK_DIR is tried after either
+	yieldSources := []services.YieldSource{treasuryService.TreasuryGovSource()}
+	if cfg.YieldSourceDir != "" {
+		fileSource, err := services.NewFileYieldSource(cfg.YieldSourceDir)
+		if err != nil {
+			fatal("Invalid YIELD_SOURCE", err)
+		}
+		yieldSources = []services.YieldSource{fileSource}
+		slog.Warn("Offline yield source: serving saved feeds instead of treasury.gov", "dir", cfg.YieldSourceDir)
+	}
+	if cfg.YieldFallbackDir != "" {
+		fileSource, err := services.NewFileYieldSource(cfg.YieldFallbackDir)
+		if err != nil {
+			fatal("Invalid YIELD_FALLBACK_DIR", err)
+		}
+		yieldSources = append(yieldSources, fileSource)
+	}
+	if err := treasuryService.SetYieldSources(yieldSources...); err != nil {
+		fatal("Invalid yield sources", err)
+	}
+	if err := treasuryService.SetMaxFailedYears(cfg.HistoricalMaxFailedYears); err != nil {
+		fatal("Invalid HISTORICAL_MAX_FAILED_YEARS", err)
+	}
+	if cfg.HistoricalCacheDuration > 0 {
+		if err := treasuryService.SetHistoricalCacheDuration(cfg.HistoricalCacheDuration); err != nil {
+			fatal("Invalid HISTORICAL_CACHE_DURATION", err)
+		}
+	}
 
 	// Start cache warming in background (non-blocking - returns immediately)
 	// Pre-fetches historical yield data for all periods (1W through 30Y)
 	// so subsequent user requests are served instantly from cache
 	treasuryService.WarmCache()
 
-	// Initialize YieldHandler with service
-	yieldHandler := handlers.NewYieldHandler(treasuryService)
-
-	// Initialize TransactionService and handlers
-	txService := services.NewTransactionService(queries, pool)
-	txHandlers := handlers.NewTransactionHandlers(txService, queries, treasuryService)
-
-	// Initialize HoldingsHandlers
-	holdingsHandlers := handlers.NewHoldingsHandlers(queries)
-
-	// Create chi router
-	r := chi.NewRouter()
-
-	// Get allowed origins from environment or use permissive defaults
-	// Since we're using nginx proxy in production, most requests come through same-origin
-	// But we still support direct API access for development and testing
-	allowedOrigins := []string{
-		"http://localhost:5173", // Vite dev server (default port)
-		"http://localhost:5174", // Vite dev server (alternate port)
-		"http://localhost:80",   // Local Docker frontend
-		"http://localhost",      // Local Docker frontend (alt)
-		"http://localhost:3000", // Alternative dev port
-	}
-
-	// Allow additional origins from environment (comma-separated)
-	if envOrigins := os.Getenv("CORS_ALLOWED_ORIGINS"); envOrigins != "" {
-		for _, origin := range strings.Split(envOrigins, ",") {
-			trimmed := strings.TrimSpace(origin)
-			if trimmed != "" {
-				allowedOrigins = append(allowedOrigins, trimmed)
-			}
-		}
+	// Refetch every historical period on an interval (daily by default) so charts pick up new data
+	treasuryService.StartHistoricalRefresh(ctx, cfg.HistoricalRefreshInterval)
+
+	// Wire handlers and register routes
+	app, err := router.New(cfg, pool, treasuryService)
+	if err != nil {
+		fatal("Invalid configuration", err)
 	}
 
-	// Add CORS middleware
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization"},
-		AllowCredentials: false,
-		MaxAge:           corsMaxAge,
-	}))
-
-	// Register routes
-	r.Get("/api/v1/users", userHandler.GetAllUsers)
-	r.Get("/api/v1/users/{userId}/transactions", txHandlers.GetUserTransactions)
-	r.Get("/api/v1/users/{id}/holdings", holdingsHandlers.GetUserHoldings)
-
-	// Historical yield data endpoint (must be registered before /api/yields)
-	r.Get("/api/yields/historical", yieldHandler.GetHistoricalYields)
-	// Current yield snapshot endpoint
-	r.Get("/api/yields", yieldHandler.GetYields)
-
-	r.Post("/api/v1/fund", txHandlers.FundHandler)
-	r.Post("/api/v1/withdraw", txHandlers.WithdrawHandler)
-	r.Post("/api/v1/buy", txHandlers.BuyHandler)
-	r.Post("/api/v1/sell", txHandlers.SellHandler)
-
-	// Health check route
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// Periodically correct remaining_amount drift from rounded partial sells (opt-in)
+	if cfg.ReconcileInterval > 0 {
+		app.TxService.StartReconciliation(ctx, cfg.ReconcileInterval)
+	}
 
 	// Configure server
 	server := &http.Server{
 		Addr:         serverPort,
-		Handler:      r,
+		Handler:      app.Handler,
 		ReadTimeout:  serverReadTimeout,
 		WriteTimeout: serverWriteTimeout,
 		IdleTimeout:  serverIdleTimeout,
@@ -155,9 +145,9 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting server on %s", server.Addr)
+		slog.Info("Starting server", "addr", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", err)
 		}
 	}()
 
@@ -166,7 +156,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	// Create shutdown context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
@@ -174,8 +164,19 @@ func main() {
 
 	// Attempt graceful shutdown
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("Server forced to shutdown", err)
+	}
+
+	// Let in-flight large transaction webhooks finish within the same shutdown window
+	if err := app.TxService.WaitForNotifications(ctx); err != nil {
+		slog.Error("Pending large transaction webhooks abandoned at shutdown", "error", err)
 	}
 
-	log.Println("Server exited")
+	slog.Info("Server exited")
+}
+
+// fatal logs msg with err at error level and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
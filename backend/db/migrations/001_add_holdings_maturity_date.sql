@@ -0,0 +1,38 @@
+-- ============================================================================
+-- Add holdings.maturity_date
+-- ============================================================================
+-- One of the upgrades for a database created from an earlier schema.sql, run in
+-- numbered order; on its own it adds only this column. Fresh databases get the
+-- column from schema.sql directly. Safe to run more than once.
+--
+-- Legacy rows are backfilled from purchase_date and term using the same day counts
+-- as utils.TermDurationDays, so stored dates match those the backend used to derive.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE holdings ADD COLUMN IF NOT EXISTS maturity_date TIMESTAMP;
+
+UPDATE holdings
+SET maturity_date = purchase_date + make_interval(days => CASE term
+    WHEN '1M' THEN 30
+    WHEN '2M' THEN 60
+    WHEN '3M' THEN 90
+    WHEN '4M' THEN 120
+    WHEN '6M' THEN 180
+    WHEN '1Y' THEN 365
+    WHEN '2Y' THEN 730
+    WHEN '5Y' THEN 1825
+    WHEN '10Y' THEN 3650
+    WHEN '30Y' THEN 10950
+END)
+WHERE maturity_date IS NULL;
+
+-- Fails, rolling back the whole migration, if any row has a term outside the list above
+ALTER TABLE holdings ALTER COLUMN maturity_date SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_holdings_maturity_date ON holdings(maturity_date);
+
+COMMENT ON COLUMN holdings.maturity_date IS 'When the holding matures, fixed at purchase from purchase_date and term';
+
+COMMIT;
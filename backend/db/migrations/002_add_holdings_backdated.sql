@@ -0,0 +1,25 @@
+-- ============================================================================
+-- Add holdings.backdated
+-- ============================================================================
+-- Adds the backdated flag to a database that already has holdings.maturity_date;
+-- fresh databases get the column from schema.sql directly. Safe to run more than once.
+--
+-- Existing holdings are backfilled as backdated when their purchase date falls on an
+-- earlier day than the buy transaction that created them, as as_of buys always did.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE holdings ADD COLUMN IF NOT EXISTS backdated BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE holdings h
+SET backdated = TRUE
+WHERE h.purchase_date::date < (
+    SELECT MIN(t.timestamp)::date
+    FROM transactions t
+    WHERE t.holding_id = h.id AND t.type = 'buy'
+);
+
+COMMENT ON COLUMN holdings.backdated IS 'Bought with an as_of date in backtest mode; only sold, matured, or rolled over in backtest mode';
+
+COMMIT;
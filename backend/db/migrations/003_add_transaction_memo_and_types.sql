@@ -0,0 +1,23 @@
+-- ============================================================================
+-- Add transactions.memo and the mature and cancel transaction types
+-- ============================================================================
+-- Upgrades a database created from an earlier schema.sql; fresh databases get both
+-- from schema.sql directly. Safe to run more than once.
+--
+-- The new enum values can't be used until this transaction commits, which nothing
+-- below does. Also adds the transaction indexes schema.sql has gained since.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'mature';
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'cancel';
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS memo TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_holding_id ON transactions(holding_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id_timestamp ON transactions(user_id, timestamp DESC);
+
+COMMENT ON COLUMN transactions.memo IS 'Reason recorded with admin balance adjustments';
+
+COMMIT;
@@ -0,0 +1,35 @@
+-- ============================================================================
+-- Add the holding_yield_corrections and idempotency_keys tables
+-- ============================================================================
+-- Upgrades a database created from an earlier schema.sql; fresh databases get the
+-- tables from schema.sql directly. Safe to run more than once.
+--
+-- idempotency_keys.operation uses the transaction_type enum, so run 003 first.
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS holding_yield_corrections (
+    id SERIAL PRIMARY KEY,
+    holding_id INTEGER NOT NULL REFERENCES holdings(id) ON DELETE CASCADE,
+    old_yield DECIMAL(5, 2) NOT NULL,
+    new_yield DECIMAL(5, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    corrected_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_holding_yield_corrections_holding_id ON holding_yield_corrections(holding_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    operation transaction_type NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+COMMENT ON TABLE holding_yield_corrections IS 'Audit trail of admin yield_at_purchase corrections';
+COMMENT ON TABLE idempotency_keys IS 'Fund/withdraw idempotency keys, scoped per user, honored for 24 hours';
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- ============================================================================
+-- Add idempotency_keys.amount
+-- ============================================================================
+-- Records the amount of the request that claimed each key, so a replay with a
+-- different amount is rejected instead of reported as applied. Fresh databases get
+-- the column from schema.sql directly. Safe to run more than once.
+--
+-- Keys claimed before this migration keep a null amount and are matched on their
+-- operation alone until they expire after 24 hours.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS amount DECIMAL(12, 2);
+
+COMMENT ON COLUMN idempotency_keys.amount IS 'Amount of the request that claimed the key; a replay with another amount is rejected';
+
+COMMIT;
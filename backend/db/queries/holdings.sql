@@ -8,9 +8,11 @@ INSERT INTO holdings (
     remaining_amount,
     face_value,
     purchase_price,
-    security_type
+    security_type,
+    maturity_date,
+    backdated
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetHoldingsByUser :many
@@ -18,12 +20,94 @@ SELECT * FROM holdings
 WHERE user_id = $1
 ORDER BY purchase_date DESC;
 
+-- name: GetMaturingHoldingsByUser :many
+SELECT * FROM holdings
+WHERE user_id = @user_id
+  AND COALESCE(remaining_amount, face_value, amount) > 0
+  AND maturity_date <= @maturing_by
+ORDER BY maturity_date ASC, id ASC;
+
+-- name: CountActiveHoldingsByUser :one
+SELECT COUNT(*) FROM holdings
+WHERE user_id = $1
+  AND COALESCE(remaining_amount, face_value, amount) > 0;
+
 -- name: GetHoldingByID :one
 SELECT * FROM holdings
 WHERE id = $1;
 
+-- name: GetHoldingByIDForUpdate :one
+SELECT * FROM holdings
+WHERE id = $1
+FOR UPDATE;
+
+-- name: DeleteHolding :exec
+DELETE FROM holdings
+WHERE id = $1;
+
+-- name: ListHoldingSellTotals :many
+SELECT
+    h.id,
+    h.amount,
+    h.face_value,
+    h.remaining_amount,
+    COALESCE(SUM(t.amount), 0)::numeric AS total_sold
+FROM holdings h
+LEFT JOIN transactions t ON t.holding_id = h.id AND t.type IN ('sell', 'mature')
+GROUP BY h.id
+ORDER BY h.id;
+
+-- name: GetHoldingSellTotal :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS total_sold
+FROM transactions
+WHERE holding_id = $1 AND type IN ('sell', 'mature');
+
 -- name: UpdateHoldingRemainingAmount :one
 UPDATE holdings
 SET remaining_amount = $2
 WHERE id = $1
 RETURNING *;
+
+-- name: FindSameDayHolding :one
+SELECT * FROM holdings
+WHERE user_id = @user_id
+  AND term = @term
+  AND yield_at_purchase = @yield_at_purchase
+  AND purchase_date >= @day_start
+  AND purchase_date < @day_end
+  AND face_value IS NOT NULL
+  AND purchase_price IS NOT NULL
+  AND backdated = @backdated
+ORDER BY purchase_date DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: AddToHolding :one
+UPDATE holdings
+SET amount = amount + @face_value_delta,
+    remaining_amount = remaining_amount + @face_value_delta,
+    face_value = face_value + @face_value_delta,
+    purchase_price = purchase_price + @purchase_price_delta
+WHERE id = @id
+RETURNING *;
+
+-- name: UpdateHoldingYield :one
+UPDATE holdings
+SET yield_at_purchase = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateHoldingYieldCorrection :one
+INSERT INTO holding_yield_corrections (
+    holding_id,
+    old_yield,
+    new_yield,
+    reason
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHoldingYieldCorrections :many
+SELECT * FROM holding_yield_corrections
+WHERE holding_id = $1
+ORDER BY corrected_at ASC, id ASC;
@@ -0,0 +1,16 @@
+-- name: ClaimIdempotencyKey :execrows
+-- Claims a key for a new operation. Affects no rows when the key was claimed within the last 24 hours,
+-- meaning the request is a replay; an expired key is reclaimed. Concurrent claims of the same key
+-- block on the primary key until the first transaction commits or rolls back.
+INSERT INTO idempotency_keys (user_id, idempotency_key, operation, amount)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET operation = EXCLUDED.operation,
+    amount = EXCLUDED.amount,
+    created_at = NOW()
+WHERE idempotency_keys.created_at < NOW() - INTERVAL '24 hours';
+
+-- name: GetIdempotencyKey :one
+SELECT user_id, idempotency_key, operation, amount, created_at
+FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2;
@@ -6,9 +6,10 @@ INSERT INTO transactions (
     amount,
     yield_at_transaction,
     balance_after,
-    holding_id
+    holding_id,
+    memo
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetTransactionsByUser :many
@@ -19,3 +20,24 @@ ORDER BY timestamp DESC;
 -- name: GetTransactionByID :one
 SELECT * FROM transactions
 WHERE id = $1;
+
+-- name: GetTransactionsByHolding :many
+SELECT * FROM transactions
+WHERE holding_id = $1
+ORDER BY timestamp ASC, id ASC;
+
+-- name: GetTransactionsByUserPaginated :many
+SELECT * FROM transactions
+WHERE user_id = @user_id
+  AND (sqlc.narg('from_time')::timestamp IS NULL OR timestamp >= sqlc.narg('from_time')::timestamp)
+  AND (sqlc.narg('to_time')::timestamp IS NULL OR timestamp < sqlc.narg('to_time')::timestamp)
+  AND (sqlc.narg('tx_type')::transaction_type IS NULL OR type = sqlc.narg('tx_type')::transaction_type)
+ORDER BY timestamp DESC, id DESC
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: CountTransactionsByUser :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = @user_id
+  AND (sqlc.narg('from_time')::timestamp IS NULL OR timestamp >= sqlc.narg('from_time')::timestamp)
+  AND (sqlc.narg('to_time')::timestamp IS NULL OR timestamp < sqlc.narg('to_time')::timestamp)
+  AND (sqlc.narg('tx_type')::transaction_type IS NULL OR type = sqlc.narg('tx_type')::transaction_type);
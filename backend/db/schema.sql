@@ -6,6 +6,8 @@
 -- ============================================================================
 
 -- Drop existing objects if they exist (for clean recreation)
+DROP TABLE IF EXISTS idempotency_keys CASCADE;
+DROP TABLE IF EXISTS holding_yield_corrections CASCADE;
 DROP TABLE IF EXISTS holdings CASCADE;
 DROP TABLE IF EXISTS transactions CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
@@ -15,8 +17,9 @@ DROP TYPE IF EXISTS transaction_type CASCADE;
 -- ENUMS
 -- ============================================================================
 
--- Transaction types: fund (deposit), withdraw, buy (treasury), sell (treasury)
-CREATE TYPE transaction_type AS ENUM ('fund', 'withdraw', 'buy', 'sell');
+-- Transaction types: fund (deposit), withdraw, buy (treasury), sell (treasury), mature (redemption at maturity),
+-- cancel (same-day buy unwound at its purchase price)
+CREATE TYPE transaction_type AS ENUM ('fund', 'withdraw', 'buy', 'sell', 'mature', 'cancel');
 
 -- ============================================================================
 -- TABLES
@@ -46,6 +49,7 @@ CREATE TABLE transactions (
     yield_at_transaction DECIMAL(5, 2),  -- Yield % at time of buy/sell - nullable for fund/withdraw
     balance_after DECIMAL(12, 2) NOT NULL,
     holding_id INTEGER,  -- References holding for sell transactions - nullable
+    memo TEXT,  -- Free-text reason for admin adjustments - nullable
 
     -- Constraints
     CONSTRAINT transactions_amount_positive CHECK (amount > 0)
@@ -64,6 +68,8 @@ CREATE TABLE holdings (
     face_value DECIMAL(12, 2),  -- Maturity value (for T-Bills with discount pricing)
     purchase_price DECIMAL(12, 2),  -- Actual price paid (discounted for T-Bills)
     security_type VARCHAR(10),  -- 'bill' (≤1Y), 'note' (2Y-10Y), 'bond' (30Y)
+    maturity_date TIMESTAMP NOT NULL,  -- purchase_date plus the term's duration in days
+    backdated BOOLEAN NOT NULL DEFAULT FALSE,  -- Bought with as_of in backtest mode
 
     -- Constraints
     CONSTRAINT holdings_amount_positive CHECK (amount > 0),
@@ -71,6 +77,29 @@ CREATE TABLE holdings (
     CONSTRAINT holdings_remaining_lte_amount CHECK (remaining_amount <= amount)
 );
 
+-- Holding Yield Corrections Table
+-- Audit trail of admin corrections to holdings.yield_at_purchase
+CREATE TABLE holding_yield_corrections (
+    id SERIAL PRIMARY KEY,
+    holding_id INTEGER NOT NULL REFERENCES holdings(id) ON DELETE CASCADE,
+    old_yield DECIMAL(5, 2) NOT NULL,
+    new_yield DECIMAL(5, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    corrected_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Idempotency Keys Table
+-- Client-supplied keys for fund/withdraw requests; a key replayed within 24 hours is not applied again
+CREATE TABLE idempotency_keys (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    operation transaction_type NOT NULL,
+    amount DECIMAL(12, 2),  -- Amount of the request that claimed the key - nullable for keys claimed before it was recorded
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
 -- ============================================================================
 -- INDEXES
 -- ============================================================================
@@ -82,10 +111,16 @@ CREATE INDEX idx_users_name ON users(name);
 CREATE INDEX idx_transactions_user_id ON transactions(user_id);
 CREATE INDEX idx_transactions_timestamp ON transactions(timestamp DESC);
 CREATE INDEX idx_transactions_type ON transactions(type);
+CREATE INDEX idx_transactions_holding_id ON transactions(holding_id);
+CREATE INDEX idx_transactions_user_id_timestamp ON transactions(user_id, timestamp DESC);  -- Paginated history
 
 -- Holdings table indexes
 CREATE INDEX idx_holdings_user_id ON holdings(user_id);
 CREATE INDEX idx_holdings_purchase_date ON holdings(purchase_date DESC);
+CREATE INDEX idx_holdings_maturity_date ON holdings(maturity_date);
+
+-- Holding yield corrections indexes
+CREATE INDEX idx_holding_yield_corrections_holding_id ON holding_yield_corrections(holding_id);
 
 -- ============================================================================
 -- COMMENTS
@@ -94,8 +129,14 @@ CREATE INDEX idx_holdings_purchase_date ON holdings(purchase_date DESC);
 COMMENT ON TABLE users IS 'User accounts with current balance';
 COMMENT ON TABLE transactions IS 'All financial transactions (deposits, withdrawals, treasury trades)';
 COMMENT ON TABLE holdings IS 'Active treasury holdings (bills, notes, bonds)';
+COMMENT ON TABLE holding_yield_corrections IS 'Audit trail of admin yield_at_purchase corrections';
+COMMENT ON TABLE idempotency_keys IS 'Fund/withdraw idempotency keys, scoped per user, honored for 24 hours';
 
 COMMENT ON COLUMN holdings.security_type IS 'Type of treasury security: bill (≤1Y), note (2Y-10Y), bond (30Y)';
 COMMENT ON COLUMN holdings.face_value IS 'Amount received at maturity (par value for T-Bills)';
 COMMENT ON COLUMN holdings.purchase_price IS 'Actual discounted price paid (for T-Bills)';
+COMMENT ON COLUMN holdings.maturity_date IS 'When the holding matures, fixed at purchase from purchase_date and term';
+COMMENT ON COLUMN holdings.backdated IS 'Bought with an as_of date in backtest mode; only sold, matured, or rolled over in backtest mode';
 COMMENT ON COLUMN transactions.holding_id IS 'References the holding being sold (for sell transactions)';
+COMMENT ON COLUMN idempotency_keys.amount IS 'Amount of the request that claimed the key; a replay with another amount is rejected';
+COMMENT ON COLUMN transactions.memo IS 'Reason recorded with admin balance adjustments';
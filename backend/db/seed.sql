@@ -87,29 +87,29 @@ INSERT INTO transactions (user_id, timestamp, type, term, amount, yield_at_trans
 -- ============================================================================
 
 -- Dylan Huff - Active Holdings (User ID: 1)
-INSERT INTO holdings (user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type) VALUES
+INSERT INTO holdings (user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date) VALUES
 -- 2Y Note from early 2023 (still holding)
-(1, '2Y', 150000.00, 4.90, '2023-01-20 14:12:45', 150000.00, 150000.00, 150000.00, 'note'),
+(1, '2Y', 150000.00, 4.90, '2023-01-20 14:12:45', 150000.00, 150000.00, 150000.00, 'note', '2025-01-19 14:12:45'),
 -- 2Y Note from Feb 2024
-(1, '2Y', 100000.00, 4.50, '2024-02-05 11:45:10', 100000.00, 100000.00, 100000.00, 'note'),
+(1, '2Y', 100000.00, 4.50, '2024-02-05 11:45:10', 100000.00, 100000.00, 100000.00, 'note', '2026-02-04 11:45:10'),
 -- Recent 1Y Bill
-(1, '1Y', 100000.00, 4.35, '2024-11-10 09:15:00', 100000.00, 104350.00, 100000.00, 'bill');
+(1, '1Y', 100000.00, 4.35, '2024-11-10 09:15:00', 100000.00, 104350.00, 100000.00, 'bill', '2025-11-10 09:15:00');
 
 -- Sarah Martinez - Active Holdings (User ID: 2)
-INSERT INTO holdings (user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type) VALUES
+INSERT INTO holdings (user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date) VALUES
 -- Original 30Y bond position (long-term hold)
-(2, '30Y', 100000.00, 4.00, '2022-06-15 15:00:00', 100000.00, 100000.00, 100000.00, 'bond'),
+(2, '30Y', 100000.00, 4.00, '2022-06-15 15:00:00', 100000.00, 100000.00, 100000.00, 'bond', '2052-06-07 15:00:00'),
 -- 10Y Note from March 2024
-(2, '10Y', 150000.00, 4.70, '2024-03-20 10:30:00', 150000.00, 150000.00, 150000.00, 'note'),
+(2, '10Y', 150000.00, 4.70, '2024-03-20 10:30:00', 150000.00, 150000.00, 150000.00, 'note', '2034-03-18 10:30:00'),
 -- Recent 5Y Note
-(2, '5Y', 85800.00, 4.40, '2024-10-15 11:00:00', 85800.00, 85800.00, 85800.00, 'note');
+(2, '5Y', 85800.00, 4.40, '2024-10-15 11:00:00', 85800.00, 85800.00, 85800.00, 'note', '2029-10-14 11:00:00');
 
 -- James Chen - Active Holdings (User ID: 3)
-INSERT INTO holdings (user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type) VALUES
+INSERT INTO holdings (user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date) VALUES
 -- Current 3M bill position
-(3, '3M', 150000.00, 4.80, '2024-06-20 14:00:00', 150000.00, 151800.00, 150000.00, 'bill'),
+(3, '3M', 150000.00, 4.80, '2024-06-20 14:00:00', 150000.00, 151800.00, 150000.00, 'bill', '2024-09-18 14:00:00'),
 -- Current 6M bill position
-(3, '6M', 150000.00, 4.60, '2024-10-01 09:30:00', 150000.00, 153450.00, 150000.00, 'bill');
+(3, '6M', 150000.00, 4.60, '2024-10-01 09:30:00', 150000.00, 153450.00, 150000.00, 'bill', '2025-03-30 09:30:00');
 
 -- ============================================================================
 -- VERIFICATION QUERIES (for testing)
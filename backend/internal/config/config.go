@@ -0,0 +1,531 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"modernfi-treasury-app/internal/utils"
+)
+
+const (
+	defaultDBMaxConns                   = 25
+	defaultDBMinConns                   = 5
+	defaultHistoricalMaxConcurrentPerIP = 2
+	defaultTransactionRateLimit         = 20
+	defaultMaxRequestBodyBytes          = 1 << 20 // 1 MiB
+	defaultSlowFetchThreshold           = 3 * time.Second
+	defaultYieldCacheDuration           = 1 * time.Hour
+	defaultHistoricalRefreshInterval    = 24 * time.Hour
+	defaultYieldMinPlausible            = -2.0
+	defaultYieldMaxPlausible            = 25.0
+	defaultReconcileThreshold           = 0.0
+	defaultMinFundAmount                = 1.00
+	defaultMaxActiveHoldings            = 500
+	defaultMinFaceValue                 = 100.0
+	defaultLargeTransactionThreshold    = 1_000_000.0
+	defaultSpendRounding                = utils.SpendRoundingMaxAffordable
+	defaultBillAccrual                  = utils.BillAccrualLinear
+	defaultBillDayCount                 = utils.DayCountThirty360
+	defaultNoteInterest                 = utils.NoteInterestSimple
+)
+
+// YIELD_SOURCE values: treasury.gov, or saved feeds in the directory after the prefix
+const (
+	yieldSourceTreasury   = "treasury"
+	yieldSourceFilePrefix = "file:"
+)
+
+// defaultAllowedOrigins are always allowed by CORS; CORS_ALLOWED_ORIGINS adds to them.
+// Since we're using nginx proxy in production, most requests come through same-origin,
+// but we still support direct API access for development and testing.
+var defaultAllowedOrigins = []string{
+	"http://localhost:5173", // Vite dev server (default port)
+	"http://localhost:5174", // Vite dev server (alternate port)
+	"http://localhost:80",   // Local Docker frontend
+	"http://localhost",      // Local Docker frontend (alt)
+	"http://localhost:3000", // Alternative dev port
+}
+
+// Config holds every setting read from the environment at startup.
+// Values are validated once by Load so a bad setting stops the server before it serves traffic.
+type Config struct {
+	DatabaseURL string
+	DBMaxConns  int32
+	DBMinConns  int32
+
+	AllowedOrigins []string
+	TrustedProxies []string // Proxy IPs or CIDRs whose X-Forwarded-For and X-Real-IP headers are believed
+
+	HistoricalPeriods            []string // Empty allows every supported period
+	HistoricalMaxConcurrentPerIP int
+	HistoricalMaxFailedYears     int // Years a multi-year fetch may skip on upstream errors; zero fails on any
+	TransactionRateLimit         int // Fund, withdraw, buy, and sell requests per minute per user (or IP)
+	MaxRequestBodyBytes          int // Larger request bodies are rejected with 413
+
+	SlowFetchThreshold        time.Duration
+	YieldCacheDuration        time.Duration
+	HistoricalCacheDuration   time.Duration // Zero keeps historical yields cached until restart
+	HistoricalRefreshInterval time.Duration
+	YieldMinPlausible         float64
+	YieldMaxPlausible         float64
+	YieldSourceDir            string // Saved yearly feeds served instead of treasury.gov (YIELD_SOURCE=file:<dir>); empty uses treasury.gov
+	YieldFallbackDir          string // Saved yearly feeds tried when treasury.gov fails; empty disables the fallback
+
+	ReconcileInterval  time.Duration // Zero disables periodic reconciliation
+	ReconcileThreshold float64       // Drift (in dollars) tolerated before a holding is corrected; zero corrects any whole-cent difference
+
+	MinFundAmount     float64
+	MaxTxAmount       float64 // Zero disables the per-transaction cap on funds and withdrawals
+	MinAccountBalance float64
+	MaxActiveHoldings int
+	MinFaceValue      float64
+	SpendRounding     utils.SpendRounding
+	ParPricing        bool // Charge face value for every buy, bills included
+	BacktestMode      bool // Allow backdated (as_of) buys and redeeming the holdings they create
+	BillAccrual       utils.BillAccrual
+	BillDayCount      utils.DayCountConvention
+	NoteInterest      utils.NoteInterest
+
+	AdminSecret string // Empty disables admin endpoints
+
+	LargeTransactionWebhookURL string  // Empty disables large transaction notifications
+	LargeTransactionThreshold  float64 // Dollars a transaction must exceed to be posted to the webhook
+
+	APIKeys map[string]int32 // Bearer token to user ID; empty disables API key authentication
+}
+
+// Load reads and validates the configuration from environment variables, applying defaults for unset ones
+func Load() (*Config, error) {
+	return load(os.Getenv)
+}
+
+// load builds a Config from getenv so tests can supply their own environment
+func load(getenv func(string) string) (*Config, error) {
+	cfg := &Config{
+		DBMaxConns:                   defaultDBMaxConns,
+		DBMinConns:                   defaultDBMinConns,
+		AllowedOrigins:               append([]string(nil), defaultAllowedOrigins...),
+		HistoricalMaxConcurrentPerIP: defaultHistoricalMaxConcurrentPerIP,
+		TransactionRateLimit:         defaultTransactionRateLimit,
+		MaxRequestBodyBytes:          defaultMaxRequestBodyBytes,
+		SlowFetchThreshold:           defaultSlowFetchThreshold,
+		YieldCacheDuration:           defaultYieldCacheDuration,
+		HistoricalRefreshInterval:    defaultHistoricalRefreshInterval,
+		YieldMinPlausible:            defaultYieldMinPlausible,
+		YieldMaxPlausible:            defaultYieldMaxPlausible,
+		ReconcileThreshold:           defaultReconcileThreshold,
+		MinFundAmount:                defaultMinFundAmount,
+		MaxActiveHoldings:            defaultMaxActiveHoldings,
+		MinFaceValue:                 defaultMinFaceValue,
+		SpendRounding:                defaultSpendRounding,
+		BillAccrual:                  defaultBillAccrual,
+		BillDayCount:                 defaultBillDayCount,
+		NoteInterest:                 defaultNoteInterest,
+		LargeTransactionThreshold:    defaultLargeTransactionThreshold,
+	}
+
+	cfg.DatabaseURL = getenv("DATABASE_URL")
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("DATABASE_URL environment variable not set")
+	}
+
+	if env := getenv("DB_MAX_CONNS"); env != "" {
+		n, err := parsePositiveInt("DB_MAX_CONNS", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.DBMaxConns = int32(n)
+	}
+	if env := getenv("DB_MIN_CONNS"); env != "" {
+		n, err := strconv.Atoi(env)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid DB_MIN_CONNS: %q must be a non-negative integer", env)
+		}
+		cfg.DBMinConns = int32(n)
+	}
+	if cfg.DBMinConns > cfg.DBMaxConns {
+		return nil, fmt.Errorf("DB_MIN_CONNS (%d) must not exceed DB_MAX_CONNS (%d)", cfg.DBMinConns, cfg.DBMaxConns)
+	}
+
+	cfg.AllowedOrigins = append(cfg.AllowedOrigins, splitList(getenv("CORS_ALLOWED_ORIGINS"))...)
+	cfg.HistoricalPeriods = splitList(getenv("HISTORICAL_PERIODS"))
+	cfg.TrustedProxies = splitList(getenv("TRUSTED_PROXIES"))
+
+	if env := getenv("HISTORICAL_MAX_CONCURRENT_PER_IP"); env != "" {
+		n, err := parsePositiveInt("HISTORICAL_MAX_CONCURRENT_PER_IP", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.HistoricalMaxConcurrentPerIP = n
+	}
+	if env := getenv("HISTORICAL_MAX_FAILED_YEARS"); env != "" {
+		n, err := strconv.Atoi(env)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid HISTORICAL_MAX_FAILED_YEARS: %q must be a non-negative integer", env)
+		}
+		cfg.HistoricalMaxFailedYears = n
+	}
+	if env := getenv("TRANSACTION_RATE_LIMIT"); env != "" {
+		n, err := parsePositiveInt("TRANSACTION_RATE_LIMIT", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TransactionRateLimit = n
+	}
+	if env := getenv("MAX_REQUEST_BODY_BYTES"); env != "" {
+		n, err := parsePositiveInt("MAX_REQUEST_BODY_BYTES", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MaxRequestBodyBytes = n
+	}
+
+	if env := getenv("SLOW_FETCH_THRESHOLD"); env != "" {
+		d, err := parsePositiveDuration("SLOW_FETCH_THRESHOLD", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SlowFetchThreshold = d
+	}
+
+	// An unusable yield cache duration isn't worth refusing to start over; the default is safe
+	if env := getenv("YIELD_CACHE_DURATION"); env != "" {
+		d, err := parsePositiveDuration("YIELD_CACHE_DURATION", env)
+		if err != nil {
+			slog.Warn("Ignoring invalid YIELD_CACHE_DURATION, using the default", "error", err, "default", defaultYieldCacheDuration)
+		} else {
+			cfg.YieldCacheDuration = d
+		}
+	}
+	if env := getenv("HISTORICAL_CACHE_DURATION"); env != "" {
+		d, err := parsePositiveDuration("HISTORICAL_CACHE_DURATION", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.HistoricalCacheDuration = d
+	}
+	if env := getenv("HISTORICAL_REFRESH_INTERVAL"); env != "" {
+		d, err := parsePositiveDuration("HISTORICAL_REFRESH_INTERVAL", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.HistoricalRefreshInterval = d
+	}
+
+	if env := getenv("YIELD_SOURCE"); env != "" {
+		dir, err := parseYieldSource(env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.YieldSourceDir = dir
+	}
+
+	cfg.YieldFallbackDir = getenv("YIELD_FALLBACK_DIR")
+
+	// The plausible band is only overridden as a pair so a half-set band can't silently keep one default
+	if envMin, envMax := getenv("YIELD_MIN_PLAUSIBLE"), getenv("YIELD_MAX_PLAUSIBLE"); envMin != "" || envMax != "" {
+		minYield, errMin := parseFinite(envMin)
+		maxYield, errMax := parseFinite(envMax)
+		if errMin != nil || errMax != nil {
+			return nil, fmt.Errorf("YIELD_MIN_PLAUSIBLE and YIELD_MAX_PLAUSIBLE must both be set to numbers")
+		}
+		if minYield >= maxYield {
+			return nil, fmt.Errorf("YIELD_MIN_PLAUSIBLE (%.2f) must be below YIELD_MAX_PLAUSIBLE (%.2f)", minYield, maxYield)
+		}
+		cfg.YieldMinPlausible = minYield
+		cfg.YieldMaxPlausible = maxYield
+	}
+
+	if env := getenv("RECONCILE_INTERVAL"); env != "" {
+		d, err := parsePositiveDuration("RECONCILE_INTERVAL", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ReconcileInterval = d
+	}
+	if env := getenv("RECONCILE_THRESHOLD"); env != "" {
+		threshold, err := parseNonNegative("RECONCILE_THRESHOLD", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ReconcileThreshold = threshold
+	}
+
+	if env := getenv("MIN_FUND_AMOUNT"); env != "" {
+		minFund, err := parseNonNegative("MIN_FUND_AMOUNT", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MinFundAmount = minFund
+	}
+
+	if env := getenv("MAX_TRANSACTION_AMOUNT"); env != "" {
+		maxAmount, err := parseNonNegative("MAX_TRANSACTION_AMOUNT", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MaxTxAmount = maxAmount
+	}
+
+	if env := getenv("MIN_ACCOUNT_BALANCE"); env != "" {
+		minBalance, err := parseNonNegative("MIN_ACCOUNT_BALANCE", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MinAccountBalance = minBalance
+	}
+
+	if env := getenv("MAX_ACTIVE_HOLDINGS"); env != "" {
+		n, err := parsePositiveInt("MAX_ACTIVE_HOLDINGS", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MaxActiveHoldings = n
+	}
+
+	if env := getenv("MIN_FACE_VALUE"); env != "" {
+		minFace, err := parseNonNegative("MIN_FACE_VALUE", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MinFaceValue = minFace
+	}
+
+	if env := getenv("BUY_SPEND_ROUNDING"); env != "" {
+		rounding, err := utils.ParseSpendRounding(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BUY_SPEND_ROUNDING: %w", err)
+		}
+		cfg.SpendRounding = rounding
+	}
+
+	if env := getenv("PAR_PRICING"); env != "" {
+		enabled, err := strconv.ParseBool(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PAR_PRICING: %q must be true or false", env)
+		}
+		cfg.ParPricing = enabled
+	}
+
+	if env := getenv("BACKTEST_MODE"); env != "" {
+		enabled, err := strconv.ParseBool(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BACKTEST_MODE: %q must be true or false", env)
+		}
+		cfg.BacktestMode = enabled
+	}
+
+	if env := getenv("BILL_ACCRUAL"); env != "" {
+		accrual, err := utils.ParseBillAccrual(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BILL_ACCRUAL: %w", err)
+		}
+		cfg.BillAccrual = accrual
+	}
+
+	if env := getenv("BILL_DAY_COUNT"); env != "" {
+		dayCount, err := utils.ParseDayCountConvention(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BILL_DAY_COUNT: %w", err)
+		}
+		cfg.BillDayCount = dayCount
+	}
+
+	if env := getenv("NOTE_INTEREST"); env != "" {
+		interest, err := utils.ParseNoteInterest(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NOTE_INTEREST: %w", err)
+		}
+		cfg.NoteInterest = interest
+	}
+
+	cfg.AdminSecret = getenv("ADMIN_SECRET")
+
+	cfg.LargeTransactionWebhookURL = getenv("LARGE_TRANSACTION_WEBHOOK_URL")
+	if env := getenv("LARGE_TRANSACTION_THRESHOLD"); env != "" {
+		threshold, err := parseNonNegative("LARGE_TRANSACTION_THRESHOLD", env)
+		if err != nil {
+			return nil, err
+		}
+		cfg.LargeTransactionThreshold = threshold
+	}
+
+	if env := getenv("API_KEYS"); env != "" {
+		keys, err := parseAPIKeys(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API_KEYS: %w", err)
+		}
+		cfg.APIKeys = keys
+	}
+
+	return cfg, nil
+}
+
+// redacted replaces secret values in the logged configuration
+const redacted = "[REDACTED]"
+
+// LogValue renders the effective configuration for structured logging, so operators can confirm
+// which settings are live. The admin secret and any database password are redacted; API keys are only counted,
+// and only the host of the webhook URL is shown since its path or query often carries a token.
+func (c *Config) LogValue() slog.Value {
+	adminSecret := ""
+	if c.AdminSecret != "" {
+		adminSecret = redacted
+	}
+
+	return slog.GroupValue(
+		slog.String("database_url", redactDatabaseURL(c.DatabaseURL)),
+		slog.Int("db_max_conns", int(c.DBMaxConns)),
+		slog.Int("db_min_conns", int(c.DBMinConns)),
+		slog.Any("allowed_origins", c.AllowedOrigins),
+		slog.Any("historical_periods", c.HistoricalPeriods),
+		slog.Any("trusted_proxies", c.TrustedProxies),
+		slog.Int("historical_max_concurrent_per_ip", c.HistoricalMaxConcurrentPerIP),
+		slog.Int("historical_max_failed_years", c.HistoricalMaxFailedYears),
+		slog.Int("transaction_rate_limit", c.TransactionRateLimit),
+		slog.Int("max_request_body_bytes", c.MaxRequestBodyBytes),
+		slog.Duration("slow_fetch_threshold", c.SlowFetchThreshold),
+		slog.Duration("yield_cache_duration", c.YieldCacheDuration),
+		slog.Duration("historical_cache_duration", c.HistoricalCacheDuration),
+		slog.Duration("historical_refresh_interval", c.HistoricalRefreshInterval),
+		slog.Float64("yield_min_plausible", c.YieldMinPlausible),
+		slog.Float64("yield_max_plausible", c.YieldMaxPlausible),
+		slog.String("yield_source", c.yieldSource()),
+		slog.String("yield_fallback_dir", c.YieldFallbackDir),
+		slog.Duration("reconcile_interval", c.ReconcileInterval),
+		slog.Float64("reconcile_threshold", c.ReconcileThreshold),
+		slog.Float64("min_fund_amount", c.MinFundAmount),
+		slog.Float64("max_transaction_amount", c.MaxTxAmount),
+		slog.Float64("min_account_balance", c.MinAccountBalance),
+		slog.Int("max_active_holdings", c.MaxActiveHoldings),
+		slog.Float64("min_face_value", c.MinFaceValue),
+		slog.String("spend_rounding", string(c.SpendRounding)),
+		slog.Bool("par_pricing", c.ParPricing),
+		slog.Bool("backtest_mode", c.BacktestMode),
+		slog.String("bill_accrual", string(c.BillAccrual)),
+		slog.String("bill_day_count", string(c.BillDayCount)),
+		slog.String("note_interest", string(c.NoteInterest)),
+		slog.String("admin_secret", adminSecret),
+		slog.String("large_transaction_webhook", redactWebhookURL(c.LargeTransactionWebhookURL)),
+		slog.Float64("large_transaction_threshold", c.LargeTransactionThreshold),
+		slog.Int("api_keys", len(c.APIKeys)),
+	)
+}
+
+// yieldSource renders the primary yield source as YIELD_SOURCE would name it
+func (c *Config) yieldSource() string {
+	if c.YieldSourceDir == "" {
+		return yieldSourceTreasury
+	}
+	return yieldSourceFilePrefix + c.YieldSourceDir
+}
+
+// redactDatabaseURL masks the password in a postgres URL (as "xxxxx"). Anything else, such as a keyword/value
+// connection string that may carry a password, is redacted entirely rather than risk logging it.
+func redactDatabaseURL(databaseURL string) string {
+	u, err := url.Parse(databaseURL)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return redacted
+	}
+	return u.Redacted()
+}
+
+// redactWebhookURL reduces a webhook URL to its scheme and host, or redacts it entirely if it doesn't parse
+func redactWebhookURL(webhookURL string) string {
+	if webhookURL == "" {
+		return ""
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// splitList splits a comma-separated value, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+// parseYieldSource parses YIELD_SOURCE: "treasury" for treasury.gov (returned as an empty directory)
+// or "file:<dir>" for saved feeds in dir
+func parseYieldSource(value string) (string, error) {
+	if value == yieldSourceTreasury {
+		return "", nil
+	}
+	dir, ok := strings.CutPrefix(value, yieldSourceFilePrefix)
+	if !ok || strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("invalid YIELD_SOURCE: %q must be %s or %s<dir>", value, yieldSourceTreasury, yieldSourceFilePrefix)
+	}
+	return dir, nil
+}
+
+// parseAPIKeys parses a comma-separated list of token:user_id pairs.
+// Tokens must be unique; one user may hold several tokens so keys can be rotated.
+func parseAPIKeys(value string) (map[string]int32, error) {
+	keys := make(map[string]int32)
+	for _, pair := range splitList(value) {
+		token, rawID, found := strings.Cut(pair, ":")
+		token = strings.TrimSpace(token)
+		if !found || token == "" {
+			return nil, fmt.Errorf("entries must be token:user_id pairs")
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(rawID), 10, 32)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("user_id %q must be a positive integer", rawID)
+		}
+		if _, dup := keys[token]; dup {
+			return nil, fmt.Errorf("duplicate token for user %d", id)
+		}
+		keys[token] = int32(id)
+	}
+	return keys, nil
+}
+
+func parsePositiveInt(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q must be a positive integer", name, value)
+	}
+	return n, nil
+}
+
+func parsePositiveDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q must be a positive duration (e.g. \"3s\")", name, value)
+	}
+	return d, nil
+}
+
+func parseNonNegative(name, value string) (float64, error) {
+	f, err := parseFinite(value)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid %s: %q must be a non-negative number", name, value)
+	}
+	return f, nil
+}
+
+// parseFinite parses a float, rejecting NaN and infinities that strconv otherwise accepts
+func parseFinite(value string) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", value)
+	}
+	return f, nil
+}
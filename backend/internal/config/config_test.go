@@ -0,0 +1,304 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"modernfi-treasury-app/internal/utils"
+)
+
+// envFrom returns a getenv function backed by vars, with DATABASE_URL set unless overridden
+func envFrom(vars map[string]string) func(string) string {
+	return func(key string) string {
+		if value, ok := vars[key]; ok {
+			return value
+		}
+		if key == "DATABASE_URL" {
+			return "postgres://localhost/treasury_db"
+		}
+		return ""
+	}
+}
+
+// TestLoad_Defaults tests that unset variables fall back to the documented defaults
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(envFrom(nil))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.DBMaxConns != 25 || cfg.DBMinConns != 5 {
+		t.Errorf("Expected pool 5-25 conns, got %d-%d", cfg.DBMinConns, cfg.DBMaxConns)
+	}
+	if cfg.SlowFetchThreshold != 3*time.Second {
+		t.Errorf("Expected slow fetch threshold 3s, got %v", cfg.SlowFetchThreshold)
+	}
+	if cfg.YieldCacheDuration != time.Hour || cfg.HistoricalCacheDuration != 0 {
+		t.Errorf("Expected 1h yield cache and permanent historical cache, got %v and %v", cfg.YieldCacheDuration, cfg.HistoricalCacheDuration)
+	}
+	if cfg.HistoricalRefreshInterval != 24*time.Hour {
+		t.Errorf("Expected daily historical refresh, got %v", cfg.HistoricalRefreshInterval)
+	}
+	if cfg.YieldMinPlausible != -2 || cfg.YieldMaxPlausible != 25 {
+		t.Errorf("Expected plausible band -2 to 25, got %v to %v", cfg.YieldMinPlausible, cfg.YieldMaxPlausible)
+	}
+	if cfg.MaxTxAmount != 0 || cfg.MinAccountBalance != 0 {
+		t.Errorf("Expected no transaction cap or minimum balance, got %v and %v", cfg.MaxTxAmount, cfg.MinAccountBalance)
+	}
+	if cfg.MinFundAmount != 1.00 || cfg.MaxActiveHoldings != 500 {
+		t.Errorf("Expected min fund 1.00 and max holdings 500, got %v and %d", cfg.MinFundAmount, cfg.MaxActiveHoldings)
+	}
+	if cfg.TransactionRateLimit != 20 {
+		t.Errorf("Expected 20 transaction requests per minute, got %d", cfg.TransactionRateLimit)
+	}
+	if cfg.MaxRequestBodyBytes != 1<<20 {
+		t.Errorf("Expected a 1 MiB request body limit, got %d", cfg.MaxRequestBodyBytes)
+	}
+	if cfg.SpendRounding != utils.SpendRoundingMaxAffordable {
+		t.Errorf("Expected max_affordable rounding, got %s", cfg.SpendRounding)
+	}
+	if cfg.BillAccrual != utils.BillAccrualLinear {
+		t.Errorf("Expected linear bill accrual, got %s", cfg.BillAccrual)
+	}
+	if cfg.BillDayCount != utils.DayCountThirty360 {
+		t.Errorf("Expected 30/360 bill day count, got %s", cfg.BillDayCount)
+	}
+	if cfg.NoteInterest != utils.NoteInterestSimple {
+		t.Errorf("Expected simple note interest, got %s", cfg.NoteInterest)
+	}
+	if cfg.HistoricalMaxFailedYears != 0 {
+		t.Errorf("Expected no failed years tolerated, got %d", cfg.HistoricalMaxFailedYears)
+	}
+	if cfg.LargeTransactionWebhookURL != "" || cfg.LargeTransactionThreshold != 1000000 {
+		t.Errorf("Expected webhook disabled with a $1M threshold, got %q and %v", cfg.LargeTransactionWebhookURL, cfg.LargeTransactionThreshold)
+	}
+	if cfg.ReconcileInterval != 0 || len(cfg.HistoricalPeriods) != 0 || len(cfg.TrustedProxies) != 0 || cfg.AdminSecret != "" || cfg.ParPricing || cfg.BacktestMode || len(cfg.APIKeys) != 0 || cfg.YieldFallbackDir != "" || cfg.YieldSourceDir != "" {
+		t.Errorf("Expected optional features disabled by default, got %+v", cfg)
+	}
+	if len(cfg.AllowedOrigins) != len(defaultAllowedOrigins) {
+		t.Errorf("Expected only default origins, got %v", cfg.AllowedOrigins)
+	}
+}
+
+// TestLoad_Overrides tests that set variables replace the defaults
+func TestLoad_Overrides(t *testing.T) {
+	cfg, err := load(envFrom(map[string]string{
+		"CORS_ALLOWED_ORIGINS":          "https://a.example, ,https://b.example",
+		"HISTORICAL_PERIODS":            "1W, 1M",
+		"TRUSTED_PROXIES":               "172.18.0.0/16, 10.0.0.5",
+		"RECONCILE_INTERVAL":            "24h",
+		"MIN_FUND_AMOUNT":               "0",
+		"MAX_TRANSACTION_AMOUNT":        "50000",
+		"MIN_ACCOUNT_BALANCE":           "100.50",
+		"BUY_SPEND_ROUNDING":            "nearest",
+		"PAR_PRICING":                   "true",
+		"BACKTEST_MODE":                 "true",
+		"BILL_ACCRUAL":                  "compound",
+		"BILL_DAY_COUNT":                "actual/360",
+		"NOTE_INTEREST":                 "compound",
+		"YIELD_CACHE_DURATION":          "15m",
+		"YIELD_FALLBACK_DIR":            "/var/lib/treasury/feeds",
+		"YIELD_SOURCE":                  "file:./testdata/yields",
+		"HISTORICAL_CACHE_DURATION":     "24h",
+		"HISTORICAL_REFRESH_INTERVAL":   "6h",
+		"API_KEYS":                      "key-one:1, key-two:2,key-three:2",
+		"TRANSACTION_RATE_LIMIT":        "5",
+		"MAX_REQUEST_BODY_BYTES":        "4096",
+		"HISTORICAL_MAX_FAILED_YEARS":   "3",
+		"LARGE_TRANSACTION_WEBHOOK_URL": "https://hooks.example.com/compliance",
+		"LARGE_TRANSACTION_THRESHOLD":   "250000",
+	}))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	origins := cfg.AllowedOrigins[len(defaultAllowedOrigins):]
+	if len(origins) != 2 || origins[0] != "https://a.example" || origins[1] != "https://b.example" {
+		t.Errorf("Expected two extra origins, got %v", origins)
+	}
+	if len(cfg.HistoricalPeriods) != 2 || cfg.HistoricalPeriods[1] != "1M" {
+		t.Errorf("Expected periods [1W 1M], got %v", cfg.HistoricalPeriods)
+	}
+	if len(cfg.TrustedProxies) != 2 || cfg.TrustedProxies[1] != "10.0.0.5" {
+		t.Errorf("Expected trusted proxies [172.18.0.0/16 10.0.0.5], got %v", cfg.TrustedProxies)
+	}
+	if cfg.ReconcileInterval != 24*time.Hour {
+		t.Errorf("Expected reconcile interval 24h, got %v", cfg.ReconcileInterval)
+	}
+	if cfg.MinFundAmount != 0 {
+		t.Errorf("Expected min fund 0, got %v", cfg.MinFundAmount)
+	}
+	if cfg.MaxTxAmount != 50000 || cfg.MinAccountBalance != 100.50 {
+		t.Errorf("Expected a 50000 transaction cap and 100.50 minimum balance, got %v and %v", cfg.MaxTxAmount, cfg.MinAccountBalance)
+	}
+	if cfg.SpendRounding != utils.SpendRoundingNearest {
+		t.Errorf("Expected nearest rounding, got %s", cfg.SpendRounding)
+	}
+	if !cfg.ParPricing {
+		t.Error("Expected par pricing enabled")
+	}
+	if !cfg.BacktestMode {
+		t.Error("Expected backtest mode enabled")
+	}
+	if cfg.BillAccrual != utils.BillAccrualCompound {
+		t.Errorf("Expected compound bill accrual, got %s", cfg.BillAccrual)
+	}
+	if cfg.BillDayCount != utils.DayCountActual360 {
+		t.Errorf("Expected actual/360 bill day count, got %s", cfg.BillDayCount)
+	}
+	if cfg.NoteInterest != utils.NoteInterestCompound {
+		t.Errorf("Expected compound note interest, got %s", cfg.NoteInterest)
+	}
+	if cfg.YieldCacheDuration != 15*time.Minute || cfg.HistoricalCacheDuration != 24*time.Hour {
+		t.Errorf("Expected 15m yield cache and 24h historical cache, got %v and %v", cfg.YieldCacheDuration, cfg.HistoricalCacheDuration)
+	}
+	if cfg.YieldFallbackDir != "/var/lib/treasury/feeds" {
+		t.Errorf("Expected yield fallback dir /var/lib/treasury/feeds, got %q", cfg.YieldFallbackDir)
+	}
+	if cfg.YieldSourceDir != "./testdata/yields" {
+		t.Errorf("Expected offline yield source ./testdata/yields, got %q", cfg.YieldSourceDir)
+	}
+	if cfg.HistoricalRefreshInterval != 6*time.Hour {
+		t.Errorf("Expected 6h historical refresh, got %v", cfg.HistoricalRefreshInterval)
+	}
+	if cfg.TransactionRateLimit != 5 {
+		t.Errorf("Expected 5 transaction requests per minute, got %d", cfg.TransactionRateLimit)
+	}
+	if cfg.MaxRequestBodyBytes != 4096 {
+		t.Errorf("Expected a 4096-byte request body limit, got %d", cfg.MaxRequestBodyBytes)
+	}
+	if cfg.HistoricalMaxFailedYears != 3 {
+		t.Errorf("Expected 3 failed years tolerated, got %d", cfg.HistoricalMaxFailedYears)
+	}
+	if cfg.LargeTransactionWebhookURL != "https://hooks.example.com/compliance" || cfg.LargeTransactionThreshold != 250000 {
+		t.Errorf("Expected webhook with a $250K threshold, got %q and %v", cfg.LargeTransactionWebhookURL, cfg.LargeTransactionThreshold)
+	}
+	if len(cfg.APIKeys) != 3 || cfg.APIKeys["key-one"] != 1 || cfg.APIKeys["key-three"] != 2 {
+		t.Errorf("Expected three API keys for users 1 and 2, got %v", cfg.APIKeys)
+	}
+}
+
+// TestLoad_RejectsInvalidValues tests that bad settings fail at load, naming the offending variable
+func TestLoad_RejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"missing database url", map[string]string{"DATABASE_URL": ""}, "DATABASE_URL"},
+		{"zero max conns", map[string]string{"DB_MAX_CONNS": "0"}, "DB_MAX_CONNS"},
+		{"min conns above max", map[string]string{"DB_MIN_CONNS": "30"}, "DB_MIN_CONNS"},
+		{"non-numeric concurrency", map[string]string{"HISTORICAL_MAX_CONCURRENT_PER_IP": "two"}, "HISTORICAL_MAX_CONCURRENT_PER_IP"},
+		{"negative max failed years", map[string]string{"HISTORICAL_MAX_FAILED_YEARS": "-1"}, "HISTORICAL_MAX_FAILED_YEARS"},
+		{"negative large transaction threshold", map[string]string{"LARGE_TRANSACTION_THRESHOLD": "-5"}, "LARGE_TRANSACTION_THRESHOLD"},
+		{"zero transaction rate limit", map[string]string{"TRANSACTION_RATE_LIMIT": "0"}, "TRANSACTION_RATE_LIMIT"},
+		{"zero request body limit", map[string]string{"MAX_REQUEST_BODY_BYTES": "0"}, "MAX_REQUEST_BODY_BYTES"},
+		{"negative slow fetch threshold", map[string]string{"SLOW_FETCH_THRESHOLD": "-1s"}, "SLOW_FETCH_THRESHOLD"},
+		{"unknown yield source", map[string]string{"YIELD_SOURCE": "fred"}, "YIELD_SOURCE"},
+		{"file yield source without a directory", map[string]string{"YIELD_SOURCE": "file:"}, "YIELD_SOURCE"},
+		{"zero historical cache duration", map[string]string{"HISTORICAL_CACHE_DURATION": "0s"}, "HISTORICAL_CACHE_DURATION"},
+		{"zero historical refresh interval", map[string]string{"HISTORICAL_REFRESH_INTERVAL": "0"}, "HISTORICAL_REFRESH_INTERVAL"},
+		{"half-set yield band", map[string]string{"YIELD_MIN_PLAUSIBLE": "-1"}, "YIELD_MAX_PLAUSIBLE"},
+		{"inverted yield band", map[string]string{"YIELD_MIN_PLAUSIBLE": "10", "YIELD_MAX_PLAUSIBLE": "5"}, "YIELD_MIN_PLAUSIBLE"},
+		{"zero reconcile interval", map[string]string{"RECONCILE_INTERVAL": "0s"}, "RECONCILE_INTERVAL"},
+		{"negative reconcile threshold", map[string]string{"RECONCILE_THRESHOLD": "-0.01"}, "RECONCILE_THRESHOLD"},
+		{"NaN min fund amount", map[string]string{"MIN_FUND_AMOUNT": "NaN"}, "MIN_FUND_AMOUNT"},
+		{"negative min fund amount", map[string]string{"MIN_FUND_AMOUNT": "-1"}, "MIN_FUND_AMOUNT"},
+		{"negative max transaction amount", map[string]string{"MAX_TRANSACTION_AMOUNT": "-1"}, "MAX_TRANSACTION_AMOUNT"},
+		{"non-numeric min account balance", map[string]string{"MIN_ACCOUNT_BALANCE": "lots"}, "MIN_ACCOUNT_BALANCE"},
+		{"zero max holdings", map[string]string{"MAX_ACTIVE_HOLDINGS": "0"}, "MAX_ACTIVE_HOLDINGS"},
+		{"negative min face value", map[string]string{"MIN_FACE_VALUE": "-100"}, "MIN_FACE_VALUE"},
+		{"unknown spend rounding", map[string]string{"BUY_SPEND_ROUNDING": "ceiling"}, "BUY_SPEND_ROUNDING"},
+		{"non-boolean par pricing", map[string]string{"PAR_PRICING": "sometimes"}, "PAR_PRICING"},
+		{"non-boolean backtest mode", map[string]string{"BACKTEST_MODE": "maybe"}, "BACKTEST_MODE"},
+		{"unknown bill accrual", map[string]string{"BILL_ACCRUAL": "simple"}, "BILL_ACCRUAL"},
+		{"unknown bill day count", map[string]string{"BILL_DAY_COUNT": "30/365"}, "BILL_DAY_COUNT"},
+		{"unknown note interest", map[string]string{"NOTE_INTEREST": "linear"}, "NOTE_INTEREST"},
+		{"API key without user", map[string]string{"API_KEYS": "key-one"}, "API_KEYS"},
+		{"API key with non-numeric user", map[string]string{"API_KEYS": "key-one:alice"}, "API_KEYS"},
+		{"duplicate API key", map[string]string{"API_KEYS": "key-one:1,key-one:2"}, "API_KEYS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(envFrom(tt.env))
+			if err == nil {
+				t.Fatalf("Expected error, got config %+v", cfg)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error mentioning %s, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestLoad_InvalidYieldCacheDuration tests that an unusable yield cache duration logs a warning and falls back to 1h
+func TestLoad_InvalidYieldCacheDuration(t *testing.T) {
+	for _, value := range []string{"15", "0s", "-5m"} {
+		t.Run(value, func(t *testing.T) {
+			var logs bytes.Buffer
+			previous := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			defer slog.SetDefault(previous)
+
+			cfg, err := load(envFrom(map[string]string{"YIELD_CACHE_DURATION": value}))
+			if err != nil {
+				t.Fatalf("Expected invalid YIELD_CACHE_DURATION to be ignored, got error: %v", err)
+			}
+			if cfg.YieldCacheDuration != time.Hour {
+				t.Errorf("Expected the 1h default, got %v", cfg.YieldCacheDuration)
+			}
+			if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "YIELD_CACHE_DURATION") {
+				t.Errorf("Expected a warning naming YIELD_CACHE_DURATION, got %q", logs.String())
+			}
+		})
+	}
+}
+
+// TestConfig_LogValueRedactsSecrets tests that logging the configuration never prints the admin secret, API keys, or database password
+func TestConfig_LogValueRedactsSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		databaseURL string
+		expectedURL string
+	}{
+		{"URL with password", "postgres://app:s3cr3t-pw@db:5432/treasury_db?sslmode=disable", "postgres://app:xxxxx@db:5432/treasury_db?sslmode=disable"},
+		{"URL without password", "postgres://localhost/treasury_db", "postgres://localhost/treasury_db"},
+		{"Keyword/value string", "host=db user=app password=s3cr3t-pw", "[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(envFrom(map[string]string{
+				"DATABASE_URL": tt.databaseURL,
+				"ADMIN_SECRET": "admin-t0ken",
+				"API_KEYS":     "user-t0ken:1",
+			}))
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			slog.New(slog.NewJSONHandler(&buf, nil)).Info("Effective configuration", "config", cfg)
+			out := buf.String()
+
+			for _, secret := range []string{"s3cr3t-pw", "admin-t0ken", "user-t0ken"} {
+				if strings.Contains(out, secret) {
+					t.Errorf("Expected %q to be redacted, got: %s", secret, out)
+				}
+			}
+			if !strings.Contains(out, `"database_url":"`+tt.expectedURL+`"`) {
+				t.Errorf("Expected database_url %s, got: %s", tt.expectedURL, out)
+			}
+			if !strings.Contains(out, `"admin_secret":"[REDACTED]"`) {
+				t.Errorf("Expected a redacted admin_secret, got: %s", out)
+			}
+			if !strings.Contains(out, `"max_active_holdings":500`) {
+				t.Errorf("Expected non-secret settings to be logged, got: %s", out)
+			}
+		})
+	}
+}
@@ -11,6 +11,55 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addToHolding = `-- name: AddToHolding :one
+UPDATE holdings
+SET amount = amount + $1,
+    remaining_amount = remaining_amount + $1,
+    face_value = face_value + $1,
+    purchase_price = purchase_price + $2
+WHERE id = $3
+RETURNING id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated
+`
+
+type AddToHoldingParams struct {
+	FaceValueDelta     pgtype.Numeric `json:"face_value_delta"`
+	PurchasePriceDelta pgtype.Numeric `json:"purchase_price_delta"`
+	ID                 int32          `json:"id"`
+}
+
+func (q *Queries) AddToHolding(ctx context.Context, arg AddToHoldingParams) (Holding, error) {
+	row := q.db.QueryRow(ctx, addToHolding, arg.FaceValueDelta, arg.PurchasePriceDelta, arg.ID)
+	var i Holding
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Term,
+		&i.Amount,
+		&i.YieldAtPurchase,
+		&i.PurchaseDate,
+		&i.RemainingAmount,
+		&i.FaceValue,
+		&i.PurchasePrice,
+		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
+	)
+	return i, err
+}
+
+const countActiveHoldingsByUser = `-- name: CountActiveHoldingsByUser :one
+SELECT COUNT(*) FROM holdings
+WHERE user_id = $1
+  AND COALESCE(remaining_amount, face_value, amount) > 0
+`
+
+func (q *Queries) CountActiveHoldingsByUser(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveHoldingsByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createHolding = `-- name: CreateHolding :one
 INSERT INTO holdings (
     user_id,
@@ -21,10 +70,12 @@ INSERT INTO holdings (
     remaining_amount,
     face_value,
     purchase_price,
-    security_type
+    security_type,
+    maturity_date,
+    backdated
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated
 `
 
 type CreateHoldingParams struct {
@@ -37,6 +88,8 @@ type CreateHoldingParams struct {
 	FaceValue       pgtype.Numeric   `json:"face_value"`
 	PurchasePrice   pgtype.Numeric   `json:"purchase_price"`
 	SecurityType    pgtype.Text      `json:"security_type"`
+	MaturityDate    pgtype.Timestamp `json:"maturity_date"`
+	Backdated       bool             `json:"backdated"`
 }
 
 func (q *Queries) CreateHolding(ctx context.Context, arg CreateHoldingParams) (Holding, error) {
@@ -50,6 +103,106 @@ func (q *Queries) CreateHolding(ctx context.Context, arg CreateHoldingParams) (H
 		arg.FaceValue,
 		arg.PurchasePrice,
 		arg.SecurityType,
+		arg.MaturityDate,
+		arg.Backdated,
+	)
+	var i Holding
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Term,
+		&i.Amount,
+		&i.YieldAtPurchase,
+		&i.PurchaseDate,
+		&i.RemainingAmount,
+		&i.FaceValue,
+		&i.PurchasePrice,
+		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
+	)
+	return i, err
+}
+
+const createHoldingYieldCorrection = `-- name: CreateHoldingYieldCorrection :one
+INSERT INTO holding_yield_corrections (
+    holding_id,
+    old_yield,
+    new_yield,
+    reason
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, holding_id, old_yield, new_yield, reason, corrected_at
+`
+
+type CreateHoldingYieldCorrectionParams struct {
+	HoldingID int32          `json:"holding_id"`
+	OldYield  pgtype.Numeric `json:"old_yield"`
+	NewYield  pgtype.Numeric `json:"new_yield"`
+	Reason    string         `json:"reason"`
+}
+
+func (q *Queries) CreateHoldingYieldCorrection(ctx context.Context, arg CreateHoldingYieldCorrectionParams) (HoldingYieldCorrection, error) {
+	row := q.db.QueryRow(ctx, createHoldingYieldCorrection,
+		arg.HoldingID,
+		arg.OldYield,
+		arg.NewYield,
+		arg.Reason,
+	)
+	var i HoldingYieldCorrection
+	err := row.Scan(
+		&i.ID,
+		&i.HoldingID,
+		&i.OldYield,
+		&i.NewYield,
+		&i.Reason,
+		&i.CorrectedAt,
+	)
+	return i, err
+}
+
+const deleteHolding = `-- name: DeleteHolding :exec
+DELETE FROM holdings
+WHERE id = $1
+`
+
+func (q *Queries) DeleteHolding(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, deleteHolding, id)
+	return err
+}
+
+const findSameDayHolding = `-- name: FindSameDayHolding :one
+SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated FROM holdings
+WHERE user_id = $1
+  AND term = $2
+  AND yield_at_purchase = $3
+  AND purchase_date >= $4
+  AND purchase_date < $5
+  AND face_value IS NOT NULL
+  AND purchase_price IS NOT NULL
+  AND backdated = $6
+ORDER BY purchase_date DESC
+LIMIT 1
+FOR UPDATE
+`
+
+type FindSameDayHoldingParams struct {
+	UserID          int32            `json:"user_id"`
+	Term            string           `json:"term"`
+	YieldAtPurchase pgtype.Numeric   `json:"yield_at_purchase"`
+	DayStart        pgtype.Timestamp `json:"day_start"`
+	DayEnd          pgtype.Timestamp `json:"day_end"`
+	Backdated       bool             `json:"backdated"`
+}
+
+func (q *Queries) FindSameDayHolding(ctx context.Context, arg FindSameDayHoldingParams) (Holding, error) {
+	row := q.db.QueryRow(ctx, findSameDayHolding,
+		arg.UserID,
+		arg.Term,
+		arg.YieldAtPurchase,
+		arg.DayStart,
+		arg.DayEnd,
+		arg.Backdated,
 	)
 	var i Holding
 	err := row.Scan(
@@ -63,12 +216,14 @@ func (q *Queries) CreateHolding(ctx context.Context, arg CreateHoldingParams) (H
 		&i.FaceValue,
 		&i.PurchasePrice,
 		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
 	)
 	return i, err
 }
 
 const getHoldingByID = `-- name: GetHoldingByID :one
-SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type FROM holdings
+SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated FROM holdings
 WHERE id = $1
 `
 
@@ -86,12 +241,73 @@ func (q *Queries) GetHoldingByID(ctx context.Context, id int32) (Holding, error)
 		&i.FaceValue,
 		&i.PurchasePrice,
 		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
+	)
+	return i, err
+}
+
+const getHoldingByIDForUpdate = `-- name: GetHoldingByIDForUpdate :one
+SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated FROM holdings
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetHoldingByIDForUpdate(ctx context.Context, id int32) (Holding, error) {
+	row := q.db.QueryRow(ctx, getHoldingByIDForUpdate, id)
+	var i Holding
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Term,
+		&i.Amount,
+		&i.YieldAtPurchase,
+		&i.PurchaseDate,
+		&i.RemainingAmount,
+		&i.FaceValue,
+		&i.PurchasePrice,
+		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
 	)
 	return i, err
 }
 
+const getHoldingYieldCorrections = `-- name: GetHoldingYieldCorrections :many
+SELECT id, holding_id, old_yield, new_yield, reason, corrected_at FROM holding_yield_corrections
+WHERE holding_id = $1
+ORDER BY corrected_at ASC, id ASC
+`
+
+func (q *Queries) GetHoldingYieldCorrections(ctx context.Context, holdingID int32) ([]HoldingYieldCorrection, error) {
+	rows, err := q.db.Query(ctx, getHoldingYieldCorrections, holdingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []HoldingYieldCorrection{}
+	for rows.Next() {
+		var i HoldingYieldCorrection
+		if err := rows.Scan(
+			&i.ID,
+			&i.HoldingID,
+			&i.OldYield,
+			&i.NewYield,
+			&i.Reason,
+			&i.CorrectedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getHoldingsByUser = `-- name: GetHoldingsByUser :many
-SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type FROM holdings
+SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated FROM holdings
 WHERE user_id = $1
 ORDER BY purchase_date DESC
 `
@@ -116,6 +332,54 @@ func (q *Queries) GetHoldingsByUser(ctx context.Context, userID int32) ([]Holdin
 			&i.FaceValue,
 			&i.PurchasePrice,
 			&i.SecurityType,
+			&i.MaturityDate,
+			&i.Backdated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMaturingHoldingsByUser = `-- name: GetMaturingHoldingsByUser :many
+SELECT id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated FROM holdings
+WHERE user_id = $1
+  AND COALESCE(remaining_amount, face_value, amount) > 0
+  AND maturity_date <= $2
+ORDER BY maturity_date ASC, id ASC
+`
+
+type GetMaturingHoldingsByUserParams struct {
+	UserID     int32            `json:"user_id"`
+	MaturingBy pgtype.Timestamp `json:"maturing_by"`
+}
+
+func (q *Queries) GetMaturingHoldingsByUser(ctx context.Context, arg GetMaturingHoldingsByUserParams) ([]Holding, error) {
+	rows, err := q.db.Query(ctx, getMaturingHoldingsByUser, arg.UserID, arg.MaturingBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Holding{}
+	for rows.Next() {
+		var i Holding
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Term,
+			&i.Amount,
+			&i.YieldAtPurchase,
+			&i.PurchaseDate,
+			&i.RemainingAmount,
+			&i.FaceValue,
+			&i.PurchasePrice,
+			&i.SecurityType,
+			&i.MaturityDate,
+			&i.Backdated,
 		); err != nil {
 			return nil, err
 		}
@@ -127,11 +391,71 @@ func (q *Queries) GetHoldingsByUser(ctx context.Context, userID int32) ([]Holdin
 	return items, nil
 }
 
+const listHoldingSellTotals = `-- name: ListHoldingSellTotals :many
+SELECT
+    h.id,
+    h.amount,
+    h.face_value,
+    h.remaining_amount,
+    COALESCE(SUM(t.amount), 0)::numeric AS total_sold
+FROM holdings h
+LEFT JOIN transactions t ON t.holding_id = h.id AND t.type IN ('sell', 'mature')
+GROUP BY h.id
+ORDER BY h.id
+`
+
+type ListHoldingSellTotalsRow struct {
+	ID              int32          `json:"id"`
+	Amount          pgtype.Numeric `json:"amount"`
+	FaceValue       pgtype.Numeric `json:"face_value"`
+	RemainingAmount pgtype.Numeric `json:"remaining_amount"`
+	TotalSold       pgtype.Numeric `json:"total_sold"`
+}
+
+func (q *Queries) ListHoldingSellTotals(ctx context.Context) ([]ListHoldingSellTotalsRow, error) {
+	rows, err := q.db.Query(ctx, listHoldingSellTotals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListHoldingSellTotalsRow{}
+	for rows.Next() {
+		var i ListHoldingSellTotalsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.FaceValue,
+			&i.RemainingAmount,
+			&i.TotalSold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHoldingSellTotal = `-- name: GetHoldingSellTotal :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS total_sold
+FROM transactions
+WHERE holding_id = $1 AND type IN ('sell', 'mature')
+`
+
+func (q *Queries) GetHoldingSellTotal(ctx context.Context, holdingID pgtype.Int4) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getHoldingSellTotal, holdingID)
+	var total_sold pgtype.Numeric
+	err := row.Scan(&total_sold)
+	return total_sold, err
+}
+
 const updateHoldingRemainingAmount = `-- name: UpdateHoldingRemainingAmount :one
 UPDATE holdings
 SET remaining_amount = $2
 WHERE id = $1
-RETURNING id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type
+RETURNING id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated
 `
 
 type UpdateHoldingRemainingAmountParams struct {
@@ -153,6 +477,40 @@ func (q *Queries) UpdateHoldingRemainingAmount(ctx context.Context, arg UpdateHo
 		&i.FaceValue,
 		&i.PurchasePrice,
 		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
+	)
+	return i, err
+}
+
+const updateHoldingYield = `-- name: UpdateHoldingYield :one
+UPDATE holdings
+SET yield_at_purchase = $2
+WHERE id = $1
+RETURNING id, user_id, term, amount, yield_at_purchase, purchase_date, remaining_amount, face_value, purchase_price, security_type, maturity_date, backdated
+`
+
+type UpdateHoldingYieldParams struct {
+	ID              int32          `json:"id"`
+	YieldAtPurchase pgtype.Numeric `json:"yield_at_purchase"`
+}
+
+func (q *Queries) UpdateHoldingYield(ctx context.Context, arg UpdateHoldingYieldParams) (Holding, error) {
+	row := q.db.QueryRow(ctx, updateHoldingYield, arg.ID, arg.YieldAtPurchase)
+	var i Holding
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Term,
+		&i.Amount,
+		&i.YieldAtPurchase,
+		&i.PurchaseDate,
+		&i.RemainingAmount,
+		&i.FaceValue,
+		&i.PurchasePrice,
+		&i.SecurityType,
+		&i.MaturityDate,
+		&i.Backdated,
 	)
 	return i, err
 }
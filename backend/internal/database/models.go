@@ -18,6 +18,8 @@ const (
 	TransactionTypeWithdraw TransactionType = "withdraw"
 	TransactionTypeBuy      TransactionType = "buy"
 	TransactionTypeSell     TransactionType = "sell"
+	TransactionTypeMature   TransactionType = "mature"
+	TransactionTypeCancel   TransactionType = "cancel"
 )
 
 func (e *TransactionType) Scan(src interface{}) error {
@@ -66,6 +68,25 @@ type Holding struct {
 	FaceValue       pgtype.Numeric   `json:"face_value"`
 	PurchasePrice   pgtype.Numeric   `json:"purchase_price"`
 	SecurityType    pgtype.Text      `json:"security_type"`
+	MaturityDate    pgtype.Timestamp `json:"maturity_date"`
+	Backdated       bool             `json:"backdated"`
+}
+
+type HoldingYieldCorrection struct {
+	ID          int32            `json:"id"`
+	HoldingID   int32            `json:"holding_id"`
+	OldYield    pgtype.Numeric   `json:"old_yield"`
+	NewYield    pgtype.Numeric   `json:"new_yield"`
+	Reason      string           `json:"reason"`
+	CorrectedAt pgtype.Timestamp `json:"corrected_at"`
+}
+
+type IdempotencyKey struct {
+	UserID         int32            `json:"user_id"`
+	IdempotencyKey string           `json:"idempotency_key"`
+	Operation      TransactionType  `json:"operation"`
+	Amount         pgtype.Numeric   `json:"amount"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
 }
 
 type Transaction struct {
@@ -78,6 +99,7 @@ type Transaction struct {
 	YieldAtTransaction pgtype.Numeric   `json:"yield_at_transaction"`
 	BalanceAfter       pgtype.Numeric   `json:"balance_after"`
 	HoldingID          pgtype.Int4      `json:"holding_id"`
+	Memo               pgtype.Text      `json:"memo"`
 }
 
 type User struct {
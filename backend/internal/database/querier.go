@@ -6,21 +6,42 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddToHolding(ctx context.Context, arg AddToHoldingParams) (Holding, error)
+	// Claims a key for a new operation. Affects no rows when the key was claimed within the last 24 hours,
+	// meaning the request is a replay; an expired key is reclaimed. Concurrent claims of the same key
+	// block on the primary key until the first transaction commits or rolls back.
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int64, error)
+	CountActiveHoldingsByUser(ctx context.Context, userID int32) (int64, error)
+	CountTransactionsByUser(ctx context.Context, arg CountTransactionsByUserParams) (int64, error)
 	CreateHolding(ctx context.Context, arg CreateHoldingParams) (Holding, error)
+	CreateHoldingYieldCorrection(ctx context.Context, arg CreateHoldingYieldCorrectionParams) (HoldingYieldCorrection, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteHolding(ctx context.Context, id int32) error
 	DeleteUser(ctx context.Context, id int32) error
+	FindSameDayHolding(ctx context.Context, arg FindSameDayHoldingParams) (Holding, error)
 	GetHoldingByID(ctx context.Context, id int32) (Holding, error)
+	GetHoldingByIDForUpdate(ctx context.Context, id int32) (Holding, error)
+	GetHoldingSellTotal(ctx context.Context, holdingID pgtype.Int4) (pgtype.Numeric, error)
+	GetHoldingYieldCorrections(ctx context.Context, holdingID int32) ([]HoldingYieldCorrection, error)
 	GetHoldingsByUser(ctx context.Context, userID int32) ([]Holding, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetMaturingHoldingsByUser(ctx context.Context, arg GetMaturingHoldingsByUserParams) ([]Holding, error)
 	GetTransactionByID(ctx context.Context, id int32) (Transaction, error)
+	GetTransactionsByHolding(ctx context.Context, holdingID pgtype.Int4) ([]Transaction, error)
 	GetTransactionsByUser(ctx context.Context, userID int32) ([]Transaction, error)
+	GetTransactionsByUserPaginated(ctx context.Context, arg GetTransactionsByUserPaginatedParams) ([]Transaction, error)
 	GetUser(ctx context.Context, id int32) (User, error)
 	GetUserForUpdate(ctx context.Context, id int32) (User, error)
+	ListHoldingSellTotals(ctx context.Context) ([]ListHoldingSellTotalsRow, error)
 	ListUsers(ctx context.Context) ([]User, error)
 	UpdateHoldingRemainingAmount(ctx context.Context, arg UpdateHoldingRemainingAmountParams) (Holding, error)
+	UpdateHoldingYield(ctx context.Context, arg UpdateHoldingYieldParams) (Holding, error)
 	UpdateUserBalance(ctx context.Context, arg UpdateUserBalanceParams) (User, error)
 }
 
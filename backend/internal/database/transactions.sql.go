@@ -11,6 +11,33 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countTransactionsByUser = `-- name: CountTransactionsByUser :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND ($2::timestamp IS NULL OR timestamp >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR timestamp < $3::timestamp)
+  AND ($4::transaction_type IS NULL OR type = $4::transaction_type)
+`
+
+type CountTransactionsByUserParams struct {
+	UserID   int32               `json:"user_id"`
+	FromTime pgtype.Timestamp    `json:"from_time"`
+	ToTime   pgtype.Timestamp    `json:"to_time"`
+	TxType   NullTransactionType `json:"tx_type"`
+}
+
+func (q *Queries) CountTransactionsByUser(ctx context.Context, arg CountTransactionsByUserParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countTransactionsByUser,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.TxType,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransaction = `-- name: CreateTransaction :one
 INSERT INTO transactions (
     user_id,
@@ -19,10 +46,11 @@ INSERT INTO transactions (
     amount,
     yield_at_transaction,
     balance_after,
-    holding_id
+    holding_id,
+    memo
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id, memo
 `
 
 type CreateTransactionParams struct {
@@ -33,6 +61,7 @@ type CreateTransactionParams struct {
 	YieldAtTransaction pgtype.Numeric  `json:"yield_at_transaction"`
 	BalanceAfter       pgtype.Numeric  `json:"balance_after"`
 	HoldingID          pgtype.Int4     `json:"holding_id"`
+	Memo               pgtype.Text     `json:"memo"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -44,6 +73,7 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.YieldAtTransaction,
 		arg.BalanceAfter,
 		arg.HoldingID,
+		arg.Memo,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -56,12 +86,13 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.YieldAtTransaction,
 		&i.BalanceAfter,
 		&i.HoldingID,
+		&i.Memo,
 	)
 	return i, err
 }
 
 const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id FROM transactions
+SELECT id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id, memo FROM transactions
 WHERE id = $1
 `
 
@@ -78,12 +109,50 @@ func (q *Queries) GetTransactionByID(ctx context.Context, id int32) (Transaction
 		&i.YieldAtTransaction,
 		&i.BalanceAfter,
 		&i.HoldingID,
+		&i.Memo,
 	)
 	return i, err
 }
 
+const getTransactionsByHolding = `-- name: GetTransactionsByHolding :many
+SELECT id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id, memo FROM transactions
+WHERE holding_id = $1
+ORDER BY timestamp ASC, id ASC
+`
+
+func (q *Queries) GetTransactionsByHolding(ctx context.Context, holdingID pgtype.Int4) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByHolding, holdingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Timestamp,
+			&i.Type,
+			&i.Term,
+			&i.Amount,
+			&i.YieldAtTransaction,
+			&i.BalanceAfter,
+			&i.HoldingID,
+			&i.Memo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransactionsByUser = `-- name: GetTransactionsByUser :many
-SELECT id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id FROM transactions
+SELECT id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id, memo FROM transactions
 WHERE user_id = $1
 ORDER BY timestamp DESC
 `
@@ -107,6 +176,64 @@ func (q *Queries) GetTransactionsByUser(ctx context.Context, userID int32) ([]Tr
 			&i.YieldAtTransaction,
 			&i.BalanceAfter,
 			&i.HoldingID,
+			&i.Memo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsByUserPaginated = `-- name: GetTransactionsByUserPaginated :many
+SELECT id, user_id, timestamp, type, term, amount, yield_at_transaction, balance_after, holding_id, memo FROM transactions
+WHERE user_id = $1
+  AND ($2::timestamp IS NULL OR timestamp >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR timestamp < $3::timestamp)
+  AND ($4::transaction_type IS NULL OR type = $4::transaction_type)
+ORDER BY timestamp DESC, id DESC
+LIMIT $5 OFFSET $6
+`
+
+type GetTransactionsByUserPaginatedParams struct {
+	UserID    int32               `json:"user_id"`
+	FromTime  pgtype.Timestamp    `json:"from_time"`
+	ToTime    pgtype.Timestamp    `json:"to_time"`
+	TxType    NullTransactionType `json:"tx_type"`
+	RowLimit  int32               `json:"row_limit"`
+	RowOffset int32               `json:"row_offset"`
+}
+
+func (q *Queries) GetTransactionsByUserPaginated(ctx context.Context, arg GetTransactionsByUserPaginatedParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByUserPaginated,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.TxType,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Timestamp,
+			&i.Type,
+			&i.Term,
+			&i.Amount,
+			&i.YieldAtTransaction,
+			&i.BalanceAfter,
+			&i.HoldingID,
+			&i.Memo,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"modernfi-treasury-app/internal/services"
+	"modernfi-treasury-app/internal/utils"
+)
+
+const (
+	// Header carrying the shared admin secret
+	adminSecretHeader = "X-Admin-Secret"
+
+	// Upper bound on adjustments per bulk request
+	maxBulkAdjustments = 500
+)
+
+// AdminHandlers handles HTTP requests for admin-only operations.
+// All endpoints require the X-Admin-Secret header to match the configured secret;
+// when no secret is configured, admin endpoints are disabled.
+type AdminHandlers struct {
+	txService       *services.TransactionService
+	treasuryService *services.TreasuryService
+	adminSecret     string
+}
+
+// NewAdminHandlers creates and returns a new AdminHandlers instance.
+func NewAdminHandlers(
+	txService *services.TransactionService,
+	treasuryService *services.TreasuryService,
+	adminSecret string,
+) *AdminHandlers {
+	return &AdminHandlers{
+		txService:       txService,
+		treasuryService: treasuryService,
+		adminSecret:     adminSecret,
+	}
+}
+
+// BulkAdjustment is one entry of a bulk adjustment request.
+// Positive amounts fund the user; negative amounts withdraw.
+type BulkAdjustment struct {
+	UserID int32   `json:"user_id"`
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// BulkAdjustRequest represents the incoming JSON request for bulk balance adjustments
+type BulkAdjustRequest struct {
+	Adjustments []BulkAdjustment `json:"adjustments"`
+}
+
+// YieldCorrectionRequest represents the incoming JSON request for correcting a holding's yield
+type YieldCorrectionRequest struct {
+	Yield  float64 `json:"yield"`
+	Reason string  `json:"reason"`
+}
+
+// authorize reports whether the request carries the admin secret, writing an error response if not
+func (h *AdminHandlers) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if h.adminSecret == "" {
+		respondWithError(w, http.StatusForbidden, "admin endpoints are disabled")
+		return false
+	}
+	provided := r.Header.Get(adminSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.adminSecret)) != 1 {
+		respondWithError(w, http.StatusUnauthorized, "invalid admin secret")
+		return false
+	}
+	return true
+}
+
+// RequireSecret is middleware restricting a non-admin route to callers with the admin secret
+func (h *AdminHandlers) RequireSecret(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.authorize(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// BulkAdjustHandler handles POST /api/v1/admin/bulk-adjust requests.
+// Expects JSON body with an adjustments array of {user_id, amount, reason}.
+// All adjustments are applied atomically; if any fails, none are applied.
+// Returns the updated users on success, or the first failure on error: 404 for an unknown user,
+// 400 for an invalid adjustment or insufficient balance, and 500 for anything else.
+func (h *AdminHandlers) BulkAdjustHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var req BulkAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding bulk adjust request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	if len(req.Adjustments) > maxBulkAdjustments {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("at most %d adjustments per request", maxBulkAdjustments))
+		return
+	}
+
+	adjustments := make([]services.BalanceAdjustment, 0, len(req.Adjustments))
+	for i, adj := range req.Adjustments {
+		amount, err := utils.FloatToNumeric(adj.Amount)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("adjustment %d: invalid amount format", i))
+			return
+		}
+		adjustments = append(adjustments, services.BalanceAdjustment{
+			UserID: adj.UserID,
+			Amount: amount,
+			Reason: adj.Reason,
+		})
+	}
+
+	users, err := h.txService.BulkAdjust(r.Context(), adjustments)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error applying bulk adjustments", "adjustments", len(adjustments), "error", err)
+		switch {
+		case errors.Is(err, services.ErrInvalidAdjustment),
+			errors.Is(err, services.ErrUserNotFound),
+			errors.Is(err, services.ErrInsufficientBalance):
+			respondWithServiceError(w, err)
+		default:
+			respondWithError(w, http.StatusInternalServerError, "failed to apply adjustments")
+		}
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Bulk adjustment successful", "adjustments", len(adjustments))
+
+	respondWithJSON(w, http.StatusOK, TransactionResponse{
+		Success: true,
+		User:    users,
+	})
+}
+
+// CorrectHoldingYieldHandler handles PATCH /api/v1/admin/holdings/{id}/yield requests.
+// Expects JSON body with yield (percent) and reason fields.
+// Overwrites the holding's yield_at_purchase and records an audit entry with the old and new values.
+// The new yield must be non-negative and fall inside the treasury service's plausible band.
+func (h *AdminHandlers) CorrectHoldingYieldHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	holdingID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req YieldCorrectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding yield correction request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	// Pricing rejects negative yields even when the plausible band admits them
+	if req.Yield < 0 || !h.treasuryService.IsPlausibleYield(req.Yield) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("yield %.2f is outside the plausible band", req.Yield))
+		return
+	}
+
+	newYield, err := utils.FloatToNumeric(req.Yield)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid yield format")
+		return
+	}
+
+	holding, correction, err := h.txService.CorrectHoldingYield(r.Context(), holdingID, newYield, req.Reason)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error correcting holding yield", "holding_id", holdingID, "error", err)
+		respondWithServiceError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"holding":    holding,
+		"correction": correction,
+	})
+}
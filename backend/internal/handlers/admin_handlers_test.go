@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"modernfi-treasury-app/internal/routes"
+	"modernfi-treasury-app/internal/services"
+)
+
+// TestBulkAdjustHandler_RequiresSecret tests that admin endpoints reject missing or wrong secrets
+func TestBulkAdjustHandler_RequiresSecret(t *testing.T) {
+	body := []byte(`{"adjustments": [{"user_id": 1, "amount": 10, "reason": "bonus"}]}`)
+
+	tests := []struct {
+		name     string
+		secret   string
+		header   string
+		expected int
+	}{
+		{"Disabled when no secret configured", "", "anything", http.StatusForbidden},
+		{"Missing header", "s3cret", "", http.StatusUnauthorized},
+		{"Wrong secret", "s3cret", "guess", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandlers(nil, nil, tt.secret)
+
+			req := httptest.NewRequest(http.MethodPost, routes.AdminBulkAdjust, bytes.NewReader(body))
+			if tt.header != "" {
+				req.Header.Set(adminSecretHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.BulkAdjustHandler(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+// TestCorrectHoldingYieldHandler_RejectsImplausibleYield tests yield validation before any database work
+func TestCorrectHoldingYieldHandler_RejectsImplausibleYield(t *testing.T) {
+	handler := NewAdminHandlers(nil, services.NewTreasuryService(), "s3cret")
+	router := chi.NewRouter()
+	router.Patch(routes.AdminHoldingYield, handler.CorrectHoldingYieldHandler)
+
+	for _, body := range []string{`{"yield": 93.5, "reason": "typo"}`, `{"yield": -0.5, "reason": "typo"}`} {
+		req := httptest.NewRequest(http.MethodPatch, routes.Path(routes.AdminHoldingYield, "1"), bytes.NewReader([]byte(body)))
+		req.Header.Set(adminSecretHeader, "s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Body %s: expected status 400, got %d", body, w.Code)
+		}
+	}
+}
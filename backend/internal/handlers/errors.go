@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"modernfi-treasury-app/internal/services"
+)
+
+// Machine-readable error codes returned in the code field of service error responses,
+// so clients can branch on the failure without matching the message text
+const (
+	ErrorCodeUserNotFound          = "user_not_found"
+	ErrorCodeHoldingNotFound       = "holding_not_found"
+	ErrorCodeUnauthorized          = "unauthorized"
+	ErrorCodeInsufficientBalance   = "insufficient_balance"
+	ErrorCodeInsufficientRemaining = "insufficient_remaining_amount"
+	ErrorCodeNotMatured            = "not_matured"
+	ErrorCodeBacktestDisabled      = "backtest_disabled"
+	ErrorCodeIdempotencyMismatch   = "idempotency_key_mismatch"
+	ErrorCodeInvalidRequest        = "invalid_request" // Any other rejected order
+)
+
+// serviceErrorStatus maps an error from the transaction services to its HTTP status and error code.
+// Errors without a sentinel are reported as bad requests.
+func serviceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound, ErrorCodeUserNotFound
+	case errors.Is(err, services.ErrHoldingNotFound):
+		return http.StatusNotFound, ErrorCodeHoldingNotFound
+	case errors.Is(err, services.ErrUnauthorized):
+		return http.StatusForbidden, ErrorCodeUnauthorized
+	case errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusBadRequest, ErrorCodeInsufficientBalance
+	case errors.Is(err, services.ErrInsufficientRemaining):
+		return http.StatusBadRequest, ErrorCodeInsufficientRemaining
+	case errors.Is(err, services.ErrNotMatured):
+		return http.StatusBadRequest, ErrorCodeNotMatured
+	case errors.Is(err, services.ErrBacktestDisabled):
+		return http.StatusForbidden, ErrorCodeBacktestDisabled
+	case errors.Is(err, services.ErrInvalidAdjustment):
+		return http.StatusBadRequest, ErrorCodeInvalidRequest
+	case errors.Is(err, services.ErrIdempotencyKeyMismatch):
+		return http.StatusUnprocessableEntity, ErrorCodeIdempotencyMismatch
+	default:
+		return http.StatusBadRequest, ErrorCodeInvalidRequest
+	}
+}
+
+// respondWithServiceError sends err's message with the status and code it maps to
+func respondWithServiceError(w http.ResponseWriter, err error) {
+	statusCode, code := serviceErrorStatus(err)
+	respondWithJSON(w, statusCode, TransactionResponse{
+		Success: false,
+		Error:   err.Error(),
+		Code:    code,
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"modernfi-treasury-app/internal/services"
+)
+
+// TestRespondWithServiceError tests that wrapped service errors map to their status and code, keeping the message
+func TestRespondWithServiceError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expected     string
+	}{
+		{"Unknown user", services.ErrUserNotFound, http.StatusNotFound, ErrorCodeUserNotFound},
+		{"Unknown holding", services.ErrHoldingNotFound, http.StatusNotFound, ErrorCodeHoldingNotFound},
+		{"Another user's holding", services.ErrUnauthorized, http.StatusForbidden, ErrorCodeUnauthorized},
+		{"Wrapped insufficient balance", fmt.Errorf("leg 1: %w: need 9775.00", services.ErrInsufficientBalance),
+			http.StatusBadRequest, ErrorCodeInsufficientBalance},
+		{"Wrapped insufficient remaining", fmt.Errorf("%w: requested 60.00, available 40.00", services.ErrInsufficientRemaining),
+			http.StatusBadRequest, ErrorCodeInsufficientRemaining},
+		{"Not matured", fmt.Errorf("%w: 12 days remaining", services.ErrNotMatured), http.StatusBadRequest, ErrorCodeNotMatured},
+		{"Reused idempotency key", fmt.Errorf("%w: claimed for fund 50.00", services.ErrIdempotencyKeyMismatch),
+			http.StatusUnprocessableEntity, ErrorCodeIdempotencyMismatch},
+		{"Invalid adjustment", fmt.Errorf("adjustment 0: %w: reason is required", services.ErrInvalidAdjustment),
+			http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{"Any other error", errors.New("invalid term: 7M"), http.StatusBadRequest, ErrorCodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			respondWithServiceError(w, tt.err)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			var resp TransactionResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Success || resp.Code != tt.expected || resp.Error != tt.err.Error() {
+				t.Errorf("Expected code %q with error %q, got %+v", tt.expected, tt.err, resp)
+			}
+		})
+	}
+}
+
+// TestServiceErrorStatus_DeeplyWrapped tests that not-found and unauthorized mapping survives several layers of wrapping,
+// as when a sell's transaction closure and the service both add context to the lookup error
+func TestServiceErrorStatus_DeeplyWrapped(t *testing.T) {
+	wrap := func(err error, layers int) error {
+		for i := 0; i < layers; i++ {
+			err = fmt.Errorf("layer %d: %w", i, err)
+		}
+		return err
+	}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"Holding not found, two layers", wrap(services.ErrHoldingNotFound, 2), http.StatusNotFound, ErrorCodeHoldingNotFound},
+		{"Holding not found, three layers", wrap(services.ErrHoldingNotFound, 3), http.StatusNotFound, ErrorCodeHoldingNotFound},
+		{"Unauthorized, two layers", wrap(services.ErrUnauthorized, 2), http.StatusForbidden, ErrorCodeUnauthorized},
+		{"Unauthorized, three layers", wrap(services.ErrUnauthorized, 3), http.StatusForbidden, ErrorCodeUnauthorized},
+		{"Joined with a rollback failure", wrap(errors.Join(services.ErrHoldingNotFound, errors.New("rollback failed")), 2),
+			http.StatusNotFound, ErrorCodeHoldingNotFound},
+		// Message text alone no longer maps: only the sentinel does
+		{"Lookalike message without the sentinel", errors.New("holding not found: no rows in result set"),
+			http.StatusBadRequest, ErrorCodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := serviceErrorStatus(tt.err)
+			if status != tt.expectedStatus || code != tt.expectedCode {
+				t.Errorf("Expected %d %q, got %d %q", tt.expectedStatus, tt.expectedCode, status, code)
+			}
+		})
+	}
+}
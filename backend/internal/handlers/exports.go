@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+)
+
+// transactionCSVHeader names the transaction export columns, in order
+var transactionCSVHeader = []string{
+	"timestamp", "type", "term", "amount", "yield_at_transaction", "balance_after", "holding_id",
+}
+
+// holdingCSVHeader names the holdings statement columns, in order
+var holdingCSVHeader = []string{
+	"holding_id", "purchase_date", "term", "security_type", "face_value", "purchase_price", "discount",
+	"remaining_amount", "cost_basis", "days_held", "current_value", "unrealized_gain_loss",
+}
+
+// ExportTransactionsCSV handles GET /api/v1/users/{userId}/transactions.csv requests.
+// Streams the user's full transaction ledger as a CSV download, oldest first.
+// Amounts, yields, and balances have two decimals; null terms, yields, and holding IDs are empty cells.
+// Returns HTTP 400 if the user ID is invalid, HTTP 500 for database errors.
+func (h *TransactionHandlers) ExportTransactionsCSV(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "userId")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transactions, err := h.queries.GetTransactionsByUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching transactions for export", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch transactions")
+		return
+	}
+
+	setCSVAttachmentHeaders(w, fmt.Sprintf("transactions-user-%d.csv", userID))
+	if err := writeTransactionsCSV(w, transactions); err != nil {
+		// Headers are already sent, so the client sees a truncated file
+		slog.ErrorContext(r.Context(), "Error writing transaction export", "user_id", userID, "error", err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Transactions exported", "user_id", userID, "rows", len(transactions))
+}
+
+// writeTransactionsCSV writes the header and one row per transaction.
+// Transactions arrive newest first (as GetTransactionsByUser returns them) and are written oldest first.
+func writeTransactionsCSV(out io.Writer, transactions []database.Transaction) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write(transactionCSVHeader); err != nil {
+		return err
+	}
+
+	for i := len(transactions) - 1; i >= 0; i-- {
+		tx := transactions[i]
+		row := []string{
+			formatTimestampCell(tx.Timestamp),
+			string(tx.Type),
+			formatTextCell(tx.Term),
+			formatNumericCell(tx.Amount),
+			formatNumericCell(tx.YieldAtTransaction),
+			formatNumericCell(tx.BalanceAfter),
+			formatInt4Cell(tx.HoldingID),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportHoldingsCSV handles GET /api/v1/users/{id}/holdings.csv requests.
+// Streams a statement of the user's active holdings as a CSV download, oldest purchase first.
+// Current values use the latest yields; when yields are unavailable the current value and gain/loss cells are empty.
+// Returns HTTP 400 if the user ID is invalid, HTTP 500 for database errors.
+func (h *HoldingsHandlers) ExportHoldingsCSV(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holdings, err := h.queries.GetHoldingsByUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching holdings for export", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	// Export without current values rather than failing, as GetUserHoldings does
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.WarnContext(r.Context(), "Error fetching yields for holdings export, omitting current values", "user_id", userID, "error", err)
+	}
+
+	views, err := buildHoldingViews(r.Context(), holdings, yieldData, h.billDayCount, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building holding views for export", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	setCSVAttachmentHeaders(w, fmt.Sprintf("holdings-user-%d.csv", userID))
+	if err := writeHoldingsCSV(w, views); err != nil {
+		// Headers are already sent, so the client sees a truncated file
+		slog.ErrorContext(r.Context(), "Error writing holdings export", "user_id", userID, "error", err)
+		return
+	}
+	slog.InfoContext(r.Context(), "Holdings exported", "user_id", userID, "rows", len(views))
+}
+
+// writeHoldingsCSV writes the header and one statement row per holding view.
+// Views arrive newest first (as GetHoldingsByUser orders them) and are written oldest first.
+// Face value, purchase price, and discount describe the original purchase; cost basis is the purchase
+// price of the unsold remainder, which the current value and unrealized gain/loss are measured against.
+func writeHoldingsCSV(out io.Writer, views []HoldingView) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write(holdingCSVHeader); err != nil {
+		return err
+	}
+
+	for i := len(views) - 1; i >= 0; i-- {
+		view := views[i]
+		faceValue, purchasePrice, err := holdingFaceAndPrice(view.Holding)
+		if err != nil {
+			return fmt.Errorf("holding %d: %w", view.ID, err)
+		}
+		remaining := numericToFloat(view.RemainingAmount)
+		costBasis := 0.0
+		if faceValue > 0 {
+			costBasis = math.Round(purchasePrice*remaining/faceValue*100) / 100
+		}
+
+		currentValue, gainLoss := "", ""
+		if view.CurrentValue != nil {
+			currentValue = formatAmountCell(*view.CurrentValue)
+			gainLoss = formatAmountCell(*view.CurrentValue - costBasis)
+		}
+
+		row := []string{
+			strconv.FormatInt(int64(view.ID), 10),
+			formatDateCell(view.PurchaseDate),
+			view.Term,
+			view.SecurityTypeLabel,
+			formatAmountCell(faceValue),
+			formatAmountCell(purchasePrice),
+			formatAmountCell(view.Discount),
+			formatAmountCell(remaining),
+			formatAmountCell(costBasis),
+			strconv.Itoa(view.DaysHeld),
+			currentValue,
+			gainLoss,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// setCSVAttachmentHeaders marks the response as a CSV file download named filename
+func setCSVAttachmentHeaders(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
+
+// formatNumericCell renders a numeric with two decimals, or an empty cell when null
+func formatNumericCell(n pgtype.Numeric) string {
+	if !n.Valid {
+		return ""
+	}
+	return formatAmountCell(numericToFloat(n))
+}
+
+// formatAmountCell renders an amount with two decimals, writing a rounded-away negative zero as 0.00
+func formatAmountCell(amount float64) string {
+	rounded := math.Round(amount*100) / 100
+	if rounded == 0 {
+		rounded = 0
+	}
+	return strconv.FormatFloat(rounded, 'f', 2, 64)
+}
+
+// formatTextCell renders a nullable text column, or an empty cell when null
+func formatTextCell(t pgtype.Text) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.String
+}
+
+// formatInt4Cell renders a nullable integer column, or an empty cell when null
+func formatInt4Cell(n pgtype.Int4) string {
+	if !n.Valid {
+		return ""
+	}
+	return strconv.FormatInt(int64(n.Int32), 10)
+}
+
+// formatTimestampCell renders a timestamp as RFC 3339 in UTC, or an empty cell when null
+func formatTimestampCell(ts pgtype.Timestamp) string {
+	if !ts.Valid {
+		return ""
+	}
+	return ts.Time.UTC().Format(time.RFC3339)
+}
+
+// formatDateCell renders a timestamp as a YYYY-MM-DD date in UTC, or an empty cell when null
+func formatDateCell(ts pgtype.Timestamp) string {
+	if !ts.Valid {
+		return ""
+	}
+	return ts.Time.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/routes"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// TestWriteTransactionsCSV tests column order, oldest-first rows, two-decimal numbers, and empty null cells
+func TestWriteTransactionsCSV(t *testing.T) {
+	fundedAt := time.Date(2025, 3, 10, 9, 30, 0, 0, time.UTC)
+	boughtAt := fundedAt.Add(24 * time.Hour)
+
+	// Newest first, as GetTransactionsByUser returns them
+	transactions := []database.Transaction{
+		{
+			Timestamp:          pgtype.Timestamp{Time: boughtAt, Valid: true},
+			Type:               database.TransactionTypeBuy,
+			Term:               pgtype.Text{String: "6M", Valid: true},
+			Amount:             mustNumeric("9775"),
+			YieldAtTransaction: mustNumeric("4.5"),
+			BalanceAfter:       mustNumeric("225.00"),
+			HoldingID:          pgtype.Int4{Int32: 42, Valid: true},
+		},
+		{
+			Timestamp:    pgtype.Timestamp{Time: fundedAt, Valid: true},
+			Type:         database.TransactionTypeFund,
+			Amount:       mustNumeric("10000.00"),
+			BalanceAfter: mustNumeric("10000.00"),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTransactionsCSV(&buf, transactions); err != nil {
+		t.Fatalf("writeTransactionsCSV failed: %v", err)
+	}
+
+	expected := "timestamp,type,term,amount,yield_at_transaction,balance_after,holding_id\n" +
+		"2025-03-10T09:30:00Z,fund,,10000.00,,10000.00,\n" +
+		"2025-03-11T09:30:00Z,buy,6M,9775.00,4.50,225.00,42\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+// TestWriteTransactionsCSV_Empty tests that a user with no transactions gets just the header
+func TestWriteTransactionsCSV_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTransactionsCSV(&buf, nil); err != nil {
+		t.Fatalf("writeTransactionsCSV failed: %v", err)
+	}
+	if buf.String() != "timestamp,type,term,amount,yield_at_transaction,balance_after,holding_id\n" {
+		t.Errorf("Expected header only, got %q", buf.String())
+	}
+}
+
+// TestWriteHoldingsCSV tests statement columns, oldest-first rows, cost basis of the unsold remainder, and gain/loss
+func TestWriteHoldingsCSV(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	// Half-sold 6M bill bought at 9775.00; valued at 4955.00 against a 4887.50 cost basis
+	bill := testHolding(1, "6M", "bill", "5000.00", "4.50", now, 90)
+	bill.FaceValue = mustNumeric("10000.00")
+	bill.PurchasePrice = mustNumeric("9775.00")
+
+	// Legacy 2Y note bought at par a year ago; valued at 10384.62
+	legacyNote := testHolding(2, "2Y", "", "10000.00", "4.00", now, 365)
+
+	// Newest first, as GetHoldingsByUser returns them
+	views, err := buildHoldingViews(context.Background(), []database.Holding{bill, legacyNote}, testYieldData(map[string]float64{"6M": 3.60, "2Y": 4.00}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildHoldingViews failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeHoldingsCSV(&buf, views); err != nil {
+		t.Fatalf("writeHoldingsCSV failed: %v", err)
+	}
+
+	expected := "holding_id,purchase_date,term,security_type,face_value,purchase_price,discount,remaining_amount,cost_basis,days_held,current_value,unrealized_gain_loss\n" +
+		"2,2024-03-14,2Y,Treasury Note,10000.00,10000.00,0.00,10000.00,10000.00,365,10384.62,384.62\n" +
+		"1,2024-12-14,6M,Treasury Bill,10000.00,9775.00,225.00,5000.00,4887.50,90,4955.00,67.50\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+// TestWriteHoldingsCSV_NoYields tests that current value and gain/loss are empty cells when yields are unavailable
+func TestWriteHoldingsCSV_NoYields(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	views, err := buildHoldingViews(context.Background(), []database.Holding{testHolding(1, "6M", "bill", "10000.00", "4.50", now, 30)}, nil, utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildHoldingViews failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeHoldingsCSV(&buf, views); err != nil {
+		t.Fatalf("writeHoldingsCSV failed: %v", err)
+	}
+
+	// Repriced from its 4.50% yield: 10000 × (1 - 0.045 × 180/360) = 9775.00
+	expected := "holding_id,purchase_date,term,security_type,face_value,purchase_price,discount,remaining_amount,cost_basis,days_held,current_value,unrealized_gain_loss\n" +
+		"1,2025-02-12,6M,Treasury Bill,10000.00,9775.00,225.00,10000.00,9775.00,30,,\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+// TestExportTransactionsCSV_InvalidUserID tests that bad user IDs get a JSON 400 rather than a CSV
+func TestExportTransactionsCSV_InvalidUserID(t *testing.T) {
+	handler := NewTransactionHandlers(nil, nil, nil)
+	router := chi.NewRouter()
+	router.Get(routes.UserTransactionsCSV, handler.ExportTransactionsCSV)
+
+	req := httptest.NewRequest(http.MethodGet, routes.Path(routes.UserTransactionsCSV, "abc"), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp TransactionResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error != "userId must be a positive integer" {
+		t.Errorf("Expected userId error, got %q", resp.Error)
+	}
+}
+
+// TestExportHoldingsCSV_InvalidUserID tests that bad user IDs get a JSON 400 rather than a CSV
+func TestExportHoldingsCSV_InvalidUserID(t *testing.T) {
+	handler := NewHoldingsHandlers(nil, nil)
+	router := chi.NewRouter()
+	router.Get(routes.UserHoldingsCSV, handler.ExportHoldingsCSV)
+
+	req := httptest.NewRequest(http.MethodGet, routes.Path(routes.UserHoldingsCSV, "0"), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp TransactionResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error != "id must be a positive integer" {
+		t.Errorf("Expected id error, got %q", resp.Error)
+	}
+}
@@ -1,64 +1,468 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"log"
-	"math/big"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/services"
+	"modernfi-treasury-app/internal/utils"
+)
+
+const (
+	// Maturity alert window bounds (days)
+	defaultMaturityAlertDays = 14
+	maxMaturityAlertDays     = 365
+
+	// Maturing holdings window bounds (days)
+	defaultMaturingWindowDays = 30
+	maxMaturingWindowDays     = 365
 )
 
 // HoldingsHandlers handles HTTP requests for holdings operations.
 type HoldingsHandlers struct {
-	queries *database.Queries
+	queries         *database.Queries
+	treasuryService *services.TreasuryService
+	billAccrual     utils.BillAccrual
+	billDayCount    utils.DayCountConvention
+	noteInterest    utils.NoteInterest
 }
 
 // NewHoldingsHandlers creates and returns a new HoldingsHandlers instance.
-func NewHoldingsHandlers(queries *database.Queries) *HoldingsHandlers {
+func NewHoldingsHandlers(queries *database.Queries, treasuryService *services.TreasuryService) *HoldingsHandlers {
 	return &HoldingsHandlers{
-		queries: queries,
+		queries:         queries,
+		treasuryService: treasuryService,
+		billAccrual:     utils.BillAccrualLinear,
+		billDayCount:    utils.DayCountThirty360,
+		noteInterest:    utils.NoteInterestSimple,
+	}
+}
+
+// SetBillAccrual sets how the lifecycle view prices an early bill sale (linear or compound); it should match the
+// transaction service so recomputed proceeds agree with what was paid.
+func (h *HoldingsHandlers) SetBillAccrual(model string) error {
+	accrual, err := utils.ParseBillAccrual(model)
+	if err != nil {
+		return err
+	}
+	h.billAccrual = accrual
+	return nil
+}
+
+// SetBillDayCount sets the day count convention bills are marked to market with (30/360, actual/360, or actual/actual);
+// it should match the transaction service so current values agree with what a buy would cost.
+func (h *HoldingsHandlers) SetBillDayCount(convention string) error {
+	dayCount, err := utils.ParseDayCountConvention(convention)
+	if err != nil {
+		return err
 	}
+	h.billDayCount = dayCount
+	return nil
+}
+
+// SetNoteInterest sets how the lifecycle view prices an early note or bond sale (simple or compound); it should
+// match the transaction service so recomputed proceeds agree with what was paid.
+func (h *HoldingsHandlers) SetNoteInterest(model string) error {
+	interest, err := utils.ParseNoteInterest(model)
+	if err != nil {
+		return err
+	}
+	h.noteInterest = interest
+	return nil
 }
 
 // GetUserHoldings handles GET /api/v1/users/{id}/holdings requests.
-// Returns all holdings for the specified user where remaining_amount > 0.
+// Returns all holdings for the specified user where remaining_amount > 0; legacy holdings with a null
+// remaining_amount are returned at their face value.
 // Holdings are ordered by purchase_date DESC (most recent first).
+// Each holding includes its investment_yield so bills and notes can be compared on one basis,
+// plus its discount, days held, and current_value at the latest yields (null if yields are unavailable).
 func (h *HoldingsHandlers) GetUserHoldings(w http.ResponseWriter, r *http.Request) {
 	// Parse user ID from URL parameter
-	userIDStr := chi.URLParam(r, "id")
-	userID, err := strconv.ParseInt(userIDStr, 10, 32)
+	userID, err := parseIDParam(r, "id")
 	if err != nil {
-		log.Printf("Invalid user ID: %s", userIDStr)
-		respondWithError(w, http.StatusBadRequest, "invalid user ID")
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	// Fetch all holdings for user using existing sqlc query
-	holdings, err := h.queries.GetHoldingsByUser(r.Context(), int32(userID))
+	holdings, err := h.queries.GetHoldingsByUser(r.Context(), userID)
 	if err != nil {
-		log.Printf("Error fetching holdings for user %d: %v", userID, err)
+		slog.ErrorContext(r.Context(), "Error fetching holdings", "user_id", userID, "error", err)
 		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
 		return
 	}
 
-	// Filter holdings to only include those with remaining_amount > 0
-	// Also handle legacy data by providing fallback values
-	activeHoldings := []database.Holding{}
-	zero := big.NewInt(0)
-	for _, holding := range holdings {
-		// Check if remaining_amount is valid and > 0
-		if holding.RemainingAmount.Valid && holding.RemainingAmount.Int.Cmp(zero) > 0 {
-			activeHoldings = append(activeHoldings, holding)
-		}
+	// Current values need the latest yields; list holdings without them rather than failing
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.WarnContext(r.Context(), "Error fetching yields for holdings, omitting current values", "user_id", userID, "error", err)
+	}
+
+	activeHoldings, err := buildHoldingViews(r.Context(), holdings, yieldData, h.billDayCount, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building holding views", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
 	}
 
 	// Return active holdings (empty array if no holdings with remaining_amount > 0)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(activeHoldings); err != nil {
-		log.Printf("Error encoding holdings response: %v", err)
+		slog.ErrorContext(r.Context(), "Error encoding holdings response", "error", err)
+	}
+}
+
+// GetMaturityAlerts handles GET /api/v1/users/{id}/maturity-alerts requests.
+// Query parameter: within_days (1-365) - defaults to 14.
+// Returns active holdings maturing within the window with days remaining and expected proceeds,
+// soonest first. Returns an empty array when nothing matures in the window.
+func (h *HoldingsHandlers) GetMaturityAlerts(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	withinDays := defaultMaturityAlertDays
+	if withinStr := r.URL.Query().Get("within_days"); withinStr != "" {
+		withinDays, err = strconv.Atoi(withinStr)
+		if err != nil || withinDays < 1 || withinDays > maxMaturityAlertDays {
+			respondWithError(w, http.StatusBadRequest, "within_days must be an integer between 1 and 365")
+			return
+		}
+	}
+
+	holdings, err := h.queries.GetHoldingsByUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching holdings", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	alerts, err := buildMaturityAlerts(r.Context(), holdings, time.Now(), withinDays)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building maturity alerts", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to compute maturity alerts")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, alerts)
+}
+
+// GetMaturingHoldings handles GET /api/v1/users/{id}/holdings/maturing requests.
+// Query parameter: days (1-365) - defaults to 30.
+// Returns active holdings whose stored maturity date falls within the window, including any already matured
+// but not yet redeemed, soonest first, each with its projected payout at maturity.
+func (h *HoldingsHandlers) GetMaturingHoldings(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	days := defaultMaturingWindowDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > maxMaturingWindowDays {
+			respondWithError(w, http.StatusBadRequest, "days must be an integer between 1 and 365")
+			return
+		}
+	}
+
+	now := time.Now()
+	holdings, err := h.queries.GetMaturingHoldingsByUser(r.Context(), database.GetMaturingHoldingsByUserParams{
+		UserID:     userID,
+		MaturingBy: pgtype.Timestamp{Time: now.AddDate(0, 0, days), Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching maturing holdings", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	maturing, err := buildMaturingHoldings(r.Context(), holdings, now)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building maturing holdings", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to compute maturity payouts")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, maturing)
+}
+
+// GetPortfolioSummary handles GET /api/v1/users/{id}/portfolio requests.
+// Returns totals for the user's active holdings (cost basis, remaining face value, and market value
+// at the latest yields), the same totals broken down by security type (bill/note/bond), and
+// projected interest income over the next 30, 90, and 365 days.
+func (h *HoldingsHandlers) GetPortfolioSummary(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holdings, err := h.queries.GetHoldingsByUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching holdings", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		return
+	}
+
+	summary, err := buildPortfolioSummary(r.Context(), holdings, yieldData, h.billDayCount, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building portfolio summary", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to compute portfolio summary")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// GetTermPosition handles GET /api/v1/users/{id}/positions/{term} requests.
+// Returns the user's aggregate position in the term: holding count, remaining face value, yield at purchase
+// weighted by remaining face value, nearest maturity, and current value at the latest yields.
+// A term the user holds nothing in returns a zeroed position rather than 404.
+// Returns HTTP 400 for an invalid user ID or term, HTTP 500 for database or yield errors.
+func (h *HoldingsHandlers) GetTermPosition(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	term, err := utils.NormalizeTerm(chi.URLParam(r, "term"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holdings, err := h.queries.GetHoldingsByUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching holdings", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		return
+	}
+
+	position, err := buildTermPosition(r.Context(), holdings, term, yieldData, h.billDayCount, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building term position", "user_id", userID, "term", term, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to compute position")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, position)
+}
+
+// GetYieldComparison handles GET /api/v1/users/{id}/yield-comparison requests.
+// Returns each active holding's yield at purchase against today's yield for its term, the difference
+// in basis points, and whether the holding beats the market (worth holding) or underperforms (a candidate to roll).
+func (h *HoldingsHandlers) GetYieldComparison(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holdings, err := h.queries.GetHoldingsByUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching holdings", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holdings")
+		return
+	}
+
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		return
+	}
+
+	comparison, err := buildYieldComparison(r.Context(), holdings, yieldData)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building yield comparison", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to compute yield comparison")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
+
+// loadOwnedHolding fetches the holding named by the id path parameter on behalf of the requesting user
+// (user_id query parameter or X-User-ID header), writing an error response and returning false when
+// either ID is invalid (400), the holding does not exist (404), or it belongs to another user (403)
+func (h *HoldingsHandlers) loadOwnedHolding(w http.ResponseWriter, r *http.Request) (database.Holding, bool) {
+	holdingID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return database.Holding{}, false
+	}
+
+	userID, err := parseRequestUserID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return database.Holding{}, false
+	}
+
+	holding, err := h.queries.GetHoldingByID(r.Context(), holdingID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respondWithServiceError(w, services.ErrHoldingNotFound)
+			return database.Holding{}, false
+		}
+		slog.ErrorContext(r.Context(), "Error fetching holding", "holding_id", holdingID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holding")
+		return database.Holding{}, false
+	}
+
+	// Security check: don't reveal other users' positions
+	if holding.UserID != userID {
+		respondWithServiceError(w, services.ErrUnauthorized)
+		return database.Holding{}, false
+	}
+	return holding, true
+}
+
+// GetHolding handles GET /api/v1/holdings/{id} requests.
+// The requesting user comes from the user_id query parameter or the X-User-ID header; the holding must belong to them.
+// Returns the holding view with its discount, days held, and current_value at the latest yields
+// (null if yields are unavailable). Returns HTTP 404 if the holding does not exist, HTTP 403 if it belongs to another user.
+func (h *HoldingsHandlers) GetHolding(w http.ResponseWriter, r *http.Request) {
+	holding, ok := h.loadOwnedHolding(w, r)
+	if !ok {
+		return
 	}
+
+	// Return the holding without a current value rather than failing, as GetUserHoldings does
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.WarnContext(r.Context(), "Error fetching yields for holding, omitting current value", "holding_id", holding.ID, "error", err)
+	}
+
+	view, err := newHoldingView(withLegacyRemaining(r.Context(), holding), yieldData, h.billDayCount, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building holding view", "holding_id", holding.ID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holding")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, view)
+}
+
+// GetHoldingLifecycle handles GET /api/v1/holdings/{id}/lifecycle requests.
+// The requesting user comes from the user_id query parameter or the X-User-ID header; the holding must belong to them.
+// Returns the holding's current state with every related transaction oldest first,
+// plus cumulative amount sold and proceeds received.
+func (h *HoldingsHandlers) GetHoldingLifecycle(w http.ResponseWriter, r *http.Request) {
+	holding, ok := h.loadOwnedHolding(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.queries.GetTransactionsByHolding(r.Context(), pgtype.Int4{Int32: holding.ID, Valid: true})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching holding transactions", "holding_id", holding.ID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch holding transactions")
+		return
+	}
+
+	lifecycle, err := buildHoldingLifecycle(withLegacyRemaining(r.Context(), holding), transactions, h.billAccrual, h.noteInterest, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building holding lifecycle", "holding_id", holding.ID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to build holding lifecycle")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, lifecycle)
+}
+
+// GetHoldingProjection handles GET /api/v1/holdings/{id}/projection requests.
+// The requesting user comes from the user_id query parameter or the X-User-ID header; the holding must belong to them.
+// Returns what the holding's remaining amount is worth today and at maturity if held, with a schedule of values
+// in between. Returns HTTP 404 if the holding does not exist, HTTP 403 if it belongs to another user, and
+// HTTP 400 if nothing remains to project.
+func (h *HoldingsHandlers) GetHoldingProjection(w http.ResponseWriter, r *http.Request) {
+	holding, ok := h.loadOwnedHolding(w, r)
+	if !ok {
+		return
+	}
+
+	holding = withLegacyRemaining(r.Context(), holding)
+	if !isActiveHolding(holding) {
+		respondWithError(w, http.StatusBadRequest, "holding has no remaining amount to project")
+		return
+	}
+
+	projection, err := buildHoldingProjection(holding, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error building holding projection", "holding_id", holding.ID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to project holding value")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, projection)
+}
+
+// buildHoldingViews filters holdings to those with remaining_amount > 0 and maps each to its view.
+// Legacy holdings with a null remaining_amount were never sold, so they are listed at their face value
+// rather than dropped.
+func buildHoldingViews(ctx context.Context, holdings []database.Holding, yieldData *models.YieldData, dayCount utils.DayCountConvention,
+	now time.Time) ([]HoldingView, error) {
+	views := []HoldingView{}
+	for _, holding := range holdings {
+		holding = withLegacyRemaining(ctx, holding)
+		if !isActiveHolding(holding) {
+			continue
+		}
+		view, err := newHoldingView(holding, yieldData, dayCount, now)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// withLegacyRemaining returns the holding with a null remaining_amount replaced by its face value
+// (or amount, for holdings that also predate face_value). Holdings with a stored remaining_amount are unchanged.
+func withLegacyRemaining(ctx context.Context, holding database.Holding) database.Holding {
+	if holding.RemainingAmount.Valid {
+		return holding
+	}
+	holding.RemainingAmount = holding.FaceValue
+	if !holding.FaceValue.Valid {
+		holding.RemainingAmount = holding.Amount
+	}
+	slog.WarnContext(ctx, "Holding has no remaining_amount, treating it as unsold", "holding_id", holding.ID, "remaining_amount", numericToFloat(holding.RemainingAmount))
+	return holding
+}
+
+// isActiveHolding reports whether a holding has a valid remaining_amount greater than zero
+func isActiveHolding(holding database.Holding) bool {
+	return holding.RemainingAmount.Valid && holding.RemainingAmount.Int.Sign() > 0
 }
@@ -0,0 +1,814 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// testHolding builds an in-memory holding purchased daysAgo days before now
+func testHolding(id int32, term string, securityType string, remaining string, yield string, now time.Time, daysAgo int) database.Holding {
+	return database.Holding{
+		ID:              id,
+		UserID:          1,
+		Term:            term,
+		Amount:          mustNumeric(remaining),
+		YieldAtPurchase: mustNumeric(yield),
+		PurchaseDate:    pgtype.Timestamp{Time: now.AddDate(0, 0, -daysAgo), Valid: true},
+		RemainingAmount: mustNumeric(remaining),
+		FaceValue:       mustNumeric(remaining),
+		SecurityType:    pgtype.Text{String: securityType, Valid: securityType != ""},
+	}
+}
+
+// TestBuildMaturityAlerts tests that only holdings maturing inside the window are returned
+func TestBuildMaturityAlerts(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holdings := []database.Holding{
+		testHolding(1, "1M", "bill", "10000.00", "4.00", now, 10), // 20 days to maturity
+		testHolding(2, "1M", "bill", "5000.00", "4.00", now, 20),  // 10 days to maturity
+		testHolding(3, "6M", "bill", "8000.00", "4.50", now, 80),  // 100 days to maturity
+	}
+
+	alerts, err := buildMaturityAlerts(context.Background(), holdings, now, 14)
+	if err != nil {
+		t.Fatalf("buildMaturityAlerts failed: %v", err)
+	}
+
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.HoldingID != 2 {
+		t.Errorf("Expected holding 2, got %d", alert.HoldingID)
+	}
+	if alert.DaysRemaining != 10 {
+		t.Errorf("Expected 10 days remaining, got %d", alert.DaysRemaining)
+	}
+	if alert.ExpectedProceeds != 5000.00 {
+		t.Errorf("Expected proceeds 5000.00 (bill face value), got %f", alert.ExpectedProceeds)
+	}
+	if alert.MaturityDate != "2025-03-24" {
+		t.Errorf("Expected maturity date 2025-03-24, got %s", alert.MaturityDate)
+	}
+}
+
+// TestBuildMaturityAlerts_EmptyAndInactive tests that sold-out holdings are ignored and the result is never nil
+func TestBuildMaturityAlerts_EmptyAndInactive(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	soldOut := testHolding(1, "1M", "bill", "10000.00", "4.00", now, 25)
+	soldOut.RemainingAmount = mustNumeric("0.00")
+
+	alerts, err := buildMaturityAlerts(context.Background(), []database.Holding{soldOut}, now, 14)
+	if err != nil {
+		t.Fatalf("buildMaturityAlerts failed: %v", err)
+	}
+	if alerts == nil || len(alerts) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %v", alerts)
+	}
+}
+
+// TestBuildMaturityAlerts_LegacyNote tests security type inference and full-term interest for legacy notes
+func TestBuildMaturityAlerts_LegacyNote(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	note := testHolding(7, "2Y", "", "10000.00", "4.00", now, 725) // 5 days to maturity, no stored type
+
+	alerts, err := buildMaturityAlerts(context.Background(), []database.Holding{note}, now, 14)
+	if err != nil {
+		t.Fatalf("buildMaturityAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	if alerts[0].SecurityType != "note" {
+		t.Errorf("Expected inferred security type note, got %s", alerts[0].SecurityType)
+	}
+	if alerts[0].ExpectedProceeds != 10800.00 {
+		t.Errorf("Expected proceeds 10800.00, got %f", alerts[0].ExpectedProceeds)
+	}
+}
+
+// TestBuildMaturingHoldings tests days to maturity from the stored date, falling back to the term for
+// legacy rows, and payouts at maturity for bills and notes
+func TestBuildMaturingHoldings(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	bill := testHolding(1, "3M", "bill", "10000.00", "4.00", now, 80)
+	bill.MaturityDate = pgtype.Timestamp{Time: now.AddDate(0, 0, 12), Valid: true} // Stored date wins over purchase + 90 days
+
+	matured := testHolding(2, "1M", "bill", "5000.00", "4.00", now, 35) // Matured 5 days ago, not yet redeemed
+	matured.MaturityDate = pgtype.Timestamp{Time: now.AddDate(0, 0, -5), Valid: true}
+
+	legacyNote := testHolding(3, "2Y", "note", "10000.00", "4.00", now, 720) // No stored date: 10 days left
+
+	legacyRemaining := testHolding(4, "1M", "bill", "2000.00", "4.00", now, 28) // Null remaining_amount: pays face value
+	legacyRemaining.RemainingAmount = pgtype.Numeric{}
+
+	maturing, err := buildMaturingHoldings(context.Background(), []database.Holding{matured, legacyNote, bill, legacyRemaining}, now)
+	if err != nil {
+		t.Fatalf("buildMaturingHoldings failed: %v", err)
+	}
+	if len(maturing) != 4 {
+		t.Fatalf("Expected 4 maturing holdings, got %d", len(maturing))
+	}
+
+	tests := []struct {
+		holdingID int32
+		days      int
+		payout    float64
+	}{
+		{2, 0, 5000.00},
+		{3, 10, 10800.00},
+		{1, 12, 10000.00},
+		{4, 2, 2000.00},
+	}
+	for i, tt := range tests {
+		got := maturing[i]
+		if got.ID != tt.holdingID {
+			t.Errorf("Position %d: expected holding %d (query order kept), got %d", i, tt.holdingID, got.ID)
+		}
+		if got.DaysToMaturity != tt.days {
+			t.Errorf("Holding %d: expected %d days to maturity, got %d", got.ID, tt.days, got.DaysToMaturity)
+		}
+		if got.ProjectedPayout != tt.payout {
+			t.Errorf("Holding %d: expected payout %.2f, got %.2f", got.ID, tt.payout, got.ProjectedPayout)
+		}
+	}
+
+	empty, err := buildMaturingHoldings(context.Background(), []database.Holding{}, now)
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %v (err %v)", empty, err)
+	}
+}
+
+// TestNewHoldingView_InvestmentYield tests investment yield enrichment for bills, notes, and legacy bills
+func TestNewHoldingView_InvestmentYield(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	bill := testHolding(1, "6M", "bill", "10000.00", "4.50", now, 30)
+	bill.PurchasePrice = mustNumeric("9775.00")
+
+	note := testHolding(2, "5Y", "note", "10000.00", "4.10", now, 30)
+	note.PurchasePrice = mustNumeric("10000.00")
+
+	legacyBill := testHolding(3, "6M", "", "10000.00", "4.50", now, 30)
+	legacyBill.FaceValue = pgtype.Numeric{}
+
+	tests := []struct {
+		name     string
+		holding  database.Holding
+		expected float64
+	}{
+		{"Bill converts discount yield", bill, 4.67},
+		{"Note keeps stored yield", note, 4.10},
+		{"Legacy bill repriced from yield", legacyBill, 4.67},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, err := newHoldingView(tt.holding, nil, utils.DayCountThirty360, now)
+			if err != nil {
+				t.Fatalf("newHoldingView failed: %v", err)
+			}
+			if view.InvestmentYield != tt.expected {
+				t.Errorf("InvestmentYield = %f, want %f", view.InvestmentYield, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewHoldingView_SecurityTypeLabel tests labels for stored security types and legacy holdings inferred from term
+func TestNewHoldingView_SecurityTypeLabel(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		holding  database.Holding
+		expected string
+	}{
+		{"Bill", testHolding(1, "6M", "bill", "10000.00", "4.50", now, 30), "Treasury Bill"},
+		{"Note", testHolding(2, "5Y", "note", "10000.00", "4.10", now, 30), "Treasury Note"},
+		{"Bond", testHolding(3, "30Y", "bond", "10000.00", "4.60", now, 30), "Treasury Bond"},
+		{"Legacy bill inferred from term", testHolding(4, "3M", "", "10000.00", "4.50", now, 30), "Treasury Bill"},
+		{"Legacy bond inferred from term", testHolding(5, "30Y", "", "10000.00", "4.60", now, 30), "Treasury Bond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, err := newHoldingView(tt.holding, nil, utils.DayCountThirty360, now)
+			if err != nil {
+				t.Fatalf("newHoldingView failed: %v", err)
+			}
+			if view.SecurityTypeLabel != tt.expected {
+				t.Errorf("SecurityTypeLabel = %q, want %q", view.SecurityTypeLabel, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewHoldingView_Valuation tests discount, days held, and current value at the latest yields
+func TestNewHoldingView_Valuation(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	// Half-sold 6M bill bought at 9775.00, 90 days left; 3.60% now values 5000 × (1 - 0.036 × 90/360) = 4955.00
+	bill := testHolding(1, "6M", "bill", "5000.00", "4.50", now, 90)
+	bill.FaceValue = mustNumeric("10000.00")
+	bill.PurchasePrice = mustNumeric("9775.00")
+
+	// Legacy 2Y note without a stored type or price is treated as bought at par
+	legacyNote := testHolding(2, "2Y", "", "10000.00", "4.00", now, 365)
+
+	yieldData := testYieldData(map[string]float64{"6M": 3.60, "2Y": 4.00})
+
+	tests := []struct {
+		name                 string
+		holding              database.Holding
+		expectedDiscount     float64
+		expectedPricePer100  float64
+		expectedDaysHeld     int
+		expectedCurrentValue float64
+	}{
+		{"Bill", bill, 225.00, 97.75, 90, 4955.00},
+		// Same yield as purchase: proceeds 10800 discounted over 365 days at 4% = 10384.62
+		{"Legacy note", legacyNote, 0, 100, 365, 10384.62},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, err := newHoldingView(tt.holding, yieldData, utils.DayCountThirty360, now)
+			if err != nil {
+				t.Fatalf("newHoldingView failed: %v", err)
+			}
+			if view.Discount != tt.expectedDiscount {
+				t.Errorf("Discount = %f, want %f", view.Discount, tt.expectedDiscount)
+			}
+			if view.PricePer100 != tt.expectedPricePer100 {
+				t.Errorf("PricePer100 = %f, want %f", view.PricePer100, tt.expectedPricePer100)
+			}
+			if view.DaysHeld != tt.expectedDaysHeld {
+				t.Errorf("DaysHeld = %d, want %d", view.DaysHeld, tt.expectedDaysHeld)
+			}
+			if view.CurrentValue == nil || *view.CurrentValue != tt.expectedCurrentValue {
+				t.Errorf("CurrentValue = %v, want %f", view.CurrentValue, tt.expectedCurrentValue)
+			}
+		})
+	}
+
+	// Without yields the current value is omitted rather than failing
+	view, err := newHoldingView(bill, nil, utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("newHoldingView without yields failed: %v", err)
+	}
+	if view.CurrentValue != nil {
+		t.Errorf("Expected nil CurrentValue without yields, got %f", *view.CurrentValue)
+	}
+}
+
+// TestBuildHoldingLifecycle tests a buy followed by two partial sells of a note
+func TestBuildHoldingLifecycle(t *testing.T) {
+	// Holding period stays within non-leap years so actual/actual matches a 365-day basis
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	holding := testHolding(7, "5Y", "note", "10000.00", "4.00", now, 146)
+	holding.RemainingAmount = mustNumeric("5000.00")
+	purchased := holding.PurchaseDate.Time
+
+	txAt := func(id int32, txType database.TransactionType, amount string, at time.Time) database.Transaction {
+		return database.Transaction{
+			ID:        id,
+			UserID:    holding.UserID,
+			Timestamp: pgtype.Timestamp{Time: at, Valid: true},
+			Type:      txType,
+			Term:      pgtype.Text{String: holding.Term, Valid: true},
+			Amount:    mustNumeric(amount),
+			HoldingID: pgtype.Int4{Int32: holding.ID, Valid: true},
+		}
+	}
+	transactions := []database.Transaction{
+		txAt(1, database.TransactionTypeBuy, "10000.00", purchased),
+		txAt(2, database.TransactionTypeSell, "2000.00", purchased.AddDate(0, 0, 73)),
+		txAt(3, database.TransactionTypeSell, "3000.00", purchased.AddDate(0, 0, 146)),
+	}
+
+	lifecycle, err := buildHoldingLifecycle(holding, transactions, utils.BillAccrualLinear, utils.NoteInterestSimple, now)
+	if err != nil {
+		t.Fatalf("buildHoldingLifecycle failed: %v", err)
+	}
+
+	if lifecycle.Holding.ID != 7 {
+		t.Errorf("Expected holding 7, got %d", lifecycle.Holding.ID)
+	}
+	if len(lifecycle.Events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(lifecycle.Events))
+	}
+
+	// 2000 × 4% × 73/365 = 16.00 interest; 3000 × 4% × 146/365 = 48.00 interest
+	expected := []struct {
+		txType             database.TransactionType
+		proceeds           float64
+		cumulativeSold     float64
+		cumulativeProceeds float64
+	}{
+		{database.TransactionTypeBuy, 0, 0, 0},
+		{database.TransactionTypeSell, 2016.00, 2000.00, 2016.00},
+		{database.TransactionTypeSell, 3048.00, 5000.00, 5064.00},
+	}
+	for i, want := range expected {
+		event := lifecycle.Events[i]
+		if event.Type != want.txType {
+			t.Errorf("Event %d: expected type %s, got %s", i, want.txType, event.Type)
+		}
+		if event.Proceeds != want.proceeds {
+			t.Errorf("Event %d: expected proceeds %.2f, got %.2f", i, want.proceeds, event.Proceeds)
+		}
+		if event.CumulativeSold != want.cumulativeSold {
+			t.Errorf("Event %d: expected cumulative sold %.2f, got %.2f", i, want.cumulativeSold, event.CumulativeSold)
+		}
+		if event.CumulativeProceeds != want.cumulativeProceeds {
+			t.Errorf("Event %d: expected cumulative proceeds %.2f, got %.2f", i, want.cumulativeProceeds, event.CumulativeProceeds)
+		}
+	}
+
+	if lifecycle.CumulativeSold != 5000.00 || lifecycle.CumulativeProceeds != 5064.00 {
+		t.Errorf("Expected totals sold 5000.00 / proceeds 5064.00, got %.2f / %.2f",
+			lifecycle.CumulativeSold, lifecycle.CumulativeProceeds)
+	}
+}
+
+// TestBuildHoldingLifecycle_EarlyBillSale tests that a bill sold before maturity reports its accreted proceeds, not face
+func TestBuildHoldingLifecycle_EarlyBillSale(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	// 6M bill: 10000.00 face bought at 9775.00, half sold 90 of its 180 days in
+	holding := testHolding(3, "6M", "bill", "10000.00", "4.50", now, 90)
+	holding.PurchasePrice = mustNumeric("9775.00")
+	holding.RemainingAmount = mustNumeric("5000.00")
+	purchased := holding.PurchaseDate.Time
+
+	transactions := []database.Transaction{
+		{ID: 1, Type: database.TransactionTypeBuy, Amount: mustNumeric("10000.00"),
+			Timestamp: pgtype.Timestamp{Time: purchased, Valid: true}},
+		{ID: 2, Type: database.TransactionTypeSell, Amount: mustNumeric("5000.00"),
+			Timestamp: pgtype.Timestamp{Time: purchased.AddDate(0, 0, 90), Valid: true}},
+	}
+
+	tests := []struct {
+		name     string
+		accrual  utils.BillAccrual
+		proceeds float64
+	}{
+		// Half the face cost 4887.50 and has accreted half of its 112.50 discount
+		{"Linear", utils.BillAccrualLinear, 4943.75},
+		// √(4887.50 × 5000.00) = 4943.43
+		{"Compound", utils.BillAccrualCompound, 4943.43},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lifecycle, err := buildHoldingLifecycle(holding, transactions, tt.accrual, utils.NoteInterestSimple, now)
+			if err != nil {
+				t.Fatalf("buildHoldingLifecycle failed: %v", err)
+			}
+			if got := lifecycle.Events[1].Proceeds; got != tt.proceeds {
+				t.Errorf("Expected sell proceeds %.2f, got %.2f", tt.proceeds, got)
+			}
+			if lifecycle.CumulativeSold != 5000.00 || lifecycle.CumulativeProceeds != tt.proceeds {
+				t.Errorf("Expected totals sold 5000.00 / proceeds %.2f, got %.2f / %.2f",
+					tt.proceeds, lifecycle.CumulativeSold, lifecycle.CumulativeProceeds)
+			}
+		})
+	}
+}
+
+// TestBuildHoldingProjection_Bill tests linear discount accretion from the price paid to face value, sampled monthly
+func TestBuildHoldingProjection_Bill(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	// 6M bill at 4.50% repriced to 9775.00, bought 90 of its 180 days ago
+	holding := testHolding(1, "6M", "bill", "10000.00", "4.50", now, 90)
+
+	projection, err := buildHoldingProjection(holding, now)
+	if err != nil {
+		t.Fatalf("buildHoldingProjection failed: %v", err)
+	}
+
+	// Half the 225.00 discount has accreted
+	if projection.ValueToday != 9887.50 || projection.ValueAtMaturity != 10000.00 {
+		t.Errorf("Expected value today 9887.50 and at maturity 10000.00, got %.2f and %.2f", projection.ValueToday, projection.ValueAtMaturity)
+	}
+	if projection.MaturityDate != "2025-06-12" || projection.DaysToMaturity != 90 {
+		t.Errorf("Expected maturity 2025-06-12 in 90 days, got %s in %d", projection.MaturityDate, projection.DaysToMaturity)
+	}
+
+	// Monthly purchase anniversaries after today, then maturity; 121 days held: 9775 + 225 × 121/180 = 9926.25
+	expected := []ProjectionPoint{
+		{Date: "2025-04-14", DaysHeld: 121, Value: 9926.25},
+		{Date: "2025-05-14", DaysHeld: 151, Value: 9963.75},
+		{Date: "2025-06-12", DaysHeld: 180, Value: 10000.00},
+	}
+	if len(projection.Schedule) != len(expected) {
+		t.Fatalf("Expected %d schedule points, got %+v", len(expected), projection.Schedule)
+	}
+	for i, want := range expected {
+		if projection.Schedule[i] != want {
+			t.Errorf("Point %d: expected %+v, got %+v", i, want, projection.Schedule[i])
+		}
+	}
+}
+
+// TestBuildHoldingProjection_NoteYearly tests simple interest for a long note, sampled yearly to keep the schedule small
+func TestBuildHoldingProjection_NoteYearly(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holding := testHolding(2, "10Y", "note", "10000.00", "4.00", now, 365)
+
+	projection, err := buildHoldingProjection(holding, now)
+	if err != nil {
+		t.Fatalf("buildHoldingProjection failed: %v", err)
+	}
+
+	// 10000 × 4% × 365/365 = 400.00 so far; 10000 × 4% × 3650/365 = 4000.00 over the term
+	if projection.ValueToday != 10400.00 || projection.ValueAtMaturity != 14000.00 {
+		t.Errorf("Expected value today 10400.00 and at maturity 14000.00, got %.2f and %.2f", projection.ValueToday, projection.ValueAtMaturity)
+	}
+
+	// Years 2 through 9 after purchase, then maturity (3650 days falls just short of the 10th anniversary)
+	if len(projection.Schedule) != 9 {
+		t.Fatalf("Expected 9 yearly schedule points, got %d: %+v", len(projection.Schedule), projection.Schedule)
+	}
+	if first := projection.Schedule[0]; first.Date != "2026-03-14" || first.Value != 10800.00 {
+		t.Errorf("Expected first point 2026-03-14 at 10800.00, got %+v", first)
+	}
+	if last := projection.Schedule[8]; last.Value != projection.ValueAtMaturity || last.Date != projection.MaturityDate {
+		t.Errorf("Expected the schedule to end at maturity, got %+v", last)
+	}
+}
+
+// TestBuildHoldingProjection_Matured tests that a matured holding is worth its maturity value with no schedule
+func TestBuildHoldingProjection_Matured(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holding := testHolding(3, "1M", "bill", "5000.00", "4.00", now, 45)
+
+	projection, err := buildHoldingProjection(holding, now)
+	if err != nil {
+		t.Fatalf("buildHoldingProjection failed: %v", err)
+	}
+	if projection.ValueToday != 5000.00 || projection.ValueAtMaturity != 5000.00 {
+		t.Errorf("Expected face value 5000.00 today and at maturity, got %.2f and %.2f", projection.ValueToday, projection.ValueAtMaturity)
+	}
+	if projection.DaysToMaturity != 0 || len(projection.Schedule) != 0 {
+		t.Errorf("Expected no days to maturity and an empty schedule, got %d and %+v", projection.DaysToMaturity, projection.Schedule)
+	}
+}
+
+// TestProjectIncome tests projected income across horizons, stopping accrual at maturity
+func TestProjectIncome(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	// 1M bill bought at 9970.00 for 10000.00 face: 30.00 discount over 30 days, 20 days left
+	bill := testHolding(1, "1M", "bill", "10000.00", "3.60", now, 10)
+	bill.PurchasePrice = mustNumeric("9970.00")
+	// 2Y note at 3.65% on 10000.00 earns 1.00 per day
+	note := testHolding(2, "2Y", "note", "10000.00", "3.65", now, 10)
+	// Half-sold 3M bill bought at 9900.00: 50.00 discount left over 90 days, 5 days left
+	halfSold := testHolding(3, "3M", "bill", "5000.00", "4.00", now, 85)
+	halfSold.FaceValue = mustNumeric("10000.00")
+	halfSold.PurchasePrice = mustNumeric("9900.00")
+	matured := testHolding(4, "1Y", "note", "10000.00", "4.00", now, 400)
+	soldOut := testHolding(5, "2Y", "note", "0.00", "4.00", now, 10)
+
+	holdings := []database.Holding{bill, note, halfSold, matured, soldOut}
+
+	tests := []struct {
+		name     string
+		horizon  int
+		expected float64
+	}{
+		{"Zero horizon", 0, 0},
+		{"Every holding accrues the full horizon", 3, 3.00 + 3.00 + 50.0/90*3},
+		{"30 days", 30, 20.00 + 30.00 + 50.0/90*5},
+		{"90 days", 90, 20.00 + 90.00 + 50.0/90*5},
+		{"365 days", 365, 20.00 + 365.00 + 50.0/90*5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := projectIncome(holdings, now, tt.horizon)
+			if err != nil {
+				t.Fatalf("projectIncome failed: %v", err)
+			}
+			want := math.Round(tt.expected*100) / 100
+			if got != want {
+				t.Errorf("projectIncome(%d) = %.2f, want %.2f", tt.horizon, got, want)
+			}
+		})
+	}
+
+	if _, err := projectIncome(holdings, now, -1); err == nil {
+		t.Error("Expected error for negative horizon")
+	}
+}
+
+// testYieldData builds latest yield data from term/rate pairs
+func testYieldData(rates map[string]float64) *models.YieldData {
+	data := &models.YieldData{Date: "2025-03-14"}
+	for term, rate := range rates {
+		data.Yields = append(data.Yields, models.YieldPoint{Term: term, Rate: rate})
+	}
+	return data
+}
+
+// TestBuildPortfolioSummary tests totals and the security type breakdown over active holdings
+func TestBuildPortfolioSummary(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	// Half-sold 6M bill bought at 9800.00, 90 days left
+	bill := testHolding(1, "6M", "bill", "5000.00", "4.00", now, 90)
+	bill.FaceValue = mustNumeric("10000.00")
+	bill.PurchasePrice = mustNumeric("9800.00")
+	// Legacy 2Y note without a stored price is costed at par, 365 days left
+	note := testHolding(2, "2Y", "note", "10000.00", "4.00", now, 365)
+	soldOut := testHolding(3, "30Y", "bond", "0.00", "4.50", now, 30)
+
+	yieldData := testYieldData(map[string]float64{"6M": 3.60, "2Y": 5.00, "30Y": 4.75})
+	summary, err := buildPortfolioSummary(context.Background(), []database.Holding{bill, note, soldOut}, yieldData, utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildPortfolioSummary failed: %v", err)
+	}
+
+	if summary.TotalFaceValue != 15000.00 {
+		t.Errorf("Expected total face value 15000.00, got %.2f", summary.TotalFaceValue)
+	}
+	if summary.TotalCostBasis != 14900.00 {
+		t.Errorf("Expected total cost basis 14900.00, got %.2f", summary.TotalCostBasis)
+	}
+	// Bill: 5000 × (1 - 0.036 × 90/360) = 4955.00; note: 10800 / 1.05 = 10285.71
+	if summary.TotalMarketValue != 15240.71 {
+		t.Errorf("Expected total market value 15240.71, got %.2f", summary.TotalMarketValue)
+	}
+
+	expectedBill := PortfolioTotals{Holdings: 1, FaceValue: 5000.00, CostBasis: 4900.00, MarketValue: 4955.00}
+	if got := summary.BySecurityType["bill"]; got != expectedBill {
+		t.Errorf("Expected bill totals %+v, got %+v", expectedBill, got)
+	}
+	expectedNote := PortfolioTotals{Holdings: 1, FaceValue: 10000.00, CostBasis: 10000.00, MarketValue: 10285.71}
+	if got := summary.BySecurityType["note"]; got != expectedNote {
+		t.Errorf("Expected note totals %+v, got %+v", expectedNote, got)
+	}
+	if _, ok := summary.BySecurityType["bond"]; ok {
+		t.Error("Expected sold-out bond to be excluded from the breakdown")
+	}
+}
+
+// TestBuildPortfolioSummary_MissingYield tests that a holding whose term has no current yield is valued at its purchase yield
+func TestBuildPortfolioSummary_MissingYield(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holdings := []database.Holding{testHolding(1, "2Y", "note", "10000.00", "4.00", now, 10)}
+
+	missing, err := buildPortfolioSummary(context.Background(), holdings, testYieldData(map[string]float64{"6M": 3.60}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildPortfolioSummary failed: %v", err)
+	}
+	atPurchaseYield, err := buildPortfolioSummary(context.Background(), holdings, testYieldData(map[string]float64{"2Y": 4.00}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildPortfolioSummary failed: %v", err)
+	}
+	if missing.TotalMarketValue != atPurchaseYield.TotalMarketValue {
+		t.Errorf("Expected market value %.2f at the purchase yield, got %.2f", atPurchaseYield.TotalMarketValue, missing.TotalMarketValue)
+	}
+}
+
+// TestBuildPortfolioSummary_ProjectedIncome tests that the summary fills every horizon
+func TestBuildPortfolioSummary_ProjectedIncome(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holdings := []database.Holding{testHolding(1, "2Y", "note", "10000.00", "3.65", now, 10)}
+
+	summary, err := buildPortfolioSummary(context.Background(), holdings, testYieldData(map[string]float64{"2Y": 4.00}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildPortfolioSummary failed: %v", err)
+	}
+	expected := ProjectedIncome{Next30Days: 30.00, Next90Days: 90.00, Next365Days: 365.00}
+	if summary.ProjectedIncome != expected {
+		t.Errorf("Expected %+v, got %+v", expected, summary.ProjectedIncome)
+	}
+}
+
+// TestBuildTermPosition tests aggregating several 6M holdings while skipping other terms and sold-out holdings
+func TestBuildTermPosition(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holdings := []database.Holding{
+		testHolding(1, "6M", "bill", "5000.00", "3.00", now, 30),  // 150 days left, matures 2025-08-11
+		testHolding(2, "6M", "bill", "10000.00", "4.50", now, 90), // 90 days left, matures 2025-06-12
+		testHolding(3, "2Y", "note", "8000.00", "4.00", now, 10),  // other term
+		testHolding(4, "6M", "bill", "0.00", "5.00", now, 20),     // sold out
+	}
+
+	position, err := buildTermPosition(context.Background(), holdings, "6M", testYieldData(map[string]float64{"6M": 3.60, "2Y": 4.00}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildTermPosition failed: %v", err)
+	}
+
+	if position.Term != "6M" || position.SecurityType != "bill" {
+		t.Errorf("Expected 6M bill position, got %s %s", position.Term, position.SecurityType)
+	}
+	if position.Holdings != 2 {
+		t.Errorf("Expected 2 holdings, got %d", position.Holdings)
+	}
+	if position.TotalFaceValue != 15000.00 {
+		t.Errorf("Expected total face value 15000.00, got %.2f", position.TotalFaceValue)
+	}
+	// (5000 × 3.00 + 10000 × 4.50) / 15000 = 4.00
+	if position.WeightedAverageYield != 4.00 {
+		t.Errorf("Expected weighted average yield 4.00, got %.4f", position.WeightedAverageYield)
+	}
+	if position.NearestMaturity == nil || *position.NearestMaturity != "2025-06-12" {
+		t.Errorf("Expected nearest maturity 2025-06-12, got %v", position.NearestMaturity)
+	}
+	// 5000 × (1 - 0.036 × 150/360) = 4925.00; 10000 × (1 - 0.036 × 90/360) = 9910.00
+	if position.CurrentValue != 14835.00 {
+		t.Errorf("Expected current value 14835.00, got %.2f", position.CurrentValue)
+	}
+}
+
+// TestBuildTermPosition_Empty tests that a term with no active holdings is a zeroed position, and bad terms are errors
+func TestBuildTermPosition_Empty(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holdings := []database.Holding{testHolding(1, "2Y", "note", "8000.00", "4.00", now, 10)}
+
+	position, err := buildTermPosition(context.Background(), holdings, "10Y", testYieldData(map[string]float64{"2Y": 4.00}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildTermPosition failed: %v", err)
+	}
+	expected := TermPosition{Term: "10Y", SecurityType: "note"}
+	if position != expected {
+		t.Errorf("Expected zeroed position %+v, got %+v", expected, position)
+	}
+
+	if _, err := buildTermPosition(context.Background(), holdings, "7M", nil, utils.DayCountThirty360, now); err == nil {
+		t.Error("Expected error for an invalid term")
+	}
+}
+
+// TestBuildYieldComparison tests basis point differences and beat/underperform flags against a fake current curve
+func TestBuildYieldComparison(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	holdings := []database.Holding{
+		testHolding(1, "6M", "bill", "10000.00", "4.50", now, 30), // locked above market
+		testHolding(2, "2Y", "note", "5000.00", "3.75", now, 90),  // locked below market
+		testHolding(3, "30Y", "", "2000.00", "4.60", now, 400),    // legacy bond at market
+		testHolding(4, "1M", "bill", "0.00", "5.00", now, 10),     // sold out
+	}
+	yieldData := testYieldData(map[string]float64{"1M": 4.00, "6M": 4.20, "2Y": 4.10, "30Y": 4.60})
+
+	comparison, err := buildYieldComparison(context.Background(), holdings, yieldData)
+	if err != nil {
+		t.Fatalf("buildYieldComparison failed: %v", err)
+	}
+
+	if comparison.YieldDate != "2025-03-14" {
+		t.Errorf("Expected yield date 2025-03-14, got %s", comparison.YieldDate)
+	}
+	expected := []HoldingYieldComparison{
+		{HoldingID: 1, Term: "6M", SecurityType: "bill", RemainingAmount: 10000.00, YieldAtPurchase: 4.50, MarketYield: 4.20, DifferenceBps: 30, Status: yieldStatusBeatsMarket},
+		{HoldingID: 2, Term: "2Y", SecurityType: "note", RemainingAmount: 5000.00, YieldAtPurchase: 3.75, MarketYield: 4.10, DifferenceBps: -35, Status: yieldStatusUnderperforms},
+		{HoldingID: 3, Term: "30Y", SecurityType: "bond", RemainingAmount: 2000.00, YieldAtPurchase: 4.60, MarketYield: 4.60, DifferenceBps: 0, Status: yieldStatusMatchesMarket},
+	}
+	if len(comparison.Holdings) != len(expected) {
+		t.Fatalf("Expected %d comparisons, got %d", len(expected), len(comparison.Holdings))
+	}
+	for i, want := range expected {
+		if got := comparison.Holdings[i]; got != want {
+			t.Errorf("Comparison %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+// TestBuildYieldComparison_EmptyAndMissingYield tests the empty result and a term missing from the curve
+func TestBuildYieldComparison_EmptyAndMissingYield(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	yieldData := testYieldData(map[string]float64{"6M": 4.20})
+
+	comparison, err := buildYieldComparison(context.Background(), nil, yieldData)
+	if err != nil {
+		t.Fatalf("buildYieldComparison failed: %v", err)
+	}
+	if comparison.Holdings == nil || len(comparison.Holdings) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %v", comparison.Holdings)
+	}
+
+	// A holding whose term has no current yield has nothing to compare against and is left out
+	holdings := []database.Holding{testHolding(1, "2Y", "note", "10000.00", "4.00", now, 10)}
+	comparison, err = buildYieldComparison(context.Background(), holdings, yieldData)
+	if err != nil {
+		t.Fatalf("buildYieldComparison failed: %v", err)
+	}
+	if len(comparison.Holdings) != 0 {
+		t.Errorf("Expected the holding without a current yield to be skipped, got %v", comparison.Holdings)
+	}
+}
+
+// TestBuildHoldingViews_NullRemainingAmount tests that legacy holdings without a remaining_amount are listed at face value
+func TestBuildHoldingViews_NullRemainingAmount(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	legacy := testHolding(1, "6M", "bill", "10000.00", "4.00", now, 30)
+	legacy.RemainingAmount = pgtype.Numeric{}
+	legacyNoFace := testHolding(2, "2Y", "note", "5000.00", "4.00", now, 30)
+	legacyNoFace.RemainingAmount = pgtype.Numeric{}
+	legacyNoFace.FaceValue = pgtype.Numeric{}
+	soldOut := testHolding(3, "1Y", "bill", "0.00", "4.00", now, 30)
+
+	views, err := buildHoldingViews(context.Background(), []database.Holding{legacy, legacyNoFace, soldOut}, nil, utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildHoldingViews failed: %v", err)
+	}
+
+	if len(views) != 2 {
+		t.Fatalf("Expected 2 active holdings, got %d", len(views))
+	}
+	if views[0].ID != 1 || numericToFloat(views[0].RemainingAmount) != 10000.00 {
+		t.Errorf("Expected holding 1 with remaining 10000.00 (face value), got holding %d with %.2f", views[0].ID, numericToFloat(views[0].RemainingAmount))
+	}
+	if views[1].ID != 2 || numericToFloat(views[1].RemainingAmount) != 5000.00 {
+		t.Errorf("Expected holding 2 with remaining 5000.00 (amount), got holding %d with %.2f", views[1].ID, numericToFloat(views[1].RemainingAmount))
+	}
+}
+
+// TestBuildViews_NullRemainingAmountCounted tests that legacy holdings listed by the holdings view also count in
+// maturity alerts, portfolio totals and projected income, and the yield comparison
+func TestBuildViews_NullRemainingAmountCounted(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	legacy := testHolding(1, "1M", "bill", "10000.00", "4.00", now, 20) // 10 days to maturity
+	legacy.RemainingAmount = pgtype.Numeric{}
+	holdings := []database.Holding{legacy}
+	yieldData := testYieldData(map[string]float64{"1M": 4.00})
+
+	alerts, err := buildMaturityAlerts(context.Background(), holdings, now, 14)
+	if err != nil {
+		t.Fatalf("buildMaturityAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].RemainingAmount != 10000.00 {
+		t.Errorf("Expected one alert for 10000.00, got %+v", alerts)
+	}
+
+	summary, err := buildPortfolioSummary(context.Background(), holdings, yieldData, utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildPortfolioSummary failed: %v", err)
+	}
+	if summary.TotalFaceValue != 10000.00 {
+		t.Errorf("Expected total face value 10000.00, got %.2f", summary.TotalFaceValue)
+	}
+	if summary.ProjectedIncome.Next30Days <= 0 {
+		t.Errorf("Expected projected income from the legacy bill, got %.2f", summary.ProjectedIncome.Next30Days)
+	}
+
+	comparison, err := buildYieldComparison(context.Background(), holdings, yieldData)
+	if err != nil {
+		t.Fatalf("buildYieldComparison failed: %v", err)
+	}
+	if len(comparison.Holdings) != 1 || comparison.Holdings[0].RemainingAmount != 10000.00 {
+		t.Errorf("Expected the legacy holding compared at 10000.00, got %+v", comparison.Holdings)
+	}
+}
+
+// TestBuildViews_StoredMaturityDate tests that a holding's stored maturity_date, not purchase date plus term,
+// drives maturity alerts, the term position, the projection, and market value
+func TestBuildViews_StoredMaturityDate(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	// Bought 60 days ago, so purchase date plus 90 days would put maturity 30 days out; stored as 10 days out
+	holding := testHolding(1, "3M", "bill", "10000.00", "4.00", now, 60)
+	holding.MaturityDate = pgtype.Timestamp{Time: now.AddDate(0, 0, 10), Valid: true}
+
+	alerts, err := buildMaturityAlerts(context.Background(), []database.Holding{holding}, now, 14)
+	if err != nil {
+		t.Fatalf("buildMaturityAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].MaturityDate != "2025-03-24" || alerts[0].DaysRemaining != 10 {
+		t.Errorf("Expected an alert for 2025-03-24 in 10 days, got %+v", alerts)
+	}
+
+	position, err := buildTermPosition(context.Background(), []database.Holding{holding}, "3M", testYieldData(map[string]float64{"3M": 4.00}), utils.DayCountThirty360, now)
+	if err != nil {
+		t.Fatalf("buildTermPosition failed: %v", err)
+	}
+	if position.NearestMaturity == nil || *position.NearestMaturity != "2025-03-24" {
+		t.Errorf("Expected nearest maturity 2025-03-24, got %v", position.NearestMaturity)
+	}
+	// 10 days left at 4.00%: 10000 × (1 - 0.04 × 10/360) = 9988.89
+	if position.CurrentValue != 9988.89 {
+		t.Errorf("Expected current value 9988.89, got %.2f", position.CurrentValue)
+	}
+
+	projection, err := buildHoldingProjection(holding, now)
+	if err != nil {
+		t.Fatalf("buildHoldingProjection failed: %v", err)
+	}
+	if projection.MaturityDate != "2025-03-24" || projection.DaysToMaturity != 10 {
+		t.Errorf("Expected projection maturity 2025-03-24 in 10 days, got %s in %d", projection.MaturityDate, projection.DaysToMaturity)
+	}
+	// The 100.00 discount (repriced at 9900.00) accretes over the stored 70-day term: 60/70 earned so far
+	if projection.ValueToday != 9985.71 || projection.ValueAtMaturity != 10000.00 {
+		t.Errorf("Expected value 9985.71 today and 10000.00 at maturity, got %.2f and %.2f", projection.ValueToday, projection.ValueAtMaturity)
+	}
+}
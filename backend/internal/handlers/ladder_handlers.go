@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/services"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// LadderHandlers handles HTTP requests for ladder planning tools.
+// These endpoints are read-only: they price hypothetical purchases without touching the database.
+type LadderHandlers struct {
+	txService       *services.TransactionService
+	treasuryService *services.TreasuryService
+}
+
+// NewLadderHandlers creates and returns a new LadderHandlers instance.
+func NewLadderHandlers(txService *services.TransactionService, treasuryService *services.TreasuryService) *LadderHandlers {
+	return &LadderHandlers{
+		txService:       txService,
+		treasuryService: treasuryService,
+	}
+}
+
+// LadderLeg is a single target position in a ladder
+type LadderLeg struct {
+	Term      string  `json:"term"`
+	FaceValue float64 `json:"face_value"`
+}
+
+// LadderCostRequest represents the incoming JSON request for ladder cost calculations
+type LadderCostRequest struct {
+	Legs []LadderLeg `json:"legs"`
+}
+
+// LadderLegCost is the priced breakdown for one leg of a ladder
+type LadderLegCost struct {
+	Term          string  `json:"term"`
+	SecurityType  string  `json:"security_type"`
+	FaceValue     float64 `json:"face_value"`
+	Yield         float64 `json:"yield"`
+	PurchasePrice float64 `json:"purchase_price"`
+	Discount      float64 `json:"discount"`
+}
+
+// LadderCostResponse represents the JSON response for ladder cost calculations
+type LadderCostResponse struct {
+	Success            bool            `json:"success"`
+	YieldDate          string          `json:"yield_date"`
+	TotalFaceValue     float64         `json:"total_face_value"`
+	TotalPurchasePrice float64         `json:"total_purchase_price"`
+	Legs               []LadderLegCost `json:"legs"`
+}
+
+// LadderCostHandler handles POST /api/v1/ladder/cost requests.
+// Expects JSON body with a legs array of {term, face_value} targets.
+// Returns the cash needed today to buy every leg at current yields, with a per-leg breakdown.
+// Each leg is priced exactly as a buy quote would be, so PAR_PRICING and BILL_DAY_COUNT apply.
+func (h *LadderHandlers) LadderCostHandler(w http.ResponseWriter, r *http.Request) {
+	var req LadderCostRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding ladder cost request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	if len(req.Legs) == 0 {
+		respondWithError(w, http.StatusBadRequest, "at least one ladder leg is required")
+		return
+	}
+
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		return
+	}
+
+	resp, err := priceLadder(h.txService, req.Legs, yieldData)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error pricing ladder", "legs", len(req.Legs), "error", err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// priceLadder quotes each leg through txService at the given yields, preserving input order, and totals the cost
+func priceLadder(txService *services.TransactionService, legs []LadderLeg, yieldData *models.YieldData) (*LadderCostResponse, error) {
+	resp := &LadderCostResponse{
+		Success:   true,
+		YieldDate: yieldData.Date,
+		Legs:      make([]LadderLegCost, 0, len(legs)),
+	}
+
+	for i, leg := range legs {
+		term, err := utils.NormalizeTerm(leg.Term)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		leg.Term = term
+		securityType, err := utils.GetSecurityType(term)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		if math.IsNaN(leg.FaceValue) || leg.FaceValue <= 0 {
+			return nil, fmt.Errorf("leg %d: face value must be greater than zero", i)
+		}
+
+		yieldRate, found := yieldData.RateForTerm(leg.Term)
+		if !found {
+			return nil, fmt.Errorf("leg %d: yield data not available for term %s", i, leg.Term)
+		}
+
+		faceValue, err := utils.FloatToNumeric(leg.FaceValue)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: invalid face value: %w", i, err)
+		}
+		currentYield, err := utils.FloatToNumeric(yieldRate)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: invalid yield: %w", i, err)
+		}
+		quotedPrice, err := txService.QuoteBuy(leg.Term, faceValue, currentYield)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		price := numericToFloat(quotedPrice)
+
+		resp.Legs = append(resp.Legs, LadderLegCost{
+			Term:          leg.Term,
+			SecurityType:  securityType,
+			FaceValue:     leg.FaceValue,
+			Yield:         yieldRate,
+			PurchasePrice: price,
+			Discount:      utils.CalculateBillDiscount(leg.FaceValue, price),
+		})
+		resp.TotalFaceValue += leg.FaceValue
+		resp.TotalPurchasePrice += price
+	}
+
+	if len(resp.Legs) == 0 {
+		return nil, errors.New("at least one ladder leg is required")
+	}
+
+	resp.TotalFaceValue = math.Round(resp.TotalFaceValue*100) / 100
+	resp.TotalPurchasePrice = math.Round(resp.TotalPurchasePrice*100) / 100
+	return resp, nil
+}
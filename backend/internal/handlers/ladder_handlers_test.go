@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"testing"
+
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/services"
+)
+
+// testYieldCurve returns a synthetic yield curve for pricing tests
+func testYieldCurve() *models.YieldData {
+	return &models.YieldData{
+		Date: "2025-03-14",
+		Yields: []models.YieldPoint{
+			{Term: "1M", Rate: 3.60},
+			{Term: "3M", Rate: 4.00},
+			{Term: "6M", Rate: 4.50},
+			{Term: "1Y", Rate: 4.20},
+			{Term: "2Y", Rate: 4.10},
+			{Term: "5Y", Rate: 4.00},
+			{Term: "10Y", Rate: 4.30},
+			{Term: "30Y", Rate: 4.60},
+		},
+	}
+}
+
+// TestPriceLadder_MixedLadder tests pricing a ladder across bills, notes, and bonds
+func TestPriceLadder_MixedLadder(t *testing.T) {
+	legs := []LadderLeg{
+		{Term: "6M", FaceValue: 10000},
+		{Term: "1M", FaceValue: 10000},
+		{Term: "2Y", FaceValue: 10000},
+		{Term: "30Y", FaceValue: 5000},
+	}
+
+	resp, err := priceLadder(services.NewTransactionService(nil, nil), legs, testYieldCurve())
+	if err != nil {
+		t.Fatalf("priceLadder failed: %v", err)
+	}
+
+	expected := []struct {
+		term          string
+		securityType  string
+		purchasePrice float64
+		discount      float64
+	}{
+		{"6M", "bill", 9775.00, 225.00}, // 10000 × (1 - 0.045 × 180/360)
+		{"1M", "bill", 9970.00, 30.00},  // 10000 × (1 - 0.036 × 30/360)
+		{"2Y", "note", 10000.00, 0},
+		{"30Y", "bond", 5000.00, 0},
+	}
+
+	if len(resp.Legs) != len(expected) {
+		t.Fatalf("Expected %d legs, got %d", len(expected), len(resp.Legs))
+	}
+	for i, want := range expected {
+		leg := resp.Legs[i]
+		if leg.Term != want.term || leg.SecurityType != want.securityType {
+			t.Errorf("Leg %d: got %s/%s, want %s/%s", i, leg.Term, leg.SecurityType, want.term, want.securityType)
+		}
+		if leg.PurchasePrice != want.purchasePrice {
+			t.Errorf("Leg %d: purchase price %f, want %f", i, leg.PurchasePrice, want.purchasePrice)
+		}
+		if leg.Discount != want.discount {
+			t.Errorf("Leg %d: discount %f, want %f", i, leg.Discount, want.discount)
+		}
+	}
+
+	if resp.TotalFaceValue != 35000.00 {
+		t.Errorf("Expected total face value 35000.00, got %f", resp.TotalFaceValue)
+	}
+	if resp.TotalPurchasePrice != 34745.00 {
+		t.Errorf("Expected total purchase price 34745.00, got %f", resp.TotalPurchasePrice)
+	}
+	if resp.YieldDate != "2025-03-14" {
+		t.Errorf("Expected yield date 2025-03-14, got %s", resp.YieldDate)
+	}
+}
+
+// TestPriceLadder_NormalizesTerm tests that terms in any casing are priced and reported in canonical form
+func TestPriceLadder_NormalizesTerm(t *testing.T) {
+	resp, err := priceLadder(services.NewTransactionService(nil, nil), []LadderLeg{{Term: " 6m ", FaceValue: 10000}}, testYieldCurve())
+	if err != nil {
+		t.Fatalf("priceLadder failed: %v", err)
+	}
+	if leg := resp.Legs[0]; leg.Term != "6M" || leg.SecurityType != "bill" || leg.PurchasePrice != 9775.00 {
+		t.Errorf("Expected 6M bill priced at 9775.00, got %+v", leg)
+	}
+}
+
+// TestPriceLadder_ParPricing tests that legs are priced the way a buy quote would be, so par pricing charges face
+func TestPriceLadder_ParPricing(t *testing.T) {
+	txService := services.NewTransactionService(nil, nil)
+	txService.SetParPricing(true)
+
+	resp, err := priceLadder(txService, []LadderLeg{{Term: "6M", FaceValue: 10000}, {Term: "2Y", FaceValue: 5000}}, testYieldCurve())
+	if err != nil {
+		t.Fatalf("priceLadder failed: %v", err)
+	}
+	if leg := resp.Legs[0]; leg.PurchasePrice != 10000.00 || leg.Discount != 0 {
+		t.Errorf("Expected 6M bill priced at face with no discount, got %+v", leg)
+	}
+	if resp.TotalPurchasePrice != 15000.00 {
+		t.Errorf("Expected total purchase price 15000.00, got %f", resp.TotalPurchasePrice)
+	}
+}
+
+// TestPriceLadder_Validation tests rejection of invalid terms and face values
+func TestPriceLadder_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		legs []LadderLeg
+	}{
+		{"Invalid term", []LadderLeg{{Term: "6M", FaceValue: 1000}, {Term: "7Y", FaceValue: 1000}}},
+		{"Zero face value", []LadderLeg{{Term: "6M", FaceValue: 0}}},
+		{"Negative face value", []LadderLeg{{Term: "2Y", FaceValue: -500}}},
+		{"No legs", []LadderLeg{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := priceLadder(services.NewTransactionService(nil, nil), tt.legs, testYieldCurve()); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
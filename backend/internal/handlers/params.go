@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"modernfi-treasury-app/internal/middleware"
+)
+
+// parseIDParam parses the named URL path parameter as a positive int32 ID.
+// The error message is suitable to return to the client with a 400.
+func parseIDParam(r *http.Request, name string) (int32, error) {
+	return parseID(chi.URLParam(r, name), name)
+}
+
+// parseID parses raw as a positive int32 ID, naming the parameter in any error.
+// Zero, negative, and out-of-range values are rejected.
+func parseID(raw string, name string) (int32, error) {
+	if raw == "" {
+		return 0, fmt.Errorf("%s is required", name)
+	}
+	id, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return int32(id), nil
+}
+
+// parseRequestUserID parses the requesting user's ID from the user_id query parameter,
+// falling back to the X-User-ID header when the parameter is absent.
+// The error message is suitable to return to the client with a 400.
+func parseRequestUserID(r *http.Request) (int32, error) {
+	raw := r.URL.Query().Get("user_id")
+	if raw == "" {
+		raw = r.Header.Get(middleware.UserIDHeader)
+	}
+	return parseID(raw, "user_id")
+}
+
+// parseAsOfDate parses an as_of date (YYYY-MM-DD) as local midnight, rejecting dates after today.
+// The error message is suitable to return to the client with a 400.
+func parseAsOfDate(raw string, now time.Time) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", raw, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("as_of must be a date in YYYY-MM-DD format")
+	}
+	if date.After(now) {
+		return time.Time{}, fmt.Errorf("as_of must not be in the future")
+	}
+	return date, nil
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"modernfi-treasury-app/internal/middleware"
+	"modernfi-treasury-app/internal/routes"
+)
+
+// TestParseID tests that only positive int32 values are accepted
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    int32
+		expectedErr string
+	}{
+		{"Valid", "42", 42, ""},
+		{"Max int32", "2147483647", 2147483647, ""},
+		{"Negative", "-1", 0, "id must be a positive integer"},
+		{"Zero", "0", 0, "id must be a positive integer"},
+		{"Overflow", "2147483648", 0, "id must be a positive integer"},
+		{"Non-numeric", "abc", 0, "id must be a positive integer"},
+		{"Decimal", "1.5", 0, "id must be a positive integer"},
+		{"Empty", "", 0, "id is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseID(tt.raw, "id")
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("parseID(%q) error = %v, want %q", tt.raw, err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseID(%q) failed: %v", tt.raw, err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseID(%q) = %d, want %d", tt.raw, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseIDParam_RejectedByHandlers tests that handlers taking an id path param return the same 400
+func TestParseIDParam_RejectedByHandlers(t *testing.T) {
+	holdingsHandlers := NewHoldingsHandlers(nil, nil)
+	txHandlers := NewTransactionHandlers(nil, nil, nil)
+	userHandler := NewUserHandler(nil, nil)
+
+	router := chi.NewRouter()
+	router.Get(routes.User, userHandler.GetUser)
+	router.Get(routes.UserHoldings, holdingsHandlers.GetUserHoldings)
+	router.Get(routes.UserPortfolio, holdingsHandlers.GetPortfolioSummary)
+	router.Get(routes.UserTermPosition, holdingsHandlers.GetTermPosition)
+	router.Get(routes.Holding, holdingsHandlers.GetHolding)
+	router.Get(routes.HoldingLifecycle, holdingsHandlers.GetHoldingLifecycle)
+	router.Get(routes.HoldingProjection, holdingsHandlers.GetHoldingProjection)
+	router.Get(routes.UserTransactions, txHandlers.GetUserTransactions)
+
+	tests := []struct {
+		path     string
+		header   string
+		expected string
+	}{
+		{routes.Path(routes.User, "abc"), "", "id must be a positive integer"},
+		{routes.Path(routes.UserHoldings, "-1"), "", "id must be a positive integer"},
+		{routes.Path(routes.UserPortfolio, "0"), "", "id must be a positive integer"},
+		{routes.Path(routes.UserTermPosition, "abc", "6M"), "", "id must be a positive integer"},
+		{routes.Path(routes.UserTermPosition, "1", "7M"), "", "invalid term: must be one of 1M, 2M, 3M, 4M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y"},
+		{routes.Path(routes.HoldingLifecycle, "abc") + "?user_id=1", "", "id must be a positive integer"},
+		{routes.Path(routes.HoldingLifecycle, "1"), "", "user_id is required"},
+		{routes.Path(routes.HoldingLifecycle, "1") + "?user_id=-3", "", "user_id must be a positive integer"},
+		{routes.Path(routes.HoldingLifecycle, "1"), "-3", "user_id must be a positive integer"},
+		{routes.Path(routes.Holding, "abc") + "?user_id=1", "", "id must be a positive integer"},
+		{routes.Path(routes.Holding, "1"), "", "user_id is required"},
+		{routes.Path(routes.HoldingProjection, "0") + "?user_id=1", "", "id must be a positive integer"},
+		{routes.Path(routes.UserTransactions, "99999999999"), "", "userId must be a positive integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.UserIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", w.Code)
+			}
+			var resp TransactionResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Error != tt.expected {
+				t.Errorf("Expected error %q, got %q", tt.expected, resp.Error)
+			}
+		})
+	}
+}
+
+// TestParseRequestUserID tests reading the user ID from the query parameter or the X-User-ID header
+func TestParseRequestUserID(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		header      string
+		expected    int32
+		expectedErr string
+	}{
+		{"Query parameter", "?user_id=7", "", 7, ""},
+		{"Header", "", "8", 8, ""},
+		{"Query parameter takes precedence", "?user_id=7", "8", 7, ""},
+		{"Invalid header", "", "abc", 0, "user_id must be a positive integer"},
+		{"Missing", "", "", 0, "user_id is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, routes.Path(routes.Holding, "1")+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.UserIDHeader, tt.header)
+			}
+			got, err := parseRequestUserID(req)
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("parseRequestUserID error = %v, want %q", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRequestUserID failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseRequestUserID = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseAsOfDate tests that as_of accepts past and current dates only, as local midnight
+func TestParseAsOfDate(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		raw         string
+		expected    time.Time
+		expectedErr string
+	}{
+		{"Past date", "2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ""},
+		{"Today", "2025-03-14", time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), ""},
+		{"Tomorrow", "2025-03-15", time.Time{}, "as_of must not be in the future"},
+		{"Timestamp", "2024-01-02T00:00:00", time.Time{}, "as_of must be a date in YYYY-MM-DD format"},
+		{"US format", "01/02/2024", time.Time{}, "as_of must be a date in YYYY-MM-DD format"},
+		{"Invalid day", "2024-02-30", time.Time{}, "as_of must be a date in YYYY-MM-DD format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAsOfDate(tt.raw, now)
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("parseAsOfDate(%q) error = %v, want %q", tt.raw, err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAsOfDate(%q) failed: %v", tt.raw, err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("parseAsOfDate(%q) = %v, want %v", tt.raw, got, tt.expected)
+			}
+		})
+	}
+}
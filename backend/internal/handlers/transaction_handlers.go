@@ -2,14 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/middleware"
+	"modernfi-treasury-app/internal/models"
 	"modernfi-treasury-app/internal/services"
 	"modernfi-treasury-app/internal/utils"
 )
@@ -20,6 +26,36 @@ type TransactionHandlers struct {
 	txService       *services.TransactionService
 	queries         *database.Queries
 	treasuryService *services.TreasuryService
+	spendRounding   utils.SpendRounding
+	minFaceValue    float64
+}
+
+const (
+	// Face value increment for buy-by-spend floor and nearest rounding (TreasuryDirect minimum)
+	spendFaceValueIncrement = 100.0
+
+	// Default smallest face value a buy may purchase, in dollars (TreasuryDirect minimum)
+	minFaceValue = 100.0
+
+	// Most legs a single batch buy may contain
+	maxBuyBatchLegs = 20
+
+	// Transaction history page size bounds
+	defaultTransactionPageLimit = 50
+	maxTransactionPageLimit     = 500
+
+	// Date format for transaction history from/to filters
+	transactionDateFormat = "2006-01-02"
+)
+
+// Transaction types accepted by the transaction history type filter
+var filterableTransactionTypes = []database.TransactionType{
+	database.TransactionTypeFund,
+	database.TransactionTypeWithdraw,
+	database.TransactionTypeBuy,
+	database.TransactionTypeSell,
+	database.TransactionTypeMature,
+	database.TransactionTypeCancel,
 }
 
 // NewTransactionHandlers creates and returns a new TransactionHandlers instance.
@@ -32,63 +68,143 @@ func NewTransactionHandlers(
 		txService:       txService,
 		queries:         queries,
 		treasuryService: treasuryService,
+		spendRounding:   utils.SpendRoundingMaxAffordable,
+		minFaceValue:    minFaceValue,
+	}
+}
+
+// SetSpendRounding sets how buy-by-spend rounds the solved face value
+// (floor_to_increment, nearest, or max_affordable).
+func (h *TransactionHandlers) SetSpendRounding(policy string) error {
+	rounding, err := utils.ParseSpendRounding(policy)
+	if err != nil {
+		return err
 	}
+	h.spendRounding = rounding
+	return nil
 }
 
-// TransactionRequest represents the incoming JSON request for fund/withdraw operations
+// SetMinFaceValue sets the smallest face value (in dollars) a buy may purchase; zero accepts any positive face value
+func (h *TransactionHandlers) SetMinFaceValue(min float64) error {
+	if min < 0 || math.IsNaN(min) || math.IsInf(min, 0) {
+		return fmt.Errorf("minimum face value must be non-negative, got: %f", min)
+	}
+	h.minFaceValue = min
+	return nil
+}
+
+// TransactionRequest represents the incoming JSON request for fund/withdraw operations.
+// The amount may be given in dollars (amount) or as integer cents (amount_cents), but not both.
+// Dollar amounts are decoded exactly; more than two decimal places is rejected rather than rounded.
+// An optional idempotency_key makes client retries safe: a replay within 24 hours is not applied twice.
 type TransactionRequest struct {
-	UserID int32   `json:"user_id"`
-	Amount float64 `json:"amount"`
+	UserID         int32       `json:"user_id"`
+	Amount         utils.Money `json:"amount"`
+	AmountCents    *int64      `json:"amount_cents,omitempty"`
+	IdempotencyKey string      `json:"idempotency_key,omitempty"`
 }
 
-// BuyRequest represents the incoming JSON request for buy operations
+// BuyRequest represents the incoming JSON request for buy operations.
+// Either a face value or a spend amount may be given; spend is solved for face value at the current yield.
 type BuyRequest struct {
-	UserID    int32   `json:"user_id"`
+	UserID         int32   `json:"user_id"`
+	Term           string  `json:"term"`
+	FaceValue      float64 `json:"face_value"`
+	FaceValueCents *int64  `json:"face_value_cents,omitempty"`
+	Spend          float64 `json:"spend,omitempty"`
+	MergeIfSameDay bool    `json:"merge_if_same_day,omitempty"` // Top up today's holding with the same term and yield
+	AsOf           string  `json:"as_of,omitempty"`             // Backdate the buy to this YYYY-MM-DD date at that day's yields
+}
+
+// BuyBatchLeg is one face-value order in a batch buy
+type BuyBatchLeg struct {
 	Term      string  `json:"term"`
 	FaceValue float64 `json:"face_value"`
 }
 
+// BuyBatchRequest represents the incoming JSON request for buying several legs at once
+type BuyBatchRequest struct {
+	UserID int32         `json:"user_id"`
+	Legs   []BuyBatchLeg `json:"legs"`
+}
+
+// BuyBatchLegResult is what one batch leg bought, keyed by its position in the request
+type BuyBatchLegResult struct {
+	Leg           int     `json:"leg"` // Index of the leg in the request
+	Term          string  `json:"term"`
+	HoldingID     int32   `json:"holding_id"`
+	FaceValue     float64 `json:"face_value"`
+	Yield         float64 `json:"yield"`
+	PurchasePrice float64 `json:"purchase_price"`
+	PricePer100   float64 `json:"price_per_100"` // Purchase price per $100 of face value
+}
+
+// BuyBatchResponse represents the JSON response for batch buys
+type BuyBatchResponse struct {
+	Success            bool                `json:"success"`
+	User               *database.User      `json:"user"`
+	TotalPurchasePrice float64             `json:"total_purchase_price"`
+	Legs               []BuyBatchLegResult `json:"legs"`
+}
+
 // SellRequest represents the incoming JSON request for sell operations
 type SellRequest struct {
-	UserID    int32   `json:"user_id"`
-	HoldingID int32   `json:"holding_id"`
-	Amount    float64 `json:"amount"`
+	UserID      int32   `json:"user_id"`
+	HoldingID   int32   `json:"holding_id"`
+	Amount      float64 `json:"amount"`
+	AmountCents *int64  `json:"amount_cents,omitempty"`
+}
+
+// MatureRequest represents the incoming JSON request for redeeming a holding at maturity
+type MatureRequest struct {
+	UserID    int32 `json:"user_id"`
+	HoldingID int32 `json:"holding_id"`
+}
+
+// RolloverRequest represents the incoming JSON request for reinvesting a matured holding
+type RolloverRequest struct {
+	UserID    int32  `json:"user_id"`
+	HoldingID int32  `json:"holding_id"`
+	Term      string `json:"term"` // Term of the new holding
 }
 
-// TransactionResponse represents the JSON response for fund/withdraw operations
+// TransactionResponse represents the JSON response for fund/withdraw operations.
+// Failed orders carry a machine-readable code (see ErrorCodeUserNotFound and friends) alongside the message.
 type TransactionResponse struct {
 	Success bool        `json:"success"`
 	User    interface{} `json:"user,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`
 }
 
 // FundHandler handles POST /api/v1/fund requests.
-// Expects JSON body with user_id and amount fields.
-// Returns updated user object on success, or error message on failure.
+// Expects JSON body with user_id and amount fields, and an optional idempotency_key.
+// A replayed idempotency_key returns the already-applied user state.
+// Returns updated user object on success, HTTP 404 if the user does not exist, or error message on failure.
 func (h *TransactionHandlers) FundHandler(w http.ResponseWriter, r *http.Request) {
 	var req TransactionRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding fund request: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding fund request", "error", err)
+		respondWithDecodeError(w, err)
 		return
 	}
 
-	// Convert float64 to pgtype.Numeric using string representation
-	amount := pgtype.Numeric{}
-	if err := amount.Scan(fmt.Sprintf("%.2f", req.Amount)); err != nil {
-		log.Printf("Error converting amount to numeric: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid amount format")
+	amount, err := resolveMoney("amount", req.Amount, req.AmountCents)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	user, err := h.txService.FundAccount(r.Context(), req.UserID, amount)
+	user, err := h.txService.FundAccount(r.Context(), req.UserID, amount, req.IdempotencyKey)
 	if err != nil {
-		log.Printf("Error funding account for user %d: %v", req.UserID, err)
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		slog.ErrorContext(r.Context(), "Error funding account", "user_id", req.UserID, "amount", amount, "error", err)
+		respondWithServiceError(w, err)
 		return
 	}
 
+	slog.InfoContext(r.Context(), "Fund successful", "user_id", req.UserID, "amount", amount)
+
 	respondWithJSON(w, http.StatusOK, TransactionResponse{
 		Success: true,
 		User:    user,
@@ -96,64 +212,234 @@ func (h *TransactionHandlers) FundHandler(w http.ResponseWriter, r *http.Request
 }
 
 // WithdrawHandler handles POST /api/v1/withdraw requests.
-// Expects JSON body with user_id and amount fields.
+// Expects JSON body with user_id and amount fields, and an optional idempotency_key.
+// A replayed idempotency_key returns the already-applied user state.
 // Validates sufficient balance before withdrawal.
 // Returns updated user object on success, or error message on failure.
 func (h *TransactionHandlers) WithdrawHandler(w http.ResponseWriter, r *http.Request) {
 	var req TransactionRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding withdraw request: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding withdraw request", "error", err)
+		respondWithDecodeError(w, err)
 		return
 	}
 
-	// Convert float64 to pgtype.Numeric using string representation
-	amount := pgtype.Numeric{}
-	if err := amount.Scan(fmt.Sprintf("%.2f", req.Amount)); err != nil {
-		log.Printf("Error converting amount to numeric: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid amount format")
+	amount, err := resolveMoney("amount", req.Amount, req.AmountCents)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	user, err := h.txService.WithdrawAccount(r.Context(), req.UserID, amount)
+	user, err := h.txService.WithdrawAccount(r.Context(), req.UserID, amount, req.IdempotencyKey)
 	if err != nil {
-		log.Printf("Error withdrawing from account for user %d: %v", req.UserID, err)
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		slog.ErrorContext(r.Context(), "Error withdrawing from account", "user_id", req.UserID, "amount", amount, "error", err)
+		respondWithServiceError(w, err)
 		return
 	}
 
+	slog.InfoContext(r.Context(), "Withdraw successful", "user_id", req.UserID, "amount", amount)
+
 	respondWithJSON(w, http.StatusOK, TransactionResponse{
 		Success: true,
 		User:    user,
 	})
 }
 
+// TransactionPage is one page of a user's transaction history.
+// TotalCount is the number of transactions matching the date and type filters across all pages.
+type TransactionPage struct {
+	Transactions []TransactionView `json:"transactions"`
+	TotalCount   int64             `json:"total_count"`
+	Limit        int32             `json:"limit"`
+	Offset       int32             `json:"offset"`
+}
+
+// transactionPageParams holds parsed pagination and date filter query parameters
+type transactionPageParams struct {
+	limit  int32
+	offset int32
+	from   pgtype.Timestamp // Inclusive lower bound, or invalid for no bound
+	to     pgtype.Timestamp // Exclusive upper bound, or invalid for no bound
+	txType database.NullTransactionType
+}
+
+// parseTransactionPageParams parses limit, offset, from, to, and type query parameters.
+// limit defaults to 50 and is capped at 500. from and to are YYYY-MM-DD dates, both inclusive.
+// type must be one of the filterable transaction types; an unknown type is an error rather than no filter.
+func parseTransactionPageParams(query url.Values) (transactionPageParams, error) {
+	params := transactionPageParams{limit: defaultTransactionPageLimit}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 32)
+		if err != nil || limit < 1 {
+			return params, errors.New("limit must be a positive integer")
+		}
+		params.limit = int32(min(limit, maxTransactionPageLimit))
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.ParseInt(offsetStr, 10, 32)
+		if err != nil || offset < 0 {
+			return params, errors.New("offset must be a non-negative integer")
+		}
+		params.offset = int32(offset)
+	}
+
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err := time.Parse(transactionDateFormat, fromStr)
+		if err != nil {
+			return params, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		params.from = pgtype.Timestamp{Time: from, Valid: true}
+	}
+
+	if toStr := query.Get("to"); toStr != "" {
+		to, err := time.Parse(transactionDateFormat, toStr)
+		if err != nil {
+			return params, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		// Include the whole "to" day
+		params.to = pgtype.Timestamp{Time: to.AddDate(0, 0, 1), Valid: true}
+	}
+
+	if params.from.Valid && params.to.Valid && !params.from.Time.Before(params.to.Time) {
+		return params, errors.New("from must not be after to")
+	}
+
+	if typeStr := query.Get("type"); typeStr != "" {
+		for _, txType := range filterableTransactionTypes {
+			if string(txType) == typeStr {
+				params.txType = database.NullTransactionType{TransactionType: txType, Valid: true}
+			}
+		}
+		if !params.txType.Valid {
+			allowed := make([]string, 0, len(filterableTransactionTypes))
+			for _, txType := range filterableTransactionTypes {
+				allowed = append(allowed, string(txType))
+			}
+			return params, fmt.Errorf("type must be one of: %s", strings.Join(allowed, ", "))
+		}
+	}
+
+	return params, nil
+}
+
 // GetUserTransactions handles GET /api/v1/users/{userId}/transactions requests.
-// Returns all transactions for the specified user, ordered by timestamp DESC.
-// Supports fund, withdraw, buy, and sell transaction types.
+// Query parameters: limit (default 50, max 500), offset (default 0), from/to (YYYY-MM-DD, inclusive),
+// and type (fund, withdraw, buy, sell, mature, or cancel).
+// Returns a page of the user's transactions ordered by timestamp DESC, with the total_count
+// of transactions matching the filters for pagination controls.
+// Each row also carries running_balance and signed_amount for statement views.
 // Used by frontend TransactionHistory component to display transaction table.
-// Returns HTTP 400 if user ID is invalid, HTTP 500 for database errors.
+// Returns HTTP 400 if user ID or query parameters are invalid, HTTP 500 for database errors.
 func (h *TransactionHandlers) GetUserTransactions(w http.ResponseWriter, r *http.Request) {
 	// Parse user ID from URL parameter
-	userIDStr := chi.URLParam(r, "userId")
-	userID, err := strconv.ParseInt(userIDStr, 10, 32)
+	userID, err := parseIDParam(r, "userId")
 	if err != nil {
-		log.Printf("Invalid user ID: %s", userIDStr)
-		respondWithError(w, http.StatusBadRequest, "invalid user ID")
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Fetch transactions using existing sqlc query
-	transactions, err := h.queries.GetTransactionsByUser(r.Context(), int32(userID))
+	params, err := parseTransactionPageParams(r.URL.Query())
 	if err != nil {
-		log.Printf("Error fetching transactions for user %d: %v", userID, err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transactions, err := h.queries.GetTransactionsByUserPaginated(r.Context(), database.GetTransactionsByUserPaginatedParams{
+		UserID:    userID,
+		FromTime:  params.from,
+		ToTime:    params.to,
+		TxType:    params.txType,
+		RowLimit:  params.limit,
+		RowOffset: params.offset,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching transactions", "user_id", userID, "error", err)
 		respondWithError(w, http.StatusInternalServerError, "failed to fetch transactions")
 		return
 	}
 
-	// Return transactions (empty array if no transactions)
-	respondWithJSON(w, http.StatusOK, transactions)
+	totalCount, err := h.queries.CountTransactionsByUser(r.Context(), database.CountTransactionsByUserParams{
+		UserID:   userID,
+		FromTime: params.from,
+		ToTime:   params.to,
+		TxType:   params.txType,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error counting transactions", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch transactions")
+		return
+	}
+
+	// Return the page (empty transactions array past the last page)
+	respondWithJSON(w, http.StatusOK, TransactionPage{
+		Transactions: newTransactionViews(transactions),
+		TotalCount:   totalCount,
+		Limit:        params.limit,
+		Offset:       params.offset,
+	})
+}
+
+// resolveAmount converts a request amount given either as a float or as integer cents into pgtype.Numeric.
+// Cents are converted exactly, without the %.2f string round-trip. Specifying both forms is rejected.
+func resolveAmount(field string, amount float64, amountCents *int64) (pgtype.Numeric, error) {
+	if amountCents != nil {
+		if amount != 0 {
+			return pgtype.Numeric{}, fmt.Errorf("specify either %s or %s_cents, not both", field, field)
+		}
+		cents, err := utils.ParseCents(*amountCents)
+		if err != nil {
+			return pgtype.Numeric{}, fmt.Errorf("invalid %s_cents: %w", field, err)
+		}
+		return cents.Numeric(), nil
+	}
+
+	numeric, err := utils.FloatToNumeric(amount)
+	if err != nil {
+		return pgtype.Numeric{}, errors.New("invalid " + field + " format")
+	}
+	return numeric, nil
+}
+
+// validateFaceValue rejects face values that are not finite, not positive, or below minimum,
+// so a malformed request can't round down to a $0.00 purchase
+func validateFaceValue(faceValue, minimum float64) error {
+	if math.IsNaN(faceValue) || math.IsInf(faceValue, 0) {
+		return errors.New("face_value must be a finite number")
+	}
+	if faceValue <= 0 {
+		return errors.New("face_value must be positive")
+	}
+	if faceValue < minimum {
+		return fmt.Errorf("face_value must be at least $%.2f", minimum)
+	}
+	return nil
+}
+
+// resolveMoney returns the dollar or cents form of an amount, rejecting requests that give both
+func resolveMoney(field string, amount utils.Money, amountCents *int64) (utils.Money, error) {
+	if amountCents != nil {
+		if amount != 0 {
+			return 0, fmt.Errorf("specify either %s or %s_cents, not both", field, field)
+		}
+		cents, err := utils.ParseCents(*amountCents)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s_cents: %w", field, err)
+		}
+		return cents, nil
+	}
+	return amount, nil
+}
+
+// numericToFloat returns the float64 value of a numeric for logging and pricing, or 0 if it is invalid
+func numericToFloat(n pgtype.Numeric) float64 {
+	f, err := utils.NumericToFloat(n)
+	if err != nil {
+		return 0
+	}
+	return f
 }
 
 // respondWithJSON is a helper function to send JSON responses with proper headers and status code
@@ -161,7 +447,7 @@ func respondWithJSON(w http.ResponseWriter, statusCode int, payload interface{})
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(payload); err != nil {
-		log.Printf("Error encoding response: %v", err)
+		slog.Error("Error encoding response", "error", err)
 	}
 }
 
@@ -173,169 +459,523 @@ func respondWithError(w http.ResponseWriter, statusCode int, message string) {
 	})
 }
 
+// decodeStrictJSON decodes the request body into v, rejecting fields v does not define
+// so a misspelled field is an error rather than silently left at its zero value
+func decodeStrictJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// respondWithDecodeError reports a request body that failed to decode: 413 if it overflowed the
+// body size limit, 400 naming the field if it had an unknown one, and 400 otherwise
+func respondWithDecodeError(w http.ResponseWriter, err error) {
+	if middleware.IsBodyTooLarge(err) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	// encoding/json has no error type for unknown fields, only this message
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		respondWithError(w, http.StatusBadRequest, "invalid request body: unknown field "+field)
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, "invalid request body")
+}
+
 // BuyHandler handles POST /api/v1/buy requests.
 // Expects JSON body with user_id, term, and face_value fields.
+// Face values that are not finite, not positive, or below the configured minimum (default $100) get HTTP 400.
 // Fetches current yield data, validates the term, calculates purchase price, and executes the buy operation atomically.
+// An optional as_of date (YYYY-MM-DD, not in the future) backdates the holding and prices it at the latest curve on or
+// before that date; the curve date used is returned as yield_date.
 // Returns updated user object with purchase details on success, or error message on failure.
 func (h *TransactionHandlers) BuyHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleBuy(w, r, false)
+}
+
+// BuyQuoteHandler handles POST /api/v1/buy/quote requests.
+// Takes the same body as BuyHandler (user_id is ignored) and runs the same validation and pricing,
+// but only quotes the order: nothing is written and no balance is checked or debited.
+// Returns the face_value, purchase_price, discount, price_per_100, and the yield and yield_date priced at.
+func (h *TransactionHandlers) BuyQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleBuy(w, r, true)
+}
+
+// handleBuy validates and prices a buy request, then executes it, or only reports the price when quoteOnly is set
+func (h *TransactionHandlers) handleBuy(w http.ResponseWriter, r *http.Request, quoteOnly bool) {
 	var req BuyRequest
 
 	// Decode JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding buy request: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding buy request", "error", err)
+		respondWithDecodeError(w, err)
 		return
 	}
 
-	log.Printf("Buy request received: user_id=%d, term=%s, face_value=%.2f", req.UserID, req.Term, req.FaceValue)
-
-	// Validate term is in allowed list
-	validTerms := map[string]bool{
-		"1M":  true,
-		"3M":  true,
-		"6M":  true,
-		"1Y":  true,
-		"2Y":  true,
-		"5Y":  true,
-		"10Y": true,
-		"30Y": true,
+	buyBySpend := req.Spend != 0
+	if buyBySpend && (req.FaceValue != 0 || req.FaceValueCents != nil) {
+		respondWithError(w, http.StatusBadRequest, "provide either face_value or spend, not both")
+		return
 	}
 
-	if !validTerms[req.Term] {
-		log.Printf("Invalid term provided: %s", req.Term)
-		respondWithError(w, http.StatusBadRequest, "invalid term: must be one of 1M, 3M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y")
-		return
+	// Convert the float or cents face value to pgtype.Numeric (solved from spend below when buying by spend)
+	var faceValueNumeric pgtype.Numeric
+	var faceValue float64
+	var err error
+	if !buyBySpend {
+		requested := req.FaceValue
+		if req.FaceValueCents != nil {
+			requested = float64(*req.FaceValueCents) / 100
+		}
+		if err := validateFaceValue(requested, h.minFaceValue); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		faceValueNumeric, err = resolveAmount("face_value", req.FaceValue, req.FaceValueCents)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error converting face value to numeric", "error", err)
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		faceValue = numericToFloat(faceValueNumeric)
 	}
 
-	// Fetch current yield data from treasury service
-	yieldData, err := h.treasuryService.GetLatestYields()
+	slog.InfoContext(r.Context(), "Buy request received", "user_id", req.UserID, "term", req.Term, "face_value", faceValue, "spend", req.Spend)
+
+	// Validate term is in allowed list, accepting any casing
+	term, err := utils.NormalizeTerm(req.Term)
 	if err != nil {
-		log.Printf("Error fetching yield data: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		slog.ErrorContext(r.Context(), "Invalid term provided", "user_id", req.UserID, "term", req.Term)
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-
-	// Extract yield rate for selected term
-	var yieldRate float64
-	found := false
-	for _, yieldPoint := range yieldData.Yields {
-		if yieldPoint.Term == req.Term {
-			yieldRate = yieldPoint.Rate
-			found = true
-			break
+	req.Term = term
+
+	// Backdated buys are priced at the curve in effect on as_of; others at the latest curve
+	var purchaseDate time.Time
+	var yieldData *models.YieldData
+	if req.AsOf != "" {
+		if !h.txService.BacktestMode() {
+			respondWithServiceError(w, fmt.Errorf("%w: set BACKTEST_MODE=true to buy with as_of", services.ErrBacktestDisabled))
+			return
+		}
+		purchaseDate, err = parseAsOfDate(req.AsOf, time.Now())
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		yieldData, err = h.treasuryService.GetYieldsForDate(req.AsOf)
+		if errors.Is(err, services.ErrNoYieldsForDate) {
+			respondWithError(w, http.StatusBadRequest, "no yield data on or before as_of date")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error fetching yield data for as_of date", "as_of", req.AsOf, "error", err)
+			respondWithError(w, http.StatusInternalServerError, "failed to fetch yield data for as_of date")
+			return
+		}
+	} else {
+		yieldData, err = h.treasuryService.GetLatestYields()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+			respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+			return
 		}
 	}
 
+	// Extract yield rate for selected term
+	yieldRate, found := yieldData.RateForTerm(req.Term)
 	if !found {
-		log.Printf("Yield not found for term: %s", req.Term)
-		respondWithError(w, http.StatusInternalServerError, "yield data not available for selected term")
+		// treasury.gov left the term blank on the latest curve; refuse rather than price at 0%
+		slog.ErrorContext(r.Context(), "Yield not found for term", "term", req.Term)
+		respondWithError(w, http.StatusServiceUnavailable, "current yield unavailable for selected term")
 		return
 	}
 
-	log.Printf("Current yield for %s: %.2f%%", req.Term, yieldRate)
+	slog.InfoContext(r.Context(), "Current yield", "term", req.Term, "yield", yieldRate, "yield_date", yieldData.Date)
 
-	// Calculate purchase price using T-Bill discount pricing
-	purchasePrice, err := utils.CalculateBillPrice(req.FaceValue, yieldRate, req.Term)
-	if err != nil {
-		// If term is not a valid T-Bill term, fall back to par pricing
-		purchasePrice = req.FaceValue
-		log.Printf("Using par pricing for term %s: purchase_price=%.2f", req.Term, purchasePrice)
-	} else {
-		discount := req.FaceValue - purchasePrice
-		log.Printf("T-Bill discount pricing: face_value=%.2f, purchase_price=%.2f, discount=%.2f", req.FaceValue, purchasePrice, discount)
+	// Solve face value from the spend amount using the configured rounding policy
+	if buyBySpend {
+		// Under par pricing a bill costs its face value, which is discount pricing at a zero rate
+		spendYield := yieldRate
+		if h.txService.ParPricing() {
+			spendYield = 0
+		}
+		// Size bills over the same period the service prices them, so the spend inverts exactly
+		spendDate := purchaseDate
+		if spendDate.IsZero() {
+			spendDate = time.Now()
+		}
+		faceValue, err = utils.FaceValueForSpend(req.Spend, spendYield, req.Term, spendDate, h.txService.BillDayCount(),
+			h.spendRounding, spendFaceValueIncrement)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if faceValue <= 0 {
+			respondWithError(w, http.StatusBadRequest, "spend is too small to buy any face value")
+			return
+		}
+		if faceValue < h.minFaceValue {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("spend is too small to buy the minimum face value of $%.2f", h.minFaceValue))
+			return
+		}
+		faceValueNumeric, err = utils.FloatToNumeric(faceValue)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error converting face value to numeric", "error", err)
+			respondWithError(w, http.StatusInternalServerError, "invalid face value format")
+			return
+		}
+		slog.InfoContext(r.Context(), "Buy by spend", "spend", req.Spend, "rounding", h.spendRounding, "face_value", faceValue)
 	}
 
-	// Convert face value to pgtype.Numeric
-	faceValueNumeric := pgtype.Numeric{}
-	if err := faceValueNumeric.Scan(fmt.Sprintf("%.2f", req.FaceValue)); err != nil {
-		log.Printf("Error converting face value to numeric: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid face value format")
+	// Convert yield to pgtype.Numeric
+	currentYield, err := utils.FloatToNumeric(yieldRate)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error converting yield to numeric", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "invalid yield format")
 		return
 	}
 
-	// Convert yield to pgtype.Numeric
-	currentYield := pgtype.Numeric{}
-	if err := currentYield.Scan(fmt.Sprintf("%.2f", yieldRate)); err != nil {
-		log.Printf("Error converting yield to numeric: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "invalid yield format")
+	if quoteOnly {
+		quotedPrice, err := h.txService.QuoteBuy(req.Term, faceValueNumeric, currentYield)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error quoting buy order", "term", req.Term, "face_value", faceValue, "error", err)
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		purchasePrice := numericToFloat(quotedPrice)
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"success":        true,
+			"face_value":     faceValue,
+			"purchase_price": purchasePrice,
+			"discount":       math.Round((faceValue-purchasePrice)*100) / 100,
+			"price_per_100":  utils.CalculatePricePer100(faceValue, purchasePrice),
+			"yield":          yieldRate,
+			"yield_date":     yieldData.Date,
+		})
 		return
 	}
 
-	// Call txService.BuyTreasury() with face value (service will calculate purchase price again)
-	user, err := h.txService.BuyTreasury(r.Context(), req.UserID, req.Term, faceValueNumeric, currentYield)
+	// The service prices the order; report exactly what it charged rather than recomputing
+	var result *services.BuyResult
+	if req.AsOf != "" {
+		result, err = h.txService.BuyTreasuryAsOf(r.Context(), req.UserID, req.Term, faceValueNumeric, currentYield, req.MergeIfSameDay, purchaseDate)
+	} else {
+		result, err = h.txService.BuyTreasury(r.Context(), req.UserID, req.Term, faceValueNumeric, currentYield, req.MergeIfSameDay)
+	}
 	if err != nil {
-		log.Printf("Error executing buy order for user %d: %v", req.UserID, err)
-		respondWithError(w, http.StatusBadRequest, err.Error())
+		slog.ErrorContext(r.Context(), "Error executing buy order", "user_id", req.UserID, "term", req.Term, "face_value", faceValue, "error", err)
+		respondWithServiceError(w, err)
 		return
 	}
 
-	log.Printf("Buy order successful: user_id=%d, term=%s, face_value=%.2f, purchase_price=%.2f, yield=%.2f%%",
-		req.UserID, req.Term, req.FaceValue, purchasePrice, yieldRate)
+	purchasePrice := numericToFloat(result.PurchasePrice)
+	faceValue = numericToFloat(result.FaceValue)
+	discount := math.Round((faceValue-purchasePrice)*100) / 100
+
+	slog.InfoContext(r.Context(), "Buy order successful", "user_id", req.UserID, "term", req.Term, "face_value", faceValue,
+		"purchase_price", purchasePrice, "discount", discount, "yield", yieldRate)
 
 	// Return success response with updated user and purchase details
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"success":        true,
-		"user":           user,
-		"face_value":     req.FaceValue,
+		"user":           result.User,
+		"face_value":     faceValue,
 		"purchase_price": purchasePrice,
-		"discount":       req.FaceValue - purchasePrice,
+		"discount":       discount,
+		"price_per_100":  utils.CalculatePricePer100(faceValue, purchasePrice),
+		"yield_date":     yieldData.Date,
 	})
 }
 
+// BuyBatchHandler handles POST /api/v1/buy/batch requests.
+// Expects JSON body with user_id and a legs array of {term, face_value} orders, bought atomically at current yields.
+// Legs are never merged, so repeating a term buys separate holdings; the response has one entry per leg in request order.
+func (h *TransactionHandlers) BuyBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req BuyBatchRequest
+
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding buy batch request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	if len(req.Legs) == 0 {
+		respondWithError(w, http.StatusBadRequest, "at least one leg is required")
+		return
+	}
+	if len(req.Legs) > maxBuyBatchLegs {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("at most %d legs may be bought at once", maxBuyBatchLegs))
+		return
+	}
+
+	// Validate every leg before fetching yields so bad input never reaches the upstream
+	faceValues := make([]pgtype.Numeric, len(req.Legs))
+	for i, leg := range req.Legs {
+		term, err := utils.NormalizeTerm(leg.Term)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("leg %d: %v", i, err))
+			return
+		}
+		req.Legs[i].Term = term
+		if err := validateFaceValue(leg.FaceValue, h.minFaceValue); err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("leg %d: %v", i, err))
+			return
+		}
+		faceValue, err := resolveAmount("face_value", leg.FaceValue, nil)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("leg %d: %v", i, err))
+			return
+		}
+		faceValues[i] = faceValue
+	}
+
+	slog.InfoContext(r.Context(), "Buy batch request received", "user_id", req.UserID, "legs", len(req.Legs))
+
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		return
+	}
+
+	legs := make([]services.BuyLeg, len(req.Legs))
+	yields := make([]float64, len(req.Legs))
+	for i, leg := range req.Legs {
+		yieldRate, found := yieldData.RateForTerm(leg.Term)
+		if !found {
+			slog.ErrorContext(r.Context(), "Yield not found for term", "leg", i, "term", leg.Term)
+			respondWithError(w, http.StatusServiceUnavailable, fmt.Sprintf("leg %d: current yield unavailable for selected term", i))
+			return
+		}
+		currentYield, err := utils.FloatToNumeric(yieldRate)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error converting yield to numeric", "error", err)
+			respondWithError(w, http.StatusInternalServerError, "invalid yield format")
+			return
+		}
+
+		legs[i] = services.BuyLeg{Term: leg.Term, FaceValue: faceValues[i], CurrentYield: currentYield}
+		yields[i] = yieldRate
+	}
+
+	results, err := h.txService.BuyTreasuryBatch(r.Context(), req.UserID, legs)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error executing buy batch", "user_id", req.UserID, "legs", len(legs), "error", err)
+		respondWithServiceError(w, err)
+		return
+	}
+
+	resp := buildBuyBatchResponse(req.Legs, yields, results)
+	slog.InfoContext(r.Context(), "Buy batch successful", "user_id", req.UserID, "legs", len(resp.Legs), "total_purchase_price", resp.TotalPurchasePrice)
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// buildBuyBatchResponse reports each leg's result at its request index; results must be in leg order.
+// The user is taken from the last result, which reflects every leg's deduction.
+func buildBuyBatchResponse(legs []BuyBatchLeg, yields []float64, results []*services.BuyResult) *BuyBatchResponse {
+	resp := &BuyBatchResponse{
+		Success: true,
+		Legs:    make([]BuyBatchLegResult, len(results)),
+	}
+
+	for i, result := range results {
+		faceValue := numericToFloat(result.FaceValue)
+		purchasePrice := numericToFloat(result.PurchasePrice)
+		resp.Legs[i] = BuyBatchLegResult{
+			Leg:           i,
+			Term:          legs[i].Term,
+			HoldingID:     result.Holding.ID,
+			FaceValue:     faceValue,
+			Yield:         yields[i],
+			PurchasePrice: purchasePrice,
+			PricePer100:   utils.CalculatePricePer100(faceValue, purchasePrice),
+		}
+		resp.TotalPurchasePrice += purchasePrice
+		resp.User = result.User
+	}
+
+	resp.TotalPurchasePrice = math.Round(resp.TotalPurchasePrice*100) / 100
+	return resp
+}
+
 // SellHandler handles POST /api/v1/sell requests.
 // Expects JSON body with user_id, holding_id, and amount fields.
 // Validates holding ownership, calculates yield, and processes the sell atomically.
-// Returns updated user object on success, or error message on failure.
+// Returns the updated user with the proceeds credited and their interest_earned portion on success,
+// or error message on failure.
 func (h *TransactionHandlers) SellHandler(w http.ResponseWriter, r *http.Request) {
 	var req SellRequest
 
 	// Decode JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("Error decoding sell request: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding sell request", "error", err)
+		respondWithDecodeError(w, err)
 		return
 	}
 
-	log.Printf("Sell request received: user_id=%d, holding_id=%d, amount=%.2f", req.UserID, req.HoldingID, req.Amount)
-
-	// Convert amount to pgtype.Numeric
-	amount := pgtype.Numeric{}
-	if err := amount.Scan(fmt.Sprintf("%.2f", req.Amount)); err != nil {
-		log.Printf("Error converting amount to numeric: %v", err)
-		respondWithError(w, http.StatusBadRequest, "invalid amount format")
+	// Convert the float or cents amount to pgtype.Numeric
+	amount, err := resolveAmount("amount", req.Amount, req.AmountCents)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error converting amount to numeric", "error", err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	slog.InfoContext(r.Context(), "Sell request received", "user_id", req.UserID, "holding_id", req.HoldingID, "amount", numericToFloat(amount))
+
 	// Call txService.SellTreasury()
-	user, err := h.txService.SellTreasury(r.Context(), req.UserID, req.HoldingID, amount)
+	result, err := h.txService.SellTreasury(r.Context(), req.UserID, req.HoldingID, amount)
 	if err != nil {
-		log.Printf("Error executing sell order for user %d: %v", req.UserID, err)
+		slog.ErrorContext(r.Context(), "Error executing sell order", "user_id", req.UserID, "holding_id", req.HoldingID, "amount", numericToFloat(amount), "error", err)
+		// Unknown holding is 404, another user's holding 403, anything else 400
+		respondWithServiceError(w, err)
+		return
+	}
 
-		// Map specific errors to appropriate HTTP status codes
-		errMsg := err.Error()
+	proceeds := numericToFloat(result.Proceeds)
+	interestEarned := numericToFloat(result.InterestEarned)
+	slog.InfoContext(r.Context(), "Sell order successful", "user_id", req.UserID, "holding_id", req.HoldingID, "amount", numericToFloat(amount),
+		"proceeds", proceeds, "interest_earned", interestEarned)
 
-		// Not found errors (404)
-		if errMsg == "holding not found: no rows in result set" {
-			respondWithError(w, http.StatusNotFound, "holding not found")
-			return
-		}
+	// Return success response with updated user and what the sale credited
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success":         true,
+		"user":            result.User,
+		"proceeds":        proceeds,
+		"interest_earned": interestEarned,
+	})
+}
 
-		// Forbidden errors (403) - holding doesn't belong to user
-		if errMsg == "unauthorized: holding does not belong to user" {
-			respondWithError(w, http.StatusForbidden, "unauthorized: holding does not belong to user")
-			return
-		}
+// MatureHandler handles POST /api/v1/mature requests.
+// Expects JSON body with user_id and holding_id fields.
+// Redeems the holding's remaining amount at full-term value if it has reached maturity;
+// returns 400 with the days remaining if it has not.
+func (h *TransactionHandlers) MatureHandler(w http.ResponseWriter, r *http.Request) {
+	var req MatureRequest
+
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding mature request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Mature request received", "user_id", req.UserID, "holding_id", req.HoldingID)
+
+	user, err := h.txService.MatureHolding(r.Context(), req.UserID, req.HoldingID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error maturing holding", "user_id", req.UserID, "holding_id", req.HoldingID, "error", err)
+		// Unknown holding is 404, another user's holding 403, anything else 400
+		respondWithServiceError(w, err)
+		return
+	}
 
-		// All other errors (400) - bad request
+	slog.InfoContext(r.Context(), "Mature successful", "user_id", req.UserID, "holding_id", req.HoldingID)
+
+	respondWithJSON(w, http.StatusOK, TransactionResponse{
+		Success: true,
+		User:    user,
+	})
+}
+
+// CancelHandler handles DELETE /api/v1/holdings/{id} requests.
+// Query parameter: user_id (required) - the holding must belong to this user.
+// Unwinds a buy made today that has not been sold from: the purchase price is refunded and the holding deleted.
+// Returns 400 for older or partially sold holdings, which must be sold instead.
+func (h *TransactionHandlers) CancelHandler(w http.ResponseWriter, r *http.Request) {
+	holdingID, err := parseIDParam(r, "id")
+	if err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	log.Printf("Sell order successful: user_id=%d, holding_id=%d, amount=%.2f", req.UserID, req.HoldingID, req.Amount)
+	userID, err := parseID(r.URL.Query().Get("user_id"), "user_id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Cancel request received", "user_id", userID, "holding_id", holdingID)
+
+	user, err := h.txService.CancelHolding(r.Context(), userID, holdingID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error cancelling holding", "user_id", userID, "holding_id", holdingID, "error", err)
+		// Unknown holding is 404, another user's holding 403, anything else 400
+		respondWithServiceError(w, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Cancel successful", "user_id", userID, "holding_id", holdingID)
 
-	// Return success response with updated user
 	respondWithJSON(w, http.StatusOK, TransactionResponse{
 		Success: true,
 		User:    user,
 	})
 }
+
+// RolloverHandler handles POST /api/v1/rollover requests.
+// Expects JSON body with user_id, holding_id, and the term to roll into.
+// Matures the holding and buys the new term at the current yield with the proceeds in one transaction;
+// any proceeds not reinvested stay in the balance.
+func (h *TransactionHandlers) RolloverHandler(w http.ResponseWriter, r *http.Request) {
+	var req RolloverRequest
+
+	if err := decodeStrictJSON(r, &req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding rollover request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Rollover request received", "user_id", req.UserID, "holding_id", req.HoldingID, "term", req.Term)
+
+	term, err := utils.NormalizeTerm(req.Term)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Term = term
+
+	yieldData, err := h.treasuryService.GetLatestYields()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching yield data", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch current yield data")
+		return
+	}
+	yieldRate, found := yieldData.RateForTerm(req.Term)
+	if !found {
+		// treasury.gov left the term blank on the latest curve; refuse rather than price at 0%
+		slog.ErrorContext(r.Context(), "Yield not found for term", "term", req.Term)
+		respondWithError(w, http.StatusServiceUnavailable, "current yield unavailable for selected term")
+		return
+	}
+	currentYield, err := utils.FloatToNumeric(yieldRate)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error converting yield to numeric", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "invalid yield format")
+		return
+	}
+
+	result, err := h.txService.RolloverHolding(r.Context(), req.UserID, req.HoldingID, req.Term, currentYield)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error rolling over holding", "user_id", req.UserID, "holding_id", req.HoldingID, "term", req.Term, "error", err)
+		// Unknown holding is 404, another user's holding 403, anything else 400
+		respondWithServiceError(w, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Rollover successful", "user_id", req.UserID, "holding_id", req.HoldingID, "term", req.Term,
+		"new_holding_id", result.Buy.Holding.ID)
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"success":        true,
+		"user":           result.User,
+		"proceeds":       numericToFloat(result.Proceeds),
+		"new_holding_id": result.Buy.Holding.ID,
+		"face_value":     numericToFloat(result.Buy.FaceValue),
+		"purchase_price": numericToFloat(result.Buy.PurchasePrice),
+		"remainder":      numericToFloat(result.Remainder),
+	})
+}
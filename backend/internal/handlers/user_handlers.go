@@ -2,22 +2,46 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/services"
+	"modernfi-treasury-app/internal/utils"
 )
 
+const (
+	// Longest user name the users.name VARCHAR(100) column holds
+	maxUserNameLength = 100
+
+	// Largest balance the users.balance NUMERIC(12, 2) column holds, in cents
+	maxUserBalanceCents = 999_999_999_999
+)
+
+// CreateUserRequest represents the incoming JSON request for creating a user
+type CreateUserRequest struct {
+	Name    string      `json:"name"`
+	Balance utils.Money `json:"balance"` // Initial balance; defaults to zero
+}
+
 // UserHandler handles HTTP requests related to user operations.
-// It uses sqlc-generated queries for type-safe database access.
+// It uses sqlc-generated queries for type-safe database access, and the transaction
+// service to create users so an initial balance is funded like any other deposit.
 type UserHandler struct {
-	queries *database.Queries
+	queries   *database.Queries
+	txService *services.TransactionService
 }
 
 // NewUserHandler creates and returns a new UserHandler instance.
 // The queries parameter should be initialized with a database connection pool.
-func NewUserHandler(queries *database.Queries) *UserHandler {
-	return &UserHandler{queries: queries}
+func NewUserHandler(queries *database.Queries, txService *services.TransactionService) *UserHandler {
+	return &UserHandler{queries: queries, txService: txService}
 }
 
 // GetAllUsers handles GET /api/v1/users requests.
@@ -27,7 +51,7 @@ func NewUserHandler(queries *database.Queries) *UserHandler {
 func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := h.queries.ListUsers(r.Context())
 	if err != nil {
-		log.Printf("Error fetching users: %v", err)
+		slog.ErrorContext(r.Context(), "Error fetching users", "error", err)
 		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
 		return
 	}
@@ -35,8 +59,96 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	// sqlc with emit_empty_slices ensures users is [] not nil
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(users); err != nil {
-		log.Printf("Error encoding users: %v", err)
+		slog.ErrorContext(r.Context(), "Error encoding users", "error", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
+
+// GetUser handles GET /api/v1/users/{id} requests.
+// Returns the user in the same JSON shape as the GetAllUsers entries, so the balance can be refreshed
+// without listing everyone. Returns HTTP 400 if the ID is invalid, HTTP 404 if no such user exists,
+// HTTP 500 for database errors.
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.queries.GetUser(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error fetching user", "user_id", userID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch user")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// CreateUser handles POST /api/v1/users requests.
+// Expects JSON body with a name and an optional initial balance (default zero).
+// A positive balance is funded in the same transaction, so it must meet the minimum fund amount and
+// the per-transaction cap, is recorded as a fund transaction, and notifies the large transaction webhook.
+// Returns the created user with HTTP 201, HTTP 400 if the name is blank or too long or the balance is
+// negative, too large, or refused as a fund, and HTTP 409 if the name is already taken under a unique constraint.
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Error decoding create user request", "error", err)
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	name, err := validateNewUser(req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Balance.IsPositive() {
+		if err := h.txService.CheckFundAmount(req.Balance); err != nil {
+			respondWithError(w, http.StatusBadRequest, "balance: "+err.Error())
+			return
+		}
+	}
+
+	user, err := h.txService.CreateUser(r.Context(), name, req.Balance)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "a user with that name already exists")
+			return
+		}
+		slog.ErrorContext(r.Context(), "Error creating user", "name", name, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to create user")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "User created", "user_id", user.ID, "balance", req.Balance)
+
+	respondWithJSON(w, http.StatusCreated, user)
+}
+
+// validateNewUser checks a create user request, returning the name with surrounding whitespace trimmed.
+// The error message is suitable to return to the client with a 400.
+func validateNewUser(req CreateUserRequest) (string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxUserNameLength {
+		return "", fmt.Errorf("name must be at most %d characters", maxUserNameLength)
+	}
+	if req.Balance < 0 {
+		return "", errors.New("balance must not be negative")
+	}
+	if req.Balance.Cents() > maxUserBalanceCents {
+		return "", fmt.Errorf("balance must not exceed %s", utils.MoneyFromCents(maxUserBalanceCents))
+	}
+	return name, nil
+}
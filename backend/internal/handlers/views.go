@@ -0,0 +1,803 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// TransactionView augments a transaction row with statement-friendly computed fields.
+// Embedding keeps the original transaction fields at the top level of the JSON object.
+type TransactionView struct {
+	database.Transaction
+	RunningBalance pgtype.Numeric `json:"running_balance"` // Balance after this transaction
+	SignedAmount   pgtype.Numeric `json:"signed_amount"`   // Negative for cash leaving the balance
+}
+
+// newTransactionView maps a transaction row to its view, deriving the amount sign from the transaction type.
+// Funds, sells, maturities, and cancels add cash to the balance; withdraws and buys remove it.
+func newTransactionView(tx database.Transaction) TransactionView {
+	signed := tx.Amount
+	switch tx.Type {
+	case database.TransactionTypeWithdraw, database.TransactionTypeBuy:
+		signed = negateNumeric(tx.Amount)
+	}
+
+	return TransactionView{
+		Transaction:    tx,
+		RunningBalance: tx.BalanceAfter,
+		SignedAmount:   signed,
+	}
+}
+
+// newTransactionViews maps transaction rows to views, preserving order
+func newTransactionViews(transactions []database.Transaction) []TransactionView {
+	views := make([]TransactionView, 0, len(transactions))
+	for _, tx := range transactions {
+		views = append(views, newTransactionView(tx))
+	}
+	return views
+}
+
+// negateNumeric returns -n without mutating n's underlying big.Int
+func negateNumeric(n pgtype.Numeric) pgtype.Numeric {
+	if !n.Valid || n.Int == nil {
+		return n
+	}
+	n.Int = new(big.Int).Neg(n.Int)
+	return n
+}
+
+// MaturityAlert describes an active holding that matures soon
+type MaturityAlert struct {
+	HoldingID        int32   `json:"holding_id"`
+	Term             string  `json:"term"`
+	SecurityType     string  `json:"security_type"`
+	MaturityDate     string  `json:"maturity_date"`
+	DaysRemaining    int     `json:"days_remaining"`
+	RemainingAmount  float64 `json:"remaining_amount"`
+	ExpectedProceeds float64 `json:"expected_proceeds"`
+}
+
+// holdingSecurityType returns the stored security type, inferring it from the term for legacy holdings
+func holdingSecurityType(holding database.Holding) (string, error) {
+	if holding.SecurityType.Valid && holding.SecurityType.String != "" {
+		return holding.SecurityType.String, nil
+	}
+	return utils.GetSecurityType(holding.Term)
+}
+
+// buildMaturityAlerts returns active holdings maturing within withinDays of now, soonest first.
+// Holdings already past maturity are included with zero days remaining; legacy holdings with a null
+// remaining_amount count at their face value, as in the holdings list.
+func buildMaturityAlerts(ctx context.Context, holdings []database.Holding, now time.Time, withinDays int) ([]MaturityAlert, error) {
+	alerts := []MaturityAlert{}
+
+	for _, holding := range holdings {
+		holding = withLegacyRemaining(ctx, holding)
+		if !isActiveHolding(holding) {
+			continue
+		}
+
+		maturity, err := holdingMaturityDate(holding)
+		if err != nil {
+			return nil, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+
+		daysRemaining := int(math.Ceil(maturity.Sub(now).Hours() / 24))
+		if daysRemaining > withinDays {
+			continue
+		}
+		if daysRemaining < 0 {
+			daysRemaining = 0
+		}
+
+		securityType, err := holdingSecurityType(holding)
+		if err != nil {
+			return nil, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+
+		remaining := numericToFloat(holding.RemainingAmount)
+		proceeds, err := utils.CalculateMaturityProceeds(remaining, numericToFloat(holding.YieldAtPurchase), holding.Term)
+		if err != nil {
+			return nil, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+
+		alerts = append(alerts, MaturityAlert{
+			HoldingID:        holding.ID,
+			Term:             holding.Term,
+			SecurityType:     securityType,
+			MaturityDate:     maturity.Format("2006-01-02"),
+			DaysRemaining:    daysRemaining,
+			RemainingAmount:  remaining,
+			ExpectedProceeds: proceeds,
+		})
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].MaturityDate < alerts[j].MaturityDate
+	})
+
+	return alerts, nil
+}
+
+// MaturingHolding is an active holding due to mature soon, with what it pays out at maturity.
+// Embedding keeps the original holding fields at the top level of the JSON object.
+type MaturingHolding struct {
+	database.Holding
+	DaysToMaturity  int     `json:"days_to_maturity"` // Zero once matured
+	ProjectedPayout float64 `json:"projected_payout"` // Bills redeem at face value; notes and bonds add full-term interest
+}
+
+// holdingMaturityDate returns the maturity date stored at purchase, computing it from the term if absent.
+// Holdings without a stored maturity predate BILL_DAY_COUNT, so they mature on 30/360.
+func holdingMaturityDate(holding database.Holding) (time.Time, error) {
+	if holding.MaturityDate.Valid {
+		return holding.MaturityDate.Time, nil
+	}
+	return utils.MaturityDate(holding.PurchaseDate.Time, holding.Term, utils.DayCountThirty360)
+}
+
+// holdingTermDays returns the calendar days from a holding's purchase to its maturity date,
+// the period a bill's discount is earned over
+func holdingTermDays(holding database.Holding) (int, error) {
+	maturity, err := holdingMaturityDate(holding)
+	if err != nil {
+		return 0, err
+	}
+	return utils.DaysUntil(maturity, holding.PurchaseDate.Time), nil
+}
+
+// buildMaturingHoldings adds days to maturity and projected payouts to holdings, keeping their order.
+// Holdings already past maturity report zero days; legacy holdings with a null remaining_amount pay out
+// at their face value, as in the holdings list.
+func buildMaturingHoldings(ctx context.Context, holdings []database.Holding, now time.Time) ([]MaturingHolding, error) {
+	maturing := make([]MaturingHolding, 0, len(holdings))
+
+	for _, holding := range holdings {
+		holding = withLegacyRemaining(ctx, holding)
+		maturity, err := holdingMaturityDate(holding)
+		if err != nil {
+			return nil, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		daysToMaturity := max(int(math.Ceil(maturity.Sub(now).Hours()/24)), 0)
+
+		payout, err := utils.CalculateMaturityProceeds(numericToFloat(holding.RemainingAmount), numericToFloat(holding.YieldAtPurchase), holding.Term)
+		if err != nil {
+			return nil, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+
+		maturing = append(maturing, MaturingHolding{
+			Holding:         holding,
+			DaysToMaturity:  daysToMaturity,
+			ProjectedPayout: payout,
+		})
+	}
+
+	return maturing, nil
+}
+
+// HoldingView augments a holding row with computed display fields.
+// Embedding keeps the original holding fields at the top level of the JSON object.
+type HoldingView struct {
+	database.Holding
+	InvestmentYield   float64  `json:"investment_yield"`    // 365-day bond-equivalent yield, comparable across bills, notes, and bonds
+	SecurityTypeLabel string   `json:"security_type_label"` // Display name, e.g. "Treasury Bill"; inferred from term for legacy holdings
+	Discount          float64  `json:"discount"`            // Original face value minus purchase price; zero for notes and bonds bought at par
+	PricePer100       float64  `json:"price_per_100"`       // Purchase price per $100 of original face value, e.g. 97.75
+	DaysHeld          int      `json:"days_held"`
+	CurrentValue      *float64 `json:"current_value"` // Remaining amount valued at the latest yields; null when yields are unavailable
+}
+
+// newHoldingView maps a holding to its view as of now.
+// Bills convert their stored discount yield to an investment yield using the price paid;
+// notes and bonds are bought at par, so their stored yield is already on an investment basis.
+// CurrentValue is only set when yieldData is non-nil, with bills discounted under dayCount.
+func newHoldingView(holding database.Holding, yieldData *models.YieldData, dayCount utils.DayCountConvention, now time.Time) (HoldingView, error) {
+	view := HoldingView{Holding: holding}
+
+	securityType, err := holdingSecurityType(holding)
+	if err != nil {
+		return view, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	view.SecurityTypeLabel = utils.SecurityTypeLabel(securityType)
+
+	faceValue, purchasePrice, err := holdingFaceAndPrice(holding)
+	if err != nil {
+		return view, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	view.Discount = math.Round((faceValue-purchasePrice)*100) / 100
+	view.PricePer100 = utils.CalculatePricePer100(faceValue, purchasePrice)
+
+	if daysHeld := int(now.Sub(holding.PurchaseDate.Time).Hours() / 24); daysHeld > 0 {
+		view.DaysHeld = daysHeld
+	}
+
+	if yieldData != nil {
+		currentValue, err := holdingMarketValue(holding, yieldData, dayCount, now)
+		if err != nil {
+			return view, err
+		}
+		currentValue = math.Round(currentValue*100) / 100
+		view.CurrentValue = &currentValue
+	}
+
+	yieldRate := numericToFloat(holding.YieldAtPurchase)
+	if securityType != utils.SecurityTypeBill {
+		view.InvestmentYield = yieldRate
+		return view, nil
+	}
+
+	view.InvestmentYield, err = utils.CalculateInvestmentYield(faceValue, purchasePrice, holding.Term)
+	if err != nil {
+		return view, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	return view, nil
+}
+
+// holdingMarketValue values a holding's remaining amount at the latest yield for its term, discounting bills under dayCount.
+// When the latest curve has no yield for the term, the holding is valued at its purchase yield.
+func holdingMarketValue(holding database.Holding, yieldData *models.YieldData, dayCount utils.DayCountConvention, now time.Time) (float64, error) {
+	currentYield, found := yieldData.RateForTerm(holding.Term)
+	if !found {
+		currentYield = numericToFloat(holding.YieldAtPurchase)
+	}
+	maturity, err := holdingMaturityDate(holding)
+	if err != nil {
+		return 0, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	marketValue, err := utils.CalculateMarketValue(numericToFloat(holding.RemainingAmount), numericToFloat(holding.YieldAtPurchase), currentYield,
+		holding.Term, now, maturity, dayCount)
+	if err != nil {
+		return 0, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	return marketValue, nil
+}
+
+// holdingFaceAndPrice returns a holding's original face value and purchase price.
+// Legacy holdings without a stored face value use amount; without a stored price they are repriced
+// from their yield at purchase (discount pricing for bills, par for notes and bonds). Those holdings
+// predate BILL_DAY_COUNT, so bills are repriced on the 30/360 convention they were bought under.
+func holdingFaceAndPrice(holding database.Holding) (float64, float64, error) {
+	faceValue := numericToFloat(holding.FaceValue)
+	if !holding.FaceValue.Valid {
+		faceValue = numericToFloat(holding.Amount)
+	}
+
+	if holding.PurchasePrice.Valid {
+		return faceValue, numericToFloat(holding.PurchasePrice), nil
+	}
+	purchasePrice, err := utils.CalculatePurchasePrice(faceValue, numericToFloat(holding.YieldAtPurchase), holding.Term,
+		holding.PurchaseDate.Time, utils.DayCountThirty360)
+	if err != nil {
+		return 0, 0, err
+	}
+	return faceValue, purchasePrice, nil
+}
+
+// ProjectedIncome is the interest income active holdings are expected to earn over upcoming horizons
+type ProjectedIncome struct {
+	Next30Days  float64 `json:"next_30_days"`
+	Next90Days  float64 `json:"next_90_days"`
+	Next365Days float64 `json:"next_365_days"`
+}
+
+// PortfolioTotals aggregates active holdings' value figures.
+// FaceValue and CostBasis cover only the unsold portion of each holding.
+type PortfolioTotals struct {
+	Holdings    int     `json:"holdings"`
+	FaceValue   float64 `json:"face_value"`
+	CostBasis   float64 `json:"cost_basis"`
+	MarketValue float64 `json:"market_value"`
+}
+
+// PortfolioSummary is the portfolio-level view of a user's active holdings
+type PortfolioSummary struct {
+	TotalCostBasis   float64                    `json:"total_cost_basis"`
+	TotalFaceValue   float64                    `json:"total_face_value"`
+	TotalMarketValue float64                    `json:"total_market_value"`
+	BySecurityType   map[string]PortfolioTotals `json:"by_security_type"`
+	ProjectedIncome  ProjectedIncome            `json:"projected_income"`
+}
+
+// buildPortfolioSummary computes the portfolio summary for a user's holdings as of now.
+// Sold-out holdings are excluded; legacy holdings with a null remaining_amount count at their face value,
+// as in the holdings list. Cost basis is each holding's purchase price weighted by its
+// remaining fraction; market value reprices the remaining amount at the term's current yield under dayCount.
+func buildPortfolioSummary(ctx context.Context, holdings []database.Holding, yieldData *models.YieldData, dayCount utils.DayCountConvention,
+	now time.Time) (PortfolioSummary, error) {
+	summary := PortfolioSummary{
+		BySecurityType: map[string]PortfolioTotals{},
+	}
+
+	// Normalized once so the totals and projected income see the same holdings
+	normalized := make([]database.Holding, 0, len(holdings))
+	for _, holding := range holdings {
+		normalized = append(normalized, withLegacyRemaining(ctx, holding))
+	}
+	holdings = normalized
+
+	for _, holding := range holdings {
+		if !isActiveHolding(holding) {
+			continue
+		}
+
+		securityType, err := holdingSecurityType(holding)
+		if err != nil {
+			return summary, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		faceValue, purchasePrice, err := holdingFaceAndPrice(holding)
+		if err != nil {
+			return summary, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		remaining := numericToFloat(holding.RemainingAmount)
+		costBasis := 0.0
+		if faceValue > 0 {
+			costBasis = purchasePrice * remaining / faceValue
+		}
+
+		marketValue, err := holdingMarketValue(holding, yieldData, dayCount, now)
+		if err != nil {
+			return summary, err
+		}
+
+		totals := summary.BySecurityType[securityType]
+		totals.Holdings++
+		totals.FaceValue += remaining
+		totals.CostBasis += costBasis
+		totals.MarketValue += marketValue
+		summary.BySecurityType[securityType] = totals
+	}
+
+	// Round once per bucket so float noise doesn't accumulate across holdings
+	for securityType, totals := range summary.BySecurityType {
+		totals.FaceValue = math.Round(totals.FaceValue*100) / 100
+		totals.CostBasis = math.Round(totals.CostBasis*100) / 100
+		totals.MarketValue = math.Round(totals.MarketValue*100) / 100
+		summary.BySecurityType[securityType] = totals
+
+		summary.TotalFaceValue += totals.FaceValue
+		summary.TotalCostBasis += totals.CostBasis
+		summary.TotalMarketValue += totals.MarketValue
+	}
+	summary.TotalFaceValue = math.Round(summary.TotalFaceValue*100) / 100
+	summary.TotalCostBasis = math.Round(summary.TotalCostBasis*100) / 100
+	summary.TotalMarketValue = math.Round(summary.TotalMarketValue*100) / 100
+
+	var err error
+	if summary.ProjectedIncome.Next30Days, err = projectIncome(holdings, now, 30); err != nil {
+		return summary, err
+	}
+	if summary.ProjectedIncome.Next90Days, err = projectIncome(holdings, now, 90); err != nil {
+		return summary, err
+	}
+	if summary.ProjectedIncome.Next365Days, err = projectIncome(holdings, now, 365); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// projectIncome returns the interest active holdings will accrue over the next horizonDays days.
+// Each holding accrues only until its maturity, so holdings maturing inside the horizon contribute
+// a partial amount and matured holdings contribute nothing.
+// Bills accrete their discount evenly over the term; notes and bonds earn simple interest on a 365-day basis,
+// matching CalculateMaturityProceeds.
+func projectIncome(holdings []database.Holding, now time.Time, horizonDays int) (float64, error) {
+	if horizonDays < 0 {
+		return 0, fmt.Errorf("horizon must be non-negative, got: %d", horizonDays)
+	}
+
+	total := 0.0
+	for _, holding := range holdings {
+		if !isActiveHolding(holding) {
+			continue
+		}
+
+		maturity, err := holdingMaturityDate(holding)
+		if err != nil {
+			return 0, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		accrualDays := min(horizonDays, utils.DaysUntil(maturity, now))
+		if accrualDays <= 0 {
+			continue
+		}
+
+		securityType, err := holdingSecurityType(holding)
+		if err != nil {
+			return 0, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+
+		remaining := numericToFloat(holding.RemainingAmount)
+		if securityType != utils.SecurityTypeBill {
+			total += remaining * numericToFloat(holding.YieldAtPurchase) / 100.0 * float64(accrualDays) / 365.0
+			continue
+		}
+
+		faceValue, purchasePrice, err := holdingFaceAndPrice(holding)
+		if err != nil {
+			return 0, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		if faceValue <= 0 {
+			continue
+		}
+		termDays, err := holdingTermDays(holding)
+		if err != nil {
+			return 0, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		if termDays <= 0 {
+			continue
+		}
+		// Discount on the unsold fraction, earned evenly over the bill's term
+		discount := (faceValue - purchasePrice) * remaining / faceValue
+		total += discount * float64(accrualDays) / float64(termDays)
+	}
+
+	return math.Round(total*100) / 100, nil
+}
+
+// TermPosition is a user's aggregate position in one term across its active holdings.
+// A term with no active holdings is a zeroed position with a null nearest maturity.
+type TermPosition struct {
+	Term                 string  `json:"term"`
+	SecurityType         string  `json:"security_type"`
+	Holdings             int     `json:"holdings"`
+	TotalFaceValue       float64 `json:"total_face_value"`       // Remaining face value across the term's holdings
+	WeightedAverageYield float64 `json:"weighted_average_yield"` // Yield at purchase weighted by remaining face value
+	NearestMaturity      *string `json:"nearest_maturity"`       // Earliest maturity date (YYYY-MM-DD)
+	CurrentValue         float64 `json:"current_value"`          // Remaining face value valued at the latest yield for the term
+}
+
+// buildTermPosition aggregates the active holdings in term as of now.
+// Holdings in other terms and sold-out holdings are skipped; legacy holdings with a null
+// remaining_amount count at their face value, as in the holdings list.
+func buildTermPosition(ctx context.Context, holdings []database.Holding, term string, yieldData *models.YieldData, dayCount utils.DayCountConvention,
+	now time.Time) (TermPosition, error) {
+	securityType, err := utils.GetSecurityType(term)
+	if err != nil {
+		return TermPosition{}, err
+	}
+	position := TermPosition{Term: term, SecurityType: securityType}
+
+	var nearest time.Time
+	weightedYield := 0.0
+	for _, holding := range holdings {
+		if holding.Term != term {
+			continue
+		}
+		holding = withLegacyRemaining(ctx, holding)
+		if !isActiveHolding(holding) {
+			continue
+		}
+
+		maturity, err := holdingMaturityDate(holding)
+		if err != nil {
+			return position, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		marketValue, err := holdingMarketValue(holding, yieldData, dayCount, now)
+		if err != nil {
+			return position, err
+		}
+
+		remaining := numericToFloat(holding.RemainingAmount)
+		position.Holdings++
+		position.TotalFaceValue += remaining
+		position.CurrentValue += marketValue
+		weightedYield += numericToFloat(holding.YieldAtPurchase) * remaining
+		if nearest.IsZero() || maturity.Before(nearest) {
+			nearest = maturity
+		}
+	}
+
+	if position.Holdings == 0 {
+		return position, nil
+	}
+	if position.TotalFaceValue > 0 {
+		position.WeightedAverageYield = math.Round(weightedYield/position.TotalFaceValue*10000) / 10000
+	}
+	position.TotalFaceValue = math.Round(position.TotalFaceValue*100) / 100
+	position.CurrentValue = math.Round(position.CurrentValue*100) / 100
+	maturityDate := nearest.Format("2006-01-02")
+	position.NearestMaturity = &maturityDate
+	return position, nil
+}
+
+// LifecycleEvent is one transaction in a holding's lifecycle with running totals.
+// Proceeds is the cash a sell or maturity returned to the balance; it is zero for other transaction types.
+type LifecycleEvent struct {
+	database.Transaction
+	Proceeds           float64 `json:"proceeds"`
+	CumulativeSold     float64 `json:"cumulative_sold"`
+	CumulativeProceeds float64 `json:"cumulative_proceeds"`
+}
+
+// HoldingLifecycle is a holding's current state plus every transaction that touched it, oldest first
+type HoldingLifecycle struct {
+	Holding            HoldingView      `json:"holding"`
+	Events             []LifecycleEvent `json:"events"`
+	CumulativeSold     float64          `json:"cumulative_sold"`
+	CumulativeProceeds float64          `json:"cumulative_proceeds"`
+}
+
+// buildHoldingLifecycle assembles the nested lifecycle view from a holding and its transactions.
+// Sell proceeds are recomputed with the same sale pricing SellTreasury uses: accreted cost for bills under accrual,
+// principal plus accrued interest for notes and bonds under interest.
+// Maturity proceeds are recomputed the same way MatureHolding paid them, over the full term.
+func buildHoldingLifecycle(holding database.Holding, transactions []database.Transaction, accrual utils.BillAccrual,
+	interest utils.NoteInterest, now time.Time) (HoldingLifecycle, error) {
+	// Without yields the view has no market value, so the day count is never applied
+	view, err := newHoldingView(holding, nil, utils.DayCountThirty360, now)
+	if err != nil {
+		return HoldingLifecycle{}, err
+	}
+
+	securityType, err := holdingSecurityType(holding)
+	if err != nil {
+		return HoldingLifecycle{}, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+
+	lifecycle := HoldingLifecycle{
+		Holding: view,
+		Events:  make([]LifecycleEvent, 0, len(transactions)),
+	}
+
+	for _, tx := range transactions {
+		event := LifecycleEvent{Transaction: tx}
+
+		principal := numericToFloat(tx.Amount)
+		switch tx.Type {
+		case database.TransactionTypeMature:
+			event.Proceeds, err = utils.CalculateMaturityProceeds(principal, numericToFloat(holding.YieldAtPurchase), holding.Term)
+		case database.TransactionTypeSell:
+			event.Proceeds, err = lifecycleSaleProceeds(holding, securityType, tx, accrual, interest)
+		}
+		if err != nil {
+			return HoldingLifecycle{}, fmt.Errorf("transaction %d: %w", tx.ID, err)
+		}
+
+		if tx.Type == database.TransactionTypeSell || tx.Type == database.TransactionTypeMature {
+			lifecycle.CumulativeSold = math.Round((lifecycle.CumulativeSold+principal)*100) / 100
+			lifecycle.CumulativeProceeds = math.Round((lifecycle.CumulativeProceeds+event.Proceeds)*100) / 100
+		}
+
+		event.CumulativeSold = lifecycle.CumulativeSold
+		event.CumulativeProceeds = lifecycle.CumulativeProceeds
+		lifecycle.Events = append(lifecycle.Events, event)
+	}
+
+	return lifecycle, nil
+}
+
+// lifecycleSaleProceeds reprices a sell transaction with the shared sale pricing, as of the sale's timestamp
+func lifecycleSaleProceeds(holding database.Holding, securityType string, tx database.Transaction,
+	accrual utils.BillAccrual, interest utils.NoteInterest) (float64, error) {
+	amount, err := utils.MoneyFromNumeric(tx.Amount)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sell amount: %w", err)
+	}
+
+	var proceeds utils.Money
+	if securityType == utils.SecurityTypeBill {
+		var termDays int
+		termDays, err = holdingTermDays(holding)
+		if err != nil {
+			return 0, err
+		}
+		daysHeld := int(tx.Timestamp.Time.Sub(holding.PurchaseDate.Time).Hours() / 24)
+		proceeds, _, err = utils.BillSaleProceeds(holding.FaceValue, holding.PurchasePrice, termDays, amount, daysHeld, accrual)
+	} else {
+		proceeds, _, err = utils.NoteSaleProceeds(amount, numericToFloat(holding.YieldAtPurchase),
+			holding.PurchaseDate.Time, tx.Timestamp.Time, interest)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return proceeds.Float64(), nil
+}
+
+// Projection schedules step monthly, then quarterly and yearly for longer remaining terms, to stay small
+const (
+	monthlyProjectionMaxMonths   = 24
+	quarterlyProjectionMaxMonths = 72
+)
+
+// ProjectionPoint is a holding's projected hold-to-date value on one date
+type ProjectionPoint struct {
+	Date     string  `json:"date"`
+	DaysHeld int     `json:"days_held"`
+	Value    float64 `json:"value"`
+}
+
+// HoldingProjection projects what a holding's remaining amount is worth from today until maturity if held
+type HoldingProjection struct {
+	HoldingID       int32             `json:"holding_id"`
+	Term            string            `json:"term"`
+	SecurityType    string            `json:"security_type"`
+	RemainingAmount float64           `json:"remaining_amount"`
+	MaturityDate    string            `json:"maturity_date"`
+	DaysToMaturity  int               `json:"days_to_maturity"` // Zero once matured
+	ValueToday      float64           `json:"value_today"`
+	ValueAtMaturity float64           `json:"value_at_maturity"`
+	Schedule        []ProjectionPoint `json:"schedule"` // Dates after today up to and including maturity; empty once matured
+}
+
+// buildHoldingProjection values a holding's remaining amount today, at maturity, and on purchase-date
+// anniversaries in between: monthly when maturity is up to 24 months away, quarterly up to 72, yearly beyond.
+// Bills accrete their discount linearly from the price paid to face value; notes and bonds earn simple interest
+// on a 365-day basis, matching CalculateMaturityProceeds at maturity.
+func buildHoldingProjection(holding database.Holding, now time.Time) (HoldingProjection, error) {
+	securityType, err := holdingSecurityType(holding)
+	if err != nil {
+		return HoldingProjection{}, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	purchased := holding.PurchaseDate.Time
+	maturity, err := holdingMaturityDate(holding)
+	if err != nil {
+		return HoldingProjection{}, fmt.Errorf("holding %d: %w", holding.ID, err)
+	}
+	daysToMaturity := utils.DaysUntil(maturity, now)
+
+	projection := HoldingProjection{
+		HoldingID:       holding.ID,
+		Term:            holding.Term,
+		SecurityType:    securityType,
+		RemainingAmount: numericToFloat(holding.RemainingAmount),
+		MaturityDate:    maturity.Format("2006-01-02"),
+		DaysToMaturity:  max(daysToMaturity, 0),
+		Schedule:        []ProjectionPoint{},
+	}
+
+	valueAt := func(date time.Time) (ProjectionPoint, error) {
+		daysHeld := max(int(date.Sub(purchased).Hours()/24), 0)
+		value, err := heldValue(holding, securityType, projection.RemainingAmount, daysHeld)
+		if err != nil {
+			return ProjectionPoint{}, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		return ProjectionPoint{Date: date.Format("2006-01-02"), DaysHeld: daysHeld, Value: value}, nil
+	}
+
+	today, err := valueAt(now)
+	if err != nil {
+		return HoldingProjection{}, err
+	}
+	atMaturity, err := valueAt(maturity)
+	if err != nil {
+		return HoldingProjection{}, err
+	}
+	projection.ValueToday = today.Value
+	projection.ValueAtMaturity = atMaturity.Value
+	if daysToMaturity <= 0 {
+		return projection, nil
+	}
+
+	stepMonths := 12
+	if months := daysToMaturity / 30; months <= monthlyProjectionMaxMonths {
+		stepMonths = 1
+	} else if months <= quarterlyProjectionMaxMonths {
+		stepMonths = 3
+	}
+	for k := stepMonths; ; k += stepMonths {
+		date := purchased.AddDate(0, k, 0)
+		if !date.Before(maturity) {
+			break
+		}
+		if !date.After(now) {
+			continue
+		}
+		point, err := valueAt(date)
+		if err != nil {
+			return HoldingProjection{}, err
+		}
+		projection.Schedule = append(projection.Schedule, point)
+	}
+	projection.Schedule = append(projection.Schedule, atMaturity)
+
+	return projection, nil
+}
+
+// heldValue returns what remaining face of a holding is worth after daysHeld days if held, capped at its maturity value.
+// Legacy bills without a usable face value or price are worth their remaining amount.
+func heldValue(holding database.Holding, securityType string, remaining float64, daysHeld int) (float64, error) {
+	if securityType != utils.SecurityTypeBill {
+		termDays, err := utils.TermDurationDays(holding.Term)
+		if err != nil {
+			return 0, err
+		}
+		return utils.CalculateNoteBondMaturityValue(remaining, numericToFloat(holding.YieldAtPurchase), min(daysHeld, termDays))
+	}
+
+	faceValue, purchasePrice, err := holdingFaceAndPrice(holding)
+	if err != nil {
+		return 0, err
+	}
+	if faceValue <= 0 || purchasePrice <= 0 {
+		return math.Round(remaining*100) / 100, nil
+	}
+	termDays, err := holdingTermDays(holding)
+	if err != nil {
+		return 0, err
+	}
+	return utils.CalculateBillAccretedValueDays(remaining, purchasePrice*remaining/faceValue, termDays, daysHeld, utils.BillAccrualLinear)
+}
+
+// Yield comparison statuses
+const (
+	yieldStatusBeatsMarket   = "beats_market"   // Locked yield is above today's rate: worth holding
+	yieldStatusMatchesMarket = "matches_market" // Locked yield equals today's rate to the basis point
+	yieldStatusUnderperforms = "underperforms"  // Locked yield is below today's rate: a candidate to roll
+)
+
+// HoldingYieldComparison compares one active holding's locked-in yield with today's rate for its term.
+// Both yields are on the curve's own basis, the same one the holding was bought at.
+type HoldingYieldComparison struct {
+	HoldingID       int32   `json:"holding_id"`
+	Term            string  `json:"term"`
+	SecurityType    string  `json:"security_type"`
+	RemainingAmount float64 `json:"remaining_amount"`
+	YieldAtPurchase float64 `json:"yield_at_purchase"`
+	MarketYield     float64 `json:"market_yield"`
+	DifferenceBps   int     `json:"difference_bps"` // Locked minus market; positive means the holding beats today's rate
+	Status          string  `json:"status"`
+}
+
+// YieldComparison is a user's active holdings compared against the latest yield curve
+type YieldComparison struct {
+	YieldDate string                   `json:"yield_date"`
+	Holdings  []HoldingYieldComparison `json:"holdings"`
+}
+
+// buildYieldComparison compares each active holding's yield at purchase with the latest yield for its term.
+// Sold-out holdings are excluded, as are holdings whose term has no yield on the latest curve; legacy holdings
+// with a null remaining_amount count at their face value, as in the holdings list.
+func buildYieldComparison(ctx context.Context, holdings []database.Holding, yieldData *models.YieldData) (YieldComparison, error) {
+	comparison := YieldComparison{
+		YieldDate: yieldData.Date,
+		Holdings:  []HoldingYieldComparison{},
+	}
+
+	for _, holding := range holdings {
+		holding = withLegacyRemaining(ctx, holding)
+		if !isActiveHolding(holding) {
+			continue
+		}
+
+		securityType, err := holdingSecurityType(holding)
+		if err != nil {
+			return comparison, fmt.Errorf("holding %d: %w", holding.ID, err)
+		}
+		// A term with no yield on the latest curve has nothing to compare against
+		marketYield, found := yieldData.RateForTerm(holding.Term)
+		if !found {
+			continue
+		}
+
+		lockedYield := numericToFloat(holding.YieldAtPurchase)
+		differenceBps := int(math.Round((lockedYield - marketYield) * 100))
+		status := yieldStatusMatchesMarket
+		if differenceBps > 0 {
+			status = yieldStatusBeatsMarket
+		} else if differenceBps < 0 {
+			status = yieldStatusUnderperforms
+		}
+
+		comparison.Holdings = append(comparison.Holdings, HoldingYieldComparison{
+			HoldingID:       holding.ID,
+			Term:            holding.Term,
+			SecurityType:    securityType,
+			RemainingAmount: numericToFloat(holding.RemainingAmount),
+			YieldAtPurchase: lockedYield,
+			MarketYield:     marketYield,
+			DifferenceBps:   differenceBps,
+			Status:          status,
+		})
+	}
+
+	return comparison, nil
+}
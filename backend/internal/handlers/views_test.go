@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+
+	"modernfi-treasury-app/internal/database"
+)
+
+// TestNewTransactionView_SignedAmount tests the amount sign for every transaction type
+func TestNewTransactionView_SignedAmount(t *testing.T) {
+	tests := []struct {
+		txType   database.TransactionType
+		expected float64
+	}{
+		{database.TransactionTypeFund, 1000.50},
+		{database.TransactionTypeWithdraw, -1000.50},
+		{database.TransactionTypeBuy, -1000.50},
+		{database.TransactionTypeSell, 1000.50},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.txType), func(t *testing.T) {
+			tx := database.Transaction{
+				Type:         tt.txType,
+				Amount:       mustNumeric("1000.50"),
+				BalanceAfter: mustNumeric("5000.00"),
+			}
+
+			view := newTransactionView(tx)
+
+			if got := mustFloat64(view.SignedAmount); got != tt.expected {
+				t.Errorf("SignedAmount = %f, want %f", got, tt.expected)
+			}
+			if got := mustFloat64(view.RunningBalance); got != 5000.00 {
+				t.Errorf("RunningBalance = %f, want 5000.00", got)
+			}
+			// The stored amount must stay unsigned
+			if got := mustFloat64(view.Amount); got != 1000.50 {
+				t.Errorf("Amount = %f, want 1000.50 (unchanged)", got)
+			}
+		})
+	}
+}
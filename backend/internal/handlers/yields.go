@@ -2,31 +2,90 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
+	"modernfi-treasury-app/internal/models"
 	"modernfi-treasury-app/internal/services"
 )
 
+const (
+	// Cache lifetimes for historical yield responses. Every period is relative to today, so a response
+	// is only good until the server's cached range is next refreshed.
+	historicalMaxAge        = 24 * time.Hour  // Cap on the time left until the next refresh
+	historicalUnknownMaxAge = 5 * time.Minute // When the server has no scheduled refresh to count down to
+)
+
 // YieldHandler handles HTTP requests for yield data
 type YieldHandler struct {
 	treasuryService *services.TreasuryService
+	allowedPeriods  []string
 }
 
-// NewYieldHandler creates a new YieldHandler with the provided TreasuryService
+// NewYieldHandler creates a new YieldHandler with the provided TreasuryService.
+// All historical periods are enabled by default.
 func NewYieldHandler(treasuryService *services.TreasuryService) *YieldHandler {
 	return &YieldHandler{
 		treasuryService: treasuryService,
+		allowedPeriods:  services.HistoricalPeriods,
 	}
 }
 
-// GetYields handles GET requests to fetch the latest treasury yields
+// SetAllowedPeriods restricts the historical endpoint to a subset of services.HistoricalPeriods.
+// Periods are kept in canonical order regardless of the order given.
+func (h *YieldHandler) SetAllowedPeriods(periods []string) error {
+	requested := make(map[string]bool, len(periods))
+	for _, period := range periods {
+		requested[period] = true
+	}
+
+	var allowed []string
+	for _, period := range services.HistoricalPeriods {
+		if requested[period] {
+			allowed = append(allowed, period)
+			delete(requested, period)
+		}
+	}
+
+	if len(requested) > 0 {
+		return fmt.Errorf("unknown historical periods: %v (valid periods: %s)", periods, strings.Join(services.HistoricalPeriods, ", "))
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("at least one historical period must be enabled")
+	}
+
+	h.allowedPeriods = allowed
+	return nil
+}
+
+// isPeriodAllowed reports whether the period is enabled for this deployment
+func (h *YieldHandler) isPeriodAllowed(period string) bool {
+	for _, allowed := range h.allowedPeriods {
+		if allowed == period {
+			return true
+		}
+	}
+	return false
+}
+
+// GetYields handles GET requests to fetch the latest treasury yields.
+// Optional query parameter: date (YYYY-MM-DD) returns the curve for that day, or the nearest prior trading day;
+// malformed or future dates get 400, and dates before the available data get 404.
 func (h *YieldHandler) GetYields(w http.ResponseWriter, r *http.Request) {
+	if date := r.URL.Query().Get("date"); date != "" {
+		h.getYieldsForDate(w, r, date)
+		return
+	}
+
 	// Fetch latest yields from the treasury service
 	yieldData, err := h.treasuryService.GetLatestYields()
 	if err != nil {
 		// Log the error for debugging
-		log.Printf("Error fetching treasury yields: %v", err)
+		slog.ErrorContext(r.Context(), "Error fetching treasury yields", "error", err)
 
 		// Return 500 Internal Server Error with error message
 		w.Header().Set("Content-Type", "application/json")
@@ -43,8 +102,49 @@ func (h *YieldHandler) GetYields(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(yieldData)
 }
 
+// getYieldsForDate serves the full curve in effect on date in the same shape as the latest yields
+func (h *YieldHandler) getYieldsForDate(w http.ResponseWriter, r *http.Request, date string) {
+	requested, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		respondWithYieldError(w, http.StatusBadRequest, "Invalid date. Must be YYYY-MM-DD")
+		return
+	}
+	if requested.After(time.Now()) {
+		respondWithYieldError(w, http.StatusBadRequest, "Invalid date. Must not be in the future")
+		return
+	}
+
+	yieldData, err := h.treasuryService.GetYieldsForDate(date)
+	if errors.Is(err, services.ErrNoYieldsForDate) {
+		respondWithYieldError(w, http.StatusNotFound, "No treasury data on or before "+date)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching treasury yields for date", "date", date, "error", err)
+		respondWithYieldError(w, http.StatusInternalServerError, "Failed to fetch treasury data")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(yieldData)
+}
+
+// respondWithYieldError writes a yield endpoint error as {"error": message}
+func respondWithYieldError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
+
 // GetHistoricalYields handles GET requests to /api/yields/historical
-// Query parameter: period (1W, 1M, 3M, 6M, 1Y, 5Y, 10Y, 30Y) - defaults to 3M
+// Query parameter: period (1W, 1M, 2M, 3M, 4M, 6M, 1Y, 5Y, 10Y, 30Y) - defaults to 3M
+// Query parameter: terms - optional comma-separated subset of terms to return (e.g. 3M,2Y,10Y) - defaults to all
+// Query parameter: resolution (daily, weekly, monthly) - defaults to monthly for 30Y, weekly for 10Y/5Y, daily otherwise
+// Periods disabled for this deployment, unknown terms, and unknown resolutions are rejected with 400, as is
+// daily resolution for 30Y, which is capped to keep responses a manageable size
 func (h *YieldHandler) GetHistoricalYields(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameter
 	period := r.URL.Query().Get("period")
@@ -52,31 +152,41 @@ func (h *YieldHandler) GetHistoricalYields(w http.ResponseWriter, r *http.Reques
 		period = "3M" // Default to 3 months
 	}
 
-	// Validate period
-	validPeriods := map[string]bool{
-		"1W":  true,
-		"1M":  true,
-		"3M":  true,
-		"6M":  true,
-		"1Y":  true,
-		"5Y":  true,
-		"10Y": true,
-		"30Y": true,
-	}
-	if !validPeriods[period] {
-		log.Printf("Invalid period requested: %s", period)
+	// Validate period against the enabled periods
+	if !h.isPeriodAllowed(period) {
+		slog.ErrorContext(r.Context(), "Invalid period requested", "period", period)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "Invalid period. Must be one of: 1W, 1M, 3M, 6M, 1Y, 5Y, 10Y, 30Y",
+			"error": "Invalid period. Must be one of: " + strings.Join(h.allowedPeriods, ", "),
 		})
 		return
 	}
 
+	var terms []string
+	if raw := r.URL.Query().Get("terms"); raw != "" {
+		var ok bool
+		if terms, ok = parseHistoricalTerms(raw); !ok {
+			slog.ErrorContext(r.Context(), "Invalid terms requested", "terms", raw)
+			respondWithYieldError(w, http.StatusBadRequest,
+				"Invalid terms. Must be a comma-separated subset of: "+strings.Join(services.HistoricalTerms, ", "))
+			return
+		}
+	}
+
+	resolution := r.URL.Query().Get("resolution")
+	if resolution != "" {
+		if err := services.ValidateHistoricalResolution(period, resolution); err != nil {
+			slog.ErrorContext(r.Context(), "Invalid resolution requested", "period", period, "resolution", resolution)
+			respondWithYieldError(w, http.StatusBadRequest, "Invalid resolution: "+err.Error())
+			return
+		}
+	}
+
 	// Fetch historical yields
-	data, err := h.treasuryService.GetHistoricalYields(period)
+	data, err := h.treasuryService.GetHistoricalYieldsAtResolution(period, resolution)
 	if err != nil {
-		log.Printf("Error fetching historical yields: %v", err)
+		slog.ErrorContext(r.Context(), "Error fetching historical yields", "period", period, "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
@@ -84,9 +194,124 @@ func (h *YieldHandler) GetHistoricalYields(w http.ResponseWriter, r *http.Reques
 		})
 		return
 	}
+	if terms != nil {
+		data = selectHistoricalTerms(data, terms)
+	}
+
+	// Short-circuit with 304 if the client already holds this exact range
+	refreshAt, _ := h.treasuryService.HistoricalRefreshAt(period, resolution)
+	etag := setHistoricalCacheHeaders(w, data, refreshAt, time.Now())
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 
 	// Return successful response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(data)
 }
+
+// parseHistoricalTerms parses a comma-separated terms selection into canonical (shortest first) order.
+// It reports false if any term is unknown or none are given; duplicates are ignored.
+func parseHistoricalTerms(raw string) ([]string, bool) {
+	requested := make(map[string]bool)
+	for _, term := range strings.Split(raw, ",") {
+		requested[strings.TrimSpace(term)] = true
+	}
+
+	var terms []string
+	for _, term := range services.HistoricalTerms {
+		if requested[term] {
+			terms = append(terms, term)
+			delete(requested, term)
+		}
+	}
+
+	if len(requested) > 0 || len(terms) == 0 {
+		return nil, false
+	}
+	return terms, true
+}
+
+// selectHistoricalTerms returns a copy of data holding only the given terms, leaving the cached original untouched
+func selectHistoricalTerms(data *models.HistoricalYieldData, terms []string) *models.HistoricalYieldData {
+	points := make([]map[string]interface{}, len(data.Data))
+	for i, point := range data.Data {
+		selected := make(map[string]interface{}, len(terms)+1)
+		selected["date"] = point["date"]
+		for _, term := range terms {
+			if rate, ok := point[term]; ok {
+				selected[term] = rate
+			}
+		}
+		points[i] = selected
+	}
+
+	return &models.HistoricalYieldData{
+		Period:     data.Period,
+		Resolution: data.Resolution,
+		StartDate:  data.StartDate,
+		EndDate:    data.EndDate,
+		Terms:      terms,
+		Data:       points,
+	}
+}
+
+// setHistoricalCacheHeaders sets Cache-Control and ETag for a historical response and returns the ETag.
+// The response may be cached until refreshAt, when the server's cached range is replaced (capped at a day,
+// and zero once overdue); a zero refreshAt gets a short max-age since no refresh is scheduled to count down to.
+// A response at a non-default resolution or narrowed to a subset of terms names them in its ETag,
+// so it never matches the default response.
+func setHistoricalCacheHeaders(w http.ResponseWriter, data *models.HistoricalYieldData, refreshAt, now time.Time) string {
+	maxAge := historicalUnknownMaxAge
+	if !refreshAt.IsZero() {
+		maxAge = min(max(refreshAt.Sub(now), 0), historicalMaxAge)
+	}
+
+	tag := data.Period + "-" + data.EndDate
+	if data.Resolution != "" && data.Resolution != services.DefaultHistoricalResolution(data.Period) {
+		tag += "-" + data.Resolution
+	}
+	if len(data.Terms) > 0 && len(data.Terms) < len(services.HistoricalTerms) {
+		tag += "-" + strings.Join(data.Terms, "+")
+	}
+	etag := `"` + tag + `"`
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	return etag
+}
+
+// etagMatches reports whether an If-None-Match header matches etag under weak comparison:
+// the header may be "*" or a comma-separated list of tags, any of them W/-prefixed
+func etagMatches(ifNoneMatch, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "" {
+		return false
+	}
+	if ifNoneMatch == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUpstreamHealth handles GET /health/upstream requests.
+// Reports the last treasury.gov fetch time and outcome and whether yields are cached,
+// without contacting treasury.gov. Returns 503 until a fetch has succeeded, or after the latest fetch failed.
+func (h *YieldHandler) GetUpstreamHealth(w http.ResponseWriter, r *http.Request) {
+	status := h.treasuryService.UpstreamStatus()
+
+	statusCode := http.StatusOK
+	if !status.Ready() {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/routes"
+	"modernfi-treasury-app/internal/services"
+)
+
+// TestSetHistoricalCacheHeaders tests that responses are cached until the server's next refresh, capped at a day
+func TestSetHistoricalCacheHeaders(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		refreshAt    time.Time
+		resolution   string
+		terms        []string
+		cacheControl string
+		etag         string
+	}{
+		{"Refresh in an hour", now.Add(time.Hour), "", nil, "public, max-age=3600", `"3M-2025-03-13"`},
+		{"Refresh beyond a day", now.Add(72 * time.Hour), "", nil, "public, max-age=86400", `"3M-2025-03-13"`},
+		{"Refresh overdue", now.Add(-time.Minute), "", nil, "public, max-age=0", `"3M-2025-03-13"`},
+		{"No scheduled refresh", time.Time{}, "", nil, "public, max-age=300", `"3M-2025-03-13"`},
+		{"All terms", now.Add(time.Hour), "", services.HistoricalTerms, "public, max-age=3600", `"3M-2025-03-13"`},
+		{"Subset of terms", now.Add(time.Hour), "", []string{"3M", "10Y"}, "public, max-age=3600", `"3M-2025-03-13-3M+10Y"`},
+		{"Default resolution", now.Add(time.Hour), "daily", nil, "public, max-age=3600", `"3M-2025-03-13"`},
+		{"Override resolution and terms", now.Add(time.Hour), "weekly", []string{"2Y"}, "public, max-age=3600", `"3M-2025-03-13-weekly-2Y"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			data := &models.HistoricalYieldData{Period: "3M", Resolution: tt.resolution, EndDate: "2025-03-13", Terms: tt.terms}
+
+			etag := setHistoricalCacheHeaders(w, data, tt.refreshAt, now)
+
+			if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cacheControl)
+			}
+			if got := w.Header().Get("ETag"); got != tt.etag {
+				t.Errorf("ETag = %q, want %q", got, tt.etag)
+			}
+			if etag != tt.etag {
+				t.Errorf("returned ETag = %q, want %q", etag, tt.etag)
+			}
+		})
+	}
+}
+
+// TestETagMatches tests If-None-Match parsing of weak tags, tag lists, and the wildcard
+func TestETagMatches(t *testing.T) {
+	const etag = `"3M-2025-03-13"`
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		expected    bool
+	}{
+		{"Absent", "", false},
+		{"Exact", `"3M-2025-03-13"`, true},
+		{"Weak", `W/"3M-2025-03-13"`, true},
+		{"In a list", `"1M-2025-03-13", W/"3M-2025-03-13"`, true},
+		{"Wildcard", "*", true},
+		{"Other tag", `"3M-2025-03-12"`, false},
+		{"Unquoted", "3M-2025-03-13", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.ifNoneMatch, etag); got != tt.expected {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.ifNoneMatch, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGetHistoricalYields_DisabledPeriod tests that periods outside the allowlist are rejected
+func TestGetHistoricalYields_DisabledPeriod(t *testing.T) {
+	handler := NewYieldHandler(services.NewTreasuryService())
+	if err := handler.SetAllowedPeriods([]string{"1W", "1M", "3M", "6M", "1Y", "5Y", "10Y"}); err != nil {
+		t.Fatalf("SetAllowedPeriods failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, routes.YieldsHistorical+"?period=30Y", nil)
+	w := httptest.NewRecorder()
+	handler.GetHistoricalYields(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+
+	var resp map[string]string
+	json.NewDecoder(w.Body).Decode(&resp)
+	if strings.Contains(resp["error"], "30Y") {
+		t.Errorf("Expected error to list only enabled periods, got %q", resp["error"])
+	}
+	if !strings.Contains(resp["error"], "10Y") {
+		t.Errorf("Expected error to list enabled periods, got %q", resp["error"])
+	}
+}
+
+// TestGetYields_InvalidDate tests that bad dates are rejected and dates before the feed get 404, without any upstream fetch
+func TestGetYields_InvalidDate(t *testing.T) {
+	handler := NewYieldHandler(services.NewTreasuryService())
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	tests := []struct {
+		date           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"03/15/2024", http.StatusBadRequest, "Invalid date. Must be YYYY-MM-DD"},
+		{"2024-02-30", http.StatusBadRequest, "Invalid date. Must be YYYY-MM-DD"},
+		{tomorrow, http.StatusBadRequest, "Invalid date. Must not be in the future"},
+		{"1989-12-29", http.StatusNotFound, "No treasury data on or before 1989-12-29"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, routes.Yields+"?date="+tt.date, nil)
+			w := httptest.NewRecorder()
+			handler.GetYields(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var resp map[string]string
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp["error"] != tt.expectedError {
+				t.Errorf("Expected error %q, got %q", tt.expectedError, resp["error"])
+			}
+		})
+	}
+}
+
+// TestParseHistoricalTerms tests that term selections are validated and put in canonical order
+func TestParseHistoricalTerms(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected []string
+		ok       bool
+	}{
+		{"10Y,3M", []string{"3M", "10Y"}, true},
+		{"2Y, 2Y ,30Y", []string{"2Y", "30Y"}, true},
+		{"1M,2M,3M,4M,6M,1Y,2Y,5Y,10Y,30Y", services.HistoricalTerms, true},
+		{"3M,7Y", nil, false},
+		{",", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			terms, ok := parseHistoricalTerms(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("parseHistoricalTerms(%q) ok = %v, want %v", tt.raw, ok, tt.ok)
+			}
+			if strings.Join(terms, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("parseHistoricalTerms(%q) = %v, want %v", tt.raw, terms, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSelectHistoricalTerms tests narrowing a response to some terms without touching the cached data
+func TestSelectHistoricalTerms(t *testing.T) {
+	cached := &models.HistoricalYieldData{
+		Period:    "1M",
+		StartDate: "2025-02-14",
+		EndDate:   "2025-03-14",
+		Terms:     services.HistoricalTerms,
+		Data: []map[string]interface{}{
+			{"date": "2025-03-13", "3M": 4.34, "2Y": 4.02, "10Y": 4.28},
+			{"date": "2025-03-14", "3M": 4.35, "2Y": 4.05, "10Y": 4.31},
+		},
+	}
+
+	selected := selectHistoricalTerms(cached, []string{"3M", "10Y"})
+
+	if strings.Join(selected.Terms, ",") != "3M,10Y" || selected.Period != "1M" || selected.EndDate != "2025-03-14" {
+		t.Errorf("Unexpected selection metadata: %+v", selected)
+	}
+	if len(selected.Data) != 2 {
+		t.Fatalf("Expected 2 data points, got %d", len(selected.Data))
+	}
+	point := selected.Data[1]
+	if point["date"] != "2025-03-14" || point["3M"] != 4.35 || point["10Y"] != 4.31 {
+		t.Errorf("Unexpected data point: %v", point)
+	}
+	if _, ok := point["2Y"]; ok {
+		t.Error("Expected unselected 2Y to be dropped")
+	}
+	if _, ok := cached.Data[1]["2Y"]; !ok || len(cached.Terms) != len(services.HistoricalTerms) {
+		t.Error("Expected cached data to be left unmodified")
+	}
+}
+
+// TestGetHistoricalYields_InvalidTerms tests that unknown terms are rejected before any fetch
+func TestGetHistoricalYields_InvalidTerms(t *testing.T) {
+	handler := NewYieldHandler(services.NewTreasuryService())
+
+	req := httptest.NewRequest(http.MethodGet, routes.YieldsHistorical+"?period=3M&terms=2Y,7Y", nil)
+	w := httptest.NewRecorder()
+	handler.GetHistoricalYields(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp map[string]string
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !strings.Contains(resp["error"], "Invalid terms") {
+		t.Errorf("Expected invalid terms error, got %q", resp["error"])
+	}
+}
+
+// TestGetHistoricalYields_InvalidResolution tests that unknown resolutions and daily 30Y are rejected before any fetch
+func TestGetHistoricalYields_InvalidResolution(t *testing.T) {
+	handler := NewYieldHandler(services.NewTreasuryService())
+
+	tests := []struct {
+		query    string
+		contains string
+	}{
+		{"?period=5Y&resolution=hourly", "must be one of daily, weekly, monthly"},
+		{"?period=30Y&resolution=daily", "not available for the 30Y period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, routes.YieldsHistorical+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.GetHistoricalYields(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", w.Code)
+			}
+			var resp map[string]string
+			json.NewDecoder(w.Body).Decode(&resp)
+			if !strings.Contains(resp["error"], tt.contains) {
+				t.Errorf("Expected error containing %q, got %q", tt.contains, resp["error"])
+			}
+		})
+	}
+}
+
+// TestSetAllowedPeriods tests validation of the configured period allowlist
+func TestSetAllowedPeriods(t *testing.T) {
+	tests := []struct {
+		name    string
+		periods []string
+		wantErr bool
+	}{
+		{"Subset", []string{"1M", "1W"}, false},
+		{"All periods", services.HistoricalPeriods, false},
+		{"Unknown period", []string{"1M", "2Y"}, true},
+		{"Empty", []string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewYieldHandler(services.NewTreasuryService())
+			err := handler.SetAllowedPeriods(tt.periods)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetAllowedPeriods(%v) error = %v, wantErr %v", tt.periods, err, tt.wantErr)
+			}
+		})
+	}
+
+	// Canonical ordering is preserved regardless of input order
+	handler := NewYieldHandler(services.NewTreasuryService())
+	handler.SetAllowedPeriods([]string{"1M", "1W"})
+	if strings.Join(handler.allowedPeriods, ",") != "1W,1M" {
+		t.Errorf("Expected canonical order 1W,1M, got %v", handler.allowedPeriods)
+	}
+}
+
+// TestGetUpstreamHealth_NeverFetched tests that a service with no fetches reports not ready
+func TestGetUpstreamHealth_NeverFetched(t *testing.T) {
+	handler := NewYieldHandler(services.NewTreasuryService())
+
+	req := httptest.NewRequest(http.MethodGet, routes.UpstreamHealth, nil)
+	w := httptest.NewRecorder()
+	handler.GetUpstreamHealth(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503, got %d", w.Code)
+	}
+
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp["last_fetch"] != nil {
+		t.Errorf("Expected null last_fetch, got %v", resp["last_fetch"])
+	}
+	if resp["last_fetch_ok"] != false {
+		t.Errorf("Expected last_fetch_ok false, got %v", resp["last_fetch_ok"])
+	}
+	if resp["cache_warm"] != false {
+		t.Errorf("Expected cache_warm false, got %v", resp["cache_warm"])
+	}
+}
@@ -0,0 +1,177 @@
+// Package metrics keeps in-process counters and serves them in the Prometheus text exposition format.
+// It implements only the counters this app needs, so the server has no metrics client dependency;
+// any Prometheus-compatible scraper can read the /metrics output.
+//
+// This is a stopgap pending agreement to add github.com/prometheus/client_golang, which this
+// package should then wrap: New registering prometheus.CounterVecs on a prometheus.Registry and
+// Handler returning promhttp.HandlerFor on it. Callers only use New, Handler, and the counters' Inc and Value,
+// so the swap stays inside this package. Until then, extend the hand-rolled format with care:
+// counters only, no histograms, and label values escaped as in labels.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Label values used by the application counters
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+
+	CacheLatest     = "latest"
+	CacheHistorical = "historical"
+	CacheYearFeed   = "year_feed"
+)
+
+// Metrics holds the application counters and the registry that exposes them
+type Metrics struct {
+	registry *Registry
+
+	// Completed transactions by type (fund, withdraw, buy, sell, mature, cancel)
+	Transactions *CounterVec
+	// treasury.gov fetches by result (success, failure)
+	UpstreamFetches *CounterVec
+	// Yield cache lookups by cache (latest, historical) and result (hit, miss)
+	CacheLookups *CounterVec
+}
+
+// New creates the application counters on a fresh registry
+func New() *Metrics {
+	registry := NewRegistry()
+	return &Metrics{
+		registry:        registry,
+		Transactions:    registry.NewCounterVec("treasury_transactions_total", "Completed transactions by type.", "type"),
+		UpstreamFetches: registry.NewCounterVec("treasury_upstream_fetches_total", "treasury.gov fetches by result.", "result"),
+		CacheLookups:    registry.NewCounterVec("treasury_yield_cache_lookups_total", "Yield cache lookups by cache and result.", "cache", "result"),
+	}
+}
+
+// Handler serves every counter in the Prometheus text exposition format
+func (m *Metrics) Handler() http.Handler {
+	return m.registry
+}
+
+// Registry is a set of counters exposed together
+type Registry struct {
+	mu       sync.Mutex
+	counters []*CounterVec
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounterVec registers a counter partitioned by the given label names
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters = append(r.counters, c)
+	return c
+}
+
+// ServeHTTP writes every registered counter in the Prometheus text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.Write(w)
+}
+
+// Write writes every registered counter to w in registration order
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	counters := append([]*CounterVec(nil), r.counters...)
+	r.mu.Unlock()
+
+	for _, c := range counters {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CounterVec is a monotonically increasing counter partitioned by label values
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64 // Keyed by the rendered label set
+}
+
+// Inc adds one to the series for labelValues, given in the order of the counter's label names.
+// A mismatched number of label values panics, as it is a programming error.
+func (c *CounterVec) Inc(labelValues ...string) {
+	if len(labelValues) != len(c.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labelNames), len(labelValues)))
+	}
+	key := c.labels(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key]++
+}
+
+// Value returns the current count for labelValues, zero if the series has never been incremented
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[c.labels(labelValues)]
+}
+
+// write renders the counter's HELP and TYPE lines and every series, sorted by labels
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	counts := make(map[string]float64, len(c.values))
+	for key, count := range c.values {
+		keys = append(keys, key)
+		counts[key] = count
+	}
+	c.mu.Unlock()
+	sort.Strings(keys)
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s%s %g\n", c.name, key, counts[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labels renders a label set as {name="value",...}, escaping values per the exposition format
+func (c *CounterVec) labels(labelValues []string) string {
+	if len(c.labelNames) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(c.labelNames))
+	for i, name := range c.labelNames {
+		value := ""
+		if i < len(labelValues) {
+			value = labelValues[i]
+		}
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelValueEscaper.Replace(value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelValueEscaper escapes the characters the exposition format reserves in label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
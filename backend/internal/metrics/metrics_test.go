@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestCounterVec_IncAndValue tests that series are counted independently per label set
+func TestCounterVec_IncAndValue(t *testing.T) {
+	c := NewRegistry().NewCounterVec("test_total", "Test counter.", "cache", "result")
+
+	c.Inc(CacheLatest, ResultHit)
+	c.Inc(CacheLatest, ResultHit)
+	c.Inc(CacheLatest, ResultMiss)
+
+	if got := c.Value(CacheLatest, ResultHit); got != 2 {
+		t.Errorf("Expected 2 latest hits, got %g", got)
+	}
+	if got := c.Value(CacheLatest, ResultMiss); got != 1 {
+		t.Errorf("Expected 1 latest miss, got %g", got)
+	}
+	if got := c.Value(CacheHistorical, ResultHit); got != 0 {
+		t.Errorf("Expected 0 historical hits, got %g", got)
+	}
+}
+
+// TestCounterVec_IncWrongLabelCount tests that a mismatched label count panics
+func TestCounterVec_IncWrongLabelCount(t *testing.T) {
+	c := NewRegistry().NewCounterVec("test_total", "Test counter.", "type")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a missing label value")
+		}
+	}()
+	c.Inc()
+}
+
+// TestRegistry_Write tests the exposition output: HELP and TYPE lines, sorted series, escaped values
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	tx := r.NewCounterVec("treasury_transactions_total", "Completed transactions by type.", "type")
+	odd := r.NewCounterVec("odd_total", "Odd labels.", "value")
+
+	tx.Inc("sell")
+	tx.Inc("buy")
+	tx.Inc("buy")
+	odd.Inc("a\"b\\c\nd")
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := `# HELP treasury_transactions_total Completed transactions by type.
+# TYPE treasury_transactions_total counter
+treasury_transactions_total{type="buy"} 2
+treasury_transactions_total{type="sell"} 1
+# HELP odd_total Odd labels.
+# TYPE odd_total counter
+odd_total{value="a\"b\\c\nd"} 1
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected exposition output:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+// TestMetrics_Handler tests that the handler serves the text format with its content type
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.Transactions.Inc("fund")
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Expected Prometheus text content type, got %q", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `treasury_transactions_total{type="fund"} 1`) {
+		t.Errorf("Expected fund counter in output, got:\n%s", body)
+	}
+	// Counters with no series still advertise themselves
+	if !strings.Contains(body, "# TYPE treasury_yield_cache_lookups_total counter") {
+		t.Errorf("Expected cache lookup counter metadata in output, got:\n%s", body)
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UserIDHeader identifies the acting user on routes that accept it in place of a user_id query parameter
+const UserIDHeader = "X-User-ID"
+
+// Largest request body inspected for a user_id; anything bigger is rejected rather than buffered
+const maxAuthBodyBytes = 1 << 20
+
+type userIDKey struct{}
+
+// errBodyTooLarge is returned by peekBodyUserID when the body exceeds maxAuthBodyBytes
+var errBodyTooLarge = errors.New("request body too large")
+
+// APIKeyAuth authenticates requests by bearer token against a static table of API keys.
+// Tokens are stored hashed so lookups take the same time whether or not a prefix matches.
+type APIKeyAuth struct {
+	users map[[sha256.Size]byte]int32
+}
+
+// NewAPIKeyAuth creates an authenticator mapping each token in keys to its user ID
+func NewAPIKeyAuth(keys map[string]int32) (*APIKeyAuth, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one API key is required")
+	}
+	users := make(map[[sha256.Size]byte]int32, len(keys))
+	for token, userID := range keys {
+		if token == "" {
+			return nil, fmt.Errorf("API keys must not be empty")
+		}
+		if userID <= 0 {
+			return nil, fmt.Errorf("API key user ID must be positive, got: %d", userID)
+		}
+		users[sha256.Sum256([]byte(token))] = userID
+	}
+	return &APIKeyAuth{users: users}, nil
+}
+
+// Handler wraps next, rejecting requests without a known "Authorization: Bearer <token>" header with 401.
+// The authenticated user ID is stored in the request context.
+func (a *APIKeyAuth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		userID, known := a.users[sha256.Sum256([]byte(token))]
+		if !found || !known {
+			slog.WarnContext(r.Context(), "Rejected unauthenticated request", "path", r.URL.Path)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondWithError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
+	})
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID
+func WithUserID(ctx context.Context, userID int32) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID stored in ctx, reporting false if there is none
+func UserIDFromContext(ctx context.Context) (int32, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int32)
+	return userID, ok
+}
+
+// RequireSameUser returns middleware rejecting, with 403, requests that name a user other than the
+// authenticated one. It checks the pathParam URL parameter (when non-empty), the user_id query parameter,
+// the X-User-ID header, and a top-level user_id in a JSON body. Values that don't parse as IDs are
+// left for the handler to reject. Must run after APIKeyAuth.Handler and, for path params, after routing.
+func RequireSameUser(pathParam string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authenticated, ok := UserIDFromContext(r.Context())
+			if !ok {
+				respondWithError(w, http.StatusUnauthorized, "missing or invalid API key")
+				return
+			}
+
+			claimed := []string{r.URL.Query().Get("user_id"), r.Header.Get(UserIDHeader)}
+			if pathParam != "" {
+				claimed = append(claimed, chi.URLParam(r, pathParam))
+			}
+
+			bodyUserID, err := peekBodyUserID(r)
+			if errors.Is(err, errBodyTooLarge) {
+				respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			if bodyUserID != nil {
+				claimed = append(claimed, strconv.FormatInt(*bodyUserID, 10))
+			}
+
+			for _, raw := range claimed {
+				id, err := strconv.ParseInt(raw, 10, 32)
+				if err != nil || int32(id) == authenticated {
+					continue
+				}
+				slog.WarnContext(r.Context(), "Rejected request for another user", "path", r.URL.Path, "authenticated_user_id", authenticated, "requested_user_id", id)
+				respondWithError(w, http.StatusForbidden, "user_id does not match the authenticated user")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// peekBodyUserID reads a top-level user_id from a JSON request body, restoring the body for the handler.
+// It returns nil when the body is empty, isn't a JSON object, or has no numeric user_id.
+func peekBodyUserID(r *http.Request) (*int64, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAuthBodyBytes+1))
+	if IsBodyTooLarge(err) {
+		return nil, errBodyTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxAuthBodyBytes {
+		return nil, errBodyTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields struct {
+		UserID *int64 `json:"user_id"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, nil
+	}
+	return fields.UserID, nil
+}
+
+// respondWithError writes a JSON error body in the handlers' {"success": false, "error": ...} shape
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestNewAPIKeyAuth_RejectsInvalidKeys tests that empty tables, blank tokens, and non-positive users are refused
+func TestNewAPIKeyAuth_RejectsInvalidKeys(t *testing.T) {
+	for _, keys := range []map[string]int32{nil, {"": 1}, {"key": 0}} {
+		if _, err := NewAPIKeyAuth(keys); err == nil {
+			t.Errorf("Expected error for keys %v", keys)
+		}
+	}
+}
+
+// TestAPIKeyAuth tests bearer token authentication and user_id matching on path, query, header, and body
+func TestAPIKeyAuth(t *testing.T) {
+	auth, err := NewAPIKeyAuth(map[string]int32{"key-one": 1, "key-two": 2})
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuth failed: %v", err)
+	}
+
+	// Echo the authenticated user and the body the handler received
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := UserIDFromContext(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(map[string]interface{}{"user_id": userID, "body": string(body)})
+	})
+	router := chi.NewRouter()
+	router.With(auth.Handler, RequireSameUser("id")).Get("/users/{id}", echo)
+	router.With(auth.Handler, RequireSameUser("")).Post("/fund", echo)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authorization  string
+		userIDHeader   string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"Missing token", http.MethodGet, "/users/1", "", "", "", http.StatusUnauthorized, "missing or invalid API key"},
+		{"Unknown token", http.MethodGet, "/users/1", "Bearer key-three", "", "", http.StatusUnauthorized, "missing or invalid API key"},
+		{"Token without Bearer scheme", http.MethodGet, "/users/1", "key-one", "", "", http.StatusUnauthorized, "missing or invalid API key"},
+		{"Own user in path", http.MethodGet, "/users/1", "Bearer key-one", "", "", http.StatusOK, ""},
+		{"Other user in path", http.MethodGet, "/users/2", "Bearer key-one", "", "", http.StatusForbidden, "user_id does not match the authenticated user"},
+		{"Other user in query", http.MethodGet, "/users/1?user_id=2", "Bearer key-one", "", "", http.StatusForbidden, "user_id does not match the authenticated user"},
+		{"Other user in header", http.MethodGet, "/users/1", "Bearer key-one", "2", "", http.StatusForbidden, "user_id does not match the authenticated user"},
+		{"Own user in body", http.MethodPost, "/fund", "Bearer key-two", "", `{"user_id": 2, "amount": 100}`, http.StatusOK, ""},
+		{"Other user in body", http.MethodPost, "/fund", "Bearer key-two", "", `{"user_id": 1, "amount": 100}`, http.StatusForbidden, "user_id does not match the authenticated user"},
+		{"Malformed body left to the handler", http.MethodPost, "/fund", "Bearer key-two", "", `{`, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.userIDHeader != "" {
+				req.Header.Set(UserIDHeader, tt.userIDHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" {
+				var resp struct {
+					Error string `json:"error"`
+				}
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Error != tt.expectedError {
+					t.Errorf("Expected error %q, got %q", tt.expectedError, resp.Error)
+				}
+				return
+			}
+
+			// The handler must see the authenticated user and the untouched body
+			var resp struct {
+				UserID int32  `json:"user_id"`
+				Body   string `json:"body"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Body != tt.body {
+				t.Errorf("Expected handler to receive body %q, got %q", tt.body, resp.Body)
+			}
+			if resp.UserID == 0 {
+				t.Error("Expected the authenticated user ID in the request context")
+			}
+		})
+	}
+}
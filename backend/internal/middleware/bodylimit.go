@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// MaxBodySize returns middleware capping request bodies at maxBytes so a client can't exhaust memory
+// with a huge payload. A declared Content-Length over the cap is rejected with 413 before the handler runs;
+// a body that overflows while being read (chunked uploads) fails with an error IsBodyTooLarge reports.
+func MaxBodySize(maxBytes int64) (func(http.Handler) http.Handler, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("max body size must be greater than 0, got: %d", maxBytes)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				slog.WarnContext(r.Context(), "Rejected oversized request body", "path", r.URL.Path, "content_length", r.ContentLength)
+				respondWithError(w, http.StatusRequestEntityTooLarge, errBodyTooLarge.Error())
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// IsBodyTooLarge reports whether err came from reading past the MaxBodySize limit
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr) || errors.Is(err, errBodyTooLarge)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestMaxBodySize tests that declared oversized bodies get 413 up front and undeclared ones fail while being read
+func TestMaxBodySize(t *testing.T) {
+	const limit = 16
+
+	limitBody, err := MaxBodySize(limit)
+	if err != nil {
+		t.Fatalf("MaxBodySize failed: %v", err)
+	}
+
+	var readErr error
+	called := false
+	handler := limitBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		_, readErr = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Within the limit
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/fund", strings.NewReader(`{"user_id": 1}`)))
+	if w.Code != http.StatusOK || readErr != nil {
+		t.Errorf("Expected a body within the limit to pass, got status %d and read error %v", w.Code, readErr)
+	}
+
+	// Declared Content-Length over the limit never reaches the handler
+	called = false
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/fund", strings.NewReader(strings.Repeat("x", limit+1))))
+	if w.Code != http.StatusRequestEntityTooLarge || called {
+		t.Errorf("Expected 413 before the handler ran, got status %d (handler called: %v)", w.Code, called)
+	}
+
+	// Unknown length (chunked): the handler's read fails once it passes the limit
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/fund", io.MultiReader(strings.NewReader(strings.Repeat("x", limit+1))))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !IsBodyTooLarge(readErr) {
+		t.Errorf("Expected a body-too-large read error, got %v", readErr)
+	}
+
+	if _, err := MaxBodySize(0); err == nil {
+		t.Error("Expected error for a zero limit")
+	}
+}
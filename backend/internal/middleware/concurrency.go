@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// ConcurrencyLimiter caps the number of in-flight requests per client IP.
+// Requests beyond the cap are rejected with 429 rather than queued, so one client
+// can't tie up the server with many slow requests at once.
+type ConcurrencyLimiter struct {
+	maxInFlight int
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing maxInFlight concurrent requests per IP
+func NewConcurrencyLimiter(maxInFlight int) (*ConcurrencyLimiter, error) {
+	if maxInFlight <= 0 {
+		return nil, fmt.Errorf("max in-flight requests must be greater than 0, got: %d", maxInFlight)
+	}
+	return &ConcurrencyLimiter{
+		maxInFlight: maxInFlight,
+		inFlight:    make(map[string]int),
+	}, nil
+}
+
+// Handler wraps next, rejecting requests from IPs already at the in-flight cap
+func (l *ConcurrencyLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r)
+		if !l.acquire(ip) {
+			slog.WarnContext(r.Context(), "Concurrency limit reached", "ip", ip, "path", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "too many concurrent requests",
+			})
+			return
+		}
+		defer l.release(ip)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire reserves an in-flight slot for ip, reporting false if it is at the cap
+func (l *ConcurrencyLimiter) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[ip] >= l.maxInFlight {
+		return false
+	}
+	l.inFlight[ip]++
+	return true
+}
+
+// release frees ip's slot, dropping the entry once idle so the map doesn't grow unbounded
+func (l *ConcurrencyLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight[ip]--
+	if l.inFlight[ip] <= 0 {
+		delete(l.inFlight, ip)
+	}
+}
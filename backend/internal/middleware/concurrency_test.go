@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestConcurrencyLimiter_RejectsOverCap tests that requests beyond the per-IP cap get 429 while others proceed
+func TestConcurrencyLimiter_RejectsOverCap(t *testing.T) {
+	const maxInFlight = 2
+	const attempts = 5
+
+	limiter, err := NewConcurrencyLimiter(maxInFlight)
+	if err != nil {
+		t.Fatalf("NewConcurrencyLimiter failed: %v", err)
+	}
+
+	entered := make(chan struct{}, attempts)
+	unblock := make(chan struct{})
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	newRequest := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/yields/historical", nil)
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	// Fill the cap and wait until both requests are in flight
+	var wg sync.WaitGroup
+	codes := make(chan int, attempts)
+	for i := 0; i < maxInFlight; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newRequest("10.0.0.1:5000"))
+			codes <- w.Code
+		}()
+	}
+	for i := 0; i < maxInFlight; i++ {
+		<-entered
+	}
+
+	// Further concurrent requests from the same IP are rejected, even from another port
+	var rejectWG sync.WaitGroup
+	rejected := make(chan int, attempts)
+	for i := maxInFlight; i < attempts; i++ {
+		rejectWG.Add(1)
+		go func() {
+			defer rejectWG.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newRequest("10.0.0.1:6000"))
+			rejected <- w.Code
+		}()
+	}
+	rejectWG.Wait()
+	close(rejected)
+	for code := range rejected {
+		if code != http.StatusTooManyRequests {
+			t.Errorf("Expected 429 over the cap, got %d", code)
+		}
+	}
+
+	// A different IP has its own allowance
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("10.0.0.2:5000"))
+		codes <- w.Code
+	}()
+	<-entered
+
+	close(unblock)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("Expected 200 within the cap, got %d", code)
+		}
+	}
+
+	// Slots are released once requests finish
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("10.0.0.1:7000"))
+	<-entered
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 after in-flight requests finished, got %d", w.Code)
+	}
+	if len(limiter.inFlight) != 0 {
+		t.Errorf("Expected no tracked IPs once idle, got %v", limiter.inFlight)
+	}
+}
+
+// TestNewConcurrencyLimiter_InvalidCap tests that a non-positive cap is rejected
+func TestNewConcurrencyLimiter_InvalidCap(t *testing.T) {
+	for _, maxInFlight := range []int{0, -1} {
+		if _, err := NewConcurrencyLimiter(maxInFlight); err == nil {
+			t.Errorf("Expected error for cap %d", maxInFlight)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter caps each client to a number of requests per minute with a token bucket: a client may burst
+// up to the full per-minute allowance, then gets one more request each time a token refills.
+// Clients are keyed by authenticated user when the request carries one, and by client IP otherwise.
+// The user_id in an unauthenticated request is deliberately ignored: it is caller-chosen, so keying on
+// it would let one client spread its requests over many buckets. Limiting per user therefore needs
+// API_KEYS; without it, clients behind a reverse proxy are only told apart when TrustedProxies.Handler
+// resolves their IP, and otherwise share the proxy's bucket.
+// Requests over the limit are rejected with 429 and a Retry-After header.
+type RateLimiter struct {
+	capacity      float64
+	refillPerSec  float64
+	now           func() time.Time
+	sweepInterval time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds a client's remaining tokens as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a limiter allowing requestsPerMinute requests per client
+func NewRateLimiter(requestsPerMinute int) (*RateLimiter, error) {
+	if requestsPerMinute <= 0 {
+		return nil, fmt.Errorf("requests per minute must be greater than 0, got: %d", requestsPerMinute)
+	}
+	return &RateLimiter{
+		capacity:      float64(requestsPerMinute),
+		refillPerSec:  float64(requestsPerMinute) / 60,
+		now:           time.Now,
+		sweepInterval: time.Minute,
+		buckets:       make(map[string]*tokenBucket),
+	}, nil
+}
+
+// Handler wraps next, rejecting requests from clients that have used up their tokens.
+// Must run after APIKeyAuth.Handler for authenticated users to be limited individually, and after
+// TrustedProxies.Handler for unauthenticated clients behind a proxy to be limited individually.
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "ip:" + clientIP(r)
+		if userID, ok := UserIDFromContext(r.Context()); ok {
+			key = "user:" + strconv.FormatInt(int64(userID), 10)
+		}
+
+		if retryAfter, allowed := l.allow(key); !allowed {
+			slog.WarnContext(r.Context(), "Rate limit exceeded", "client", key, "path", r.URL.Path, "retry_after", retryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			respondWithError(w, http.StatusTooManyRequests, "rate limit exceeded, retry later")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from key's bucket, or reports how long until one is available
+func (l *RateLimiter) allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, found := l.buckets[key]
+	if !found {
+		bucket = &tokenBucket{tokens: l.capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = l.refilled(bucket, now)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.refillPerSec * float64(time.Second))
+		return wait, false
+	}
+	bucket.tokens--
+	return 0, true
+}
+
+// refilled returns the bucket's tokens at now, capped at capacity
+func (l *RateLimiter) refilled(bucket *tokenBucket, now time.Time) float64 {
+	elapsed := now.Sub(bucket.updated).Seconds()
+	return math.Min(l.capacity, bucket.tokens+elapsed*l.refillPerSec)
+}
+
+// sweep drops buckets that have refilled completely, at most once per sweepInterval, so the map
+// only holds recently active clients. A full bucket behaves the same as a missing one.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if l.refilled(bucket, now) >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
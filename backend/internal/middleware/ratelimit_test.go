@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter_ExhaustsBucket tests that a client gets 429 with Retry-After once its bucket is empty,
+// that other clients are unaffected, and that tokens refill over time
+func TestRateLimiter_ExhaustsBucket(t *testing.T) {
+	const perMinute = 20
+
+	limiter, err := NewRateLimiter(perMinute)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	clock := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return clock }
+
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	send := func(userID int32) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/buy", nil)
+		req = req.WithContext(WithUserID(req.Context(), userID))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// The full allowance may be used at once
+	for i := 0; i < perMinute; i++ {
+		if w := send(1); w.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i+1, w.Code)
+		}
+	}
+
+	w := send(1)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the bucket is empty, got %d", w.Code)
+	}
+	// One token refills every 60s / 20 = 3s
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Expected Retry-After 3, got %q", got)
+	}
+
+	if w := send(2); w.Code != http.StatusOK {
+		t.Errorf("Expected another user to be unaffected, got %d", w.Code)
+	}
+
+	clock = clock.Add(3 * time.Second)
+	if w := send(1); w.Code != http.StatusOK {
+		t.Errorf("Expected a request to succeed after a token refills, got %d", w.Code)
+	}
+	if w := send(1); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected only one refilled token, got %d", w.Code)
+	}
+}
+
+// TestRateLimiter_KeysByIPWhenUnauthenticated tests that requests without a user share a bucket per IP
+func TestRateLimiter_KeysByIPWhenUnauthenticated(t *testing.T) {
+	limiter, err := NewRateLimiter(1)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	send := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/fund", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("10.0.0.1:1111"); code != http.StatusOK {
+		t.Fatalf("Expected first request to succeed, got %d", code)
+	}
+	if code := send("10.0.0.1:2222"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the same IP on another port to be limited, got %d", code)
+	}
+	if code := send("10.0.0.2:1111"); code != http.StatusOK {
+		t.Errorf("Expected a different IP to be allowed, got %d", code)
+	}
+
+	if _, err := NewRateLimiter(0); err == nil {
+		t.Error("Expected error for a zero limit")
+	}
+}
+
+// TestRateLimiter_KeysByClientBehindProxy tests that unauthenticated clients behind a trusted proxy get
+// their own buckets, and that a caller-chosen user_id doesn't open a new one
+func TestRateLimiter_KeysByClientBehindProxy(t *testing.T) {
+	limiter, err := NewRateLimiter(1)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	proxies, err := NewTrustedProxies([]string{"172.18.0.0/16"})
+	if err != nil {
+		t.Fatalf("NewTrustedProxies failed: %v", err)
+	}
+	handler := proxies.Handler(limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	send := func(client, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/fund", strings.NewReader(body))
+		req.RemoteAddr = "172.18.0.3:40000"
+		req.Header.Set("X-Forwarded-For", client)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("198.51.100.1", `{"user_id": 1}`); code != http.StatusOK {
+		t.Fatalf("Expected first request to succeed, got %d", code)
+	}
+	if code := send("198.51.100.1", `{"user_id": 2}`); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the same client to be limited under another user_id, got %d", code)
+	}
+	if code := send("198.51.100.2", `{"user_id": 1}`); code != http.StatusOK {
+		t.Errorf("Expected another client behind the same proxy to be allowed, got %d", code)
+	}
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestTrustedProxies_ClientIP tests that forwarding headers are only read from a trusted peer
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("NewTrustedProxies failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		expectedIP   string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"forged headers from an untrusted peer", "203.0.113.7:5000", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7"},
+		{"forwarded by a trusted proxy", "10.0.0.2:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"single trusted proxy address", "192.168.1.5:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"forged hop before the real client", "10.0.0.2:5000", []string{"1.2.3.4, 198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.2:5000", []string{"198.51.100.1, 10.0.0.3"}, "", "198.51.100.1"},
+		{"repeated forwarded headers", "10.0.0.2:5000", []string{"1.2.3.4", "198.51.100.1"}, "", "198.51.100.1"},
+		{"real IP header only", "10.0.0.2:5000", nil, "198.51.100.2", "198.51.100.2"},
+		{"unparseable forwarded hop", "10.0.0.2:5000", []string{"unknown"}, "198.51.100.2", "198.51.100.2"},
+		{"trusted proxy without headers", "10.0.0.2:5000", nil, "", "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := proxies.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = clientIP(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/yields", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, hop := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", hop)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.expectedIP {
+				t.Errorf("Expected client IP %s, got %s", tt.expectedIP, got)
+			}
+		})
+	}
+}
+
+// TestTrustedProxies_LimitsClientsBehindProxy tests that clients sharing a proxy get their own
+// concurrency allowance, while a client's requests through the proxy still share one
+func TestTrustedProxies_LimitsClientsBehindProxy(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"172.18.0.0/16"})
+	if err != nil {
+		t.Fatalf("NewTrustedProxies failed: %v", err)
+	}
+	limiter, err := NewConcurrencyLimiter(1)
+	if err != nil {
+		t.Fatalf("NewConcurrencyLimiter failed: %v", err)
+	}
+
+	entered := make(chan struct{}, 3)
+	unblock := make(chan struct{})
+	handler := proxies.Handler(limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	viaProxy := func(client string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/yields/historical", nil)
+		req.RemoteAddr = "172.18.0.3:40000"
+		req.Header.Set("X-Real-IP", client)
+		req.Header.Set("X-Forwarded-For", client)
+		return req
+	}
+
+	codes := make(chan int, 2)
+	for _, client := range []string{"198.51.100.1", "198.51.100.2"} {
+		go func(client string) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, viaProxy(client))
+			codes <- w.Code
+		}(client)
+	}
+	<-entered
+	<-entered
+
+	// The first client is at its cap, even though the proxy's own address is shared by both
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, viaProxy("198.51.100.1"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 for a client over its cap, got %d", w.Code)
+	}
+
+	close(unblock)
+	for i := 0; i < 2; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Errorf("Expected 200 for each client behind the proxy, got %d", code)
+		}
+	}
+}
+
+// TestNewTrustedProxies_Invalid tests that malformed proxy addresses and ranges are rejected
+func TestNewTrustedProxies_Invalid(t *testing.T) {
+	for _, proxy := range []string{"nginx", "10.0.0.0/33", "10.0.0"} {
+		if _, err := NewTrustedProxies([]string{proxy}); err == nil {
+			t.Errorf("Expected error for proxy %q", proxy)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRequestID tests that client IDs are reused when well formed and replaced otherwise
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		reused   bool
+	}{
+		{"No header", "", false},
+		{"Client ID", "client-abc_123.4:5", true},
+		{"Unsafe characters", "abc\n{\"level\":\"ERROR\"}", false},
+		{"Too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/buy", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if seen == "" {
+				t.Fatal("Expected a request ID in the context")
+			}
+			if got := rec.Header().Get(RequestIDHeader); got != seen {
+				t.Errorf("Expected response header %q to match context ID %q", got, seen)
+			}
+			if tt.reused && seen != tt.incoming {
+				t.Errorf("Expected client ID %q to be reused, got %q", tt.incoming, seen)
+			}
+			if !tt.reused && (seen == tt.incoming || len(seen) != 32) {
+				t.Errorf("Expected a generated 32-character ID, got %q", seen)
+			}
+		})
+	}
+}
+
+// TestRequestID_UniquePerRequest tests that generated IDs differ between requests
+func TestRequestID_UniquePerRequest(t *testing.T) {
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/yields", nil))
+		id := rec.Header().Get(RequestIDHeader)
+		if seen[id] {
+			t.Fatalf("Duplicate request ID %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+// TestRequestIDLogHandler tests that context-aware log calls carry the request ID and others don't
+func TestRequestIDLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRequestIDLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "Sell order successful", "user_id", 7)
+	logger.InfoContext(context.Background(), "Cache warmed successfully")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var withID, withoutID map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &withID); err != nil {
+		t.Fatalf("Invalid JSON log line: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &withoutID); err != nil {
+		t.Fatalf("Invalid JSON log line: %v", err)
+	}
+
+	if withID["request_id"] != "req-1" || withID["component"] != "test" {
+		t.Errorf("Expected request_id and component attributes, got %v", withID)
+	}
+	if _, ok := withoutID["request_id"]; ok {
+		t.Errorf("Expected no request_id without one in the context, got %v", withoutID)
+	}
+}
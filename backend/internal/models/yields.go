@@ -1,6 +1,11 @@
 package models
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // YieldPoint represents a single term and its corresponding yield rate
 type YieldPoint struct {
@@ -12,6 +17,31 @@ type YieldPoint struct {
 type YieldData struct {
 	Date   string       `json:"date"`   // ISO 8601 date
 	Yields []YieldPoint `json:"yields"` // Array of yield points
+
+	Spreads *CurveSpreads `json:"spreads,omitempty"` // Inversion indicators; omitted if a needed term is missing
+
+	// Set when upstream is unavailable and the last successfully fetched curve is served instead
+	Stale    bool       `json:"stale,omitempty"`
+	CachedAt *time.Time `json:"cached_at,omitempty"` // When the stale curve was fetched
+}
+
+// CurveSpreads holds the commonly watched yield curve spreads, in percentage points.
+// A negative spread means the curve is inverted between those terms.
+type CurveSpreads struct {
+	TwoTen                float64 `json:"2s10s"`          // 10Y rate minus 2Y rate
+	ThreeMonthTen         float64 `json:"3m10y"`          // 10Y rate minus 3M rate
+	Inverted              bool    `json:"inverted"`       // 2s10s spread is negative
+	InvertedThreeMonthTen bool    `json:"inverted_3m10y"` // 3m10y spread is negative
+}
+
+// RateForTerm returns the yield rate for the given term and whether it was present
+func (y *YieldData) RateForTerm(term string) (float64, bool) {
+	for _, point := range y.Yields {
+		if point.Term == term {
+			return point.Rate, true
+		}
+	}
+	return 0, false
 }
 
 // TreasuryFeed represents the XML feed structure from Treasury.gov
@@ -22,24 +52,85 @@ type TreasuryFeed struct {
 
 // Entry represents a single entry in the Treasury XML feed
 type Entry struct {
-	Date     string  `xml:"content>properties>NEW_DATE"`
-	BC1Month float64 `xml:"content>properties>BC_1MONTH"`
-	BC3Month float64 `xml:"content>properties>BC_3MONTH"`
-	BC6Month float64 `xml:"content>properties>BC_6MONTH"`
-	BC1Year  float64 `xml:"content>properties>BC_1YEAR"`
-	BC2Year  float64 `xml:"content>properties>BC_2YEAR"`
-	BC5Year  float64 `xml:"content>properties>BC_5YEAR"`
-	BC10Year float64 `xml:"content>properties>BC_10YEAR"`
-	BC30Year float64 `xml:"content>properties>BC_30YEAR"`
+	Date     string   `xml:"content>properties>NEW_DATE"`
+	BC1Month FeedRate `xml:"content>properties>BC_1MONTH"`
+	BC2Month FeedRate `xml:"content>properties>BC_2MONTH"`
+	BC3Month FeedRate `xml:"content>properties>BC_3MONTH"`
+	BC4Month FeedRate `xml:"content>properties>BC_4MONTH"`
+	BC6Month FeedRate `xml:"content>properties>BC_6MONTH"`
+	BC1Year  FeedRate `xml:"content>properties>BC_1YEAR"`
+	BC2Year  FeedRate `xml:"content>properties>BC_2YEAR"`
+	BC5Year  FeedRate `xml:"content>properties>BC_5YEAR"`
+	BC10Year FeedRate `xml:"content>properties>BC_10YEAR"`
+	BC30Year FeedRate `xml:"content>properties>BC_30YEAR"`
+}
+
+// TermRate returns the entry's rate field for term ("1M" through "30Y"), or nil for an unknown term
+func (e *Entry) TermRate(term string) *FeedRate {
+	switch term {
+	case "1M":
+		return &e.BC1Month
+	case "2M":
+		return &e.BC2Month
+	case "3M":
+		return &e.BC3Month
+	case "4M":
+		return &e.BC4Month
+	case "6M":
+		return &e.BC6Month
+	case "1Y":
+		return &e.BC1Year
+	case "2Y":
+		return &e.BC2Year
+	case "5Y":
+		return &e.BC5Year
+	case "10Y":
+		return &e.BC10Year
+	case "30Y":
+		return &e.BC30Year
+	}
+	return nil
+}
+
+// FeedRate is one term's yield in a feed entry. Valid is false when treasury.gov left the term
+// blank or omitted it (as for the 30Y during its suspension), so a missing rate is never read as 0%.
+type FeedRate struct {
+	Rate  float64
+	Valid bool
+}
+
+// KnownRate returns a FeedRate holding a published rate
+func KnownRate(rate float64) FeedRate {
+	return FeedRate{Rate: rate, Valid: true}
+}
+
+// UnmarshalXML reads a rate element, leaving the rate invalid when its text is blank
+func (r *FeedRate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		*r = FeedRate{}
+		return nil
+	}
+	rate, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return err
+	}
+	*r = KnownRate(rate)
+	return nil
 }
 
 // HistoricalYieldData represents time-series yield data for a specific period
 // The data is formatted for direct consumption by Tremor LineChart component
-// Data array contains flattened objects: {date: "2025-01-02", "10Y": 4.25, "5Y": 4.10, "2Y": 4.05}
+// Data array contains flattened objects with one rate per term: {date: "2025-01-02", "1M": 4.30, ..., "30Y": 4.60}
 type HistoricalYieldData struct {
-	Period    string                   `json:"period"`    // "1M", "3M", "6M", or "1Y"
-	StartDate string                   `json:"startDate"` // YYYY-MM-DD format
-	EndDate   string                   `json:"endDate"`   // YYYY-MM-DD format
-	Terms     []string                 `json:"terms"`     // e.g., ["10Y", "5Y", "2Y"]
-	Data      []map[string]interface{} `json:"data"`      // Flattened for Tremor chart compatibility
+	Period     string                   `json:"period"`     // "1M", "3M", "6M", or "1Y"
+	Resolution string                   `json:"resolution"` // "daily", "weekly", or "monthly"
+	StartDate  string                   `json:"startDate"`  // YYYY-MM-DD format
+	EndDate    string                   `json:"endDate"`    // YYYY-MM-DD format
+	Terms      []string                 `json:"terms"`      // e.g., ["1M", "2M", ..., "10Y", "30Y"]
+	Data       []map[string]interface{} `json:"data"`       // Flattened for Tremor chart compatibility
 }
@@ -0,0 +1,242 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/cors"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"modernfi-treasury-app/internal/config"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/handlers"
+	"modernfi-treasury-app/internal/metrics"
+	"modernfi-treasury-app/internal/middleware"
+	"modernfi-treasury-app/internal/routes"
+	"modernfi-treasury-app/internal/services"
+)
+
+// CORS preflight cache lifetime (seconds)
+const corsMaxAge = 300
+
+// App is the wired application: the HTTP handler serving every route, plus the services
+// the caller may need to start background work on.
+type App struct {
+	Handler   http.Handler
+	TxService *services.TransactionService
+}
+
+// New wires the handlers and services for cfg and registers every route on a chi router.
+// The treasury service is passed in already configured so callers control its upstream.
+// Settings the services reject are returned as errors naming the offending variable.
+func New(cfg *config.Config, pool *pgxpool.Pool, treasuryService *services.TreasuryService) (*App, error) {
+	// Share one set of counters between the services and the /metrics endpoint
+	appMetrics := metrics.New()
+	treasuryService.SetMetrics(appMetrics)
+
+	// Initialize sqlc queries
+	queries := database.New(pool)
+
+	// Initialize handlers
+	// Initialize YieldHandler with service
+	yieldHandler := handlers.NewYieldHandler(treasuryService)
+	if len(cfg.HistoricalPeriods) > 0 {
+		if err := yieldHandler.SetAllowedPeriods(cfg.HistoricalPeriods); err != nil {
+			return nil, fmt.Errorf("invalid HISTORICAL_PERIODS: %w", err)
+		}
+	}
+
+	// Initialize TransactionService and handlers
+	txService := services.NewTransactionService(queries, pool)
+	if err := txService.SetMinFundAmount(cfg.MinFundAmount); err != nil {
+		return nil, fmt.Errorf("invalid MIN_FUND_AMOUNT: %w", err)
+	}
+	if err := txService.SetMaxTransactionAmount(cfg.MaxTxAmount); err != nil {
+		return nil, fmt.Errorf("invalid MAX_TRANSACTION_AMOUNT: %w", err)
+	}
+	if err := txService.SetMinAccountBalance(cfg.MinAccountBalance); err != nil {
+		return nil, fmt.Errorf("invalid MIN_ACCOUNT_BALANCE: %w", err)
+	}
+	if err := txService.SetMaxActiveHoldings(cfg.MaxActiveHoldings); err != nil {
+		return nil, fmt.Errorf("invalid MAX_ACTIVE_HOLDINGS: %w", err)
+	}
+	if err := txService.SetReconcileThreshold(cfg.ReconcileThreshold); err != nil {
+		return nil, fmt.Errorf("invalid RECONCILE_THRESHOLD: %w", err)
+	}
+	txService.SetParPricing(cfg.ParPricing)
+	txService.SetBacktestMode(cfg.BacktestMode)
+	if err := txService.SetBillAccrual(string(cfg.BillAccrual)); err != nil {
+		return nil, fmt.Errorf("invalid BILL_ACCRUAL: %w", err)
+	}
+	if err := txService.SetBillDayCount(string(cfg.BillDayCount)); err != nil {
+		return nil, fmt.Errorf("invalid BILL_DAY_COUNT: %w", err)
+	}
+	if err := txService.SetNoteInterest(string(cfg.NoteInterest)); err != nil {
+		return nil, fmt.Errorf("invalid NOTE_INTEREST: %w", err)
+	}
+	txService.SetMetrics(appMetrics)
+	if cfg.LargeTransactionWebhookURL != "" {
+		webhook, err := services.NewWebhookNotifier(cfg.LargeTransactionWebhookURL, cfg.LargeTransactionThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LARGE_TRANSACTION_WEBHOOK_URL: %w", err)
+		}
+		txService.SetLargeTransactionWebhook(webhook)
+	}
+	if cfg.ParPricing {
+		slog.Warn("Par pricing enabled: all buys, including bills, are charged face value")
+	}
+
+	userHandler := handlers.NewUserHandler(queries, txService)
+	txHandlers := handlers.NewTransactionHandlers(txService, queries, treasuryService)
+	if err := txHandlers.SetSpendRounding(string(cfg.SpendRounding)); err != nil {
+		return nil, fmt.Errorf("invalid BUY_SPEND_ROUNDING: %w", err)
+	}
+	if err := txHandlers.SetMinFaceValue(cfg.MinFaceValue); err != nil {
+		return nil, fmt.Errorf("invalid MIN_FACE_VALUE: %w", err)
+	}
+
+	// Initialize AdminHandlers (disabled unless ADMIN_SECRET is set)
+	adminHandlers := handlers.NewAdminHandlers(txService, treasuryService, cfg.AdminSecret)
+
+	// Initialize HoldingsHandlers
+	holdingsHandlers := handlers.NewHoldingsHandlers(queries, treasuryService)
+	if err := holdingsHandlers.SetBillAccrual(string(cfg.BillAccrual)); err != nil {
+		return nil, fmt.Errorf("invalid BILL_ACCRUAL: %w", err)
+	}
+	if err := holdingsHandlers.SetBillDayCount(string(cfg.BillDayCount)); err != nil {
+		return nil, fmt.Errorf("invalid BILL_DAY_COUNT: %w", err)
+	}
+	if err := holdingsHandlers.SetNoteInterest(string(cfg.NoteInterest)); err != nil {
+		return nil, fmt.Errorf("invalid NOTE_INTEREST: %w", err)
+	}
+
+	// Initialize LadderHandlers (read-only planning tools)
+	ladderHandlers := handlers.NewLadderHandlers(txService, treasuryService)
+
+	// Attribute requests to the client behind a trusted reverse proxy rather than the proxy itself
+	trustedProxies, err := middleware.NewTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	// Cap concurrent historical requests per IP; each can trigger a slow multi-year upstream fetch
+	historicalLimiter, err := middleware.NewConcurrencyLimiter(cfg.HistoricalMaxConcurrentPerIP)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HISTORICAL_MAX_CONCURRENT_PER_IP: %w", err)
+	}
+
+	// Cap order and balance requests per user (or IP when unauthenticated) to stop runaway clients
+	transactionLimiter, err := middleware.NewRateLimiter(cfg.TransactionRateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRANSACTION_RATE_LIMIT: %w", err)
+	}
+
+	// Cap request bodies so an oversized payload is refused rather than buffered
+	maxBodySize, err := middleware.MaxBodySize(int64(cfg.MaxRequestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_REQUEST_BODY_BYTES: %w", err)
+	}
+
+	// Authenticate user-scoped routes by bearer token (disabled unless API_KEYS is set)
+	var apiKeyAuth *middleware.APIKeyAuth
+	if len(cfg.APIKeys) > 0 {
+		apiKeyAuth, err = middleware.NewAPIKeyAuth(cfg.APIKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API_KEYS: %w", err)
+		}
+	} else {
+		slog.Warn("API key authentication disabled: any caller can act on any user")
+	}
+
+	// Create chi router
+	r := chi.NewRouter()
+
+	// Tag every request with an ID first so even rejected requests can be traced
+	r.Use(middleware.RequestID)
+
+	// Resolve the client IP before any per-IP limiter runs
+	r.Use(trustedProxies.Handler)
+
+	// Add CORS middleware
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization", "X-Admin-Secret", middleware.UserIDHeader, middleware.RequestIDHeader},
+		ExposedHeaders:   []string{middleware.RequestIDHeader},
+		AllowCredentials: false,
+		MaxAge:           corsMaxAge,
+	}))
+
+	// After CORS, so browsers can read the 413; before auth, which buffers JSON bodies to check user_id
+	r.Use(maxBodySize)
+
+	// User-scoped routes require an API key for the user they act on once API_KEYS is set.
+	// userParam names the path parameter holding the user ID, if any; body, query, and
+	// X-User-ID user IDs are checked on every authenticated route.
+	authenticated := func(userParam string) chi.Router {
+		if apiKeyAuth == nil {
+			return r.With()
+		}
+		return r.With(apiKeyAuth.Handler, middleware.RequireSameUser(userParam))
+	}
+
+	// Register routes. The user list exposes every balance and creating a user funds its starting
+	// balance, so once API_KEYS is set both are admin-only.
+	if apiKeyAuth == nil {
+		r.Get(routes.Users, userHandler.GetAllUsers)
+		r.Post(routes.Users, userHandler.CreateUser)
+	} else {
+		r.With(adminHandlers.RequireSecret).Get(routes.Users, userHandler.GetAllUsers)
+		r.With(adminHandlers.RequireSecret).Post(routes.Users, userHandler.CreateUser)
+	}
+	authenticated("id").Get(routes.User, userHandler.GetUser)
+	authenticated("userId").Get(routes.UserTransactions, txHandlers.GetUserTransactions)
+	authenticated("userId").Get(routes.UserTransactionsCSV, txHandlers.ExportTransactionsCSV)
+	authenticated("id").Get(routes.UserHoldings, holdingsHandlers.GetUserHoldings)
+	authenticated("id").Get(routes.UserHoldingsCSV, holdingsHandlers.ExportHoldingsCSV)
+	authenticated("id").Get(routes.UserHoldingsMaturing, holdingsHandlers.GetMaturingHoldings)
+	authenticated("id").Get(routes.UserMaturityAlerts, holdingsHandlers.GetMaturityAlerts)
+	authenticated("id").Get(routes.UserPortfolio, holdingsHandlers.GetPortfolioSummary)
+	authenticated("id").Get(routes.UserTermPosition, holdingsHandlers.GetTermPosition)
+	authenticated("id").Get(routes.UserYieldComparison, holdingsHandlers.GetYieldComparison)
+	authenticated("").Get(routes.Holding, holdingsHandlers.GetHolding)
+	authenticated("").Get(routes.HoldingLifecycle, holdingsHandlers.GetHoldingLifecycle)
+	authenticated("").Get(routes.HoldingProjection, holdingsHandlers.GetHoldingProjection)
+
+	// Historical yield data endpoint (must be registered before /api/yields)
+	r.With(historicalLimiter.Handler).Get(routes.YieldsHistorical, yieldHandler.GetHistoricalYields)
+	// Current yield snapshot endpoint
+	r.Get(routes.Yields, yieldHandler.GetYields)
+
+	authenticated("").With(transactionLimiter.Handler).Post(routes.Fund, txHandlers.FundHandler)
+	authenticated("").With(transactionLimiter.Handler).Post(routes.Withdraw, txHandlers.WithdrawHandler)
+	authenticated("").With(transactionLimiter.Handler).Post(routes.Buy, txHandlers.BuyHandler)
+	authenticated("").With(transactionLimiter.Handler).Post(routes.BuyBatch, txHandlers.BuyBatchHandler)
+	authenticated("").With(transactionLimiter.Handler).Post(routes.Sell, txHandlers.SellHandler)
+	authenticated("").Post(routes.Mature, txHandlers.MatureHandler)
+	authenticated("").Post(routes.Rollover, txHandlers.RolloverHandler)
+	authenticated("").Delete(routes.Holding, txHandlers.CancelHandler)
+	r.Post(routes.BuyQuote, txHandlers.BuyQuoteHandler)
+	r.Post(routes.LadderCost, ladderHandlers.LadderCostHandler)
+	r.Post(routes.AdminBulkAdjust, adminHandlers.BulkAdjustHandler)
+	r.Patch(routes.AdminHoldingYield, adminHandlers.CorrectHoldingYieldHandler)
+
+	// Health check route
+	r.Get(routes.Health, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	// Upstream readiness: last treasury.gov fetch outcome and cache state
+	r.Get(routes.UpstreamHealth, yieldHandler.GetUpstreamHealth)
+
+	// Prometheus scrape endpoint for transaction, upstream fetch, and cache counters
+	r.Method(http.MethodGet, routes.Metrics, appMetrics.Handler())
+
+	return &App{
+		Handler:   r,
+		TxService: txService,
+	}, nil
+}
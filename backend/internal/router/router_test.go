@@ -0,0 +1,414 @@
+package router_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"modernfi-treasury-app/internal/middleware"
+	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/routes"
+	"modernfi-treasury-app/internal/testutil"
+)
+
+// TestRouter_HealthAndRequestID tests that requests pass through the middleware stack and get an ID back
+func TestRouter_HealthAndRequestID(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	resp, err := http.Get(server.URL + routes.Health)
+	if err != nil {
+		t.Fatalf("GET /health failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "OK" {
+		t.Errorf("Expected 200 OK, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get(middleware.RequestIDHeader) == "" {
+		t.Error("Expected an X-Request-ID response header")
+	}
+}
+
+// TestRouter_YieldsFromFakeUpstream tests that the yields endpoint serves the fake treasury curve end to end
+func TestRouter_YieldsFromFakeUpstream(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	resp, err := http.Get(server.URL + routes.Yields)
+	if err != nil {
+		t.Fatalf("GET /api/yields failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var data models.YieldData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		t.Fatalf("Failed to decode yields: %v", err)
+	}
+	if data.Date != testutil.TestYieldDate {
+		t.Errorf("Expected curve date %s, got %s", testutil.TestYieldDate, data.Date)
+	}
+	if rate, found := data.RateForTerm("6M"); !found || rate != testutil.TestYields["6M"] {
+		t.Errorf("Expected 6M rate %.2f, got %.2f (found=%v)", testutil.TestYields["6M"], rate, found)
+	}
+}
+
+// TestRouter_YieldsForDate tests that a dated lookup serves the nearest prior curve from the fake upstream
+func TestRouter_YieldsForDate(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	// The fake feed's only entry is TestYieldDate (a Friday), so the following Monday resolves to it
+	resp, err := http.Get(server.URL + routes.Yields + "?date=2025-03-17")
+	if err != nil {
+		t.Fatalf("GET /api/yields?date failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var data models.YieldData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		t.Fatalf("Failed to decode yields: %v", err)
+	}
+	if data.Date != testutil.TestYieldDate {
+		t.Errorf("Expected curve date %s, got %s", testutil.TestYieldDate, data.Date)
+	}
+	if len(data.Yields) != len(testutil.TestYields) {
+		t.Errorf("Expected %d terms, got %d", len(testutil.TestYields), len(data.Yields))
+	}
+}
+
+// TestRouter_CORSPreflight tests that preflights from allowed origins are answered and others are not
+func TestRouter_CORSPreflight(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"http://localhost:5173", true},
+		{"https://evil.example", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodOptions, server.URL+routes.Buy, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Request-ID")
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Preflight failed: %v", err)
+			}
+			resp.Body.Close()
+
+			got := resp.Header.Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("Expected origin %s to be allowed, got %q", tt.origin, got)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("Expected origin %s to be refused, got %q", tt.origin, got)
+			}
+		})
+	}
+}
+
+// TestRouter_ErrorMapping tests routing misses and handler validation errors as seen over HTTP
+func TestRouter_ErrorMapping(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"Malformed buy body", http.MethodPost, routes.Buy, "{", http.StatusBadRequest, "invalid request body"},
+		{"Non-numeric holding ID", http.MethodDelete, routes.Path(routes.Holding, "abc") + "?user_id=1", "", http.StatusBadRequest, "id must be a positive integer"},
+		{"Non-numeric export user ID", http.MethodGet, routes.Path(routes.UserTransactionsCSV, "abc"), "", http.StatusBadRequest, "userId must be a positive integer"},
+		{"Empty ladder", http.MethodPost, routes.LadderCost, `{"legs": []}`, http.StatusBadRequest, "at least one ladder leg is required"},
+		{"Unknown route", http.MethodGet, "/api/v1/nope", "", http.StatusNotFound, ""},
+		{"Wrong method", http.MethodGet, routes.Buy, "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedError == "" {
+				return
+			}
+			var body struct {
+				Success bool   `json:"success"`
+				Error   string `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode error response: %v", err)
+			}
+			if body.Success || body.Error != tt.expectedError {
+				t.Errorf("Expected error %q, got %+v", tt.expectedError, body)
+			}
+		})
+	}
+}
+
+// TestRouter_OversizedBody tests that POST bodies over MAX_REQUEST_BODY_BYTES get 413,
+// whether the size is declared up front or only discovered while decoding a chunked upload
+func TestRouter_OversizedBody(t *testing.T) {
+	t.Setenv("MAX_REQUEST_BODY_BYTES", "1024")
+	server := testutil.NewTestServer(t)
+
+	oversized := `{"user_id": 1, "amount": 100, "term": "` + strings.Repeat("x", 2048) + `"}`
+	tests := []struct {
+		name    string
+		path    string
+		chunked bool
+	}{
+		{"Fund with Content-Length", routes.Fund, false},
+		{"Withdraw with Content-Length", routes.Withdraw, false},
+		{"Buy chunked", routes.Buy, true},
+		{"Sell chunked", routes.Sell, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(oversized)
+			if tt.chunked {
+				// Hiding the length makes the client send the body chunked
+				body = io.MultiReader(body)
+			}
+			req, _ := http.NewRequest(http.MethodPost, server.URL+tt.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusRequestEntityTooLarge {
+				t.Errorf("Expected status 413, got %d", resp.StatusCode)
+			}
+			var errBody struct {
+				Success bool   `json:"success"`
+				Error   string `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil {
+				t.Fatalf("Failed to decode error response: %v", err)
+			}
+			if errBody.Success || errBody.Error != "request body too large" {
+				t.Errorf("Expected error %q, got %+v", "request body too large", errBody)
+			}
+		})
+	}
+}
+
+// TestRouter_APIKeyAuth tests that user-scoped routes require a matching API key once API_KEYS is set,
+// while public routes stay open
+func TestRouter_APIKeyAuth(t *testing.T) {
+	t.Setenv("API_KEYS", "key-one:1")
+	t.Setenv("ADMIN_SECRET", "admin-secret")
+	server := testutil.NewTestServer(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authorization  string
+		body           string
+		expectedStatus int
+	}{
+		{"Public yields", http.MethodGet, routes.Yields, "", "", http.StatusOK},
+		{"Buy without a key", http.MethodPost, routes.Buy, "", `{"user_id": 1}`, http.StatusUnauthorized},
+		// Every user's balance is listed, so the list needs the admin secret rather than a user's key
+		{"User list without a key", http.MethodGet, routes.Users, "", "", http.StatusUnauthorized},
+		{"User list with a user key", http.MethodGet, routes.Users, "Bearer key-one", "", http.StatusUnauthorized},
+		// Creating a user funds its starting balance, so it needs the admin secret too
+		{"Create user without a key", http.MethodPost, routes.Users, "", `{"name": "Mallory", "balance": 1000000}`, http.StatusUnauthorized},
+		{"Create user with a user key", http.MethodPost, routes.Users, "Bearer key-one", `{"name": "Mallory"}`, http.StatusUnauthorized},
+		{"Buy for another user", http.MethodPost, routes.Buy, "Bearer key-one", `{"user_id": 2}`, http.StatusForbidden},
+		{"Holdings of another user", http.MethodGet, routes.Path(routes.UserHoldings, "2"), "Bearer key-one", "", http.StatusForbidden},
+		{"Maturing holdings of another user", http.MethodGet, routes.Path(routes.UserHoldingsMaturing, "2"), "Bearer key-one", "", http.StatusForbidden},
+		{"Transactions of another user", http.MethodGet, routes.Path(routes.UserTransactions, "2"), "Bearer key-one", "", http.StatusForbidden},
+		{"Holding for another user", http.MethodGet, routes.Path(routes.Holding, "5") + "?user_id=2", "Bearer key-one", "", http.StatusForbidden},
+		// Authenticated and matching, so the request reaches the handler, which rejects the invalid term
+		{"Buy for own user", http.MethodPost, routes.Buy, "Bearer key-one", `{"user_id": 1, "term": "7M", "face_value": 1000}`, http.StatusBadRequest},
+		{"Maturing holdings with a bad window", http.MethodGet, routes.Path(routes.UserHoldingsMaturing, "1") + "?days=0", "Bearer key-one", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// TestRouter_TransactionRateLimit tests that transaction endpoints answer 429 with Retry-After once a client's
+// per-minute allowance is spent, while other routes are not limited
+func TestRouter_TransactionRateLimit(t *testing.T) {
+	t.Setenv("TRANSACTION_RATE_LIMIT", "2")
+	server := testutil.NewTestServer(t)
+
+	// Malformed bodies are rejected by the handler without touching the database, but still spend tokens
+	for i, expected := range []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusTooManyRequests} {
+		resp, err := http.Post(server.URL+routes.Fund, "application/json", strings.NewReader("{"))
+		if err != nil {
+			t.Fatalf("POST /api/v1/fund failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Fatalf("Request %d: expected status %d, got %d", i+1, expected, resp.StatusCode)
+		}
+		if expected == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Error("Expected a Retry-After header on 429")
+		}
+	}
+
+	// The limit covers fund, withdraw, buy, and sell together
+	resp, err := http.Post(server.URL+routes.Sell, "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatalf("POST /api/v1/sell failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected sell to share the exhausted bucket, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+routes.LadderCost, "application/json", strings.NewReader(`{"legs": []}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/ladder/cost failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected ladder cost to be unlimited, got %d", resp.StatusCode)
+	}
+}
+
+// TestRouter_LadderCost tests pricing a ladder against the fake curve through the full stack
+func TestRouter_LadderCost(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	resp, err := http.Post(server.URL+routes.LadderCost, "application/json",
+		strings.NewReader(`{"legs": [{"term": "6M", "face_value": 10000}, {"term": "2Y", "face_value": 5000}]}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/ladder/cost failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var body struct {
+		TotalPurchasePrice float64 `json:"total_purchase_price"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode ladder cost: %v", err)
+	}
+	// 6M bill at 4.50%: 10000 × (1 - 0.045 × 180/360) = 9775.00; 2Y note at par: 5000.00
+	if body.TotalPurchasePrice != 14775.00 {
+		t.Errorf("Expected total purchase price 14775.00, got %.2f", body.TotalPurchasePrice)
+	}
+}
+
+// TestRouter_BuyQuote tests quoting a bill against the fake curve without a database, and that the term is still validated
+func TestRouter_BuyQuote(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	resp, err := http.Post(server.URL+routes.BuyQuote, "application/json",
+		strings.NewReader(`{"user_id": 1, "term": "6M", "face_value": 100000}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/buy/quote failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var quote struct {
+		PurchasePrice float64 `json:"purchase_price"`
+		Discount      float64 `json:"discount"`
+		Yield         float64 `json:"yield"`
+		YieldDate     string  `json:"yield_date"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		t.Fatalf("Failed to decode quote: %v", err)
+	}
+	// 6M bill at 4.50%: 100000 × (1 - 0.045 × 180/360) = 97750.00
+	if quote.PurchasePrice != 97750.00 || quote.Discount != 2250.00 {
+		t.Errorf("Expected price 97750.00 and discount 2250.00, got %.2f and %.2f", quote.PurchasePrice, quote.Discount)
+	}
+	if quote.Yield != testutil.TestYields["6M"] || quote.YieldDate != testutil.TestYieldDate {
+		t.Errorf("Expected yield %.2f on %s, got %.2f on %s", testutil.TestYields["6M"], testutil.TestYieldDate, quote.Yield, quote.YieldDate)
+	}
+
+	invalid, err := http.Post(server.URL+routes.BuyQuote, "application/json",
+		strings.NewReader(`{"term": "7M", "face_value": 100000}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/buy/quote failed: %v", err)
+	}
+	invalid.Body.Close()
+	if invalid.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid term, got %d", invalid.StatusCode)
+	}
+}
+
+// TestRouter_Metrics tests that yield lookups show up as cache and upstream counters on /metrics
+func TestRouter_Metrics(t *testing.T) {
+	server := testutil.NewTestServer(t)
+
+	// The first lookup misses the cache and fetches upstream; the second is served from cache
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(server.URL + routes.Yields)
+		if err != nil {
+			t.Fatalf("GET /api/yields failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + routes.Metrics)
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	for _, series := range []string{
+		`treasury_yield_cache_lookups_total{cache="latest",result="hit"} 1`,
+		`treasury_yield_cache_lookups_total{cache="latest",result="miss"} 1`,
+		`treasury_upstream_fetches_total{result="success"} 1`,
+	} {
+		if !strings.Contains(string(body), series) {
+			t.Errorf("Expected %s in metrics output, got:\n%s", series, body)
+		}
+	}
+}
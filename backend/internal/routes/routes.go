@@ -0,0 +1,81 @@
+// Package routes names every API path pattern so route registration and tests share one definition.
+// Patterns use chi's {param} placeholders; Path fills them in to build request URLs.
+package routes
+
+import "strings"
+
+// User reads
+const (
+	Users                = "/api/v1/users"
+	User                 = "/api/v1/users/{id}"
+	UserTransactions     = "/api/v1/users/{userId}/transactions"
+	UserTransactionsCSV  = "/api/v1/users/{userId}/transactions.csv"
+	UserHoldings         = "/api/v1/users/{id}/holdings"
+	UserHoldingsCSV      = "/api/v1/users/{id}/holdings.csv"
+	UserHoldingsMaturing = "/api/v1/users/{id}/holdings/maturing"
+	UserMaturityAlerts   = "/api/v1/users/{id}/maturity-alerts"
+	UserPortfolio        = "/api/v1/users/{id}/portfolio"
+	UserTermPosition     = "/api/v1/users/{id}/positions/{term}"
+	UserYieldComparison  = "/api/v1/users/{id}/yield-comparison"
+)
+
+// Holdings
+const (
+	Holding           = "/api/v1/holdings/{id}" // GET fetches the holding, DELETE cancels it
+	HoldingLifecycle  = "/api/v1/holdings/{id}/lifecycle"
+	HoldingProjection = "/api/v1/holdings/{id}/projection"
+)
+
+// Yields
+const (
+	Yields           = "/api/yields"
+	YieldsHistorical = "/api/yields/historical"
+)
+
+// Account and order operations
+const (
+	Fund       = "/api/v1/fund"
+	Withdraw   = "/api/v1/withdraw"
+	Buy        = "/api/v1/buy"
+	BuyBatch   = "/api/v1/buy/batch"
+	BuyQuote   = "/api/v1/buy/quote"
+	Sell       = "/api/v1/sell"
+	Mature     = "/api/v1/mature"
+	Rollover   = "/api/v1/rollover"
+	LadderCost = "/api/v1/ladder/cost"
+)
+
+// Admin operations (require X-Admin-Secret)
+const (
+	AdminBulkAdjust   = "/api/v1/admin/bulk-adjust"
+	AdminHoldingYield = "/api/v1/admin/holdings/{id}/yield"
+)
+
+// Health and monitoring
+const (
+	Health         = "/health"
+	UpstreamHealth = "/health/upstream"
+	Metrics        = "/metrics"
+)
+
+// Path fills the {param} placeholders of pattern with values, in order.
+// Placeholders without a value are left as-is, so a missing argument shows up in the URL rather than vanishing.
+func Path(pattern string, values ...string) string {
+	var b strings.Builder
+	rest := pattern
+	for _, value := range values {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(value)
+		rest = rest[start+end+1:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
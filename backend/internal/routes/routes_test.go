@@ -0,0 +1,27 @@
+package routes
+
+import "testing"
+
+// TestPath tests filling placeholders in order, with too few and too many values
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		values   []string
+		expected string
+	}{
+		{"No placeholders", Buy, nil, "/api/v1/buy"},
+		{"Single placeholder", Holding, []string{"5"}, "/api/v1/holdings/5"},
+		{"Placeholder mid-path", UserTransactionsCSV, []string{"42"}, "/api/v1/users/42/transactions.csv"},
+		{"Missing value keeps placeholder", HoldingLifecycle, nil, "/api/v1/holdings/{id}/lifecycle"},
+		{"Extra values ignored", AdminHoldingYield, []string{"7", "8"}, "/api/v1/admin/holdings/7/yield"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Path(tt.pattern, tt.values...); got != tt.expected {
+				t.Errorf("Path(%q, %v) = %q, expected %q", tt.pattern, tt.values, got, tt.expected)
+			}
+		})
+	}
+}
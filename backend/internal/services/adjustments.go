@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// BalanceAdjustment is a signed admin correction to one user's balance.
+// Positive amounts are recorded as funds and negative amounts as withdrawals.
+type BalanceAdjustment struct {
+	UserID int32
+	Amount pgtype.Numeric
+	Reason string
+}
+
+// BulkAdjust applies every adjustment in a single database transaction.
+// Each adjustment writes a fund or withdraw transaction with the reason as its memo.
+// If any adjustment fails (ErrInvalidAdjustment, ErrUserNotFound, ErrInsufficientBalance) none are applied.
+// Returns the updated users in the order the adjustments were given.
+func (s *TransactionService) BulkAdjust(ctx context.Context, adjustments []BalanceAdjustment) ([]database.User, error) {
+	if len(adjustments) == 0 {
+		return nil, fmt.Errorf("%w: at least one adjustment is required", ErrInvalidAdjustment)
+	}
+
+	// Validate everything up front so no partial work is attempted
+	for i, adj := range adjustments {
+		amountFloat, err := utils.NumericToFloat(adj.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("adjustment %d: %w: invalid amount format: %v", i, ErrInvalidAdjustment, err)
+		}
+		if amountFloat == 0 {
+			return nil, fmt.Errorf("adjustment %d: %w: amount must be non-zero", i, ErrInvalidAdjustment)
+		}
+		if strings.TrimSpace(adj.Reason) == "" {
+			return nil, fmt.Errorf("adjustment %d: %w: reason is required", i, ErrInvalidAdjustment)
+		}
+	}
+
+	updatedUsers := make([]database.User, 0, len(adjustments))
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+
+		for i, adj := range adjustments {
+			// Verify the user exists and lock the row for the balance update
+			if _, err := qtx.GetUserForUpdate(ctx, adj.UserID); err != nil {
+				return fmt.Errorf("adjustment %d (user %d): %w", i, adj.UserID, userLookupError(err))
+			}
+
+			user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
+				Balance: adj.Amount,
+				ID:      adj.UserID,
+			})
+			if err != nil {
+				// Check if error is due to balance constraint violation (SQLSTATE 23514)
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+					return fmt.Errorf("adjustment %d (user %d): %w", i, adj.UserID, ErrInsufficientBalance)
+				}
+				return fmt.Errorf("adjustment %d: failed to update balance: %w", i, err)
+			}
+
+			// Transaction amounts are always positive; the type carries the direction
+			txType := database.TransactionTypeFund
+			amount := adj.Amount
+			if adj.Amount.Int.Sign() < 0 {
+				txType = database.TransactionTypeWithdraw
+				amount = pgtype.Numeric{Int: new(big.Int).Neg(adj.Amount.Int), Exp: adj.Amount.Exp, Valid: true}
+			}
+
+			_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
+				UserID:             adj.UserID,
+				Type:               txType,
+				Term:               pgtype.Text{Valid: false},
+				Amount:             amount,
+				YieldAtTransaction: pgtype.Numeric{Valid: false},
+				BalanceAfter:       user.Balance,
+				HoldingID:          pgtype.Int4{Valid: false},
+				Memo:               pgtype.Text{String: strings.TrimSpace(adj.Reason), Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("adjustment %d: failed to create transaction record: %w", i, err)
+			}
+
+			updatedUsers = append(updatedUsers, user)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedUsers, nil
+}
+
+// CorrectHoldingYield overwrites a holding's yield_at_purchase and records the old and new
+// values with the reason in holding_yield_corrections, atomically.
+// Subsequent sell and maturity proceeds use the corrected yield.
+func (s *TransactionService) CorrectHoldingYield(
+	ctx context.Context,
+	holdingID int32,
+	newYield pgtype.Numeric,
+	reason string,
+) (database.Holding, database.HoldingYieldCorrection, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return database.Holding{}, database.HoldingYieldCorrection{}, errors.New("reason is required")
+	}
+
+	var updated database.Holding
+	var correction database.HoldingYieldCorrection
+
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+
+		holding, err := qtx.GetHoldingByID(ctx, holdingID)
+		if err != nil {
+			return holdingLookupError(err)
+		}
+
+		updated, err = qtx.UpdateHoldingYield(ctx, database.UpdateHoldingYieldParams{
+			ID:              holdingID,
+			YieldAtPurchase: newYield,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update holding yield: %w", err)
+		}
+
+		correction, err = qtx.CreateHoldingYieldCorrection(ctx, database.CreateHoldingYieldCorrectionParams{
+			HoldingID: holdingID,
+			OldYield:  holding.YieldAtPurchase,
+			NewYield:  updated.YieldAtPurchase,
+			Reason:    reason,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record yield correction: %w", err)
+		}
+
+		oldYield, _ := utils.NumericToFloat(holding.YieldAtPurchase)
+		corrected, _ := utils.NumericToFloat(updated.YieldAtPurchase)
+		slog.WarnContext(ctx, "Corrected holding yield", "holding_id", holdingID, "previous_yield", oldYield, "yield", corrected, "reason", reason)
+		return nil
+	})
+	if err != nil {
+		return database.Holding{}, database.HoldingYieldCorrection{}, err
+	}
+
+	return updated, correction, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// CancelHolding unwinds a same-day buy: the exact purchase price is refunded, the holding is deleted,
+// and a cancel transaction records the reversal. Only holdings bought today and never sold can be cancelled;
+// anything else must go through SellTreasury.
+func (s *TransactionService) CancelHolding(ctx context.Context, userID int32, holdingID int32) (*database.User, error) {
+	var updatedUser *database.User
+	var refund utils.Money
+
+	// Use database transaction for atomicity
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+
+		// Lock the holding so a concurrent sell can't slip in between the check and the delete
+		holding, err := qtx.GetHoldingByIDForUpdate(ctx, holdingID)
+		if err != nil {
+			return holdingLookupError(err)
+		}
+
+		// Verify holding belongs to user (security check)
+		if holding.UserID != userID {
+			return ErrUnauthorized
+		}
+
+		refund, err = checkCancellable(holding, time.Now())
+		if err != nil {
+			return err
+		}
+
+		// Refund exactly what was paid
+		user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
+			Balance: refund.Numeric(),
+			ID:      userID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		if err := qtx.DeleteHolding(ctx, holding.ID); err != nil {
+			return fmt.Errorf("failed to delete holding: %w", err)
+		}
+
+		// Record the reversal; holding_id keeps the link to the original buy after the row is gone
+		_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
+			UserID:             userID,
+			Type:               database.TransactionTypeCancel,
+			Term:               pgtype.Text{String: holding.Term, Valid: true},
+			Amount:             refund.Numeric(),
+			YieldAtTransaction: holding.YieldAtPurchase,
+			BalanceAfter:       user.Balance,
+			HoldingID:          pgtype.Int4{Int32: holding.ID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create transaction record: %w", err)
+		}
+
+		updatedUser = &user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Cancelled holding", "user_id", userID, "holding_id", holdingID, "amount", refund)
+	s.countTransaction(database.TransactionTypeCancel)
+	return updatedUser, nil
+}
+
+// checkCancellable returns the purchase price to refund if the holding was bought on now's calendar day
+// and its remaining amount is still its full face value, or an error explaining why it can't be unwound
+func checkCancellable(holding database.Holding, now time.Time) (utils.Money, error) {
+	purchased := holding.PurchaseDate.Time
+	if !holding.PurchaseDate.Valid || purchased.Year() != now.Year() || purchased.YearDay() != now.YearDay() {
+		return 0, errors.New("only holdings purchased today can be cancelled")
+	}
+
+	faceValue, err := utils.MoneyFromNumeric(holding.FaceValue)
+	if err != nil {
+		return 0, fmt.Errorf("invalid face value format: %w", err)
+	}
+	remaining, err := holdingRemaining(holding)
+	if err != nil {
+		return 0, err
+	}
+	if remaining != faceValue {
+		return 0, errors.New("holding has been partially sold and can no longer be cancelled")
+	}
+
+	refund, err := utils.MoneyFromNumeric(holding.PurchasePrice)
+	if err != nil {
+		return 0, fmt.Errorf("invalid purchase price format: %w", err)
+	}
+	if !refund.IsPositive() {
+		return 0, errors.New("holding has no purchase price to refund")
+	}
+	return refund, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Errors the transaction services return, possibly wrapped with detail such as the amounts involved.
+// Callers should match them with errors.Is rather than comparing messages.
+var (
+	// ErrUserNotFound is returned when no user has the given ID
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrHoldingNotFound is returned when no holding has the given ID
+	ErrHoldingNotFound = errors.New("holding not found")
+
+	// ErrUnauthorized is returned when a holding does not belong to the user acting on it
+	ErrUnauthorized = errors.New("unauthorized: holding does not belong to user")
+
+	// ErrInsufficientBalance is returned when a withdrawal or purchase exceeds the user's balance
+	ErrInsufficientBalance = errors.New("insufficient balance")
+
+	// ErrInsufficientRemaining is returned when a sell exceeds the holding's remaining amount
+	ErrInsufficientRemaining = errors.New("insufficient remaining amount")
+
+	// ErrNotMatured is returned when redeeming or rolling over a holding before its maturity date
+	ErrNotMatured = errors.New("holding has not matured")
+
+	// ErrBacktestDisabled is returned for a backdated buy, or a sell, maturity, or rollover of a backdated
+	// holding, while backtest mode is off
+	ErrBacktestDisabled = errors.New("backtest mode is disabled")
+
+	// ErrInvalidAdjustment is returned when an admin balance adjustment has a zero amount or no reason
+	ErrInvalidAdjustment = errors.New("invalid adjustment")
+
+	// ErrIdempotencyKeyMismatch is returned when an idempotency key is replayed for a different operation or amount
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key already used for a different request")
+)
+
+// userLookupError maps a failed user lookup to ErrUserNotFound when no row matched
+func userLookupError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	return fmt.Errorf("failed to get user: %w", err)
+}
+
+// holdingLookupError maps a failed holding lookup to ErrHoldingNotFound when no row matched
+func holdingLookupError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrHoldingNotFound
+	}
+	return fmt.Errorf("failed to get holding: %w", err)
+}
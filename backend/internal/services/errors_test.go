@@ -0,0 +1,31 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TestLookupErrors tests that a missing row maps to the not-found sentinel and other failures stay distinct
+func TestLookupErrors(t *testing.T) {
+	if err := holdingLookupError(pgx.ErrNoRows); err != ErrHoldingNotFound {
+		t.Errorf("Expected ErrHoldingNotFound for no rows, got %v", err)
+	}
+	// The driver may add context of its own around the missing row
+	if err := holdingLookupError(fmt.Errorf("scan holding: %w", pgx.ErrNoRows)); err != ErrHoldingNotFound {
+		t.Errorf("Expected ErrHoldingNotFound for wrapped no rows, got %v", err)
+	}
+	if err := userLookupError(pgx.ErrNoRows); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for no rows, got %v", err)
+	}
+
+	dbErr := errors.New("connection reset")
+	if err := holdingLookupError(dbErr); errors.Is(err, ErrHoldingNotFound) || !errors.Is(err, dbErr) {
+		t.Errorf("Expected a wrapped database error, got %v", err)
+	}
+	if err := userLookupError(dbErr); errors.Is(err, ErrUserNotFound) || !errors.Is(err, dbErr) {
+		t.Errorf("Expected a wrapped database error, got %v", err)
+	}
+}
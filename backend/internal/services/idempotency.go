@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/utils"
+)
+
+const (
+	// Upper bound on client-supplied idempotency key length, matching the column width
+	maxIdempotencyKeyLength = 255
+)
+
+// claimIdempotencyKey claims key for the given operation and amount inside the caller's transaction and
+// reports whether the operation should be applied. An empty key always applies.
+// A key replayed within 24 hours for the same operation and amount returns false so the caller can
+// return the already-applied state; reusing a key for a different operation or amount is
+// ErrIdempotencyKeyMismatch, since the replay would otherwise report a request that was never applied.
+func claimIdempotencyKey(ctx context.Context, qtx *database.Queries, userID int32, key string, operation database.TransactionType, amount utils.Money) (bool, error) {
+	if key == "" {
+		return true, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return false, fmt.Errorf("idempotency key must be at most %d characters", maxIdempotencyKeyLength)
+	}
+
+	claimed, err := qtx.ClaimIdempotencyKey(ctx, database.ClaimIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+		Operation:      operation,
+		Amount:         amount.Numeric(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed > 0 {
+		return true, nil
+	}
+
+	existing, err := qtx.GetIdempotencyKey(ctx, database.GetIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if existing.Operation != operation {
+		return false, fmt.Errorf("%w: claimed for %s", ErrIdempotencyKeyMismatch, existing.Operation)
+	}
+	// Keys claimed before amounts were recorded have none to compare
+	if existing.Amount.Valid {
+		claimedAmount, err := utils.MoneyFromNumeric(existing.Amount)
+		if err != nil {
+			return false, fmt.Errorf("invalid idempotency key amount: %w", err)
+		}
+		if claimedAmount != amount {
+			return false, fmt.Errorf("%w: claimed for %s %s", ErrIdempotencyKeyMismatch, existing.Operation, claimedAmount)
+		}
+	}
+	return false, nil
+}
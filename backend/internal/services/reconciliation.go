@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/utils"
+)
+
+// HoldingCorrection records a remaining_amount fix applied by ReconcileHoldings
+type HoldingCorrection struct {
+	HoldingID int32   `json:"holding_id"`
+	Previous  float64 `json:"previous"`
+	Corrected float64 `json:"corrected"`
+	Drift     float64 `json:"drift"` // Previous - Corrected
+}
+
+// SetReconcileThreshold sets the drift (in dollars) tolerated before a holding is corrected
+func (s *TransactionService) SetReconcileThreshold(threshold float64) error {
+	if threshold < 0 || math.IsNaN(threshold) {
+		return fmt.Errorf("reconcile threshold must be non-negative, got: %f", threshold)
+	}
+	s.reconcileThreshold = threshold
+	return nil
+}
+
+// expectedRemainingCents recomputes a holding's remaining amount in cents as face value minus total sold.
+// Working in whole cents avoids reintroducing the float drift being corrected.
+func expectedRemainingCents(faceValue float64, totalSold float64) (int64, error) {
+	faceCents := int64(math.Round(faceValue * 100))
+	soldCents := int64(math.Round(totalSold * 100))
+	if soldCents > faceCents {
+		return 0, fmt.Errorf("total sold %.2f exceeds face value %.2f", totalSold, faceValue)
+	}
+	return faceCents - soldCents, nil
+}
+
+// ReconcileHoldings recomputes every holding's remaining_amount from its face value minus
+// the sum of its sell and mature transactions, and corrects holdings whose drift exceeds the threshold.
+// Holdings whose sells exceed face value are logged and left untouched for manual review.
+// The unlocked listing only picks candidates; each correction is recomputed under the holding's row
+// lock, so a sell committing in between is counted rather than overwritten.
+func (s *TransactionService) ReconcileHoldings(ctx context.Context) ([]HoldingCorrection, error) {
+	rows, err := s.queries.ListHoldingSellTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holding sell totals: %w", err)
+	}
+
+	thresholdCents := int64(math.Round(s.reconcileThreshold * 100))
+	corrections := []HoldingCorrection{}
+
+	for _, row := range rows {
+		drift, err := measureDrift(row.FaceValue, row.Amount, row.TotalSold, row.RemainingAmount)
+		if err != nil {
+			slog.WarnContext(ctx, "Reconcile skipping holding", "holding_id", row.ID, "error", err)
+			continue
+		}
+		if !drift.exceeds(thresholdCents) {
+			continue
+		}
+
+		correction, err := s.reconcileHolding(ctx, row.ID, thresholdCents)
+		if err != nil {
+			return corrections, err
+		}
+		if correction == nil {
+			continue
+		}
+		corrections = append(corrections, *correction)
+		slog.WarnContext(ctx, "Reconcile corrected holding remaining_amount", "holding_id", correction.HoldingID,
+			"previous", correction.Previous, "corrected", correction.Corrected, "drift", correction.Drift)
+	}
+
+	return corrections, nil
+}
+
+// reconcileHolding locks a holding, re-sums its sells, and corrects its remaining_amount if it still drifts
+// past the threshold. Returns nil if the holding is gone, no longer drifts, or can't be recomputed.
+func (s *TransactionService) reconcileHolding(ctx context.Context, holdingID int32, thresholdCents int64) (*HoldingCorrection, error) {
+	var correction *HoldingCorrection
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+
+		// Sells and maturities lock the holding too, so its sell total can't change until we commit
+		holding, err := qtx.GetHoldingByIDForUpdate(ctx, holdingID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("failed to lock holding %d: %w", holdingID, err)
+		}
+		totalSold, err := qtx.GetHoldingSellTotal(ctx, pgtype.Int4{Int32: holdingID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("failed to sum sells for holding %d: %w", holdingID, err)
+		}
+
+		drift, err := measureDrift(holding.FaceValue, holding.Amount, totalSold, holding.RemainingAmount)
+		if err != nil {
+			slog.WarnContext(ctx, "Reconcile skipping holding", "holding_id", holdingID, "error", err)
+			return nil
+		}
+		if !drift.exceeds(thresholdCents) {
+			return nil
+		}
+
+		corrected := pgtype.Numeric{}
+		if err := corrected.Scan(fmt.Sprintf("%d.%02d", drift.expectedCents/100, drift.expectedCents%100)); err != nil {
+			return fmt.Errorf("failed to create corrected amount for holding %d: %w", holdingID, err)
+		}
+		if _, err := qtx.UpdateHoldingRemainingAmount(ctx, database.UpdateHoldingRemainingAmountParams{
+			ID:              holdingID,
+			RemainingAmount: corrected,
+		}); err != nil {
+			return fmt.Errorf("failed to correct holding %d: %w", holdingID, err)
+		}
+
+		correction = &HoldingCorrection{
+			HoldingID: holdingID,
+			Previous:  drift.remaining,
+			Corrected: float64(drift.expectedCents) / 100,
+			Drift:     float64(drift.driftCents) / 100,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return correction, nil
+}
+
+// holdingDrift compares a holding's stored remaining amount with the one its sells imply
+type holdingDrift struct {
+	remaining     float64
+	expectedCents int64
+	driftCents    int64 // Stored minus expected
+}
+
+// exceeds reports whether the drift is nonzero and beyond thresholdCents
+func (d holdingDrift) exceeds(thresholdCents int64) bool {
+	return d.driftCents != 0 && abs64(d.driftCents) > thresholdCents
+}
+
+// measureDrift computes a holding's drift from its face value (or amount for legacy holdings
+// without one, which buys set to face value), its sell total, and its stored remaining amount
+func measureDrift(faceValue, amount, totalSold, remainingAmount pgtype.Numeric) (holdingDrift, error) {
+	if !faceValue.Valid {
+		faceValue = amount
+	}
+	face, err := utils.NumericToFloat(faceValue)
+	if err != nil {
+		return holdingDrift{}, errors.New("invalid face value")
+	}
+	sold, err := utils.NumericToFloat(totalSold)
+	if err != nil {
+		return holdingDrift{}, errors.New("invalid sell total")
+	}
+	remaining, err := utils.NumericToFloat(remainingAmount)
+	if err != nil {
+		return holdingDrift{}, errors.New("invalid remaining amount")
+	}
+
+	expectedCents, err := expectedRemainingCents(face, sold)
+	if err != nil {
+		return holdingDrift{}, err
+	}
+	return holdingDrift{
+		remaining:     remaining,
+		expectedCents: expectedCents,
+		driftCents:    int64(math.Round(remaining*100)) - expectedCents,
+	}, nil
+}
+
+// StartReconciliation runs ReconcileHoldings every interval in the background until ctx is cancelled
+func (s *TransactionService) StartReconciliation(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				corrections, err := s.ReconcileHoldings(ctx)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					slog.ErrorContext(ctx, "Reconcile run failed", "error", err)
+					continue
+				}
+				slog.InfoContext(ctx, "Reconcile run complete", "corrected", len(corrections))
+			}
+		}
+	}()
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
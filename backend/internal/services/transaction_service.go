@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -12,138 +12,393 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/metrics"
 	"modernfi-treasury-app/internal/utils"
 )
 
+const (
+	// Default cap on holdings with a remaining amount per user; merged same-day buys don't add to the count
+	defaultMaxActiveHoldings = 500
+
+	// Default smallest amount (in cents) FundAccount accepts, to keep dust out of transaction history
+	defaultMinFundAmountCents = 100
+)
+
 type TransactionService struct {
-	queries *database.Queries
-	pool    *pgxpool.Pool
+	queries            *database.Queries
+	pool               *pgxpool.Pool
+	reconcileThreshold float64 // Drift (in dollars) tolerated before reconciliation corrects a holding; zero corrects any whole-cent difference
+	maxActiveHoldings  int64
+	minFundAmount      utils.Money
+	maxTxAmount        utils.Money // Zero disables the per-transaction cap on funds and withdrawals
+	minAccountBalance  utils.Money // Balance a withdrawal must leave behind
+	parPricing         bool
+	backtestMode       bool // Allows backdated buys, and selling or maturing the holdings they create
+	billAccrual        utils.BillAccrual
+	billDayCount       utils.DayCountConvention
+	noteInterest       utils.NoteInterest
+	metrics            *metrics.Metrics
+	webhook            *WebhookNotifier // Nil disables large transaction notifications
 }
 
 func NewTransactionService(queries *database.Queries, pool *pgxpool.Pool) *TransactionService {
 	return &TransactionService{
-		queries: queries,
-		pool:    pool,
+		queries:           queries,
+		pool:              pool,
+		maxActiveHoldings: defaultMaxActiveHoldings,
+		minFundAmount:     utils.MoneyFromCents(defaultMinFundAmountCents),
+		billAccrual:       utils.BillAccrualLinear,
+		billDayCount:      utils.DayCountThirty360,
+		noteInterest:      utils.NoteInterestSimple,
+		metrics:           metrics.New(),
 	}
 }
 
-// FundAccount adds funds to user account atomically
-func (s *TransactionService) FundAccount(ctx context.Context, userID int32, amount pgtype.Numeric) (*database.User, error) {
-	// Validate amount > 0
-	amountFloat, err := amount.Float64Value()
+// SetMaxActiveHoldings sets the number of active holdings a user may have before new buys are rejected
+func (s *TransactionService) SetMaxActiveHoldings(max int) error {
+	if max <= 0 {
+		return fmt.Errorf("max active holdings must be positive, got: %d", max)
+	}
+	s.maxActiveHoldings = int64(max)
+	return nil
+}
+
+// SetMinFundAmount sets the smallest amount (in dollars) a fund may add; zero accepts any positive amount
+func (s *TransactionService) SetMinFundAmount(min float64) error {
+	minAmount, err := utils.MoneyFromFloat(min)
+	if err != nil || minAmount < 0 {
+		return fmt.Errorf("minimum fund amount must be non-negative, got: %f", min)
+	}
+	s.minFundAmount = minAmount
+	return nil
+}
+
+// SetMaxTransactionAmount sets the largest amount (in dollars) a single fund or withdrawal may move; zero disables the cap
+func (s *TransactionService) SetMaxTransactionAmount(max float64) error {
+	maxAmount, err := utils.MoneyFromFloat(max)
+	if err != nil || maxAmount < 0 {
+		return fmt.Errorf("maximum transaction amount must be non-negative, got: %f", max)
+	}
+	s.maxTxAmount = maxAmount
+	return nil
+}
+
+// SetMinAccountBalance sets the balance (in dollars) a withdrawal must leave in the account; zero allows draining it
+func (s *TransactionService) SetMinAccountBalance(min float64) error {
+	minBalance, err := utils.MoneyFromFloat(min)
+	if err != nil || minBalance < 0 {
+		return fmt.Errorf("minimum account balance must be non-negative, got: %f", min)
+	}
+	s.minAccountBalance = minBalance
+	return nil
+}
+
+// checkTransactionCap rejects a fund or withdrawal of more than the per-transaction cap
+func (s *TransactionService) checkTransactionCap(amount utils.Money) error {
+	if s.maxTxAmount.IsPositive() && amount > s.maxTxAmount {
+		return fmt.Errorf("amount must not exceed %s per transaction", s.maxTxAmount)
+	}
+	return nil
+}
+
+// checkWithdrawal rejects withdrawing amount from balance if it overdraws or leaves less than the minimum balance
+func (s *TransactionService) checkWithdrawal(balance, amount utils.Money) error {
+	if balance < amount {
+		return ErrInsufficientBalance
+	}
+	if balance-amount < s.minAccountBalance {
+		return fmt.Errorf("withdrawal would leave %s, below the minimum balance of %s", balance-amount, s.minAccountBalance)
+	}
+	return nil
+}
+
+// SetParPricing switches buys between market pricing and par pricing.
+// When enabled, every buy is charged its face value: bills skip discount pricing and have no discount.
+func (s *TransactionService) SetParPricing(enabled bool) {
+	s.parPricing = enabled
+}
+
+// ParPricing reports whether buys are charged face value regardless of term
+func (s *TransactionService) ParPricing() bool {
+	return s.parPricing
+}
+
+// SetBacktestMode allows or refuses backdated buys. A backdated holding matures early by design, so outside
+// backtest mode it also refuses to sell, mature, or roll over holdings that were bought backdated.
+func (s *TransactionService) SetBacktestMode(enabled bool) {
+	s.backtestMode = enabled
+}
+
+// BacktestMode reports whether backdated buys are allowed
+func (s *TransactionService) BacktestMode() bool {
+	return s.backtestMode
+}
+
+// checkBackdated refuses to act on a backdated holding outside backtest mode, where redeeming it
+// would pay out interest or discount that was never earned
+func (s *TransactionService) checkBackdated(holding database.Holding) error {
+	if holding.Backdated && !s.backtestMode {
+		return fmt.Errorf("%w: holding %d was bought backdated", ErrBacktestDisabled, holding.ID)
+	}
+	return nil
+}
+
+// BillDayCount reports the day count convention bills are priced with
+func (s *TransactionService) BillDayCount() utils.DayCountConvention {
+	return s.billDayCount
+}
+
+// SetBillAccrual sets how a bill sold before maturity earns its discount (linear or compound)
+func (s *TransactionService) SetBillAccrual(model string) error {
+	accrual, err := utils.ParseBillAccrual(model)
 	if err != nil {
-		return nil, fmt.Errorf("invalid amount format: %w", err)
+		return err
 	}
-	if !amountFloat.Valid || amountFloat.Float64 <= 0 {
-		return nil, errors.New("amount must be greater than zero")
+	s.billAccrual = accrual
+	return nil
+}
+
+// SetBillDayCount sets the day count convention bills are priced with (30/360, actual/360, or actual/actual)
+func (s *TransactionService) SetBillDayCount(convention string) error {
+	dayCount, err := utils.ParseDayCountConvention(convention)
+	if err != nil {
+		return err
+	}
+	s.billDayCount = dayCount
+	return nil
+}
+
+// SetNoteInterest sets how a note or bond sold before maturity earns interest (simple or compound)
+func (s *TransactionService) SetNoteInterest(model string) error {
+	interest, err := utils.ParseNoteInterest(model)
+	if err != nil {
+		return err
+	}
+	s.noteInterest = interest
+	return nil
+}
+
+// SetMetrics sets the counters completed transactions are recorded on
+func (s *TransactionService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// countTransaction records a completed transaction of txType
+func (s *TransactionService) countTransaction(txType database.TransactionType) {
+	s.metrics.Transactions.Inc(string(txType))
+}
+
+// FundAccount adds funds to user account atomically.
+// A non-empty idempotencyKey replayed within 24 hours returns the user's current state without funding again.
+func (s *TransactionService) FundAccount(ctx context.Context, userID int32, amount utils.Money, idempotencyKey string) (*database.User, error) {
+	if err := s.CheckFundAmount(amount); err != nil {
+		return nil, err
 	}
 
 	var updatedUser *database.User
+	var applied bool // False when an idempotent replay returns without applying
 
 	// Use database transaction for atomicity
-	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
 		qtx := s.queries.WithTx(tx)
 
-		// Update user balance
-		user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
-			Balance: amount,
-			ID:      userID,
-		})
+		// Verify the user exists before the key claim, which would otherwise fail on its foreign key
+		if _, err := qtx.GetUserForUpdate(ctx, userID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		// Claim the idempotency key in the same transaction so concurrent retries can't both apply
+		apply, err := claimIdempotencyKey(ctx, qtx, userID, idempotencyKey, database.TransactionTypeFund, amount)
 		if err != nil {
-			return fmt.Errorf("failed to update balance: %w", err)
+			return err
+		}
+		if !apply {
+			slog.InfoContext(ctx, "Replayed fund request, not applied again", "user_id", userID, "amount", amount, "idempotency_key", idempotencyKey)
+			user, err := qtx.GetUser(ctx, userID)
+			if err != nil {
+				return fmt.Errorf("failed to get user: %w", err)
+			}
+			updatedUser = &user
+			return nil
 		}
 
-		// Create transaction record
-		_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
-			UserID:             userID,
-			Type:               database.TransactionTypeFund,
-			Term:               pgtype.Text{Valid: false},
-			Amount:             amount,
-			YieldAtTransaction: pgtype.Numeric{Valid: false},
-			BalanceAfter:       user.Balance,
-			HoldingID:          pgtype.Int4{Valid: false},
-		})
+		user, err := creditFund(ctx, qtx, userID, amount)
 		if err != nil {
-			return fmt.Errorf("failed to create transaction record: %w", err)
+			return err
 		}
 
 		updatedUser = &user
+		applied = true
 		return nil
 	})
 
+	if err == nil && applied {
+		s.countTransaction(database.TransactionTypeFund)
+		s.notifyLargeTransaction(database.TransactionTypeFund, userID, amount.Numeric(), "", 0)
+	}
 	return updatedUser, err
 }
 
-// WithdrawAccount withdraws funds from user account atomically
-func (s *TransactionService) WithdrawAccount(ctx context.Context, userID int32, amount pgtype.Numeric) (*database.User, error) {
-	// Validate amount > 0
-	amountFloat, err := amount.Float64Value()
+// CreateUser creates a user named name, funding any positive initialBalance in the same transaction.
+// The initial balance is checked and recorded like any other fund: it must meet the minimum fund
+// amount and the per-transaction cap, writes a fund ledger row, and is posted to the webhook if large.
+// A name already taken surfaces the unique violation from the insert.
+func (s *TransactionService) CreateUser(ctx context.Context, name string, initialBalance utils.Money) (*database.User, error) {
+	if initialBalance < 0 {
+		return nil, errors.New("balance must not be negative")
+	}
+	funded := initialBalance.IsPositive()
+	if funded {
+		if err := s.CheckFundAmount(initialBalance); err != nil {
+			return nil, err
+		}
+	}
+
+	var created *database.User
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+
+		user, err := qtx.CreateUser(ctx, database.CreateUserParams{
+			Name:    name,
+			Balance: utils.Money(0).Numeric(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		if funded {
+			user, err = creditFund(ctx, qtx, user.ID, initialBalance)
+			if err != nil {
+				return err
+			}
+		}
+
+		created = &user
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid amount format: %w", err)
+		return nil, err
 	}
-	if !amountFloat.Valid || amountFloat.Float64 <= 0 {
-		return nil, errors.New("amount must be greater than zero")
+
+	if funded {
+		s.countTransaction(database.TransactionTypeFund)
+		s.notifyLargeTransaction(database.TransactionTypeFund, created.ID, initialBalance.Numeric(), "", 0)
 	}
+	return created, nil
+}
 
-	// Get current user to check balance (quick pre-check for better UX)
-	user, err := s.queries.GetUser(ctx, userID)
+// CheckFundAmount rejects a fund amount that isn't positive, is below the minimum fund amount, or exceeds the cap
+func (s *TransactionService) CheckFundAmount(amount utils.Money) error {
+	if !amount.IsPositive() {
+		return errors.New("amount must be greater than zero")
+	}
+	if amount < s.minFundAmount {
+		return fmt.Errorf("amount must be at least %s to fund", s.minFundAmount)
+	}
+	return s.checkTransactionCap(amount)
+}
+
+// creditFund adds amount to the user's balance and records the fund transaction, within the caller's transaction
+func creditFund(ctx context.Context, qtx *database.Queries, userID int32, amount utils.Money) (database.User, error) {
+	user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
+		Balance: amount.Numeric(),
+		ID:      userID,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return database.User{}, fmt.Errorf("failed to update balance: %w", err)
 	}
 
-	// Validate sufficient balance
-	balanceFloat, err := user.Balance.Float64Value()
+	_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
+		UserID:             userID,
+		Type:               database.TransactionTypeFund,
+		Term:               pgtype.Text{Valid: false},
+		Amount:             amount.Numeric(),
+		YieldAtTransaction: pgtype.Numeric{Valid: false},
+		BalanceAfter:       user.Balance,
+		HoldingID:          pgtype.Int4{Valid: false},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid balance format: %w", err)
+		return database.User{}, fmt.Errorf("failed to create transaction record: %w", err)
 	}
-	if !balanceFloat.Valid {
-		return nil, errors.New("user balance is invalid")
+	return user, nil
+}
+
+// WithdrawAccount withdraws funds from user account atomically.
+// The withdrawal must leave at least the minimum account balance, checked against the row-locked balance.
+// A non-empty idempotencyKey replayed within 24 hours returns the user's current state without withdrawing again.
+func (s *TransactionService) WithdrawAccount(ctx context.Context, userID int32, amount utils.Money, idempotencyKey string) (*database.User, error) {
+	if !amount.IsPositive() {
+		return nil, errors.New("amount must be greater than zero")
 	}
-	if balanceFloat.Float64 < amountFloat.Float64 {
-		return nil, errors.New("insufficient balance")
+	if err := s.checkTransactionCap(amount); err != nil {
+		return nil, err
+	}
+
+	// Get current user to check balance (quick pre-check for better UX).
+	// Skipped for keyed requests: a replay of a withdrawal that emptied the balance must not fail here.
+	if idempotencyKey == "" {
+		user, err := s.queries.GetUser(ctx, userID)
+		if err != nil {
+			return nil, userLookupError(err)
+		}
+
+		// Validate sufficient balance
+		balance, err := utils.MoneyFromNumeric(user.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance format: %w", err)
+		}
+		if err := s.checkWithdrawal(balance, amount); err != nil {
+			return nil, err
+		}
 	}
 
 	var updatedUser *database.User
+	var applied bool // False when an idempotent replay returns without applying
 
 	// Use database transaction for atomicity
-	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
 		qtx := s.queries.WithTx(tx)
 
 		// Re-check balance inside transaction to prevent race conditions
-		// Use FOR UPDATE to lock the row until transaction completes
+		// Use FOR UPDATE to lock the row until transaction completes.
+		// The user is verified before the key claim, which would otherwise fail on its foreign key.
 		currentUser, err := qtx.GetUserForUpdate(ctx, userID)
 		if err != nil {
-			return fmt.Errorf("failed to get user in transaction: %w", err)
+			return userLookupError(err)
 		}
 
-		currentBalanceFloat, err := currentUser.Balance.Float64Value()
+		// Claim the idempotency key in the same transaction so concurrent retries can't both apply
+		apply, err := claimIdempotencyKey(ctx, qtx, userID, idempotencyKey, database.TransactionTypeWithdraw, amount)
 		if err != nil {
-			return fmt.Errorf("invalid current balance format: %w", err)
+			return err
 		}
-		if !currentBalanceFloat.Valid {
-			return errors.New("current user balance is invalid")
-		}
-		if currentBalanceFloat.Float64 < amountFloat.Float64 {
-			return errors.New("insufficient balance")
+		if !apply {
+			slog.InfoContext(ctx, "Replayed withdraw request, not applied again", "user_id", userID, "amount", amount, "idempotency_key", idempotencyKey)
+			updatedUser = &currentUser
+			return nil
 		}
 
-		// Create negative amount for withdrawal
-		negativeAmount := pgtype.Numeric{}
-		err = negativeAmount.Scan(fmt.Sprintf("-%.2f", amountFloat.Float64))
+		currentBalance, err := utils.MoneyFromNumeric(currentUser.Balance)
 		if err != nil {
-			return fmt.Errorf("failed to create negative amount: %w", err)
+			return fmt.Errorf("invalid current balance format: %w", err)
+		}
+		if err := s.checkWithdrawal(currentBalance, amount); err != nil {
+			return err
 		}
 
 		// Update user balance (negative amount to subtract)
 		user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
-			Balance: negativeAmount,
+			Balance: amount.Neg().Numeric(),
 			ID:      userID,
 		})
 		if err != nil {
 			// Check if error is due to balance constraint violation (SQLSTATE 23514)
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) && pgErr.Code == "23514" {
-				return errors.New("insufficient balance")
+				return ErrInsufficientBalance
 			}
 			return fmt.Errorf("failed to update balance: %w", err)
 		}
@@ -153,7 +408,7 @@ func (s *TransactionService) WithdrawAccount(ctx context.Context, userID int32,
 			UserID:             userID,
 			Type:               database.TransactionTypeWithdraw,
 			Term:               pgtype.Text{Valid: false},
-			Amount:             amount,
+			Amount:             amount.Numeric(),
 			YieldAtTransaction: pgtype.Numeric{Valid: false},
 			BalanceAfter:       user.Balance,
 			HoldingID:          pgtype.Int4{Valid: false},
@@ -163,22 +418,195 @@ func (s *TransactionService) WithdrawAccount(ctx context.Context, userID int32,
 		}
 
 		updatedUser = &user
+		applied = true
 		return nil
 	})
 
+	if err == nil && applied {
+		s.countTransaction(database.TransactionTypeWithdraw)
+		s.notifyLargeTransaction(database.TransactionTypeWithdraw, userID, amount.Numeric(), "", 0)
+	}
 	return updatedUser, err
 }
 
+// BuyResult describes a completed buy as it was recorded
+type BuyResult struct {
+	User          *database.User
+	Holding       database.Holding // New holding, or the same-day holding that was topped up
+	FaceValue     pgtype.Numeric   // Face value bought by this order
+	PurchasePrice pgtype.Numeric   // Amount deducted from the balance
+}
+
 // BuyTreasury purchases a treasury security for a user atomically
-// For T-Bills (1M, 3M, 6M, 1Y): faceValue is the amount at maturity, purchasePrice is calculated using discount pricing
+// For T-Bills (1M, 2M, 3M, 4M, 6M, 1Y): faceValue is the amount at maturity, purchasePrice is calculated using discount pricing
 // For Notes/Bonds (2Y, 5Y, 10Y, 30Y): uses par pricing (purchase price = face value)
+// With par pricing enabled (SetParPricing), bills are also bought at face value.
+// When mergeIfSameDay is set, a holding bought today with the same term and yield is topped up instead of creating a new row.
 func (s *TransactionService) BuyTreasury(
 	ctx context.Context,
 	userID int32,
 	term string,
 	faceValue pgtype.Numeric,
 	currentYield pgtype.Numeric,
-) (*database.User, error) {
+	mergeIfSameDay bool,
+) (*BuyResult, error) {
+	order, err := s.priceBuy(userID, term, faceValue, currentYield, mergeIfSameDay, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return s.executeBuy(ctx, order)
+}
+
+// QuoteBuy returns the purchase price BuyTreasury would charge for faceValue of term at currentYield,
+// applying the same validation and pricing mode, without touching the database or any balance
+func (s *TransactionService) QuoteBuy(term string, faceValue pgtype.Numeric, currentYield pgtype.Numeric) (pgtype.Numeric, error) {
+	order, err := s.priceBuy(0, term, faceValue, currentYield, false, time.Time{})
+	if err != nil {
+		return pgtype.Numeric{}, err
+	}
+	return order.purchasePrice, nil
+}
+
+// BuyTreasuryAsOf is BuyTreasury backdated to purchaseDate, for backtesting and seeding demo portfolios.
+// currentYield should be the curve rate in effect on purchaseDate. The holding's purchase date and the
+// same-day merge window use purchaseDate; the balance is debited and the buy transaction recorded now.
+// Only allowed in backtest mode; the holding is marked backdated and only merges with other backdated holdings.
+func (s *TransactionService) BuyTreasuryAsOf(
+	ctx context.Context,
+	userID int32,
+	term string,
+	faceValue pgtype.Numeric,
+	currentYield pgtype.Numeric,
+	mergeIfSameDay bool,
+	purchaseDate time.Time,
+) (*BuyResult, error) {
+	if !s.backtestMode {
+		return nil, fmt.Errorf("%w: set BACKTEST_MODE=true to buy with as_of", ErrBacktestDisabled)
+	}
+	if purchaseDate.IsZero() {
+		return nil, errors.New("purchase date is required")
+	}
+	if purchaseDate.After(time.Now()) {
+		return nil, errors.New("purchase date must not be in the future")
+	}
+
+	order, err := s.priceBuy(userID, term, faceValue, currentYield, mergeIfSameDay, purchaseDate)
+	if err != nil {
+		return nil, err
+	}
+	return s.executeBuy(ctx, order)
+}
+
+// executeBuy checks the user's balance for a priced order and records it in a database transaction
+func (s *TransactionService) executeBuy(ctx context.Context, order *buyOrder) (*BuyResult, error) {
+	userID := order.userID
+
+	// Get current user to check balance
+	user, err := s.queries.GetUser(ctx, userID)
+	if err != nil {
+		return nil, userLookupError(err)
+	}
+
+	// Validate sufficient balance for purchase price (NOT face value!)
+	balance, err := utils.MoneyFromNumeric(user.Balance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance format: %w", err)
+	}
+	if balance < order.purchasePriceMoney {
+		return nil, fmt.Errorf("%w: need %s for %s (face value: %s)", ErrInsufficientBalance,
+			order.purchasePriceMoney, utils.SecurityTypeLabel(order.securityType), order.faceValueMoney)
+	}
+
+	var result *BuyResult
+
+	// Use database transaction for atomicity
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		result, err = s.recordBuy(ctx, s.queries.WithTx(tx), order)
+		return err
+	})
+
+	if err == nil {
+		s.countTransaction(database.TransactionTypeBuy)
+		s.notifyLargeTransaction(database.TransactionTypeBuy, userID, result.FaceValue, order.term, result.Holding.ID)
+	}
+	return result, err
+}
+
+// BuyLeg is one order in a batch buy
+type BuyLeg struct {
+	Term         string
+	FaceValue    pgtype.Numeric
+	CurrentYield pgtype.Numeric
+}
+
+// BuyTreasuryBatch buys every leg for a user in one database transaction: either all legs are bought or none are.
+// Legs are never merged into existing holdings or into each other, so two legs with the same term create two holdings.
+// Results are returned in leg order.
+func (s *TransactionService) BuyTreasuryBatch(ctx context.Context, userID int32, legs []BuyLeg) ([]*BuyResult, error) {
+	if len(legs) == 0 {
+		return nil, errors.New("at least one leg is required")
+	}
+
+	orders := make([]*buyOrder, len(legs))
+	for i, leg := range legs {
+		order, err := s.priceBuy(userID, leg.Term, leg.FaceValue, leg.CurrentYield, false, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+		orders[i] = order
+	}
+
+	var results []*BuyResult
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+		results = make([]*BuyResult, 0, len(orders))
+		for i, order := range orders {
+			result, err := s.recordBuy(ctx, qtx, order)
+			if err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		s.countTransaction(database.TransactionTypeBuy)
+		s.notifyLargeTransaction(database.TransactionTypeBuy, userID, result.FaceValue, orders[i].term, result.Holding.ID)
+	}
+	return results, nil
+}
+
+// buyOrder is a validated, priced buy ready to be recorded.
+// Amounts are held as exact cents; only the yield rate is a float, for the pricing formulas.
+type buyOrder struct {
+	userID             int32
+	term               string
+	securityType       string
+	faceValue          pgtype.Numeric
+	faceValueMoney     utils.Money
+	currentYield       pgtype.Numeric
+	yieldRateFloat     float64
+	purchasePrice      pgtype.Numeric
+	purchasePriceMoney utils.Money
+	mergeIfSameDay     bool
+	purchaseDate       time.Time // Zero means the holding is bought now
+}
+
+// priceBuy validates the term, face value, and yield of a buy and calculates its purchase price.
+// purchaseDate is when the holding is bought, which the actual day counts price bills from; zero means now.
+func (s *TransactionService) priceBuy(
+	userID int32,
+	term string,
+	faceValue pgtype.Numeric,
+	currentYield pgtype.Numeric,
+	mergeIfSameDay bool,
+	purchaseDate time.Time,
+) (*buyOrder, error) {
 	// Determine security type (bill, note, or bond)
 	securityType, err := utils.GetSecurityType(term)
 	if err != nil {
@@ -186,205 +614,272 @@ func (s *TransactionService) BuyTreasury(
 	}
 
 	// Validate face value > 0
-	faceValueFloat, err := faceValue.Float64Value()
+	faceValueMoney, err := utils.MoneyFromNumeric(faceValue)
 	if err != nil {
 		return nil, fmt.Errorf("invalid face value format: %w", err)
 	}
-	if !faceValueFloat.Valid || faceValueFloat.Float64 <= 0 {
+	if !faceValueMoney.IsPositive() {
 		return nil, errors.New("face value must be greater than zero")
 	}
 
 	// Extract yield rate for pricing calculation
-	yieldRateFloat, err := currentYield.Float64Value()
+	if !currentYield.Valid {
+		return nil, errors.New("yield rate is required")
+	}
+	yieldRateFloat, err := utils.NumericToFloat(currentYield)
 	if err != nil {
 		return nil, fmt.Errorf("invalid yield rate format: %w", err)
 	}
-	if !yieldRateFloat.Valid {
-		return nil, errors.New("yield rate is required")
-	}
 	// Edge case validation: yield rate must be non-negative
-	if yieldRateFloat.Float64 < 0 {
+	if yieldRateFloat < 0 {
 		return nil, errors.New("yield rate must be greater than or equal to zero")
 	}
 
 	// Calculate purchase price based on security type
-	var purchasePriceFloat float64
+	var purchasePriceMoney utils.Money
 
-	if securityType == utils.SecurityTypeBill {
-		// Treasury Bills: Use discount pricing
-		// price = faceValue × (1 - (yield × days) / 360)
-		purchasePriceFloat, err = utils.CalculateBillPrice(faceValueFloat.Float64, yieldRateFloat.Float64, term)
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate bill price: %w", err)
-		}
+	if s.parPricing {
+		// Par pricing mode: every term, bills included, is charged its face value
+		purchasePriceMoney = faceValueMoney
 	} else {
-		// Treasury Notes/Bonds: Use par pricing
-		purchasePriceFloat, err = utils.CalculateNoteBondPrice(faceValueFloat.Float64, yieldRateFloat.Float64, term)
+		var purchasePriceFloat float64
+		if securityType == utils.SecurityTypeBill {
+			// Treasury Bills: Use discount pricing under the configured day count
+			// price = faceValue × (1 - yield × yearFraction)
+			pricingDate := purchaseDate
+			if pricingDate.IsZero() {
+				pricingDate = time.Now()
+			}
+			purchasePriceFloat, err = utils.CalculateBillPriceDayCount(faceValueMoney.Float64(), yieldRateFloat, term, pricingDate, s.billDayCount)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate bill price: %w", err)
+			}
+		} else {
+			// Treasury Notes/Bonds: Use par pricing
+			purchasePriceFloat, err = utils.CalculateNoteBondPrice(faceValueMoney.Float64(), yieldRateFloat, term)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate note/bond price: %w", err)
+			}
+		}
+
+		// Round the formula's result to cents once; everything after this is exact
+		purchasePriceMoney, err = utils.MoneyFromFloat(purchasePriceFloat)
 		if err != nil {
-			return nil, fmt.Errorf("failed to calculate note/bond price: %w", err)
+			return nil, fmt.Errorf("failed to create purchase price: %w", err)
 		}
 	}
 
-	// Convert purchase price to pgtype.Numeric
-	purchasePrice := pgtype.Numeric{}
-	err = purchasePrice.Scan(fmt.Sprintf("%.2f", purchasePriceFloat))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create purchase price: %w", err)
-	}
+	return &buyOrder{
+		userID:             userID,
+		term:               term,
+		securityType:       securityType,
+		faceValue:          faceValue,
+		faceValueMoney:     faceValueMoney,
+		currentYield:       currentYield,
+		yieldRateFloat:     yieldRateFloat,
+		purchasePrice:      purchasePriceMoney.Numeric(),
+		purchasePriceMoney: purchasePriceMoney,
+		mergeIfSameDay:     mergeIfSameDay,
+		purchaseDate:       purchaseDate,
+	}, nil
+}
 
-	// Get current user to check balance
-	user, err := s.queries.GetUser(ctx, userID)
+// recordBuy creates or tops up the holding, deducts the purchase price, and records the buy transaction.
+// It must run inside a database transaction; the user row is locked while the balance is checked.
+func (s *TransactionService) recordBuy(ctx context.Context, qtx *database.Queries, order *buyOrder) (*BuyResult, error) {
+	// Re-check balance inside transaction to prevent race conditions
+	// Use FOR UPDATE to lock the row until transaction completes
+	currentUser, err := qtx.GetUserForUpdate(ctx, order.userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to get user in transaction: %w", err)
 	}
 
-	// Validate sufficient balance for purchase price (NOT face value!)
-	balanceFloat, err := user.Balance.Float64Value()
+	currentBalance, err := utils.MoneyFromNumeric(currentUser.Balance)
 	if err != nil {
-		return nil, fmt.Errorf("invalid balance format: %w", err)
+		return nil, fmt.Errorf("invalid current balance format: %w", err)
 	}
-	if !balanceFloat.Valid {
-		return nil, errors.New("user balance is invalid")
-	}
-	if balanceFloat.Float64 < purchasePriceFloat {
-		// Create friendly security type name for error message
-		securityTypeName := "Treasury Bill"
-		if securityType == utils.SecurityTypeNote {
-			securityTypeName = "Treasury Note"
-		} else if securityType == utils.SecurityTypeBond {
-			securityTypeName = "Treasury Bond"
-		}
-		return nil, fmt.Errorf("insufficient balance: need %.2f for %s (face value: %.2f)",
-			purchasePriceFloat, securityTypeName, faceValueFloat.Float64)
+	// Check against purchase price (NOT face value!)
+	if currentBalance < order.purchasePriceMoney {
+		return nil, ErrInsufficientBalance
 	}
 
-	var updatedUser *database.User
-
-	// Use database transaction for atomicity
-	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
-		qtx := s.queries.WithTx(tx)
+	purchaseDate := order.purchaseDate
+	if purchaseDate.IsZero() {
+		purchaseDate = time.Now()
+	}
+	var holding database.Holding
+	merged := false
 
-		// Re-check balance inside transaction to prevent race conditions
-		// Use FOR UPDATE to lock the row until transaction completes
-		currentUser, err := qtx.GetUserForUpdate(ctx, userID)
-		if err != nil {
-			return fmt.Errorf("failed to get user in transaction: %w", err)
+	// Top up the purchase day's matching holding if requested (row is locked FOR UPDATE)
+	if order.mergeIfSameDay {
+		dayStart := time.Date(purchaseDate.Year(), purchaseDate.Month(), purchaseDate.Day(), 0, 0, 0, 0, purchaseDate.Location())
+		existing, err := qtx.FindSameDayHolding(ctx, database.FindSameDayHoldingParams{
+			UserID:          order.userID,
+			Term:            order.term,
+			YieldAtPurchase: order.currentYield,
+			DayStart:        pgtype.Timestamp{Time: dayStart, Valid: true},
+			DayEnd:          pgtype.Timestamp{Time: dayStart.AddDate(0, 0, 1), Valid: true},
+			Backdated:       !order.purchaseDate.IsZero(),
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to look up same-day holding: %w", err)
 		}
+		if err == nil {
+			holding, err = qtx.AddToHolding(ctx, database.AddToHoldingParams{
+				FaceValueDelta:     order.faceValue,
+				PurchasePriceDelta: order.purchasePrice,
+				ID:                 existing.ID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge into holding %d: %w", existing.ID, err)
+			}
+			merged = true
+			slog.InfoContext(ctx, "Merged buy into same-day holding", "user_id", order.userID, "holding_id", holding.ID, "term", order.term, "face_value", order.faceValueMoney.Float64())
+		}
+	}
 
-		currentBalanceFloat, err := currentUser.Balance.Float64Value()
+	// Create holding record with security type, face_value, and purchase_price
+	// amount column is set to face_value for backward compatibility
+	if !merged {
+		// Counted under the user row lock, so concurrent buys can't both pass at the cap
+		activeHoldings, err := qtx.CountActiveHoldingsByUser(ctx, order.userID)
 		if err != nil {
-			return fmt.Errorf("invalid current balance format: %w", err)
+			return nil, fmt.Errorf("failed to count active holdings: %w", err)
 		}
-		if !currentBalanceFloat.Valid {
-			return errors.New("current user balance is invalid")
-		}
-		// Check against purchase price (NOT face value!)
-		if currentBalanceFloat.Float64 < purchasePriceFloat {
-			return errors.New("insufficient balance")
-		}
-
-		// Create holding record with security type, face_value, and purchase_price
-		// amount column is set to face_value for backward compatibility
-		holding, err := qtx.CreateHolding(ctx, database.CreateHoldingParams{
-			UserID:          userID,
-			Term:            term,
-			Amount:          faceValue, // Set to face value for backward compatibility
-			YieldAtPurchase: currentYield,
-			PurchaseDate:    pgtype.Timestamp{Time: time.Now(), Valid: true},
-			RemainingAmount: faceValue,                                      // Initially, remaining amount equals face value
-			FaceValue:       faceValue,                                      // Amount at maturity
-			PurchasePrice:   purchasePrice,                                  // Actual discounted price paid (or par for notes/bonds)
-			SecurityType:    pgtype.Text{String: securityType, Valid: true}, // bill, note, or bond
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create holding: %w", err)
+		if activeHoldings >= s.maxActiveHoldings {
+			return nil, fmt.Errorf("maximum of %d active holdings reached: consolidate by buying with merge_if_same_day, or sell existing holdings first",
+				s.maxActiveHoldings)
 		}
 
-		// Create negative purchase price for withdrawal (subtract from balance)
-		// Deduct purchase price, NOT face value!
-		negativePurchasePrice := pgtype.Numeric{}
-		err = negativePurchasePrice.Scan(fmt.Sprintf("-%.2f", purchasePriceFloat))
+		// Fix the maturity date at purchase so readers don't re-derive it from the term; bills mature
+		// at the end of the period they were discounted over
+		maturityDate, err := utils.MaturityDate(purchaseDate, order.term, s.billDayCount)
 		if err != nil {
-			return fmt.Errorf("failed to create negative purchase price: %w", err)
+			return nil, fmt.Errorf("failed to calculate maturity date: %w", err)
 		}
 
-		// Update user balance (deduct purchase price)
-		user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
-			Balance: negativePurchasePrice,
-			ID:      userID,
+		holding, err = qtx.CreateHolding(ctx, database.CreateHoldingParams{
+			UserID:          order.userID,
+			Term:            order.term,
+			Amount:          order.faceValue, // Set to face value for backward compatibility
+			YieldAtPurchase: order.currentYield,
+			PurchaseDate:    pgtype.Timestamp{Time: purchaseDate, Valid: true},
+			RemainingAmount: order.faceValue,                                      // Initially, remaining amount equals face value
+			FaceValue:       order.faceValue,                                      // Amount at maturity
+			PurchasePrice:   order.purchasePrice,                                  // Actual discounted price paid (or par for notes/bonds)
+			SecurityType:    pgtype.Text{String: order.securityType, Valid: true}, // bill, note, or bond
+			MaturityDate:    pgtype.Timestamp{Time: maturityDate, Valid: true},
+			Backdated:       !order.purchaseDate.IsZero(), // Bought with as_of
 		})
 		if err != nil {
-			// Check if error is due to balance constraint violation (SQLSTATE 23514)
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) && pgErr.Code == "23514" {
-				return errors.New("insufficient balance")
-			}
-			return fmt.Errorf("failed to update balance: %w", err)
+			return nil, fmt.Errorf("failed to create holding: %w", err)
 		}
+	}
 
-		// Create transaction record (amount stores purchase price for buy transactions)
-		_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
-			UserID:             userID,
-			Type:               database.TransactionTypeBuy,
-			Term:               pgtype.Text{String: term, Valid: true},
-			Amount:             purchasePrice, // Record the actual amount deducted (purchase price)
-			YieldAtTransaction: currentYield,
-			BalanceAfter:       user.Balance,
-			HoldingID:          pgtype.Int4{Int32: holding.ID, Valid: true},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create transaction record: %w", err)
+	// Update user balance (deduct purchase price, NOT face value!)
+	user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
+		Balance: order.purchasePriceMoney.Neg().Numeric(),
+		ID:      order.userID,
+	})
+	if err != nil {
+		// Check if error is due to balance constraint violation (SQLSTATE 23514)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+			return nil, ErrInsufficientBalance
 		}
+		return nil, fmt.Errorf("failed to update balance: %w", err)
+	}
 
-		updatedUser = &user
-		return nil
+	// Create transaction record (amount stores purchase price for buy transactions)
+	_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
+		UserID:             order.userID,
+		Type:               database.TransactionTypeBuy,
+		Term:               pgtype.Text{String: order.term, Valid: true},
+		Amount:             order.purchasePrice, // Record the actual amount deducted (purchase price)
+		YieldAtTransaction: order.currentYield,
+		BalanceAfter:       user.Balance,
+		HoldingID:          pgtype.Int4{Int32: holding.ID, Valid: true},
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %w", err)
+	}
 
-	return updatedUser, err
+	return &BuyResult{
+		User:          &user,
+		Holding:       holding,
+		FaceValue:     order.faceValue,
+		PurchasePrice: order.purchasePrice,
+	}, nil
+}
+
+// remainingAmountUpdateError maps a failed remaining_amount update to the error a sell reports.
+// The holdings_remaining_non_negative CHECK constraint (SQLSTATE 23514) is a backstop behind the
+// service's own check, so its violation reads as the same insufficient remaining amount error.
+func remainingAmountUpdateError(err error, requested utils.Money, available utils.Money) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == "holdings_remaining_non_negative" {
+		return fmt.Errorf("%w: requested %s, available %s", ErrInsufficientRemaining, requested, available)
+	}
+	return fmt.Errorf("failed to update holding remaining amount: %w", err)
+}
+
+// SellResult is the outcome of a sell
+type SellResult struct {
+	User           *database.User
+	Proceeds       pgtype.Numeric // Credited to the balance
+	InterestEarned pgtype.Numeric // Accrued interest for notes and bonds, discount earned for bills
 }
 
-// SellTreasury sells a treasury holding (full or partial) and returns proceeds to balance
+// SellTreasury sells a treasury holding (full or partial), credits the proceeds to the balance,
+// and returns them along with the interest portion
 func (s *TransactionService) SellTreasury(
 	ctx context.Context,
 	userID int32,
 	holdingID int32,
 	amount pgtype.Numeric,
-) (*database.User, error) {
+) (*SellResult, error) {
 	// Validate amount > 0
-	amountFloat, err := amount.Float64Value()
+	amountMoney, err := utils.MoneyFromNumeric(amount)
 	if err != nil {
 		return nil, fmt.Errorf("invalid amount format: %w", err)
 	}
-	if !amountFloat.Valid || amountFloat.Float64 <= 0 {
+	if !amountMoney.IsPositive() {
 		return nil, errors.New("amount must be greater than zero")
 	}
 
 	// Fetch holding to verify it exists and belongs to user
 	holding, err := s.queries.GetHoldingByID(ctx, holdingID)
 	if err != nil {
-		return nil, fmt.Errorf("holding not found: %w", err)
+		return nil, holdingLookupError(err)
 	}
 
 	// Verify holding belongs to user (security check)
 	if holding.UserID != userID {
-		return nil, errors.New("unauthorized: holding does not belong to user")
+		return nil, ErrUnauthorized
+	}
+	if err := s.checkBackdated(holding); err != nil {
+		return nil, err
 	}
 
 	// Validate amount <= remaining_amount
-	remainingFloat, err := holding.RemainingAmount.Float64Value()
+	remaining, err := holdingRemaining(holding)
 	if err != nil {
-		return nil, fmt.Errorf("invalid remaining amount format: %w", err)
+		return nil, err
 	}
-	if !remainingFloat.Valid {
-		return nil, errors.New("holding remaining amount is invalid")
+	if amountMoney > remaining {
+		return nil, fmt.Errorf("%w: requested %s, available %s", ErrInsufficientRemaining,
+			amountMoney, remaining)
 	}
-	if amountFloat.Float64 > remainingFloat.Float64 {
-		return nil, fmt.Errorf("insufficient remaining amount: requested %.2f, available %.2f",
-			amountFloat.Float64, remainingFloat.Float64)
+
+	// Canonicalize the stored term so pricing by term accepts any legacy casing
+	term, err := utils.NormalizeTerm(holding.Term)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine security type for holding %d (term: %s): %w", holdingID, holding.Term, err)
 	}
+	holding.Term = term
 
 	// Calculate proceeds based on security type
-	var totalProceeds float64
+	var totalProceeds, interestEarned utils.Money
 
 	// Determine security type from holding (with legacy fallback)
 	var securityType string
@@ -402,77 +897,85 @@ func (s *TransactionService) SellTreasury(
 		securityType = inferredType
 	}
 
-	if securityType == utils.SecurityTypeBill {
-		// Treasury Bills: Return face value
-		// The yield was already earned as the discount (face_value - purchase_price)
-		totalProceeds = amountFloat.Float64
-	} else {
-		// Treasury Notes/Bonds: Calculate maturity value with simple interest
-		// maturityValue = principal + (principal × yieldRate × daysHeld / 365)
+	// Calculate days held from purchase date to now
+	purchaseTime := holding.PurchaseDate.Time
+	currentTime := time.Now()
+	daysHeld := int(currentTime.Sub(purchaseTime).Hours() / 24)
 
-		// Calculate days held from purchase date to now
-		purchaseTime := holding.PurchaseDate.Time
-		currentTime := time.Now()
-		daysHeld := int(currentTime.Sub(purchaseTime).Hours() / 24)
+	// Edge case validation: ensure days held is non-negative (protects against clock issues)
+	if daysHeld < 0 {
+		return nil, errors.New("invalid holding: purchase date is in the future")
+	}
 
-		// Edge case validation: ensure days held is non-negative (protects against clock issues)
-		if daysHeld < 0 {
-			return nil, errors.New("invalid holding: purchase date is in the future")
+	if securityType == utils.SecurityTypeBill {
+		// Treasury Bills: Return the sold share of the price paid plus the discount earned so far,
+		// which reaches face value only at the stored maturity date
+		maturity, err := holdingMaturityDate(holding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine maturity date: %w", err)
+		}
+		termDays := utils.DaysUntil(maturity, purchaseTime)
+		totalProceeds, interestEarned, err = utils.BillSaleProceeds(holding.FaceValue, holding.PurchasePrice, termDays, amountMoney, daysHeld, s.billAccrual)
+		if err != nil {
+			return nil, err
 		}
+		slog.InfoContext(ctx, "Selling holding", "user_id", userID, "holding_id", holdingID, "security_type", securityType,
+			"amount", amountMoney.Float64(), "days_held", daysHeld, "proceeds", totalProceeds.Float64())
+	} else {
+		// Treasury Notes/Bonds: Calculate sale value with simple interest accrued actual/actual
+		// maturityValue = principal + Σ(principal × yieldRate × daysInYear / yearLength),
+		// or with semiannual coupons reinvested when note interest is compound
 
 		// Get yield rate from holding
-		yieldRateFloat, err := holding.YieldAtPurchase.Float64Value()
-		if err != nil || !yieldRateFloat.Valid {
+		yieldRateFloat, err := utils.NumericToFloat(holding.YieldAtPurchase)
+		if err != nil {
 			return nil, fmt.Errorf("invalid yield rate for note/bond holding: %w", err)
 		}
 		// Edge case validation: yield rate must be non-negative
-		if yieldRateFloat.Float64 < 0 {
+		if yieldRateFloat < 0 {
 			return nil, errors.New("invalid holding: yield rate must be greater than or equal to zero")
 		}
 
-		// Calculate maturity value using the helper function
-		// principal = amount being sold, yieldRate = yield at purchase, daysHeld = time held
-		maturityValue, err := utils.CalculateNoteBondMaturityValue(
-			amountFloat.Float64,
-			yieldRateFloat.Float64,
-			daysHeld,
-		)
+		totalProceeds, interestEarned, err = utils.NoteSaleProceeds(amountMoney, yieldRateFloat, purchaseTime, currentTime, s.noteInterest)
 		if err != nil {
-			return nil, fmt.Errorf("failed to calculate note/bond maturity value: %w", err)
+			return nil, err
 		}
-
-		totalProceeds = maturityValue
-		log.Printf("Selling %s holding %d: principal=%.2f, yield=%.2f%%, days_held=%d, maturity_value=%.2f",
-			securityType, holdingID, amountFloat.Float64, yieldRateFloat.Float64, daysHeld, maturityValue)
+		slog.InfoContext(ctx, "Selling holding", "user_id", userID, "holding_id", holdingID, "security_type", securityType,
+			"amount", amountMoney.Float64(), "yield", yieldRateFloat, "days_held", daysHeld, "interest", s.noteInterest,
+			"maturity_value", totalProceeds.Float64())
 	}
 
+	proceedsAmount := totalProceeds.Numeric()
+	interestAmount := interestEarned.Numeric()
+
 	var updatedUser *database.User
 
 	// Use database transaction for atomicity
 	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
 		qtx := s.queries.WithTx(tx)
 
-		// Update holding remaining_amount (subtract sold amount)
-		newRemainingAmount := remainingFloat.Float64 - amountFloat.Float64
-		newRemaining := pgtype.Numeric{}
-		err = newRemaining.Scan(fmt.Sprintf("%.2f", newRemainingAmount))
+		// Lock the holding and re-check its remaining amount: a concurrent sell may have committed
+		// since the check above, and both must not subtract from the same stale value
+		locked, err := qtx.GetHoldingByIDForUpdate(ctx, holdingID)
 		if err != nil {
-			return fmt.Errorf("failed to create new remaining amount: %w", err)
+			return holdingLookupError(err)
+		}
+		lockedRemaining, err := holdingRemaining(locked)
+		if err != nil {
+			return err
+		}
+		if amountMoney > lockedRemaining {
+			return fmt.Errorf("%w: requested %s, available %s", ErrInsufficientRemaining,
+				amountMoney, lockedRemaining)
 		}
 
+		// Update holding remaining_amount (subtract sold amount)
 		_, err = qtx.UpdateHoldingRemainingAmount(ctx, database.UpdateHoldingRemainingAmountParams{
 			ID:              holdingID,
-			RemainingAmount: newRemaining,
+			RemainingAmount: lockedRemaining.Sub(amountMoney).Numeric(),
 		})
 		if err != nil {
-			return fmt.Errorf("failed to update holding remaining amount: %w", err)
-		}
-
-		// Create proceeds amount
-		proceedsAmount := pgtype.Numeric{}
-		err = proceedsAmount.Scan(fmt.Sprintf("%.2f", totalProceeds))
-		if err != nil {
-			return fmt.Errorf("failed to create proceeds amount: %w", err)
+			return remainingAmountUpdateError(err, amountMoney, lockedRemaining)
 		}
 
 		// Add proceeds to user balance
@@ -501,6 +1004,263 @@ func (s *TransactionService) SellTreasury(
 		updatedUser = &user
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.countTransaction(database.TransactionTypeSell)
+	s.notifyLargeTransaction(database.TransactionTypeSell, userID, amount, holding.Term, holdingID)
+	return &SellResult{
+		User:           updatedUser,
+		Proceeds:       proceedsAmount,
+		InterestEarned: interestAmount,
+	}, nil
+}
+
+// MatureHolding redeems a holding's remaining amount at its scheduled maturity date.
+// Bills pay face value; notes and bonds pay principal plus simple interest over the full term.
+// Holdings that have not yet matured are rejected with the number of days remaining.
+func (s *TransactionService) MatureHolding(ctx context.Context, userID int32, holdingID int32) (*database.User, error) {
+	holding, totalProceeds, err := s.maturityProceeds(ctx, userID, holdingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedUser *database.User
+
+	// Use database transaction for atomicity
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		user, err := recordMaturity(ctx, s.queries.WithTx(tx), holding, totalProceeds)
+		if err != nil {
+			return err
+		}
+		updatedUser = &user
+		return nil
+	})
 
+	if err == nil {
+		s.countTransaction(database.TransactionTypeMature)
+	}
 	return updatedUser, err
 }
+
+// maturityProceeds loads a holding owned by userID, checks that it has matured, and returns
+// the proceeds its remaining amount redeems for
+func (s *TransactionService) maturityProceeds(ctx context.Context, userID int32, holdingID int32) (database.Holding, utils.Money, error) {
+	// Fetch holding to verify it exists and belongs to user
+	holding, err := s.queries.GetHoldingByID(ctx, holdingID)
+	if err != nil {
+		return holding, 0, holdingLookupError(err)
+	}
+
+	// Verify holding belongs to user (security check)
+	if holding.UserID != userID {
+		return holding, 0, ErrUnauthorized
+	}
+	if err := s.checkBackdated(holding); err != nil {
+		return holding, 0, err
+	}
+
+	remaining, err := holdingRemaining(holding)
+	if err != nil {
+		return holding, 0, err
+	}
+	if !remaining.IsPositive() {
+		return holding, 0, errors.New("holding has no remaining amount to redeem")
+	}
+
+	// Only redeem on or after the maturity date stored at purchase
+	maturity, err := holdingMaturityDate(holding)
+	if err != nil {
+		return holding, 0, fmt.Errorf("cannot determine maturity for holding %d: %w", holdingID, err)
+	}
+	if daysRemaining := utils.DaysUntil(maturity, time.Now()); daysRemaining > 0 {
+		return holding, 0, fmt.Errorf("%w: %d days remaining", ErrNotMatured, daysRemaining)
+	}
+
+	yieldRateFloat, err := utils.NumericToFloat(holding.YieldAtPurchase)
+	if err != nil {
+		return holding, 0, fmt.Errorf("invalid yield rate for holding: %w", err)
+	}
+
+	proceedsFloat, err := utils.CalculateMaturityProceeds(remaining.Float64(), yieldRateFloat, holding.Term)
+	if err != nil {
+		return holding, 0, fmt.Errorf("failed to calculate maturity proceeds: %w", err)
+	}
+	totalProceeds, err := utils.MoneyFromFloat(proceedsFloat)
+	if err != nil {
+		return holding, 0, fmt.Errorf("failed to create proceeds amount: %w", err)
+	}
+	slog.InfoContext(ctx, "Maturing holding", "user_id", userID, "holding_id", holdingID, "term", holding.Term,
+		"amount", remaining.Float64(), "yield", yieldRateFloat, "proceeds", totalProceeds.Float64())
+
+	return holding, totalProceeds, nil
+}
+
+// holdingMaturityDate returns the maturity date stored at purchase, computing it from the term for legacy holdings.
+// Legacy holdings predate BILL_DAY_COUNT, so their bills were priced and mature on 30/360.
+func holdingMaturityDate(holding database.Holding) (time.Time, error) {
+	if holding.MaturityDate.Valid {
+		return holding.MaturityDate.Time, nil
+	}
+	return utils.MaturityDate(holding.PurchaseDate.Time, holding.Term, utils.DayCountThirty360)
+}
+
+// holdingRemaining returns a holding's unsold face value. Legacy holdings with a null remaining_amount
+// count at their face value, or their amount if that is null too, as in the holdings views.
+func holdingRemaining(holding database.Holding) (utils.Money, error) {
+	remaining := holding.RemainingAmount
+	if !remaining.Valid {
+		remaining = holding.FaceValue
+	}
+	if !remaining.Valid {
+		remaining = holding.Amount
+	}
+	amount, err := utils.MoneyFromNumeric(remaining)
+	if err != nil {
+		return 0, fmt.Errorf("invalid remaining amount format: %w", err)
+	}
+	return amount, nil
+}
+
+// recordMaturity zeroes a holding's remaining amount, credits the proceeds, and records the mature transaction.
+// It must run inside a database transaction. totalProceeds were priced on holding's remaining amount, so the
+// holding is locked and the maturity is refused if a concurrent sell has changed that amount since it was read.
+func recordMaturity(ctx context.Context, qtx *database.Queries, holding database.Holding, totalProceeds utils.Money) (database.User, error) {
+	locked, err := qtx.GetHoldingByIDForUpdate(ctx, holding.ID)
+	if err != nil {
+		return database.User{}, holdingLookupError(err)
+	}
+	pricedRemaining, err := holdingRemaining(holding)
+	if err != nil {
+		return database.User{}, err
+	}
+	lockedRemaining, err := holdingRemaining(locked)
+	if err != nil {
+		return database.User{}, err
+	}
+	if lockedRemaining != pricedRemaining {
+		return database.User{}, fmt.Errorf("holding remaining amount changed during redemption: priced %s, now %s; retry the request",
+			pricedRemaining, lockedRemaining)
+	}
+
+	// Holding is fully redeemed
+	_, err = qtx.UpdateHoldingRemainingAmount(ctx, database.UpdateHoldingRemainingAmountParams{
+		ID:              holding.ID,
+		RemainingAmount: utils.MoneyFromCents(0).Numeric(),
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to update holding remaining amount: %w", err)
+	}
+
+	// Add proceeds to user balance
+	user, err := qtx.UpdateUserBalance(ctx, database.UpdateUserBalanceParams{
+		Balance: totalProceeds.Numeric(),
+		ID:      holding.UserID,
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	// Create transaction record (store principal redeemed, consistent with sell)
+	_, err = qtx.CreateTransaction(ctx, database.CreateTransactionParams{
+		UserID:             holding.UserID,
+		Type:               database.TransactionTypeMature,
+		Term:               pgtype.Text{String: holding.Term, Valid: true},
+		Amount:             pricedRemaining.Numeric(),
+		YieldAtTransaction: holding.YieldAtPurchase,
+		BalanceAfter:       user.Balance,
+		HoldingID:          pgtype.Int4{Int32: holding.ID, Valid: true},
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to create transaction record: %w", err)
+	}
+
+	return user, nil
+}
+
+// RolloverResult describes a matured holding reinvested into a new one
+type RolloverResult struct {
+	User      *database.User
+	Proceeds  pgtype.Numeric // Credited by maturing the old holding
+	Buy       *BuyResult     // Purchase of the new holding
+	Remainder pgtype.Numeric // Proceeds not reinvested, left in the balance
+}
+
+// RolloverHolding matures a holding and reinvests the proceeds in a new holding of term at currentYield,
+// all in one database transaction. The new face value equals the proceeds; if that would cost more than
+// the proceeds, the largest affordable face value is bought instead. Anything not reinvested stays in the balance.
+func (s *TransactionService) RolloverHolding(
+	ctx context.Context,
+	userID int32,
+	holdingID int32,
+	term string,
+	currentYield pgtype.Numeric,
+) (*RolloverResult, error) {
+	holding, totalProceeds, err := s.maturityProceeds(ctx, userID, holdingID)
+	if err != nil {
+		return nil, err
+	}
+
+	proceeds := totalProceeds.Numeric()
+
+	order, err := s.priceBuy(userID, term, proceeds, currentYield, false, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if order.purchasePriceMoney > totalProceeds {
+		faceValueFloat, err := utils.FaceValueForSpend(totalProceeds.Float64(), order.yieldRateFloat, term, time.Now(), s.billDayCount,
+			utils.SpendRoundingMaxAffordable, 0.01)
+		if err != nil {
+			return nil, fmt.Errorf("failed to size rollover purchase: %w", err)
+		}
+		if faceValueFloat <= 0 {
+			return nil, errors.New("maturity proceeds are too small to buy any face value")
+		}
+		faceValue, err := utils.FloatToNumeric(faceValueFloat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create face value: %w", err)
+		}
+		if order, err = s.priceBuy(userID, term, faceValue, currentYield, false, time.Time{}); err != nil {
+			return nil, err
+		}
+	}
+
+	remainder := totalProceeds.Sub(order.purchasePriceMoney).Numeric()
+
+	var result *RolloverResult
+
+	// Maturity and purchase commit or roll back together
+	err = pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		qtx := s.queries.WithTx(tx)
+
+		if _, err := recordMaturity(ctx, qtx, holding, totalProceeds); err != nil {
+			return err
+		}
+
+		buy, err := s.recordBuy(ctx, qtx, order)
+		if err != nil {
+			return err
+		}
+
+		result = &RolloverResult{
+			User:      buy.User,
+			Proceeds:  proceeds,
+			Buy:       buy,
+			Remainder: remainder,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Rolled over holding", "user_id", userID, "holding_id", holdingID, "new_holding_id", result.Buy.Holding.ID,
+		"term", term, "proceeds", totalProceeds.Float64(), "face_value", order.faceValueMoney.Float64(),
+		"purchase_price", order.purchasePriceMoney.Float64())
+
+	// A rollover records a maturity and a buy
+	s.countTransaction(database.TransactionTypeMature)
+	s.countTransaction(database.TransactionTypeBuy)
+	return result, nil
+}
@@ -1,52 +1,320 @@
 package services
 
 import (
-	"encoding/xml"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
+	"math"
+	"modernfi-treasury-app/internal/metrics"
 	"modernfi-treasury-app/internal/models"
+	"modernfi-treasury-app/internal/utils"
 	"net/http"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	treasuryURLTemplate  = "https://home.treasury.gov/resource-center/data-chart-center/interest-rates/pages/xml?data=daily_treasury_yield_curve&field_tdr_date_value=%d"
 	httpTimeout          = 10 * time.Second
-	httpTimeoutMultiYear = 30 * time.Second // Longer timeout for multi-year requests
-	cacheDuration        = 1 * time.Hour
-	iso8601DateLength    = 10 // Length of "YYYY-MM-DD"
+	httpTimeoutMultiYear = 30 * time.Second       // Longer timeout for multi-year requests
+	multiYearDeadline    = 20 * time.Second       // Overall deadline for a combined multi-year fetch
+	minYearCompletion    = 0.5                    // Fraction of years that must complete to serve partial results
+	slowFetchThreshold   = 3 * time.Second        // Upstream fetches slower than this are logged as warnings
+	fetchRetries         = 3                      // Retries after the first attempt on network errors and 5xx responses
+	fetchRetryBaseDelay  = 200 * time.Millisecond // Delay before the first retry, doubled for each one after
+	minPlausibleYield    = -2.0                   // Lowest rate (%) accepted from the feed
+	maxPlausibleYield    = 25.0                   // Highest rate (%) accepted from the feed
+	cacheDuration        = 1 * time.Hour          // Default lifetime of the latest-yields cache
+	isoDateLayout        = "2006-01-02"
+	firstYieldCurveDate  = "1990-01-02" // First trading day in the daily par yield curve feed
 )
 
+// ErrNoYieldsForDate is returned by GetYieldsForDate when the feed has no curve on or before the requested date
+var ErrNoYieldsForDate = errors.New("no yield data on or before date")
+
+// entryDateLayouts lists the date formats the treasury feed has been observed to use, most common first
+var entryDateLayouts = []string{
+	"2006-01-02T15:04:05",
+	isoDateLayout,
+	time.RFC3339,
+	"01/02/2006",
+}
+
 // historicalCacheEntry stores cached historical yield data with a timestamp
 type historicalCacheEntry struct {
 	data      *models.HistoricalYieldData
 	timestamp time.Time
 }
 
+// yearFeedEntry is one calendar year's feed as fetched from the yield sources
+type yearFeedEntry struct {
+	entries   []models.Entry
+	timestamp time.Time
+}
+
 // TreasuryService handles fetching and caching of treasury yield data
 type TreasuryService struct {
-	cacheData      *models.YieldData
-	cacheTimestamp time.Time
-	cacheDuration  time.Duration
-	mu             sync.RWMutex
-	httpClient     *http.Client
+	cacheData         *models.YieldData
+	lastGoodTimestamp time.Time // When cacheData was last fetched successfully
+	cacheDuration     time.Duration
+	historicalTTL     time.Duration // Lifetime of historical cache entries; zero keeps them until restart
+	historicalRefresh time.Duration // Interval of the background historical refresh; zero when not running
+	mu                sync.RWMutex
+	httpClient        *http.Client
+	yearsClient       *http.Client // Used by multi-year fetches, which allow a longer timeout
+	urlTemplate       string
+	sources           []YieldSource // Tried in order for each year; defaults to treasury.gov alone
+
+	multiYearDeadline   time.Duration
+	maxFailedYears      int // Years a multi-year fetch may lose to errors and still serve the rest
+	slowFetchThreshold  time.Duration
+	fetchRetries        int
+	fetchRetryBaseDelay time.Duration
+	minPlausibleYield   float64
+	maxPlausibleYield   float64
 
 	historicalCache map[string]*historicalCacheEntry
 	historicalMu    sync.RWMutex
+	yearFeeds       map[int]*yearFeedEntry // Raw feeds by year, shared by the historical and as-of-date paths
+	yearFeedsMu     sync.RWMutex
+	warming         atomic.Bool // Set while WarmCache goroutines are running
+
+	// Outcome of the most recent upstream fetch, for readiness checks
+	lastFetch   time.Time
+	lastFetchOK bool
+	statusMu    sync.RWMutex
+
+	metrics *metrics.Metrics
 }
 
-var historicalPeriods = []string{"1W", "1M", "3M", "6M", "1Y", "5Y", "10Y", "30Y"}
+// UpstreamStatus reports treasury.gov reachability as last observed by the service
+type UpstreamStatus struct {
+	LastFetch   *time.Time `json:"last_fetch"` // Null until the first fetch completes
+	LastFetchOK bool       `json:"last_fetch_ok"`
+	CacheWarm   bool       `json:"cache_warm"` // Latest or historical yields are cached
+}
+
+// Ready reports whether the most recent upstream fetch succeeded
+func (u UpstreamStatus) Ready() bool {
+	return u.LastFetch != nil && u.LastFetchOK
+}
+
+// HistoricalPeriods lists every period supported by GetHistoricalYields, shortest first
+var HistoricalPeriods = []string{"1W", "1M", "2M", "3M", "4M", "6M", "1Y", "5Y", "10Y", "30Y"}
+
+// HistoricalTerms lists every term included in each historical data point, shortest first (the purchasable terms)
+var HistoricalTerms = utils.ValidTerms
+
+// Historical sampling resolutions: every trading day, the last trading day of each ISO week, or of each month
+const (
+	ResolutionDaily   = "daily"
+	ResolutionWeekly  = "weekly"
+	ResolutionMonthly = "monthly"
+)
+
+// HistoricalResolutions lists every resolution accepted by GetHistoricalYieldsAtResolution, finest first
+var HistoricalResolutions = []string{ResolutionDaily, ResolutionWeekly, ResolutionMonthly}
+
+// DefaultHistoricalResolution returns the sampling used for a period when none is requested:
+// monthly for 30Y, weekly for 10Y and 5Y, and daily for everything shorter
+func DefaultHistoricalResolution(period string) string {
+	switch period {
+	case "30Y":
+		return ResolutionMonthly
+	case "10Y", "5Y":
+		return ResolutionWeekly
+	default:
+		return ResolutionDaily
+	}
+}
+
+// ValidateHistoricalResolution checks that resolution is known and allowed for period.
+// Daily data is capped at the 10Y period (about 2,500 points); 30Y must be sampled weekly or monthly.
+func ValidateHistoricalResolution(period, resolution string) error {
+	switch resolution {
+	case ResolutionDaily:
+		if period == "30Y" {
+			return fmt.Errorf("daily resolution is not available for the 30Y period: use weekly or monthly")
+		}
+		return nil
+	case ResolutionWeekly, ResolutionMonthly:
+		return nil
+	default:
+		return fmt.Errorf("invalid resolution %q: must be one of %s", resolution, strings.Join(HistoricalResolutions, ", "))
+	}
+}
+
+// historicalCacheKey keys the historical cache by period, suffixed with the resolution when it isn't the period's default
+func historicalCacheKey(period, resolution string) string {
+	if resolution == DefaultHistoricalResolution(period) {
+		return period
+	}
+	return period + "@" + resolution
+}
 
 func NewTreasuryService() *TreasuryService {
-	return &TreasuryService{
+	s := &TreasuryService{
 		cacheDuration: cacheDuration,
 		httpClient: &http.Client{
 			Timeout: httpTimeout,
 		},
-		historicalCache: make(map[string]*historicalCacheEntry),
+		yearsClient: &http.Client{
+			Timeout: httpTimeoutMultiYear,
+		},
+		urlTemplate:         treasuryURLTemplate,
+		multiYearDeadline:   multiYearDeadline,
+		slowFetchThreshold:  slowFetchThreshold,
+		fetchRetries:        fetchRetries,
+		fetchRetryBaseDelay: fetchRetryBaseDelay,
+		minPlausibleYield:   minPlausibleYield,
+		maxPlausibleYield:   maxPlausibleYield,
+		historicalCache:     make(map[string]*historicalCacheEntry),
+		yearFeeds:           make(map[int]*yearFeedEntry),
+		metrics:             metrics.New(),
+	}
+	s.sources = []YieldSource{s.TreasuryGovSource()}
+	return s
+}
+
+// SetPlausibleYieldBand sets the inclusive range of rates (%) accepted for every term of a curve
+func (s *TreasuryService) SetPlausibleYieldBand(min, max float64) error {
+	if min >= max {
+		return fmt.Errorf("plausible yield band minimum (%.2f) must be below maximum (%.2f)", min, max)
+	}
+	s.minPlausibleYield = min
+	s.maxPlausibleYield = max
+	return nil
+}
+
+// IsPlausibleYield reports whether a rate (%) falls inside the configured plausible band
+func (s *TreasuryService) IsPlausibleYield(rate float64) bool {
+	return rate >= s.minPlausibleYield && rate <= s.maxPlausibleYield
+}
+
+// SetHTTPClient replaces the client used for every upstream fetch, including multi-year fetches
+func (s *TreasuryService) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+	s.yearsClient = client
+}
+
+// SetCacheDuration sets how long the latest yields are served from cache before refetching
+func (s *TreasuryService) SetCacheDuration(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("cache duration must be positive, got: %v", d)
+	}
+	s.cacheDuration = d
+	return nil
+}
+
+// SetHistoricalCacheDuration sets how long a historical period is served from cache before refetching.
+// By default historical entries never expire.
+func (s *TreasuryService) SetHistoricalCacheDuration(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("historical cache duration must be positive, got: %v", d)
+	}
+	s.historicalMu.Lock()
+	defer s.historicalMu.Unlock()
+	s.historicalTTL = d
+	return nil
+}
+
+// HistoricalRefreshAt reports when the cached entry for period at resolution (empty for the default) is
+// next replaced, by expiring or by the background refresh, whichever comes first. It reports false
+// if the entry isn't cached or nothing will replace it before a restart.
+func (s *TreasuryService) HistoricalRefreshAt(period, resolution string) (time.Time, bool) {
+	if resolution == "" {
+		resolution = DefaultHistoricalResolution(period)
+	}
+
+	s.historicalMu.RLock()
+	defer s.historicalMu.RUnlock()
+	cached, exists := s.historicalCache[historicalCacheKey(period, resolution)]
+	if !exists {
+		return time.Time{}, false
+	}
+
+	lifetime := s.historicalTTL
+	if s.historicalRefresh > 0 && (lifetime == 0 || s.historicalRefresh < lifetime) {
+		lifetime = s.historicalRefresh
+	}
+	if lifetime == 0 {
+		return time.Time{}, false
+	}
+	return cached.timestamp.Add(lifetime), true
+}
+
+// historicalFresh reports whether a historical cache entry may still be served; callers hold historicalMu
+func (s *TreasuryService) historicalFresh(entry *historicalCacheEntry) bool {
+	return s.historicalTTL == 0 || time.Since(entry.timestamp) < s.historicalTTL
+}
+
+// SetMetrics sets the counters upstream fetches and cache lookups are recorded on
+func (s *TreasuryService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// SetMaxFailedYears sets how many years of a multi-year fetch may fail (after retries) while the
+// remaining years are still served. By default any failed year fails the whole fetch.
+func (s *TreasuryService) SetMaxFailedYears(n int) error {
+	if n < 0 {
+		return fmt.Errorf("max failed years must not be negative, got: %d", n)
+	}
+	s.maxFailedYears = n
+	return nil
+}
+
+// SetSlowFetchThreshold sets the duration above which upstream fetches are logged as slow
+func (s *TreasuryService) SetSlowFetchThreshold(threshold time.Duration) {
+	s.slowFetchThreshold = threshold
+}
+
+// recordFetch stores the outcome of an upstream fetch for UpstreamStatus
+func (s *TreasuryService) recordFetch(err error) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.lastFetch = time.Now()
+	s.lastFetchOK = err == nil
+
+	if err == nil {
+		s.metrics.UpstreamFetches.Inc(metrics.ResultSuccess)
+	} else {
+		s.metrics.UpstreamFetches.Inc(metrics.ResultFailure)
+	}
+}
+
+// UpstreamStatus returns the last upstream fetch time and outcome and whether any yields are cached.
+// It does not contact treasury.gov.
+func (s *TreasuryService) UpstreamStatus() UpstreamStatus {
+	var status UpstreamStatus
+
+	s.statusMu.RLock()
+	if !s.lastFetch.IsZero() {
+		lastFetch := s.lastFetch
+		status.LastFetch = &lastFetch
+	}
+	status.LastFetchOK = s.lastFetchOK
+	s.statusMu.RUnlock()
+
+	s.mu.RLock()
+	status.CacheWarm = s.cacheData != nil
+	s.mu.RUnlock()
+
+	s.historicalMu.RLock()
+	status.CacheWarm = status.CacheWarm || len(s.historicalCache) > 0
+	s.historicalMu.RUnlock()
+
+	return status
+}
+
+// logIfSlow logs a warning when an upstream fetch that began at start exceeded the slow-fetch threshold
+func (s *TreasuryService) logIfSlow(years string, start time.Time) {
+	if elapsed := time.Since(start); elapsed > s.slowFetchThreshold {
+		slog.Warn("Slow upstream treasury.gov fetch", "years", years, "elapsed_ms", elapsed.Milliseconds(), "threshold_ms", s.slowFetchThreshold.Milliseconds())
 	}
 }
 
@@ -59,8 +327,12 @@ func calculateDateRange(period string) (startDate, endDate time.Time, err error)
 		startDate = endDate.AddDate(0, 0, -7)
 	case "1M":
 		startDate = endDate.AddDate(0, -1, 0)
+	case "2M":
+		startDate = endDate.AddDate(0, -2, 0)
 	case "3M":
 		startDate = endDate.AddDate(0, -3, 0)
+	case "4M":
+		startDate = endDate.AddDate(0, -4, 0)
 	case "6M":
 		startDate = endDate.AddDate(0, -6, 0)
 	case "1Y":
@@ -72,46 +344,73 @@ func calculateDateRange(period string) (startDate, endDate time.Time, err error)
 	case "30Y":
 		startDate = endDate.AddDate(-30, 0, 0)
 	default:
-		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (must be 1W, 1M, 3M, 6M, 1Y, 5Y, 10Y, or 30Y)", period)
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (must be 1W, 1M, 2M, 3M, 4M, 6M, 1Y, 5Y, 10Y, or 30Y)", period)
 	}
 
 	return startDate, endDate, nil
 }
 
-func (s *TreasuryService) fetchFromAPI() (*models.TreasuryFeed, error) {
-	url := fmt.Sprintf(treasuryURLTemplate, time.Now().Year())
-	resp, err := s.httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch treasury data: %w", err)
-	}
-	defer resp.Body.Close()
+// fetchWithRetry issues a GET for url, retrying network errors and 5xx responses with
+// exponential backoff. 4xx responses are returned without retrying, as is the final
+// response once retries are exhausted. Cancelling ctx stops any further attempts.
+func (s *TreasuryService) fetchWithRetry(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	delay := s.fetchRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("treasury API returned status %d", resp.StatusCode)
-	}
+		resp, err := client.Do(req)
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= s.fetchRetries || ctx.Err() != nil {
+			return resp, err
+		}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		if err != nil {
+			slog.WarnContext(ctx, "treasury.gov fetch failed, retrying", "attempt", attempt+1, "attempts", s.fetchRetries+1, "retry_in_ms", delay.Milliseconds(), "error", err)
+		} else {
+			slog.WarnContext(ctx, "treasury.gov returned an error status, retrying", "status", resp.StatusCode, "attempt", attempt+1, "attempts", s.fetchRetries+1, "retry_in_ms", delay.Milliseconds())
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
 	}
+}
+
+// fetchFromAPI fetches the current year's feed from the first yield source that succeeds
+func (s *TreasuryService) fetchFromAPI() (*models.TreasuryFeed, error) {
+	year := time.Now().Year()
+	defer s.logIfSlow(fmt.Sprintf("year %d", year), time.Now())
 
-	var feed models.TreasuryFeed
-	if err := xml.Unmarshal(body, &feed); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	feed, err := s.fetchYear(context.Background(), year, false)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(feed.Entries) == 0 {
 		return nil, fmt.Errorf("no entries found in treasury feed")
 	}
 
-	return &feed, nil
+	sortEntriesByDate(feed.Entries)
+	return feed, nil
 }
 
-// fetchFromAPIForYears fetches and combines data from multiple years in parallel
+// fetchFromAPIForYears fetches and combines data from multiple years in parallel, each from the first
+// yield source that succeeds for it.
+// If the overall deadline expires, the years that completed in time are returned
+// as long as they meet the minimum completion threshold. Up to maxFailedYears years that
+// fail outright are logged and skipped. The combined entries are sorted by date, oldest first.
 func (s *TreasuryService) fetchFromAPIForYears(startYear, endYear int) (*models.TreasuryFeed, error) {
-	client := &http.Client{
-		Timeout: httpTimeoutMultiYear,
-	}
+	defer s.logIfSlow(fmt.Sprintf("years %d-%d", startYear, endYear), time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.multiYearDeadline)
+	defer cancel()
 
 	yearCount := endYear - startYear + 1
 
@@ -120,53 +419,59 @@ func (s *TreasuryService) fetchFromAPIForYears(startYear, endYear int) (*models.
 		entries []models.Entry
 		err     error
 	}
+	// Buffered so goroutines never block after the deadline stops collection
 	results := make(chan yearResult, yearCount)
 
 	for year := startYear; year <= endYear; year++ {
 		go func(y int) {
-			url := fmt.Sprintf(treasuryURLTemplate, y)
-			resp, err := client.Get(url)
+			feed, err := s.fetchYear(ctx, y, true)
 			if err != nil {
-				results <- yearResult{year: y, err: fmt.Errorf("failed to fetch treasury data for year %d: %w", y, err)}
+				results <- yearResult{year: y, err: err}
 				return
 			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				results <- yearResult{year: y, err: fmt.Errorf("treasury API returned status %d for year %d", resp.StatusCode, y)}
-				return
-			}
-
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				results <- yearResult{year: y, err: fmt.Errorf("failed to read response body for year %d: %w", y, err)}
-				return
-			}
-
-			var feed models.TreasuryFeed
-			if err := xml.Unmarshal(body, &feed); err != nil {
-				results <- yearResult{year: y, err: fmt.Errorf("failed to parse XML for year %d: %w", y, err)}
-				return
-			}
-
 			results <- yearResult{year: y, entries: feed.Entries, err: nil}
 		}(year)
 	}
 
 	yearData := make(map[int][]models.Entry)
-	var errors []error
+	var fetchErrs []error
+	var failedYears []int
+	timedOut := 0
 
+collect:
 	for i := 0; i < yearCount; i++ {
-		result := <-results
-		if result.err != nil {
-			errors = append(errors, result.err)
-		} else {
-			yearData[result.year] = result.entries
+		select {
+		case result := <-results:
+			if errors.Is(result.err, context.DeadlineExceeded) {
+				timedOut++
+			} else if result.err != nil {
+				fetchErrs = append(fetchErrs, result.err)
+				failedYears = append(failedYears, result.year)
+			} else {
+				yearData[result.year] = result.entries
+			}
+		case <-ctx.Done():
+			timedOut = yearCount - len(yearData) - len(fetchErrs)
+			break collect
 		}
 	}
 
-	if len(errors) > 0 {
-		return nil, errors[0]
+	if len(fetchErrs) > s.maxFailedYears {
+		return nil, fetchErrs[0]
+	}
+	if len(fetchErrs) > 0 {
+		sort.Ints(failedYears)
+		slog.Warn("Skipping failed years in multi-year fetch", "start_year", startYear, "end_year", endYear,
+			"failed_years", failedYears, "max_failed_years", s.maxFailedYears, "error", errors.Join(fetchErrs...))
+	}
+
+	if timedOut > 0 {
+		completion := float64(len(yearData)) / float64(yearCount)
+		if completion < minYearCompletion {
+			return nil, fmt.Errorf("multi-year fetch deadline exceeded: only %d of %d years completed", len(yearData), yearCount)
+		}
+		slog.Warn("Multi-year fetch deadline exceeded, returning partial data", "start_year", startYear, "end_year", endYear,
+			"years_completed", len(yearData), "years", yearCount)
 	}
 
 	var combinedFeed models.TreasuryFeed
@@ -180,54 +485,161 @@ func (s *TreasuryService) fetchFromAPIForYears(startYear, endYear int) (*models.
 		return nil, fmt.Errorf("no entries found in treasury feed for years %d-%d", startYear, endYear)
 	}
 
+	sortEntriesByDate(combinedFeed.Entries)
 	return &combinedFeed, nil
 }
 
-// convertToYieldData transforms the most recent XML entry into YieldData format
+// sortEntriesByDate orders feed entries oldest first, so conversions can take the last entry as the newest
+// without trusting upstream ordering. Entries with unparseable dates sort first, keeping their relative order.
+func sortEntriesByDate(entries []models.Entry) {
+	type datedEntry struct {
+		date  time.Time
+		entry models.Entry
+	}
+	dated := make([]datedEntry, len(entries))
+	for i, entry := range entries {
+		date, _ := parseEntryDate(entry.Date) // Zero time when unparseable; conversion skips these anyway
+		dated[i] = datedEntry{date: date, entry: entry}
+	}
+
+	slices.SortStableFunc(dated, func(a, b datedEntry) int {
+		return a.date.Compare(b.date)
+	})
+	for i := range dated {
+		entries[i] = dated[i].entry
+	}
+}
+
+// parseEntryDate parses a feed entry date by trying each known layout in turn
+func parseEntryDate(raw string) (time.Time, error) {
+	trimmed := strings.TrimSpace(raw)
+	for _, layout := range entryDateLayouts {
+		if date, err := time.Parse(layout, trimmed); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized entry date format: %q", raw)
+}
+
+// entryYieldPoints returns the term structure of a single feed entry, omitting terms with no published rate.
+// The omitted terms are returned separately, shortest first.
+func entryYieldPoints(entry models.Entry) (yields []models.YieldPoint, missing []string) {
+	rates := []struct {
+		term string
+		rate models.FeedRate
+	}{
+		{"1M", entry.BC1Month},
+		{"2M", entry.BC2Month},
+		{"3M", entry.BC3Month},
+		{"4M", entry.BC4Month},
+		{"6M", entry.BC6Month},
+		{"1Y", entry.BC1Year},
+		{"2Y", entry.BC2Year},
+		{"5Y", entry.BC5Year},
+		{"10Y", entry.BC10Year},
+		{"30Y", entry.BC30Year},
+	}
+
+	yields = make([]models.YieldPoint, 0, len(rates))
+	for _, r := range rates {
+		if !r.rate.Valid {
+			missing = append(missing, r.term)
+			continue
+		}
+		yields = append(yields, models.YieldPoint{Term: r.term, Rate: r.rate.Rate})
+	}
+	return yields, missing
+}
+
+// implausibleYield returns the first yield point outside the plausible band, if any
+func (s *TreasuryService) implausibleYield(yields []models.YieldPoint) (models.YieldPoint, bool) {
+	for _, point := range yields {
+		if !s.IsPlausibleYield(point.Rate) {
+			return point, true
+		}
+	}
+	return models.YieldPoint{}, false
+}
+
+// convertToYieldData transforms the most recent valid XML entry into YieldData format.
+// Entries with an unparseable date or any rate outside the plausible band are skipped
+// in favor of the next most recent entry, so a corrupt curve is never served.
 func (s *TreasuryService) convertToYieldData(feed *models.TreasuryFeed) (*models.YieldData, error) {
 	if len(feed.Entries) == 0 {
 		return nil, fmt.Errorf("no entries to convert")
 	}
 
-	entry := feed.Entries[len(feed.Entries)-1]
+	for i := len(feed.Entries) - 1; i >= 0; i-- {
+		entry := feed.Entries[i]
 
-	date := entry.Date
-	if len(date) > iso8601DateLength {
-		date = date[:iso8601DateLength]
-	}
+		entryDate, err := parseEntryDate(entry.Date)
+		if err != nil {
+			slog.Warn("Skipping treasury entry with unparseable date", "error", err)
+			continue
+		}
+
+		yields, missing := entryYieldPoints(entry)
+		if len(yields) == 0 {
+			slog.Warn("Skipping treasury entry with no published yields", "date", entryDate.Format(isoDateLayout))
+			continue
+		}
+		if point, bad := s.implausibleYield(yields); bad {
+			slog.Warn("Skipping treasury entry with rate outside plausible band", "date", entryDate.Format(isoDateLayout),
+				"term", point.Term, "yield", point.Rate, "min_yield", s.minPlausibleYield, "max_yield", s.maxPlausibleYield)
+			continue
+		}
+
+		// Unpublished terms are left out rather than served as 0%, so buys at those terms are refused
+		if len(missing) > 0 {
+			slog.Warn("Latest curve has no yield for some terms", "date", entryDate.Format(isoDateLayout), "terms", missing)
+		}
 
-	yields := []models.YieldPoint{
-		{Term: "1M", Rate: entry.BC1Month},
-		{Term: "3M", Rate: entry.BC3Month},
-		{Term: "6M", Rate: entry.BC6Month},
-		{Term: "1Y", Rate: entry.BC1Year},
-		{Term: "2Y", Rate: entry.BC2Year},
-		{Term: "5Y", Rate: entry.BC5Year},
-		{Term: "10Y", Rate: entry.BC10Year},
-		{Term: "30Y", Rate: entry.BC30Year},
+		data := &models.YieldData{
+			Date:   entryDate.Format(isoDateLayout),
+			Yields: yields,
+		}
+		if data.Spreads, err = calculateCurveSpreads(data); err != nil {
+			slog.Warn("Omitting curve spreads", "date", data.Date, "error", err)
+		}
+		return data, nil
 	}
 
-	return &models.YieldData{
-		Date:   date,
-		Yields: yields,
-	}, nil
+	return nil, fmt.Errorf("no valid entries to convert")
 }
 
-// sampleDataPoints reduces data density for long periods (30Y: monthly, 10Y/5Y: weekly)
-func sampleDataPoints(dataPoints []map[string]interface{}, period string) []map[string]interface{} {
-	if period == "1W" || period == "1M" || period == "3M" || period == "6M" || period == "1Y" {
-		return dataPoints
+// calculateCurveSpreads computes the 2s10s and 3m10y spreads of a curve, rounded to basis points
+func calculateCurveSpreads(data *models.YieldData) (*models.CurveSpreads, error) {
+	rates := make(map[string]float64, 3)
+	for _, term := range []string{"3M", "2Y", "10Y"} {
+		rate, found := data.RateForTerm(term)
+		if !found {
+			return nil, fmt.Errorf("curve has no %s rate", term)
+		}
+		rates[term] = rate
 	}
 
+	twoTen := math.Round((rates["10Y"]-rates["2Y"])*100) / 100
+	threeMonthTen := math.Round((rates["10Y"]-rates["3M"])*100) / 100
+	return &models.CurveSpreads{
+		TwoTen:                twoTen,
+		ThreeMonthTen:         threeMonthTen,
+		Inverted:              twoTen < 0,
+		InvertedThreeMonthTen: threeMonthTen < 0,
+	}, nil
+}
+
+// sampleDataPoints reduces data density to the given resolution, keeping the last point of each week or month.
+// Daily (or unknown) resolutions return every point.
+func sampleDataPoints(dataPoints []map[string]interface{}, resolution string) []map[string]interface{} {
 	if len(dataPoints) == 0 {
 		return dataPoints
 	}
 
 	var samplingInterval int
-	switch period {
-	case "30Y":
+	switch resolution {
+	case ResolutionMonthly:
 		samplingInterval = 30
-	case "10Y", "5Y":
+	case ResolutionWeekly:
 		samplingInterval = 7
 	default:
 		return dataPoints
@@ -241,7 +653,7 @@ func sampleDataPoints(dataPoints []map[string]interface{}, period string) []map[
 			continue
 		}
 
-		date, err := time.Parse("2006-01-02", dateStr)
+		date, err := time.Parse(isoDateLayout, dateStr)
 		if err != nil {
 			continue
 		}
@@ -278,55 +690,73 @@ func sampleDataPoints(dataPoints []map[string]interface{}, period string) []map[
 	return sampledPoints
 }
 
-// convertToHistoricalData builds time-series dataset from feed entries
+// convertToHistoricalData builds time-series dataset from feed entries, sampled at resolution
 func (s *TreasuryService) convertToHistoricalData(
 	feed *models.TreasuryFeed,
 	startDate, endDate time.Time,
-	period string,
+	period, resolution string,
 ) (*models.HistoricalYieldData, error) {
 	var dataPoints []map[string]interface{}
 
 	for _, entry := range feed.Entries {
-		dateStr := entry.Date
-		if len(dateStr) > iso8601DateLength {
-			dateStr = dateStr[:iso8601DateLength]
-		}
-
-		entryDate, err := time.Parse("2006-01-02", dateStr)
+		entryDate, err := parseEntryDate(entry.Date)
 		if err != nil {
+			slog.Warn("Skipping treasury entry with unparseable date", "error", err)
 			continue
 		}
+		dateStr := entryDate.Format(isoDateLayout)
 
 		if entryDate.Before(startDate) || entryDate.After(endDate) {
 			continue
 		}
 
-		point := map[string]interface{}{
-			"date": dateStr,
-			"10Y":  entry.BC10Year,
-			"5Y":   entry.BC5Year,
-			"2Y":   entry.BC2Year,
+		// Terms with no published rate are null rather than 0, leaving a gap in that term's line
+		point := map[string]interface{}{"date": dateStr}
+		yields, missing := entryYieldPoints(entry)
+		for _, yield := range yields {
+			point[yield.Term] = yield.Rate
+		}
+		for _, term := range missing {
+			point[term] = nil
 		}
 		dataPoints = append(dataPoints, point)
 	}
 
-	sampledPoints := sampleDataPoints(dataPoints, period)
+	sampledPoints := sampleDataPoints(dataPoints, resolution)
 
 	return &models.HistoricalYieldData{
-		Period:    period,
-		StartDate: startDate.Format("2006-01-02"),
-		EndDate:   endDate.Format("2006-01-02"),
-		Terms:     []string{"10Y", "5Y", "2Y"},
-		Data:      sampledPoints,
+		Period:     period,
+		Resolution: resolution,
+		StartDate:  startDate.Format(isoDateLayout),
+		EndDate:    endDate.Format(isoDateLayout),
+		Terms:      HistoricalTerms,
+		Data:       sampledPoints,
 	}, nil
 }
 
-// GetHistoricalYields fetches historical yield data with permanent caching
+// GetHistoricalYields fetches historical yield data at the period's default resolution with caching.
+// Entries are kept until restart unless SetHistoricalCacheDuration is used; an expired entry
+// is refetched, and served as-is if the refetch fails.
 func (s *TreasuryService) GetHistoricalYields(period string) (*models.HistoricalYieldData, error) {
+	return s.GetHistoricalYieldsAtResolution(period, "")
+}
+
+// GetHistoricalYieldsAtResolution is GetHistoricalYields sampled at resolution instead of the period's default.
+// An empty resolution means the default. Each period and resolution pair is cached separately.
+func (s *TreasuryService) GetHistoricalYieldsAtResolution(period, resolution string) (*models.HistoricalYieldData, error) {
+	if resolution == "" {
+		resolution = DefaultHistoricalResolution(period)
+	}
+	if err := ValidateHistoricalResolution(period, resolution); err != nil {
+		return nil, err
+	}
+	key := historicalCacheKey(period, resolution)
+
 	s.historicalMu.RLock()
-	if cached, exists := s.historicalCache[period]; exists {
+	if cached, exists := s.historicalCache[key]; exists && s.historicalFresh(cached) {
 		data := cached.data
 		s.historicalMu.RUnlock()
+		s.metrics.CacheLookups.Inc(metrics.CacheHistorical, metrics.ResultHit)
 		return data, nil
 	}
 	s.historicalMu.RUnlock()
@@ -334,37 +764,21 @@ func (s *TreasuryService) GetHistoricalYields(period string) (*models.Historical
 	s.historicalMu.Lock()
 	defer s.historicalMu.Unlock()
 
-	if cached, exists := s.historicalCache[period]; exists {
+	if cached, exists := s.historicalCache[key]; exists && s.historicalFresh(cached) {
+		s.metrics.CacheLookups.Inc(metrics.CacheHistorical, metrics.ResultHit)
 		return cached.data, nil
 	}
+	s.metrics.CacheLookups.Inc(metrics.CacheHistorical, metrics.ResultMiss)
 
-	fmt.Printf("Fetching historical yields for period %s (cache miss)\n", period)
+	slog.Info("Fetching historical yields (cache miss)", "period", period, "resolution", resolution)
 
-	startDate, endDate, err := calculateDateRange(period)
+	// An invalid period is never cached, so its error is returned as-is
+	data, err := s.fetchHistorical(period, resolution)
 	if err != nil {
-		return nil, err
+		return s.expiredHistoricalOr(key, err)
 	}
 
-	var feed *models.TreasuryFeed
-	startYear := startDate.Year()
-	endYear := endDate.Year()
-
-	if startYear == endYear {
-		feed, err = s.fetchFromAPI()
-	} else {
-		feed, err = s.fetchFromAPIForYears(startYear, endYear)
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	data, err := s.convertToHistoricalData(feed, startDate, endDate, period)
-	if err != nil {
-		return nil, err
-	}
-
-	s.historicalCache[period] = &historicalCacheEntry{
+	s.historicalCache[key] = &historicalCacheEntry{
 		data:      data,
 		timestamp: time.Now(),
 	}
@@ -372,12 +786,15 @@ func (s *TreasuryService) GetHistoricalYields(period string) (*models.Historical
 	return data, nil
 }
 
-// GetLatestYields returns latest yields with 1-hour caching
+// GetLatestYields returns latest yields, cached for 1 hour unless SetCacheDuration is used.
+// If the cache has expired and upstream fails, the last successfully fetched curve is
+// returned marked stale; an error is returned only if no fetch has ever succeeded.
 func (s *TreasuryService) GetLatestYields() (*models.YieldData, error) {
 	s.mu.RLock()
-	if s.cacheData != nil && time.Since(s.cacheTimestamp) < s.cacheDuration {
+	if s.cacheData != nil && time.Since(s.lastGoodTimestamp) < s.cacheDuration {
 		data := s.cacheData
 		s.mu.RUnlock()
+		s.metrics.CacheLookups.Inc(metrics.CacheLatest, metrics.ResultHit)
 		return data, nil
 	}
 	s.mu.RUnlock()
@@ -385,40 +802,251 @@ func (s *TreasuryService) GetLatestYields() (*models.YieldData, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.cacheData != nil && time.Since(s.cacheTimestamp) < s.cacheDuration {
+	if s.cacheData != nil && time.Since(s.lastGoodTimestamp) < s.cacheDuration {
+		s.metrics.CacheLookups.Inc(metrics.CacheLatest, metrics.ResultHit)
 		return s.cacheData, nil
 	}
+	s.metrics.CacheLookups.Inc(metrics.CacheLatest, metrics.ResultMiss)
 
 	feed, err := s.fetchFromAPI()
+	s.recordFetch(err)
 	if err != nil {
-		return nil, err
+		return s.staleYieldsOr(err)
 	}
 
 	data, err := s.convertToYieldData(feed)
 	if err != nil {
-		return nil, err
+		return s.staleYieldsOr(err)
 	}
 
 	s.cacheData = data
-	s.cacheTimestamp = time.Now()
+	s.lastGoodTimestamp = time.Now()
+
+	return data, nil
+}
+
+// GetYieldsForDate returns the curve in effect on date (YYYY-MM-DD): the latest valid entry on or before it,
+// so weekends and holidays resolve to the prior trading day. The feeds for that year and the one before are
+// fetched, so a date before the first entry of its year falls back to the previous December; those feeds come
+// from the per-year feed cache when already fetched. Dates before the feed's first curve return ErrNoYieldsForDate.
+func (s *TreasuryService) GetYieldsForDate(date string) (*models.YieldData, error) {
+	asOf, err := time.Parse(isoDateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: must be YYYY-MM-DD", date)
+	}
+	if asOf.After(time.Now()) {
+		return nil, fmt.Errorf("date %s is in the future", asOf.Format(isoDateLayout))
+	}
+	if asOf.Format(isoDateLayout) < firstYieldCurveDate {
+		return nil, fmt.Errorf("%w: %s is before the first curve on %s", ErrNoYieldsForDate, asOf.Format(isoDateLayout), firstYieldCurveDate)
+	}
+
+	feed, err := s.feedForYears(asOf.Year()-1, asOf.Year())
+	if err != nil {
+		return nil, err
+	}
 
+	data, err := s.convertToYieldData(entriesOnOrBefore(feed, asOf))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNoYieldsForDate, asOf.Format(isoDateLayout), err)
+	}
 	return data, nil
 }
 
-// WarmCache pre-fetches all historical data in background on startup
-func (s *TreasuryService) WarmCache() {
-	log.Println("Starting historical yield cache warming for all periods...")
+// feedForYears returns the combined feed for startYear through endYear, oldest first, from the per-year
+// feed cache when every year is there; otherwise the whole range is fetched, as fetchFromAPIForYears does
+func (s *TreasuryService) feedForYears(startYear, endYear int) (*models.TreasuryFeed, error) {
+	combined := &models.TreasuryFeed{}
+	for year := startYear; year <= endYear; year++ {
+		entries, ok := s.cachedYearFeed(year)
+		if !ok {
+			return s.fetchFromAPIForYears(startYear, endYear)
+		}
+		combined.Entries = append(combined.Entries, entries...)
+	}
+
+	sortEntriesByDate(combined.Entries)
+	return combined, nil
+}
 
-	for _, period := range historicalPeriods {
+// entriesOnOrBefore returns the feed entries dated on or before asOf, in feed order.
+// Entries with an unparseable date are kept so convertToYieldData logs and skips them as usual.
+func entriesOnOrBefore(feed *models.TreasuryFeed, asOf time.Time) *models.TreasuryFeed {
+	filtered := &models.TreasuryFeed{}
+	for _, entry := range feed.Entries {
+		if entryDate, err := parseEntryDate(entry.Date); err == nil && entryDate.After(asOf) {
+			continue
+		}
+		filtered.Entries = append(filtered.Entries, entry)
+	}
+	return filtered
+}
+
+// fetchHistorical fetches and converts the data for a historical period at resolution without touching the cache
+func (s *TreasuryService) fetchHistorical(period, resolution string) (*models.HistoricalYieldData, error) {
+	feed, startDate, endDate, err := s.fetchHistoricalFeed(period)
+	if err != nil {
+		return nil, err
+	}
+	return s.convertToHistoricalData(feed, startDate, endDate, period, resolution)
+}
+
+// fetchHistoricalFeed fetches the feed entries covering a historical period, returning them with the period's date range
+func (s *TreasuryService) fetchHistoricalFeed(period string) (*models.TreasuryFeed, time.Time, time.Time, error) {
+	startDate, endDate, err := calculateDateRange(period)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	var feed *models.TreasuryFeed
+	startYear := startDate.Year()
+	endYear := endDate.Year()
+
+	if startYear == endYear {
+		feed, err = s.fetchFromAPI()
+	} else {
+		feed, err = s.fetchFromAPIForYears(startYear, endYear)
+	}
+	s.recordFetch(err)
+
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	return feed, startDate, endDate, nil
+}
+
+// RefreshHistoricalCache refetches every historical period and replaces its cache entry, so long-lived
+// caches pick up new daily data. Fetches run without holding the cache lock, which is only taken to
+// swap each entry in, so reads keep being served from the old entries meanwhile.
+// Each period is fetched once and re-sampled for its default resolution and any other resolution cached for it.
+// A period whose fetch fails keeps its existing entries. It returns the number of periods refreshed.
+func (s *TreasuryService) RefreshHistoricalCache() int {
+	refreshed := 0
+	for _, period := range HistoricalPeriods {
+		start := time.Now()
+		feed, startDate, endDate, err := s.fetchHistoricalFeed(period)
+		if err != nil {
+			slog.Error("Failed to refresh historical yields", "period", period, "error", err)
+			continue
+		}
+
+		for _, resolution := range s.cachedResolutions(period) {
+			data, err := s.convertToHistoricalData(feed, startDate, endDate, period, resolution)
+			if err != nil {
+				slog.Error("Failed to refresh historical yields", "period", period, "resolution", resolution, "error", err)
+				continue
+			}
+
+			s.historicalMu.Lock()
+			s.historicalCache[historicalCacheKey(period, resolution)] = &historicalCacheEntry{
+				data:      data,
+				timestamp: time.Now(),
+			}
+			s.historicalMu.Unlock()
+		}
+
+		refreshed++
+		slog.Info("Historical yields refreshed", "period", period, "elapsed_ms", time.Since(start).Milliseconds())
+	}
+	return refreshed
+}
+
+// cachedResolutions returns the period's default resolution followed by any other resolution cached for it
+func (s *TreasuryService) cachedResolutions(period string) []string {
+	defaultResolution := DefaultHistoricalResolution(period)
+	resolutions := []string{defaultResolution}
+
+	s.historicalMu.RLock()
+	defer s.historicalMu.RUnlock()
+	for _, resolution := range HistoricalResolutions {
+		if resolution == defaultResolution {
+			continue
+		}
+		if _, exists := s.historicalCache[historicalCacheKey(period, resolution)]; exists {
+			resolutions = append(resolutions, resolution)
+		}
+	}
+	return resolutions
+}
+
+// StartHistoricalRefresh runs RefreshHistoricalCache every interval in the background until ctx is cancelled
+func (s *TreasuryService) StartHistoricalRefresh(ctx context.Context, interval time.Duration) {
+	s.historicalMu.Lock()
+	s.historicalRefresh = interval
+	s.historicalMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refreshed := s.RefreshHistoricalCache()
+				slog.InfoContext(ctx, "Historical refresh run complete", "refreshed", refreshed, "periods", len(HistoricalPeriods))
+			}
+		}
+	}()
+}
+
+// expiredHistoricalOr returns the expired cache entry for key, or err if it has never been cached.
+// Callers must hold s.historicalMu.
+func (s *TreasuryService) expiredHistoricalOr(key string, err error) (*models.HistoricalYieldData, error) {
+	cached, exists := s.historicalCache[key]
+	if !exists {
+		return nil, err
+	}
+
+	slog.Warn("Serving expired historical yields", "period", key, "cached_at", cached.timestamp.Format(time.RFC3339), "error", err)
+	return cached.data, nil
+}
+
+// staleYieldsOr returns a stale copy of the cached curve, or err if nothing has been cached.
+// Callers must hold s.mu.
+func (s *TreasuryService) staleYieldsOr(err error) (*models.YieldData, error) {
+	if s.cacheData == nil {
+		return nil, err
+	}
+
+	slog.Warn("Serving stale treasury yields", "cached_at", s.lastGoodTimestamp.Format(time.RFC3339), "error", err)
+	stale := *s.cacheData
+	stale.Stale = true
+	cachedAt := s.lastGoodTimestamp
+	stale.CachedAt = &cachedAt
+	return &stale, nil
+}
+
+// WarmCache pre-fetches all historical data in background on startup.
+// Calls made while a previous warm is still running are no-ops; it reports whether warming started.
+func (s *TreasuryService) WarmCache() bool {
+	if !s.warming.CompareAndSwap(false, true) {
+		slog.Info("Historical yield cache warming already in progress, skipping")
+		return false
+	}
+
+	slog.Info("Starting historical yield cache warming for all periods")
+
+	var wg sync.WaitGroup
+	for _, period := range HistoricalPeriods {
+		wg.Add(1)
 		go func(p string) {
-			log.Printf("Warming cache for period: %s", p)
+			defer wg.Done()
+			slog.Info("Warming cache", "period", p)
 			start := time.Now()
 
 			if _, err := s.GetHistoricalYields(p); err != nil {
-				log.Printf("ERROR: Failed to warm cache for period %s: %v", p, err)
+				slog.Error("Failed to warm cache", "period", p, "error", err)
 			} else {
-				log.Printf("Cache warmed successfully for period %s in %v", p, time.Since(start))
+				slog.Info("Cache warmed successfully", "period", p, "elapsed_ms", time.Since(start).Milliseconds())
 			}
 		}(period)
 	}
+
+	go func() {
+		wg.Wait()
+		s.warming.Store(false)
+	}()
+	return true
 }
@@ -0,0 +1,1283 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"modernfi-treasury-app/internal/models"
+)
+
+// TestParseEntryDate tests parsing of the date formats seen in the treasury feed
+func TestParseEntryDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+		wantErr  bool
+	}{
+		{"Date with time component", "2025-03-14T00:00:00", "2025-03-14", false},
+		{"Plain ISO date", "2025-03-14", "2025-03-14", false},
+		{"RFC3339 with zone", "2025-03-14T00:00:00Z", "2025-03-14", false},
+		{"US format", "03/14/2025", "2025-03-14", false},
+		{"Surrounding whitespace", " 2025-03-14T00:00:00 ", "2025-03-14", false},
+		{"Malformed", "14th of March", "", true},
+		{"Empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := parseEntryDate(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEntryDate(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if !tt.wantErr && date.Format(isoDateLayout) != tt.expected {
+				t.Errorf("parseEntryDate(%q) = %s, want %s", tt.raw, date.Format(isoDateLayout), tt.expected)
+			}
+		})
+	}
+}
+
+// TestConvertToHistoricalData_DateFormats tests that timestamped dates are kept and malformed dates are skipped
+func TestConvertToHistoricalData_DateFormats(t *testing.T) {
+	s := NewTreasuryService()
+	feed := &models.TreasuryFeed{
+		Entries: []models.Entry{
+			{Date: "2025-03-14T00:00:00", BC3Month: models.KnownRate(4.35), BC10Year: models.KnownRate(4.31)},
+			{Date: "not-a-date", BC10Year: models.KnownRate(9.99)},
+		},
+	}
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	data, err := s.convertToHistoricalData(feed, start, end, "1M", ResolutionDaily)
+	if err != nil {
+		t.Fatalf("convertToHistoricalData failed: %v", err)
+	}
+
+	if len(data.Data) != 1 {
+		t.Fatalf("Expected 1 data point, got %d", len(data.Data))
+	}
+	if data.Data[0]["date"] != "2025-03-14" {
+		t.Errorf("Expected date 2025-03-14, got %v", data.Data[0]["date"])
+	}
+	if data.Data[0]["10Y"] != 4.31 {
+		t.Errorf("Expected 10Y rate 4.31, got %v", data.Data[0]["10Y"])
+	}
+
+	// Every term is included, bills too; terms the entry left blank are null rather than 0%
+	if data.Data[0]["3M"] != 4.35 {
+		t.Errorf("Expected 3M rate 4.35, got %v", data.Data[0]["3M"])
+	}
+	if rate, ok := data.Data[0]["30Y"]; !ok || rate != nil {
+		t.Errorf("Expected null 30Y rate, got %v", rate)
+	}
+	if len(data.Terms) != len(HistoricalTerms) {
+		t.Errorf("Expected terms %v, got %v", HistoricalTerms, data.Terms)
+	}
+	for _, term := range HistoricalTerms {
+		if _, ok := data.Data[0][term]; !ok {
+			t.Errorf("Expected data point to include %s", term)
+		}
+	}
+}
+
+// TestConvertToYieldData_OmitsBlankTerms tests that blank or null feed rates are left off the curve instead of read as 0%,
+// while a published 0.00 is kept
+func TestConvertToYieldData_OmitsBlankTerms(t *testing.T) {
+	raw := `<feed xmlns:m="http://schemas.microsoft.com/ado/2007/08/dataservices/metadata"><entry><content><properties>
+		<NEW_DATE>2025-03-14T00:00:00</NEW_DATE>
+		<BC_1MONTH>0.00</BC_1MONTH><BC_2MONTH> </BC_2MONTH><BC_3MONTH>4.35</BC_3MONTH>
+		<BC_6MONTH>4.50</BC_6MONTH><BC_1YEAR>4.20</BC_1YEAR><BC_2YEAR>4.10</BC_2YEAR><BC_5YEAR>4.00</BC_5YEAR>
+		<BC_10YEAR>4.30</BC_10YEAR><BC_30YEAR m:null="true" />
+	</properties></content></entry></feed>`
+	var feed models.TreasuryFeed
+	if err := xml.Unmarshal([]byte(raw), &feed); err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+
+	data, err := NewTreasuryService().convertToYieldData(&feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	for _, term := range []string{"2M", "4M", "30Y"} {
+		if rate, found := data.RateForTerm(term); found {
+			t.Errorf("Expected no %s yield, got %.2f", term, rate)
+		}
+	}
+	if rate, found := data.RateForTerm("1M"); !found || rate != 0 {
+		t.Errorf("Expected published 1M yield 0.00, got %.2f (found %v)", rate, found)
+	}
+	if rate, found := data.RateForTerm("10Y"); !found || rate != 4.30 {
+		t.Errorf("Expected 10Y yield 4.30, got %.2f (found %v)", rate, found)
+	}
+	if len(data.Yields) != 7 {
+		t.Errorf("Expected 7 published terms, got %v", data.Yields)
+	}
+
+	// An entry with no published rates at all is skipped in favor of an earlier one
+	feed.Entries = append([]models.Entry{{Date: "2025-03-13T00:00:00", BC10Year: models.KnownRate(4.28)}}, feed.Entries[0],
+		models.Entry{Date: "2025-03-17T00:00:00"})
+	data, err = NewTreasuryService().convertToYieldData(&feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	if data.Date != "2025-03-14" {
+		t.Errorf("Expected the empty 2025-03-17 entry to be skipped, got %s", data.Date)
+	}
+}
+
+// TestConvertToYieldData_SkipsMalformedLatestEntry tests falling back to the newest entry with a valid date
+func TestConvertToYieldData_SkipsMalformedLatestEntry(t *testing.T) {
+	s := NewTreasuryService()
+	feed := &models.TreasuryFeed{
+		Entries: []models.Entry{
+			{Date: "2025-03-14T00:00:00", BC1Month: models.KnownRate(4.30)},
+			{Date: "garbage", BC1Month: models.KnownRate(1.00)},
+		},
+	}
+
+	data, err := s.convertToYieldData(feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	if data.Date != "2025-03-14" {
+		t.Errorf("Expected date 2025-03-14, got %s", data.Date)
+	}
+	if data.Yields[0].Rate != 4.30 {
+		t.Errorf("Expected 1M rate 4.30, got %f", data.Yields[0].Rate)
+	}
+
+	// All entries malformed should be an error rather than an empty curve
+	_, err = s.convertToYieldData(&models.TreasuryFeed{Entries: []models.Entry{{Date: "garbage"}}})
+	if err == nil {
+		t.Error("Expected error when no entry has a valid date")
+	}
+}
+
+// feedXML builds a minimal treasury XML feed with one entry per date, using rate for every term
+func feedXML(rate float64, dates ...string) string {
+	xml := "<feed>"
+	for _, date := range dates {
+		xml += fmt.Sprintf(`<entry><content><properties>
+			<NEW_DATE>%s</NEW_DATE>
+			<BC_1MONTH>%[2]g</BC_1MONTH><BC_2MONTH>%[2]g</BC_2MONTH><BC_3MONTH>%[2]g</BC_3MONTH><BC_4MONTH>%[2]g</BC_4MONTH>
+			<BC_6MONTH>%[2]g</BC_6MONTH><BC_1YEAR>%[2]g</BC_1YEAR>
+			<BC_2YEAR>%[2]g</BC_2YEAR><BC_5YEAR>%[2]g</BC_5YEAR><BC_10YEAR>%[2]g</BC_10YEAR><BC_30YEAR>%[2]g</BC_30YEAR>
+		</properties></content></entry>`, date, rate)
+	}
+	return xml + "</feed>"
+}
+
+// newYearServer starts a fake treasury server and points the service at it.
+// The handler receives the requested year parsed from the query string.
+func newYearServer(t *testing.T, s *TreasuryService, handler func(w http.ResponseWriter, r *http.Request, year int)) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		year, _ := strconv.Atoi(r.URL.Query().Get("year"))
+		handler(w, r, year)
+	}))
+	t.Cleanup(server.Close)
+	s.urlTemplate = server.URL + "/?year=%d"
+}
+
+// TestFetchFromAPIForYears_DeadlineReturnsPartialResults tests that a hanging year doesn't block the combined fetch
+func TestFetchFromAPIForYears_DeadlineReturnsPartialResults(t *testing.T) {
+	s := NewTreasuryService()
+	s.multiYearDeadline = 200 * time.Millisecond
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if year == 2021 {
+			// Hang until the client gives up
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-06-01T00:00:00", year)))
+	})
+
+	start := time.Now()
+	feed, err := s.fetchFromAPIForYears(2020, 2023)
+	if err != nil {
+		t.Fatalf("Expected partial results, got error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected fetch to stop at the deadline, took %v", elapsed)
+	}
+
+	if len(feed.Entries) != 3 {
+		t.Fatalf("Expected 3 entries from completed years, got %d", len(feed.Entries))
+	}
+	for _, entry := range feed.Entries {
+		if entry.Date[:4] == "2021" {
+			t.Errorf("Did not expect entry from the timed-out year, got %s", entry.Date)
+		}
+	}
+}
+
+// TestFetchFromAPIForYears_DeadlineBelowThreshold tests that too few completed years is an error
+func TestFetchFromAPIForYears_DeadlineBelowThreshold(t *testing.T) {
+	s := NewTreasuryService()
+	s.multiYearDeadline = 200 * time.Millisecond
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if year != 2020 {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, "2020-06-01T00:00:00"))
+	})
+
+	if _, err := s.fetchFromAPIForYears(2020, 2023); err == nil {
+		t.Error("Expected error when fewer than the minimum fraction of years complete")
+	}
+}
+
+// TestFetchFromAPIForYears_ToleratesFailedYears tests that failed years within the tolerance are skipped,
+// the remaining years are combined in year order, and one failure too many fails the fetch
+func TestFetchFromAPIForYears_ToleratesFailedYears(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetryBaseDelay = 0
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if year == 2021 || year == 2023 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-03-01T00:00:00", year), fmt.Sprintf("%d-09-01T00:00:00", year)))
+	})
+
+	// By default any failed year fails the whole fetch
+	if _, err := s.fetchFromAPIForYears(2020, 2024); err == nil {
+		t.Fatal("Expected error when a year fails and none are tolerated")
+	}
+
+	if err := s.SetMaxFailedYears(1); err != nil {
+		t.Fatalf("SetMaxFailedYears failed: %v", err)
+	}
+	if _, err := s.fetchFromAPIForYears(2020, 2024); err == nil {
+		t.Fatal("Expected error when more years fail than are tolerated")
+	}
+
+	if err := s.SetMaxFailedYears(2); err != nil {
+		t.Fatalf("SetMaxFailedYears failed: %v", err)
+	}
+	feed, err := s.fetchFromAPIForYears(2020, 2024)
+	if err != nil {
+		t.Fatalf("Expected the remaining years, got error: %v", err)
+	}
+	var dates []string
+	for _, entry := range feed.Entries {
+		dates = append(dates, entry.Date[:10])
+	}
+	expected := []string{"2020-03-01", "2020-09-01", "2022-03-01", "2022-09-01", "2024-03-01", "2024-09-01"}
+	if strings.Join(dates, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected entries %v in year order, got %v", expected, dates)
+	}
+
+	if err := s.SetMaxFailedYears(-1); err == nil {
+		t.Error("Expected error for a negative tolerance")
+	}
+}
+
+// TestGetHistoricalYields_SkipsFailedYear tests that a year returning a 500 leaves a chart built from the other years
+func TestGetHistoricalYields_SkipsFailedYear(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetryBaseDelay = 0
+	if err := s.SetMaxFailedYears(1); err != nil {
+		t.Fatalf("SetMaxFailedYears failed: %v", err)
+	}
+
+	// One entry per year on yesterday's month and day, so every year but the oldest falls inside the 5Y window
+	yesterday := time.Now().AddDate(0, 0, -1)
+	failedYear := yesterday.Year() - 2
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if year == failedYear {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		date := yesterday.AddDate(year-yesterday.Year(), 0, 0)
+		fmt.Fprint(w, feedXML(4.0, date.Format("2006-01-02T00:00:00")))
+	})
+
+	data, err := s.GetHistoricalYieldsAtResolution("5Y", ResolutionDaily)
+	if err != nil {
+		t.Fatalf("Expected a chart from the remaining years, got error: %v", err)
+	}
+	var years []string
+	for _, point := range data.Data {
+		years = append(years, point["date"].(string)[:4])
+	}
+	var expected []string
+	for year := yesterday.Year() - 4; year <= yesterday.Year(); year++ {
+		if year != failedYear {
+			expected = append(expected, strconv.Itoa(year))
+		}
+	}
+	if strings.Join(years, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected points for years %v, got %v", expected, years)
+	}
+}
+
+// TestFetchFromAPIForYears_AllYearsFail tests that tolerated failures still need at least one year of data
+func TestFetchFromAPIForYears_AllYearsFail(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetryBaseDelay = 0
+	if err := s.SetMaxFailedYears(5); err != nil {
+		t.Fatalf("SetMaxFailedYears failed: %v", err)
+	}
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if _, err := s.fetchFromAPIForYears(2020, 2022); err == nil {
+		t.Error("Expected error when every year fails")
+	}
+}
+
+// TestFetchFromAPI_LogsSlowFetch tests that fetches slower than the threshold emit a warning
+func TestFetchFromAPI_LogsSlowFetch(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer func() {
+		// Restoring slog's default handler doesn't undo the log package redirect SetDefault installed
+		slog.SetDefault(prev)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	s := NewTreasuryService()
+	s.SetSlowFetchThreshold(50 * time.Millisecond)
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-01-02T00:00:00", year)))
+	})
+
+	if _, err := s.fetchFromAPI(); err != nil {
+		t.Fatalf("fetchFromAPI failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"msg":"Slow upstream treasury.gov fetch"`) {
+		t.Errorf("Expected slow fetch warning, got log output: %q", buf.String())
+	}
+
+	// A fast fetch stays quiet
+	buf.Reset()
+	s.SetSlowFetchThreshold(10 * time.Second)
+	if _, err := s.fetchFromAPI(); err != nil {
+		t.Fatalf("fetchFromAPI failed: %v", err)
+	}
+	if strings.Contains(buf.String(), `"msg":"Slow upstream treasury.gov fetch"`) {
+		t.Errorf("Did not expect slow fetch warning, got log output: %q", buf.String())
+	}
+}
+
+// TestConvertToYieldData_RejectsImplausibleCurve tests falling back to the prior entry when any rate is out of band
+func TestConvertToYieldData_RejectsImplausibleCurve(t *testing.T) {
+	s := NewTreasuryService()
+	feed := &models.TreasuryFeed{
+		Entries: []models.Entry{
+			{Date: "2025-03-13T00:00:00", BC1Month: models.KnownRate(4.30), BC3Month: models.KnownRate(4.32), BC6Month: models.KnownRate(4.25), BC1Year: models.KnownRate(4.10),
+				BC2Year: models.KnownRate(4.00), BC5Year: models.KnownRate(4.05), BC10Year: models.KnownRate(4.28), BC30Year: models.KnownRate(4.60)},
+			{Date: "2025-03-14T00:00:00", BC1Month: models.KnownRate(4.31), BC3Month: models.KnownRate(4.33), BC6Month: models.KnownRate(4.26), BC1Year: models.KnownRate(4.11),
+				BC2Year: models.KnownRate(4.01), BC5Year: models.KnownRate(93.5), BC10Year: models.KnownRate(4.29), BC30Year: models.KnownRate(4.61)},
+		},
+	}
+
+	data, err := s.convertToYieldData(feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	if data.Date != "2025-03-13" {
+		t.Errorf("Expected fallback to prior entry 2025-03-13, got %s", data.Date)
+	}
+	if rate, _ := data.RateForTerm("5Y"); rate != 4.05 {
+		t.Errorf("Expected 5Y rate 4.05 from prior entry, got %f", rate)
+	}
+
+	// A narrower configured band rejects both entries
+	if err := s.SetPlausibleYieldBand(0, 4.5); err != nil {
+		t.Fatalf("SetPlausibleYieldBand failed: %v", err)
+	}
+	if _, err := s.convertToYieldData(feed); err == nil {
+		t.Error("Expected error when every entry is outside the plausible band")
+	}
+
+	if err := s.SetPlausibleYieldBand(5, 5); err == nil {
+		t.Error("Expected error for an empty band")
+	}
+}
+
+// TestUpstreamStatus_TracksFetchOutcome tests that fetch success and failure are reflected in the status
+func TestUpstreamStatus_TracksFetchOutcome(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	if s.UpstreamStatus().Ready() {
+		t.Fatal("Expected never-fetched service to be not ready")
+	}
+
+	healthy := true
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if !healthy {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-01-02T00:00:00", year)))
+	})
+
+	if _, err := s.GetLatestYields(); err != nil {
+		t.Fatalf("GetLatestYields failed: %v", err)
+	}
+	status := s.UpstreamStatus()
+	if !status.Ready() || !status.CacheWarm || status.LastFetch == nil {
+		t.Errorf("Expected ready with warm cache after successful fetch, got %+v", status)
+	}
+
+	// Upstream goes down: cache stays warm but readiness drops
+	healthy = false
+	s.cacheDuration = 0
+	data, err := s.GetLatestYields()
+	if err != nil {
+		t.Fatalf("Expected stale yields from failing upstream, got error: %v", err)
+	}
+	if !data.Stale {
+		t.Error("Expected yields served during an outage to be marked stale")
+	}
+	status = s.UpstreamStatus()
+	if status.Ready() || status.LastFetchOK {
+		t.Errorf("Expected not ready after failed fetch, got %+v", status)
+	}
+	if !status.CacheWarm {
+		t.Error("Expected cache to remain warm after failed fetch")
+	}
+}
+
+// TestFetchFromAPI_RetriesServerErrors tests that 5xx responses are retried until one succeeds
+func TestFetchFromAPI_RetriesServerErrors(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetryBaseDelay = 0
+
+	var attempts atomic.Int32
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-01-02T00:00:00", year)))
+	})
+
+	if _, err := s.fetchFromAPI(); err != nil {
+		t.Fatalf("Expected success after retries, got error: %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+// TestFetchFromAPI_RetryLimits tests that 4xx responses are not retried and 5xx retries stop at the limit
+func TestFetchFromAPI_RetryLimits(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		expectedAttempts int32
+	}{
+		{"Client error not retried", http.StatusNotFound, 1},
+		{"Server error retried up to the limit", http.StatusBadGateway, fetchRetries + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTreasuryService()
+			s.fetchRetryBaseDelay = 0
+
+			var attempts atomic.Int32
+			newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+			})
+
+			if _, err := s.fetchFromAPI(); err == nil {
+				t.Fatal("Expected error from failing upstream")
+			}
+			if got := attempts.Load(); got != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, got)
+			}
+		})
+	}
+}
+
+// TestFetchFromAPIForYears_RetriesPerYear tests that a transient failure for one year is retried independently
+func TestFetchFromAPIForYears_RetriesPerYear(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetryBaseDelay = 0
+
+	var failed atomic.Bool
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if year == 2021 && !failed.Swap(true) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-06-01T00:00:00", year)))
+	})
+
+	feed, err := s.fetchFromAPIForYears(2020, 2022)
+	if err != nil {
+		t.Fatalf("Expected success after retry, got error: %v", err)
+	}
+	if len(feed.Entries) != 3 {
+		t.Errorf("Expected 3 entries, got %d", len(feed.Entries))
+	}
+}
+
+// waitForWarm blocks until running WarmCache goroutines finish or the timeout expires
+func waitForWarm(t *testing.T, s *TreasuryService, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for s.warming.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for cache warming to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestWarmCache_OverlappingCallsAreNoOps tests that a second WarmCache during warming doesn't refetch any period
+func TestWarmCache_OverlappingCallsAreNoOps(t *testing.T) {
+	countingService := func() (*TreasuryService, *atomic.Int32, chan struct{}) {
+		s := NewTreasuryService()
+		s.fetchRetries = 0
+		var requests atomic.Int32
+		release := make(chan struct{})
+		newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+			requests.Add(1)
+			<-release
+			fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-01-02T00:00:00", year)))
+		})
+		return s, &requests, release
+	}
+
+	// Baseline: upstream requests made by a single warm
+	single, singleRequests, release := countingService()
+	if !single.WarmCache() {
+		t.Fatal("Expected first WarmCache to start warming")
+	}
+	close(release)
+	waitForWarm(t, single, 5*time.Second)
+
+	// Second call lands while the first warm is blocked on upstream
+	overlapped, overlappedRequests, release := countingService()
+	if !overlapped.WarmCache() {
+		t.Fatal("Expected first WarmCache to start warming")
+	}
+	if overlapped.WarmCache() {
+		t.Error("Expected overlapping WarmCache to be a no-op")
+	}
+	close(release)
+	waitForWarm(t, overlapped, 5*time.Second)
+
+	if got, want := overlappedRequests.Load(), singleRequests.Load(); got != want {
+		t.Errorf("Expected %d upstream requests with overlapping calls, got %d", want, got)
+	}
+
+	// Once warming finishes, a new call may start again
+	if !overlapped.WarmCache() {
+		t.Error("Expected WarmCache to start again after the previous warm finished")
+	}
+	waitForWarm(t, overlapped, 5*time.Second)
+}
+
+// failingTransport fails every request without contacting a server
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+// TestGetLatestYields_OutOfOrderEntries tests that the newest entry is selected even when the feed is not chronological
+func TestGetLatestYields_OutOfOrderEntries(t *testing.T) {
+	s := NewTreasuryService()
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		fmt.Fprint(w, feedXML(4.0, "2025-03-14T00:00:00", "2025-03-12T00:00:00", "03/13/2025"))
+	})
+
+	data, err := s.GetLatestYields()
+	if err != nil {
+		t.Fatalf("GetLatestYields failed: %v", err)
+	}
+	if data.Date != "2025-03-14" {
+		t.Errorf("Expected the newest curve from 2025-03-14, got %s", data.Date)
+	}
+}
+
+// TestFetchFromAPIForYears_SortsEntriesByDate tests that combined entries are ordered by date within and across years
+func TestFetchFromAPIForYears_SortsEntriesByDate(t *testing.T) {
+	s := NewTreasuryService()
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		// Each year lists December first, and one date uses a different layout
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-12-01T00:00:00", year), fmt.Sprintf("06/01/%d", year), "garbage"))
+	})
+
+	feed, err := s.fetchFromAPIForYears(2020, 2021)
+	if err != nil {
+		t.Fatalf("fetchFromAPIForYears failed: %v", err)
+	}
+	var dates []string
+	for _, entry := range feed.Entries {
+		dates = append(dates, entry.Date)
+	}
+	expected := []string{"garbage", "garbage", "06/01/2020", "2020-12-01T00:00:00", "06/01/2021", "2021-12-01T00:00:00"}
+	if strings.Join(dates, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected entries %v, got %v", expected, dates)
+	}
+
+	data, err := s.convertToYieldData(feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	if data.Date != "2021-12-01" {
+		t.Errorf("Expected the newest curve from 2021-12-01, got %s", data.Date)
+	}
+}
+
+// TestGetLatestYields_ServesStaleOnFailure tests the fallback to the last good curve when upstream fails
+func TestGetLatestYields_ServesStaleOnFailure(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	s.SetHTTPClient(&http.Client{Transport: failingTransport{}})
+
+	// Never fetched successfully: hard error
+	if _, err := s.GetLatestYields(); err == nil {
+		t.Fatal("Expected error when no fetch has ever succeeded")
+	}
+
+	fetchedAt := time.Now().Add(-3 * time.Hour)
+	s.cacheData = &models.YieldData{
+		Date:   "2025-03-14",
+		Yields: []models.YieldPoint{{Term: "3M", Rate: 4.32}},
+	}
+	s.lastGoodTimestamp = fetchedAt
+
+	data, err := s.GetLatestYields()
+	if err != nil {
+		t.Fatalf("Expected stale yields, got error: %v", err)
+	}
+	if !data.Stale {
+		t.Error("Expected Stale to be set")
+	}
+	if data.CachedAt == nil || !data.CachedAt.Equal(fetchedAt) {
+		t.Errorf("Expected CachedAt %v, got %v", fetchedAt, data.CachedAt)
+	}
+	if data.Date != "2025-03-14" {
+		t.Errorf("Expected cached curve date 2025-03-14, got %s", data.Date)
+	}
+	if rate, _ := data.RateForTerm("3M"); rate != 4.32 {
+		t.Errorf("Expected cached 3M rate 4.32, got %f", rate)
+	}
+
+	// The cached entry itself is not marked stale
+	if s.cacheData.Stale || s.cacheData.CachedAt != nil {
+		t.Error("Expected cached curve to be left unmodified")
+	}
+	if s.UpstreamStatus().LastFetchOK {
+		t.Error("Expected failed fetch to be recorded")
+	}
+}
+
+// cannedTransport answers every request with a feed for the requested year and records the years asked for
+type cannedTransport struct {
+	mu    sync.Mutex
+	years []int
+}
+
+func (c *cannedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	year, err := strconv.Atoi(req.URL.Query().Get("field_tdr_date_value"))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected request URL %s", req.URL)
+	}
+	c.mu.Lock()
+	c.years = append(c.years, year)
+	c.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(feedXML(4.0, fmt.Sprintf("%d-06-03T00:00:00", year)))),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+// TestSetHTTPClient_UsedForAllFetches tests that an injected client serves both single- and multi-year fetches
+func TestSetHTTPClient_UsedForAllFetches(t *testing.T) {
+	transport := &cannedTransport{}
+	s := NewTreasuryService()
+	s.SetHTTPClient(&http.Client{Transport: transport})
+
+	data, err := s.GetLatestYields()
+	if err != nil {
+		t.Fatalf("GetLatestYields failed: %v", err)
+	}
+	if want := fmt.Sprintf("%d-06-03", time.Now().Year()); data.Date != want {
+		t.Errorf("Expected curve date %s from canned feed, got %s", want, data.Date)
+	}
+
+	feed, err := s.fetchFromAPIForYears(2020, 2022)
+	if err != nil {
+		t.Fatalf("fetchFromAPIForYears failed: %v", err)
+	}
+	if len(feed.Entries) != 3 {
+		t.Errorf("Expected 3 entries from canned feeds, got %d", len(feed.Entries))
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.years) != 4 {
+		t.Errorf("Expected 4 requests through the injected client, got %d (%v)", len(transport.years), transport.years)
+	}
+}
+
+// TestGetYieldsForDate tests that the latest entry on or before the date is used, reaching into the prior year
+func TestGetYieldsForDate(t *testing.T) {
+	s := NewTreasuryService()
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		switch year {
+		case 2023:
+			fmt.Fprint(w, feedXML(5.10, "2023-12-28T00:00:00", "2023-12-29T00:00:00"))
+		case 2024:
+			fmt.Fprint(w, feedXML(4.80, "2024-01-02T00:00:00", "2024-01-08T00:00:00", "2024-01-09T00:00:00"))
+		default:
+			fmt.Fprint(w, feedXML(0))
+		}
+	})
+
+	tests := []struct {
+		date         string
+		expectedDate string
+		expectedRate float64
+	}{
+		{"2024-01-09", "2024-01-09", 4.80},
+		{"2024-01-07", "2024-01-02", 4.80}, // Weekend falls back to the last business day
+		{"2024-01-01", "2023-12-29", 5.10}, // Before the year's first entry falls back to the prior December
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			data, err := s.GetYieldsForDate(tt.date)
+			if err != nil {
+				t.Fatalf("GetYieldsForDate(%s) failed: %v", tt.date, err)
+			}
+			if data.Date != tt.expectedDate {
+				t.Errorf("Expected curve date %s, got %s", tt.expectedDate, data.Date)
+			}
+			if rate, _ := data.RateForTerm("6M"); rate != tt.expectedRate {
+				t.Errorf("Expected 6M rate %.2f, got %.2f", tt.expectedRate, rate)
+			}
+		})
+	}
+
+	for _, bad := range []string{"01/09/2024", "2024-13-01", time.Now().AddDate(0, 0, 2).Format("2006-01-02")} {
+		if _, err := s.GetYieldsForDate(bad); err == nil || errors.Is(err, ErrNoYieldsForDate) {
+			t.Errorf("Expected invalid date error for %q, got %v", bad, err)
+		}
+	}
+
+	// Before the earliest fetched entry, and before the feed began, there is no curve to serve
+	for _, early := range []string{"2023-12-27", "1989-06-30"} {
+		if _, err := s.GetYieldsForDate(early); !errors.Is(err, ErrNoYieldsForDate) {
+			t.Errorf("Expected ErrNoYieldsForDate for %s, got %v", early, err)
+		}
+	}
+}
+
+// TestGetYieldsForDate_ReusesYearFeeds tests that as-of-date lookups reuse feeds already fetched, by themselves
+// or by a historical fetch, and refetch the current year once the latest-yields cache duration passes
+func TestGetYieldsForDate_ReusesYearFeeds(t *testing.T) {
+	transport := &cannedTransport{}
+	s := NewTreasuryService()
+	s.SetHTTPClient(&http.Client{Transport: transport})
+
+	requests := func() int {
+		transport.mu.Lock()
+		defer transport.mu.Unlock()
+		return len(transport.years)
+	}
+
+	if _, err := s.GetYieldsForDate("2021-07-01"); err != nil {
+		t.Fatalf("GetYieldsForDate failed: %v", err)
+	}
+	if requests() != 2 {
+		t.Fatalf("Expected 2020 and 2021 fetched, got %d requests", requests())
+	}
+	if _, err := s.GetYieldsForDate("2021-08-02"); err != nil || requests() != 2 {
+		t.Errorf("Expected a repeat lookup served from cached feeds, got %d requests (err=%v)", requests(), err)
+	}
+
+	// A historical fetch covering 2018-2019 leaves those years for as-of lookups
+	if _, err := s.fetchFromAPIForYears(2018, 2019); err != nil {
+		t.Fatalf("fetchFromAPIForYears failed: %v", err)
+	}
+	before := requests()
+	if _, err := s.GetYieldsForDate("2019-07-01"); err != nil || requests() != before {
+		t.Errorf("Expected feeds from the historical fetch reused, got %d new requests (err=%v)", requests()-before, err)
+	}
+
+	// Finished years never expire; the current year does
+	thisYear := time.Now().Year()
+	s.yearFeeds[thisYear-1] = &yearFeedEntry{timestamp: time.Now().Add(-48 * time.Hour)}
+	s.yearFeeds[thisYear] = &yearFeedEntry{timestamp: time.Now().Add(-2 * cacheDuration)}
+	if _, ok := s.cachedYearFeed(thisYear - 1); !ok {
+		t.Error("Expected last year's feed to stay cached")
+	}
+	if _, ok := s.cachedYearFeed(thisYear); ok {
+		t.Error("Expected the expired current year feed to be refetched")
+	}
+}
+
+// TestCalculateCurveSpreads tests 2s10s and 3m10y spreads and inversion flags on synthetic curves
+func TestCalculateCurveSpreads(t *testing.T) {
+	curve := func(threeMonth, twoYear, tenYear float64) *models.YieldData {
+		return &models.YieldData{Yields: []models.YieldPoint{
+			{Term: "3M", Rate: threeMonth},
+			{Term: "2Y", Rate: twoYear},
+			{Term: "10Y", Rate: tenYear},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		data     *models.YieldData
+		expected models.CurveSpreads
+	}{
+		{"Normal curve", curve(4.00, 4.10, 4.28), models.CurveSpreads{TwoTen: 0.18, ThreeMonthTen: 0.28}},
+		{"Fully inverted", curve(5.45, 4.98, 4.20), models.CurveSpreads{TwoTen: -0.78, ThreeMonthTen: -1.25, Inverted: true, InvertedThreeMonthTen: true}},
+		{"Only 3m10y inverted", curve(4.50, 4.10, 4.30), models.CurveSpreads{TwoTen: 0.20, ThreeMonthTen: -0.20, InvertedThreeMonthTen: true}},
+		{"Flat is not inverted", curve(4.25, 4.25, 4.25), models.CurveSpreads{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spreads, err := calculateCurveSpreads(tt.data)
+			if err != nil {
+				t.Fatalf("calculateCurveSpreads failed: %v", err)
+			}
+			if *spreads != tt.expected {
+				t.Errorf("calculateCurveSpreads = %+v, want %+v", *spreads, tt.expected)
+			}
+		})
+	}
+
+	missing := &models.YieldData{Yields: []models.YieldPoint{{Term: "2Y", Rate: 4.10}, {Term: "10Y", Rate: 4.28}}}
+	if _, err := calculateCurveSpreads(missing); err == nil {
+		t.Error("Expected error when the 3M rate is missing")
+	}
+}
+
+// TestConvertToYieldData_IncludesSpreads tests that converted curves carry their spreads
+func TestConvertToYieldData_IncludesSpreads(t *testing.T) {
+	s := NewTreasuryService()
+	feed := &models.TreasuryFeed{
+		Entries: []models.Entry{
+			{Date: "2025-03-14T00:00:00", BC1Month: models.KnownRate(4.31), BC3Month: models.KnownRate(4.33), BC6Month: models.KnownRate(4.26), BC1Year: models.KnownRate(4.11),
+				BC2Year: models.KnownRate(4.01), BC5Year: models.KnownRate(4.05), BC10Year: models.KnownRate(4.29), BC30Year: models.KnownRate(4.61)},
+		},
+	}
+
+	data, err := s.convertToYieldData(feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	if data.Spreads == nil {
+		t.Fatal("Expected spreads on converted curve")
+	}
+	if data.Spreads.TwoTen != 0.28 || data.Spreads.ThreeMonthTen != -0.04 || data.Spreads.Inverted || !data.Spreads.InvertedThreeMonthTen {
+		t.Errorf("Unexpected spreads: %+v", *data.Spreads)
+	}
+}
+
+// TestConvertToYieldData_IncludesTwoAndFourMonthBills tests that the 2M and 4M feed columns become yield points
+func TestConvertToYieldData_IncludesTwoAndFourMonthBills(t *testing.T) {
+	var feed models.TreasuryFeed
+	if err := xml.Unmarshal([]byte(feedXML(4.2, "2025-03-14T00:00:00")), &feed); err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+
+	data, err := NewTreasuryService().convertToYieldData(&feed)
+	if err != nil {
+		t.Fatalf("convertToYieldData failed: %v", err)
+	}
+	for _, term := range []string{"2M", "4M"} {
+		if rate, found := data.RateForTerm(term); !found || rate != 4.2 {
+			t.Errorf("Expected %s rate 4.2, got %v (found %v)", term, rate, found)
+		}
+	}
+}
+
+// TestSetCacheDurations tests that cache durations must be positive
+func TestSetCacheDurations(t *testing.T) {
+	s := NewTreasuryService()
+
+	for _, d := range []time.Duration{0, -time.Minute} {
+		if err := s.SetCacheDuration(d); err == nil {
+			t.Errorf("Expected SetCacheDuration(%v) to fail", d)
+		}
+		if err := s.SetHistoricalCacheDuration(d); err == nil {
+			t.Errorf("Expected SetHistoricalCacheDuration(%v) to fail", d)
+		}
+	}
+	if s.cacheDuration != cacheDuration || s.historicalTTL != 0 {
+		t.Errorf("Expected rejected durations to leave defaults, got %v and %v", s.cacheDuration, s.historicalTTL)
+	}
+
+	if err := s.SetCacheDuration(15 * time.Minute); err != nil || s.cacheDuration != 15*time.Minute {
+		t.Errorf("Expected 15m cache duration, got %v (err=%v)", s.cacheDuration, err)
+	}
+	if err := s.SetHistoricalCacheDuration(24 * time.Hour); err != nil || s.historicalTTL != 24*time.Hour {
+		t.Errorf("Expected 24h historical cache duration, got %v (err=%v)", s.historicalTTL, err)
+	}
+}
+
+// TestGetLatestYields_CacheDuration tests that the latest curve is refetched once the configured duration passes
+func TestGetLatestYields_CacheDuration(t *testing.T) {
+	transport := &cannedTransport{}
+	s := NewTreasuryService()
+	s.SetHTTPClient(&http.Client{Transport: transport})
+	if err := s.SetCacheDuration(15 * time.Minute); err != nil {
+		t.Fatalf("SetCacheDuration failed: %v", err)
+	}
+
+	requests := func() int {
+		transport.mu.Lock()
+		defer transport.mu.Unlock()
+		return len(transport.years)
+	}
+
+	if _, err := s.GetLatestYields(); err != nil {
+		t.Fatalf("GetLatestYields failed: %v", err)
+	}
+	s.lastGoodTimestamp = time.Now().Add(-10 * time.Minute)
+	if _, err := s.GetLatestYields(); err != nil || requests() != 1 {
+		t.Fatalf("Expected a cache hit within 15m, got %d requests (err=%v)", requests(), err)
+	}
+
+	s.lastGoodTimestamp = time.Now().Add(-20 * time.Minute)
+	if _, err := s.GetLatestYields(); err != nil || requests() != 2 {
+		t.Errorf("Expected a refetch after 15m, got %d requests (err=%v)", requests(), err)
+	}
+}
+
+// TestGetHistoricalYields_CacheDuration tests historical expiry, and serving the expired entry when the refetch fails
+func TestGetHistoricalYields_CacheDuration(t *testing.T) {
+	transport := &cannedTransport{}
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	s.SetHTTPClient(&http.Client{Transport: transport})
+
+	requests := func() int {
+		transport.mu.Lock()
+		defer transport.mu.Unlock()
+		return len(transport.years)
+	}
+	backdate := func(age time.Duration) {
+		s.historicalCache["1M"].timestamp = time.Now().Add(-age)
+	}
+
+	first, err := s.GetHistoricalYields("1M")
+	if err != nil {
+		t.Fatalf("GetHistoricalYields failed: %v", err)
+	}
+	fetches := requests()
+
+	// By default entries never expire
+	backdate(30 * 24 * time.Hour)
+	if _, err := s.GetHistoricalYields("1M"); err != nil || requests() != fetches {
+		t.Fatalf("Expected a permanent cache hit, got %d requests (err=%v)", requests(), err)
+	}
+
+	if err := s.SetHistoricalCacheDuration(24 * time.Hour); err != nil {
+		t.Fatalf("SetHistoricalCacheDuration failed: %v", err)
+	}
+	refreshed, err := s.GetHistoricalYields("1M")
+	if err != nil {
+		t.Fatalf("GetHistoricalYields after expiry failed: %v", err)
+	}
+	if requests() != 2*fetches || refreshed == first {
+		t.Errorf("Expected the expired entry to be refetched, got %d requests", requests())
+	}
+	if _, err := s.GetHistoricalYields("1M"); err != nil || requests() != 2*fetches {
+		t.Errorf("Expected the refreshed entry to be cached, got %d requests (err=%v)", requests(), err)
+	}
+
+	// Upstream down: the expired entry is served rather than failing
+	s.SetHTTPClient(&http.Client{Transport: failingTransport{}})
+	backdate(48 * time.Hour)
+	data, err := s.GetHistoricalYields("1M")
+	if err != nil {
+		t.Fatalf("Expected the expired entry on upstream failure, got error: %v", err)
+	}
+	if data != refreshed {
+		t.Error("Expected the expired cached entry to be returned")
+	}
+}
+
+// TestHistoricalRefreshAt tests that a cached entry is next replaced by whichever of expiry and the
+// background refresh comes first, and that nothing is reported for an entry nothing will replace
+func TestHistoricalRefreshAt(t *testing.T) {
+	s := NewTreasuryService()
+	fetchedAt := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	s.historicalCache["1M"] = &historicalCacheEntry{data: &models.HistoricalYieldData{}, timestamp: fetchedAt}
+
+	if _, ok := s.HistoricalRefreshAt("3M", ""); ok {
+		t.Error("Expected no refresh time for an uncached period")
+	}
+	if _, ok := s.HistoricalRefreshAt("1M", ""); ok {
+		t.Error("Expected no refresh time without expiry or a background refresh")
+	}
+
+	if err := s.SetHistoricalCacheDuration(48 * time.Hour); err != nil {
+		t.Fatalf("SetHistoricalCacheDuration failed: %v", err)
+	}
+	if got, ok := s.HistoricalRefreshAt("1M", "daily"); !ok || !got.Equal(fetchedAt.Add(48*time.Hour)) {
+		t.Errorf("Expected refresh at expiry %v, got %v (ok=%v)", fetchedAt.Add(48*time.Hour), got, ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.StartHistoricalRefresh(ctx, 24*time.Hour)
+	if got, ok := s.HistoricalRefreshAt("1M", ""); !ok || !got.Equal(fetchedAt.Add(24*time.Hour)) {
+		t.Errorf("Expected refresh at the next background run %v, got %v (ok=%v)", fetchedAt.Add(24*time.Hour), got, ok)
+	}
+}
+
+// TestRefreshHistoricalCache tests that a refresh replaces every entry, and keeps entries whose refetch fails
+func TestRefreshHistoricalCache(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	s.SetHTTPClient(&http.Client{Transport: &cannedTransport{}})
+
+	if refreshed := s.RefreshHistoricalCache(); refreshed != len(HistoricalPeriods) {
+		t.Fatalf("Expected %d periods refreshed, got %d", len(HistoricalPeriods), refreshed)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	s.historicalMu.Lock()
+	for _, entry := range s.historicalCache {
+		entry.timestamp = old
+	}
+	before := s.historicalCache["1M"]
+	s.historicalMu.Unlock()
+
+	if refreshed := s.RefreshHistoricalCache(); refreshed != len(HistoricalPeriods) {
+		t.Fatalf("Expected %d periods refreshed, got %d", len(HistoricalPeriods), refreshed)
+	}
+	s.historicalMu.RLock()
+	for period, entry := range s.historicalCache {
+		if !entry.timestamp.After(old) {
+			t.Errorf("Expected %s entry to be replaced", period)
+		}
+	}
+	after := s.historicalCache["1M"]
+	s.historicalMu.RUnlock()
+	if after == before {
+		t.Error("Expected a new 1M entry")
+	}
+
+	// Upstream down: nothing is refreshed and the cached entries survive
+	s.SetHTTPClient(&http.Client{Transport: failingTransport{}})
+	if refreshed := s.RefreshHistoricalCache(); refreshed != 0 {
+		t.Errorf("Expected no periods refreshed, got %d", refreshed)
+	}
+	data, err := s.GetHistoricalYields("1M")
+	if err != nil || data != after.data {
+		t.Errorf("Expected the cached 1M entry to survive a failed refresh (err=%v)", err)
+	}
+}
+
+// blockingTransport holds every request until release is closed
+type blockingTransport struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.once.Do(func() { close(b.started) })
+	<-b.release
+	return (&cannedTransport{}).RoundTrip(req)
+}
+
+// TestRefreshHistoricalCache_OverrideResolutions tests that cached non-default resolutions are refreshed alongside the default
+func TestRefreshHistoricalCache_OverrideResolutions(t *testing.T) {
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	transport := &cannedTransport{}
+	s.SetHTTPClient(&http.Client{Transport: transport})
+
+	if _, err := s.GetHistoricalYieldsAtResolution("5Y", ResolutionDaily); err != nil {
+		t.Fatalf("GetHistoricalYieldsAtResolution failed: %v", err)
+	}
+	transport.mu.Lock()
+	transport.years = nil
+	transport.mu.Unlock()
+
+	old := time.Now().Add(-48 * time.Hour)
+	s.historicalMu.Lock()
+	s.historicalCache["5Y@daily"].timestamp = old
+	s.historicalMu.Unlock()
+
+	s.RefreshHistoricalCache()
+
+	s.historicalMu.RLock()
+	daily, defaultEntry := s.historicalCache["5Y@daily"], s.historicalCache["5Y"]
+	_, monthly := s.historicalCache["5Y@monthly"]
+	s.historicalMu.RUnlock()
+	if !daily.timestamp.After(old) || daily.data.Resolution != ResolutionDaily {
+		t.Errorf("Expected the daily 5Y entry to be refreshed at daily resolution, got %+v", daily)
+	}
+	if defaultEntry == nil || defaultEntry.data.Resolution != ResolutionWeekly {
+		t.Errorf("Expected the default 5Y entry to be refreshed weekly, got %+v", defaultEntry)
+	}
+	if monthly {
+		t.Error("Expected uncached resolutions not to be added by a refresh")
+	}
+
+	// Re-sampling reuses the period's single fetch rather than refetching per resolution
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	counts := make(map[int]int)
+	for _, year := range transport.years {
+		counts[year]++
+	}
+	if year := time.Now().Year() - 5; counts[year] != 3 {
+		t.Errorf("Expected year %d fetched once each for the 5Y, 10Y, and 30Y periods, got %d", year, counts[year])
+	}
+}
+
+// TestSampleDataPoints_Resolution tests that weekly and monthly sampling keep the last trading day of each interval
+func TestSampleDataPoints_Resolution(t *testing.T) {
+	var points []map[string]interface{}
+	for _, date := range []string{"2025-02-26", "2025-02-28", "2025-03-03", "2025-03-05", "2025-03-07", "2025-03-10"} {
+		points = append(points, map[string]interface{}{"date": date, "10Y": 4.0})
+	}
+
+	tests := []struct {
+		resolution string
+		expected   []string
+	}{
+		{ResolutionDaily, []string{"2025-02-26", "2025-02-28", "2025-03-03", "2025-03-05", "2025-03-07", "2025-03-10"}},
+		{ResolutionWeekly, []string{"2025-02-28", "2025-03-07", "2025-03-10"}},
+		{ResolutionMonthly, []string{"2025-02-28", "2025-03-10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resolution, func(t *testing.T) {
+			sampled := sampleDataPoints(points, tt.resolution)
+			var dates []string
+			for _, point := range sampled {
+				dates = append(dates, point["date"].(string))
+			}
+			if strings.Join(dates, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, dates)
+			}
+		})
+	}
+}
+
+// TestValidateHistoricalResolution tests the accepted resolutions and the daily cap on 30Y
+func TestValidateHistoricalResolution(t *testing.T) {
+	tests := []struct {
+		period     string
+		resolution string
+		valid      bool
+	}{
+		{"5Y", ResolutionDaily, true},
+		{"10Y", ResolutionDaily, true},
+		{"30Y", ResolutionDaily, false},
+		{"30Y", ResolutionWeekly, true},
+		{"1M", ResolutionMonthly, true},
+		{"1Y", "hourly", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateHistoricalResolution(tt.period, tt.resolution)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateHistoricalResolution(%s, %s) = %v, want valid=%v", tt.period, tt.resolution, err, tt.valid)
+		}
+	}
+
+	if DefaultHistoricalResolution("30Y") != ResolutionMonthly || DefaultHistoricalResolution("5Y") != ResolutionWeekly ||
+		DefaultHistoricalResolution("1Y") != ResolutionDaily {
+		t.Error("Unexpected default resolutions")
+	}
+}
+
+// TestRefreshHistoricalCache_ReadsNotBlocked tests that cached reads are served while a refresh is fetching
+func TestRefreshHistoricalCache_ReadsNotBlocked(t *testing.T) {
+	s := NewTreasuryService()
+	s.SetHTTPClient(&http.Client{Transport: &cannedTransport{}})
+	if _, err := s.GetHistoricalYields("1M"); err != nil {
+		t.Fatalf("GetHistoricalYields failed: %v", err)
+	}
+
+	transport := &blockingTransport{started: make(chan struct{}), release: make(chan struct{})}
+	s.SetHTTPClient(&http.Client{Transport: transport})
+
+	done := make(chan int)
+	go func() { done <- s.RefreshHistoricalCache() }()
+	<-transport.started
+
+	read := make(chan error)
+	go func() {
+		_, err := s.GetHistoricalYields("1M")
+		read <- err
+	}()
+	select {
+	case err := <-read:
+		if err != nil {
+			t.Errorf("Cached read failed during refresh: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cached read blocked behind an in-progress refresh")
+	}
+
+	close(transport.release)
+	select {
+	case refreshed := <-done:
+		if refreshed != len(HistoricalPeriods) {
+			t.Errorf("Expected %d periods refreshed, got %d", len(HistoricalPeriods), refreshed)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Refresh did not finish")
+	}
+}
+
+// TestStartHistoricalRefresh tests that the background refresh runs on its interval and stops on cancel
+func TestStartHistoricalRefresh(t *testing.T) {
+	s := NewTreasuryService()
+	s.SetHTTPClient(&http.Client{Transport: &cannedTransport{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.StartHistoricalRefresh(ctx, 10*time.Millisecond)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.historicalMu.RLock()
+		cached := len(s.historicalCache)
+		s.historicalMu.RUnlock()
+		if cached == len(HistoricalPeriods) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected every period cached by the background refresh, got %d", cached)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+}
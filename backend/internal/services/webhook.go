@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"modernfi-treasury-app/internal/database"
+	"modernfi-treasury-app/internal/utils"
+)
+
+const (
+	webhookTimeout        = 10 * time.Second
+	webhookRetries        = 3               // Retries after the first attempt on network errors and 5xx responses
+	webhookRetryBaseDelay = 1 * time.Second // Delay before the first retry, doubled for each one after
+
+	// LargeTransactionEventType names the event in every large transaction webhook payload
+	LargeTransactionEventType = "large_transaction"
+)
+
+// LargeTransactionEvent is the JSON payload posted to the webhook for a transaction above the threshold
+type LargeTransactionEvent struct {
+	Event      string    `json:"event"` // Always LargeTransactionEventType
+	Type       string    `json:"type"`  // fund, withdraw, buy, or sell
+	UserID     int32     `json:"user_id"`
+	Amount     float64   `json:"amount"` // Face value for buys and sells, cash for funds and withdrawals
+	Term       string    `json:"term,omitempty"`
+	HoldingID  int32     `json:"holding_id,omitempty"`
+	Threshold  float64   `json:"threshold"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// WebhookNotifier posts large transactions to a webhook for compliance review.
+// Deliveries run in the background after the transaction commits; a delivery that still fails
+// after retries is logged and dropped, never affecting the transaction itself.
+type WebhookNotifier struct {
+	url            string
+	threshold      utils.Money
+	client         *http.Client
+	retries        int
+	retryBaseDelay time.Duration
+	pending        sync.WaitGroup
+}
+
+// NewWebhookNotifier creates a notifier posting to webhookURL (http or https) for every transaction
+// whose amount exceeds threshold dollars
+func NewWebhookNotifier(webhookURL string, threshold float64) (*WebhookNotifier, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook URL must be an absolute http or https URL, got: %q", webhookURL)
+	}
+	if threshold < 0 {
+		return nil, fmt.Errorf("webhook threshold must be non-negative, got: %f", threshold)
+	}
+	thresholdMoney, err := utils.MoneyFromFloat(threshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook threshold: %w", err)
+	}
+
+	return &WebhookNotifier{
+		url:            webhookURL,
+		threshold:      thresholdMoney,
+		client:         &http.Client{Timeout: webhookTimeout},
+		retries:        webhookRetries,
+		retryBaseDelay: webhookRetryBaseDelay,
+	}, nil
+}
+
+// Notify posts event in the background if amount exceeds the threshold.
+// The event's amount, threshold, and timestamp are filled in here.
+func (n *WebhookNotifier) Notify(event LargeTransactionEvent, amount utils.Money) {
+	if amount <= n.threshold {
+		return
+	}
+	event.Event = LargeTransactionEventType
+	event.Amount = amount.Float64()
+	event.Threshold = n.threshold.Float64()
+	event.OccurredAt = time.Now().UTC()
+
+	n.pending.Add(1)
+	go func() {
+		defer n.pending.Done()
+		if err := n.deliver(event); err != nil {
+			slog.Error("Large transaction webhook delivery failed", "type", event.Type, "user_id", event.UserID,
+				"amount", event.Amount, "error", err)
+			return
+		}
+		slog.Info("Large transaction webhook delivered", "type", event.Type, "user_id", event.UserID, "amount", event.Amount)
+	}()
+}
+
+// Wait blocks until every pending delivery finishes or ctx is done, so shutdown can flush notifications
+func (n *WebhookNotifier) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver posts event, retrying network errors and 5xx responses with exponential backoff.
+// 4xx responses are not retried.
+func (n *WebhookNotifier) deliver(event LargeTransactionEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	delay := n.retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < http.StatusMultipleChoices {
+				return nil
+			}
+			err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+			if resp.StatusCode < http.StatusInternalServerError {
+				return err
+			}
+		}
+		if attempt >= n.retries {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt+1, err)
+		}
+
+		slog.Warn("Large transaction webhook delivery failed, retrying", "attempt", attempt+1, "attempts", n.retries+1,
+			"retry_in_ms", delay.Milliseconds(), "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// SetLargeTransactionWebhook sets the notifier told about committed funds, withdrawals, buys, and sells.
+// Nil (the default) disables notifications.
+func (s *TransactionService) SetLargeTransactionWebhook(n *WebhookNotifier) {
+	s.webhook = n
+}
+
+// WaitForNotifications blocks until pending webhook deliveries finish or ctx is done
+func (s *TransactionService) WaitForNotifications(ctx context.Context) error {
+	if s.webhook == nil {
+		return nil
+	}
+	return s.webhook.Wait(ctx)
+}
+
+// notifyLargeTransaction reports a committed transaction to the webhook, if one is set.
+// Call only after the database transaction commits.
+func (s *TransactionService) notifyLargeTransaction(txType database.TransactionType, userID int32, amount pgtype.Numeric, term string, holdingID int32) {
+	if s.webhook == nil {
+		return
+	}
+	money, err := utils.MoneyFromNumeric(amount)
+	if err != nil {
+		slog.Error("Cannot check transaction against webhook threshold", "type", txType, "user_id", userID, "error", err)
+		return
+	}
+	s.webhook.Notify(LargeTransactionEvent{
+		Type:      string(txType),
+		UserID:    userID,
+		Term:      term,
+		HoldingID: holdingID,
+	}, money)
+}
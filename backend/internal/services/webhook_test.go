@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"modernfi-treasury-app/internal/utils"
+)
+
+// newTestWebhook starts a webhook receiver answering with the statuses in order (200 once they run out)
+// and returns a notifier posting to it with a $1,000 threshold and no retry delay
+func newTestWebhook(t *testing.T, statuses ...int) (*WebhookNotifier, *[]LargeTransactionEvent, *atomic.Int32) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []LargeTransactionEvent
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := int(attempts.Add(1))
+		if attempt <= len(statuses) && statuses[attempt-1] != http.StatusOK {
+			w.WriteHeader(statuses[attempt-1])
+			return
+		}
+		var event LargeTransactionEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Failed to decode webhook payload: %v", err)
+		}
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	notifier, err := NewWebhookNotifier(server.URL+"/hooks/compliance", 1000)
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier failed: %v", err)
+	}
+	notifier.retryBaseDelay = 0
+	return notifier, &received, &attempts
+}
+
+// waitForDeliveries waits for the notifier's background deliveries to finish
+func waitForDeliveries(t *testing.T, n *WebhookNotifier) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Wait(ctx); err != nil {
+		t.Fatalf("Timed out waiting for webhook deliveries: %v", err)
+	}
+}
+
+// TestWebhookNotifier_Threshold tests that only amounts strictly above the threshold are posted, with the full payload
+func TestWebhookNotifier_Threshold(t *testing.T) {
+	notifier, received, _ := newTestWebhook(t)
+
+	notifier.Notify(LargeTransactionEvent{Type: "fund", UserID: 7}, utils.MoneyFromCents(100000))
+	notifier.Notify(LargeTransactionEvent{Type: "buy", UserID: 7, Term: "6M", HoldingID: 42}, utils.MoneyFromCents(100001))
+	waitForDeliveries(t, notifier)
+
+	if len(*received) != 1 {
+		t.Fatalf("Expected only the amount above the threshold to be posted, got %+v", *received)
+	}
+	event := (*received)[0]
+	if event.Event != LargeTransactionEventType || event.Type != "buy" || event.UserID != 7 || event.Term != "6M" || event.HoldingID != 42 {
+		t.Errorf("Unexpected event identity: %+v", event)
+	}
+	if event.Amount != 1000.01 || event.Threshold != 1000 {
+		t.Errorf("Expected amount 1000.01 over threshold 1000, got %.2f over %.2f", event.Amount, event.Threshold)
+	}
+	if event.OccurredAt.IsZero() {
+		t.Error("Expected occurred_at to be set")
+	}
+}
+
+// TestWebhookNotifier_Retries tests that 5xx responses are retried up to the limit and 4xx responses are not
+func TestWebhookNotifier_Retries(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		wantAttempts  int32
+		wantDelivered int
+	}{
+		{"Recovers after server errors", []int{http.StatusInternalServerError, http.StatusBadGateway}, 3, 1},
+		{"Gives up at the retry limit", []int{500, 500, 500, 500, 500}, webhookRetries + 1, 0},
+		{"Client error not retried", []int{http.StatusBadRequest}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier, received, attempts := newTestWebhook(t, tt.statuses...)
+			notifier.Notify(LargeTransactionEvent{Type: "withdraw", UserID: 1}, utils.MoneyFromCents(500000))
+			waitForDeliveries(t, notifier)
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, got)
+			}
+			if len(*received) != tt.wantDelivered {
+				t.Errorf("Expected %d deliveries, got %d", tt.wantDelivered, len(*received))
+			}
+		})
+	}
+}
+
+// TestNewWebhookNotifier_Validation tests rejection of unusable URLs and thresholds
+func TestNewWebhookNotifier_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		threshold float64
+		wantErr   bool
+	}{
+		{"https URL", "https://hooks.example.com/compliance?token=abc", 1000000, false},
+		{"zero threshold", "http://localhost:9000/hook", 0, false},
+		{"relative URL", "/hook", 1000000, true},
+		{"unsupported scheme", "ftp://hooks.example.com/hook", 1000000, true},
+		{"negative threshold", "https://hooks.example.com/hook", -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookNotifier(tt.url, tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewWebhookNotifier(%q, %v) error = %v, wantErr %v", tt.url, tt.threshold, err, tt.wantErr)
+			}
+		})
+	}
+}
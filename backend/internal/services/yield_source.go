@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"modernfi-treasury-app/internal/metrics"
+	"modernfi-treasury-app/internal/models"
+)
+
+// YieldSource supplies the daily par yield curve feed for one calendar year.
+// The service tries its sources in order, falling through to the next when one fails.
+type YieldSource interface {
+	Fetch(ctx context.Context, year int) (*models.TreasuryFeed, error)
+}
+
+// treasuryGovSource is the default YieldSource: the treasury.gov XML feed, fetched with the service's
+// URL, retry policy, and HTTP client. multiYear selects the longer-timeout client used by multi-year fetches.
+type treasuryGovSource struct {
+	s         *TreasuryService
+	multiYear bool
+}
+
+func (g treasuryGovSource) Fetch(ctx context.Context, year int) (*models.TreasuryFeed, error) {
+	client := g.s.httpClient
+	if g.multiYear {
+		client = g.s.yearsClient
+	}
+
+	resp, err := g.s.fetchWithRetry(ctx, client, fmt.Sprintf(g.s.urlTemplate, year))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch treasury data for year %d: %w", year, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("treasury API returned status %d for year %d", resp.StatusCode, year)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body for year %d: %w", year, err)
+	}
+
+	var feed models.TreasuryFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse XML for year %d: %w", year, err)
+	}
+	return &feed, nil
+}
+
+// FileYieldSource serves feeds saved in a directory, one file per year: <year>.xml in treasury.gov's
+// XML format (such as a saved response), or <year>.json in savedFeedEntry form. It backs offline
+// development and serves as a fallback through an upstream outage.
+type FileYieldSource struct {
+	dir string
+}
+
+// savedFeedEntry is one day of a <year>.json feed: rates (%) keyed by term, with null or absent
+// terms treated as unpublished, e.g. {"date": "2025-03-14", "yields": {"1M": 4.30, "30Y": null}}
+type savedFeedEntry struct {
+	Date   string              `json:"date"`
+	Yields map[string]*float64 `json:"yields"`
+}
+
+// NewFileYieldSource creates a source reading yearly feeds from dir, which must be an existing directory
+func NewFileYieldSource(dir string) (*FileYieldSource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("yield source directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("yield source path %q is not a directory", dir)
+	}
+	return &FileYieldSource{dir: dir}, nil
+}
+
+// Fetch reads the year's XML feed, or its JSON feed if there is no XML file
+func (f *FileYieldSource) Fetch(ctx context.Context, year int) (*models.TreasuryFeed, error) {
+	xmlPath := filepath.Join(f.dir, fmt.Sprintf("%d.xml", year))
+	body, err := os.ReadFile(xmlPath)
+	if err == nil {
+		var feed models.TreasuryFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse saved feed %s: %w", xmlPath, err)
+		}
+		return &feed, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read saved feed for year %d: %w", year, err)
+	}
+
+	jsonPath := filepath.Join(f.dir, fmt.Sprintf("%d.json", year))
+	body, err = os.ReadFile(jsonPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no saved feed for year %d in %s (expected %d.xml or %d.json)", year, f.dir, year, year)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved feed for year %d: %w", year, err)
+	}
+	feed, err := parseSavedJSONFeed(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse saved feed %s: %w", jsonPath, err)
+	}
+	return feed, nil
+}
+
+// parseSavedJSONFeed converts a JSON array of savedFeedEntry days into a feed
+func parseSavedJSONFeed(body []byte) (*models.TreasuryFeed, error) {
+	var saved []savedFeedEntry
+	if err := json.Unmarshal(body, &saved); err != nil {
+		return nil, err
+	}
+
+	feed := &models.TreasuryFeed{Entries: make([]models.Entry, 0, len(saved))}
+	for i, day := range saved {
+		if day.Date == "" {
+			return nil, fmt.Errorf("entry %d: date is required", i)
+		}
+		entry := models.Entry{Date: day.Date}
+		for term, rate := range day.Yields {
+			field := entry.TermRate(term)
+			if field == nil {
+				return nil, fmt.Errorf("entry %d: unknown term %q", i, term)
+			}
+			if rate != nil {
+				*field = models.KnownRate(*rate)
+			}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed, nil
+}
+
+// TreasuryGovSource returns the service's treasury.gov source, for listing alongside fallbacks in SetYieldSources
+func (s *TreasuryService) TreasuryGovSource() YieldSource {
+	return treasuryGovSource{s: s}
+}
+
+// SetYieldSources sets the sources yields are fetched from, in the order they are tried.
+// The default is treasury.gov alone.
+func (s *TreasuryService) SetYieldSources(sources ...YieldSource) error {
+	if len(sources) == 0 {
+		return errors.New("at least one yield source is required")
+	}
+	s.sources = sources
+
+	// Feeds cached from the previous sources may differ from what the new ones serve
+	s.yearFeedsMu.Lock()
+	clear(s.yearFeeds)
+	s.yearFeedsMu.Unlock()
+	return nil
+}
+
+// fetchYear fetches year from the yield sources and stores a copy in the per-year feed cache, so the
+// latest, historical, and refresh fetches (which always go upstream) leave feeds cachedYearFeed can reuse
+func (s *TreasuryService) fetchYear(ctx context.Context, year int, multiYear bool) (*models.TreasuryFeed, error) {
+	feed, err := s.fetchYearFromSources(ctx, year, multiYear)
+	if err != nil {
+		return nil, err
+	}
+
+	s.yearFeedsMu.Lock()
+	s.yearFeeds[year] = &yearFeedEntry{entries: slices.Clone(feed.Entries), timestamp: time.Now()}
+	s.yearFeedsMu.Unlock()
+	return feed, nil
+}
+
+// cachedYearFeed returns a copy of year's entries from the per-year feed cache, if fresh.
+// A finished year's feed no longer changes, so it never expires; the current year's expires after the
+// latest-yields cache duration so new daily curves are picked up.
+func (s *TreasuryService) cachedYearFeed(year int) ([]models.Entry, bool) {
+	s.yearFeedsMu.RLock()
+	entry, ok := s.yearFeeds[year]
+	s.yearFeedsMu.RUnlock()
+	if !ok || (year >= time.Now().Year() && time.Since(entry.timestamp) >= s.cacheDuration) {
+		s.metrics.CacheLookups.Inc(metrics.CacheYearFeed, metrics.ResultMiss)
+		return nil, false
+	}
+	s.metrics.CacheLookups.Inc(metrics.CacheYearFeed, metrics.ResultHit)
+	return slices.Clone(entry.entries), true
+}
+
+// fetchYearFromSources fetches year from each source in turn, returning the first feed fetched successfully.
+// multiYear gives treasury.gov its multi-year client. If every source fails, their errors are joined.
+func (s *TreasuryService) fetchYearFromSources(ctx context.Context, year int, multiYear bool) (*models.TreasuryFeed, error) {
+	var errs []error
+	for i, source := range s.sources {
+		if g, ok := source.(treasuryGovSource); ok {
+			g.multiYear = multiYear
+			source = g
+		}
+
+		feed, err := source.Fetch(ctx, year)
+		if err == nil {
+			if i > 0 {
+				slog.WarnContext(ctx, "Yields served from fallback source", "source", fmt.Sprintf("%T", source), "year", year)
+			}
+			return feed, nil
+		}
+		errs = append(errs, err)
+
+		// A cancelled or expired fetch would fail at every remaining source too
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(s.sources)-1 {
+			slog.WarnContext(ctx, "Yield source failed, trying next", "source", fmt.Sprintf("%T", source), "year", year, "error", err)
+		}
+	}
+	return nil, errors.Join(errs...)
+}
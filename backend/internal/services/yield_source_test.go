@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"modernfi-treasury-app/internal/models"
+)
+
+// stubSource is a YieldSource returning a fixed feed or error and counting its calls
+type stubSource struct {
+	feed  *models.TreasuryFeed
+	err   error
+	calls int
+}
+
+func (s *stubSource) Fetch(ctx context.Context, year int) (*models.TreasuryFeed, error) {
+	s.calls++
+	return s.feed, s.err
+}
+
+// writeSavedFeed saves a one-entry feed at rate for year into dir, as FileYieldSource expects
+func writeSavedFeed(t *testing.T, dir string, year int, rate float64) {
+	t.Helper()
+	path := filepath.Join(dir, fmt.Sprintf("%d.xml", year))
+	if err := os.WriteFile(path, []byte(feedXML(rate, fmt.Sprintf("%d-01-02T00:00:00", year))), 0o644); err != nil {
+		t.Fatalf("Failed to write saved feed: %v", err)
+	}
+}
+
+// TestFetchYear_FallsThroughSources tests that sources are tried in order until one succeeds
+func TestFetchYear_FallsThroughSources(t *testing.T) {
+	s := NewTreasuryService()
+	feed := &models.TreasuryFeed{Entries: []models.Entry{{Date: "2025-01-02T00:00:00"}}}
+	down := &stubSource{err: errors.New("upstream down")}
+	backup := &stubSource{feed: feed}
+	unused := &stubSource{err: errors.New("never reached")}
+	if err := s.SetYieldSources(down, backup, unused); err != nil {
+		t.Fatalf("SetYieldSources failed: %v", err)
+	}
+
+	got, err := s.fetchYear(context.Background(), 2025, false)
+	if err != nil {
+		t.Fatalf("fetchYear failed: %v", err)
+	}
+	if got != feed {
+		t.Error("Expected the backup source's feed")
+	}
+	if down.calls != 1 || backup.calls != 1 || unused.calls != 0 {
+		t.Errorf("Expected calls 1/1/0, got %d/%d/%d", down.calls, backup.calls, unused.calls)
+	}
+}
+
+// TestFetchYear_AllSourcesFail tests that every source's error is reported, and an expired context stops the chain
+func TestFetchYear_AllSourcesFail(t *testing.T) {
+	s := NewTreasuryService()
+	first := &stubSource{err: errors.New("first down")}
+	second := &stubSource{err: errors.New("second down")}
+	if err := s.SetYieldSources(first, second); err != nil {
+		t.Fatalf("SetYieldSources failed: %v", err)
+	}
+
+	_, err := s.fetchYear(context.Background(), 2025, false)
+	if err == nil || !strings.Contains(err.Error(), "first down") || !strings.Contains(err.Error(), "second down") {
+		t.Errorf("Expected both source errors, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	first.calls, second.calls = 0, 0
+	if _, err := s.fetchYear(ctx, 2025, false); err == nil {
+		t.Error("Expected error with a cancelled context")
+	}
+	if first.calls != 1 || second.calls != 0 {
+		t.Errorf("Expected the chain to stop after a cancelled fetch, got calls %d/%d", first.calls, second.calls)
+	}
+
+	if err := s.SetYieldSources(); err == nil {
+		t.Error("Expected error for an empty source list")
+	}
+}
+
+// TestGetLatestYields_FileFallback tests that saved feeds are served when treasury.gov fails
+func TestGetLatestYields_FileFallback(t *testing.T) {
+	dir := t.TempDir()
+	year := time.Now().Year()
+	writeSavedFeed(t, dir, year, 3.75)
+
+	fileSource, err := NewFileYieldSource(dir)
+	if err != nil {
+		t.Fatalf("NewFileYieldSource failed: %v", err)
+	}
+
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	s.SetHTTPClient(&http.Client{Transport: failingTransport{}})
+	if err := s.SetYieldSources(s.TreasuryGovSource(), fileSource); err != nil {
+		t.Fatalf("SetYieldSources failed: %v", err)
+	}
+
+	data, err := s.GetLatestYields()
+	if err != nil {
+		t.Fatalf("Expected yields from the saved feed, got error: %v", err)
+	}
+	if len(data.Yields) == 0 || data.Yields[0].Rate != 3.75 {
+		t.Errorf("Expected saved rate 3.75, got %+v", data.Yields)
+	}
+}
+
+// TestFetchFromAPIForYears_FileFallbackPerYear tests that each year falls back on its own,
+// so a year treasury.gov serves is not replaced by the saved copy
+func TestFetchFromAPIForYears_FileFallbackPerYear(t *testing.T) {
+	dir := t.TempDir()
+	writeSavedFeed(t, dir, 2021, 1.25)
+	writeSavedFeed(t, dir, 2022, 1.25)
+
+	s := NewTreasuryService()
+	s.fetchRetries = 0
+	newYearServer(t, s, func(w http.ResponseWriter, r *http.Request, year int) {
+		if year == 2021 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, feedXML(4.0, fmt.Sprintf("%d-01-02T00:00:00", year)))
+	})
+
+	fileSource, err := NewFileYieldSource(dir)
+	if err != nil {
+		t.Fatalf("NewFileYieldSource failed: %v", err)
+	}
+	if err := s.SetYieldSources(s.TreasuryGovSource(), fileSource); err != nil {
+		t.Fatalf("SetYieldSources failed: %v", err)
+	}
+
+	feed, err := s.fetchFromAPIForYears(2021, 2022)
+	if err != nil {
+		t.Fatalf("fetchFromAPIForYears failed: %v", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Expected one entry per year, got %d", len(feed.Entries))
+	}
+	if got := feed.Entries[0].BC1Month.Rate; got != 1.25 {
+		t.Errorf("Expected 2021 from the saved feed (1.25), got %v", got)
+	}
+	if got := feed.Entries[1].BC1Month.Rate; got != 4.0 {
+		t.Errorf("Expected 2022 from treasury.gov (4.0), got %v", got)
+	}
+}
+
+// TestNewFileYieldSource tests directory validation and the error for a year with no saved feed
+func TestNewFileYieldSource(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileYieldSource(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected error for a missing directory")
+	}
+
+	file := filepath.Join(dir, "2025.xml")
+	writeSavedFeed(t, dir, 2025, 4.0)
+	if _, err := NewFileYieldSource(file); err == nil {
+		t.Error("Expected error for a file path")
+	}
+
+	source, err := NewFileYieldSource(dir)
+	if err != nil {
+		t.Fatalf("NewFileYieldSource failed: %v", err)
+	}
+	if _, err := source.Fetch(context.Background(), 2024); err == nil {
+		t.Error("Expected error for a year with no saved feed")
+	}
+}
+
+// TestFileYieldSource_JSON tests JSON feeds: null and omitted terms are unpublished, unknown terms are rejected
+func TestFileYieldSource_JSON(t *testing.T) {
+	dir := t.TempDir()
+	write := func(year int, body string) {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.json", year)), []byte(body), 0o644); err != nil {
+			t.Fatalf("Failed to write saved feed: %v", err)
+		}
+	}
+	write(2025, `[{"date": "2025-03-14", "yields": {"1M": 4.30, "10Y": 4.25, "30Y": null}}]`)
+	write(2024, `[{"date": "2024-03-14", "yields": {"7M": 4.30}}]`)
+
+	source, err := NewFileYieldSource(dir)
+	if err != nil {
+		t.Fatalf("NewFileYieldSource failed: %v", err)
+	}
+
+	feed, err := source.Fetch(context.Background(), 2025)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(feed.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(feed.Entries))
+	}
+	entry := feed.Entries[0]
+	if entry.Date != "2025-03-14" || entry.BC1Month != models.KnownRate(4.30) || entry.BC10Year != models.KnownRate(4.25) {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.BC30Year.Valid || entry.BC2Year.Valid {
+		t.Errorf("Expected null and omitted terms to be unpublished, got 30Y %+v and 2Y %+v", entry.BC30Year, entry.BC2Year)
+	}
+
+	if _, err := source.Fetch(context.Background(), 2024); err == nil || !strings.Contains(err.Error(), `unknown term "7M"`) {
+		t.Errorf("Expected unknown term error, got %v", err)
+	}
+}
+
+// TestGetLatestYields_OfflineFileSource tests that a file source alone serves the latest curve without any HTTP request
+func TestGetLatestYields_OfflineFileSource(t *testing.T) {
+	dir := t.TempDir()
+	year := time.Now().Year()
+	writeSavedFeed(t, dir, year, 4.10)
+
+	source, err := NewFileYieldSource(dir)
+	if err != nil {
+		t.Fatalf("NewFileYieldSource failed: %v", err)
+	}
+
+	s := NewTreasuryService()
+	s.SetHTTPClient(&http.Client{Transport: failingTransport{}}) // Any network use would fail the fetch
+	if err := s.SetYieldSources(source); err != nil {
+		t.Fatalf("SetYieldSources failed: %v", err)
+	}
+
+	data, err := s.GetLatestYields()
+	if err != nil {
+		t.Fatalf("GetLatestYields failed: %v", err)
+	}
+	if rate, ok := data.RateForTerm("6M"); !ok || rate != 4.10 {
+		t.Errorf("Expected 6M at 4.10 from the saved feed, got %v (found %v)", rate, ok)
+	}
+	if !s.UpstreamStatus().Ready() {
+		t.Error("Expected a successful offline fetch to count as ready")
+	}
+}
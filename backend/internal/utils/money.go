@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Money is a dollar amount held as whole cents, so amounts add, subtract, and compare exactly.
+// It marshals to and from JSON as a decimal number of dollars (12.34), matching NUMERIC(12,2) columns.
+// Pricing formulas (discounts, accrual, compounding) may still run in float64, but each result is
+// rounded to Money once; balances, prices, and proceeds are then compared, added, and stored exactly.
+type Money int64
+
+// MoneyFromCents returns the amount of the given number of cents
+func MoneyFromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// ParseCents converts a client-supplied number of cents to Money, rejecting amounts outside the range
+// MoneyFromFloat and ParseMoney accept so they fail validation rather than overflowing a NUMERIC column
+func ParseCents(cents int64) (Money, error) {
+	if cents > maxExactCents || cents < -maxExactCents {
+		return 0, fmt.Errorf("amount of %d cents is too large", cents)
+	}
+	return Money(cents), nil
+}
+
+// MoneyFromFloat converts a dollar amount to Money, rounding to the nearest cent like FloatToNumeric.
+// NaN, infinities, and amounts too large to hold whole cents exactly are rejected.
+func MoneyFromFloat(f float64) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("cannot convert %v to money", f)
+	}
+
+	cents := math.Round(f * 100)
+	if math.Abs(cents) > maxExactCents {
+		return 0, fmt.Errorf("amount %.2f is too large to convert exactly", f)
+	}
+	return Money(cents), nil
+}
+
+// MoneyFromNumeric converts a numeric to Money without going through float64.
+// NULL, NaN, infinite, and fractional-cent numerics are rejected rather than rounded.
+func MoneyFromNumeric(n pgtype.Numeric) (Money, error) {
+	if !n.Valid {
+		return 0, errors.New("numeric is null")
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return 0, errors.New("numeric is not a finite number")
+	}
+
+	// Rescale the digits to a cent exponent of -2
+	cents := new(big.Int).Set(n.Int)
+	if n.Exp > -2 {
+		cents.Mul(cents, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp+2)), nil))
+	} else if n.Exp < -2 {
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-2-n.Exp)), nil)
+		var remainder big.Int
+		cents.QuoRem(cents, divisor, &remainder)
+		if remainder.Sign() != 0 {
+			return 0, fmt.Errorf("amount %se%d has fractional cents", n.Int, n.Exp)
+		}
+	}
+
+	if cents.CmpAbs(big.NewInt(maxExactCents)) > 0 {
+		return 0, errors.New("amount is too large to convert exactly")
+	}
+	return Money(cents.Int64()), nil
+}
+
+// ParseMoney parses a decimal dollar string such as "12.34" or "-0.5" exactly.
+// At most two decimal places are accepted; exponents and thousands separators are not.
+func ParseMoney(s string) (Money, error) {
+	invalid := fmt.Errorf("invalid amount %q", s)
+
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return 0, invalid
+	}
+	if len(frac) > 2 {
+		// Trailing zeros past the cents ("1.500") don't change the amount
+		frac = frac[:2] + strings.TrimRight(frac[2:], "0")
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has fractional cents", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	cents, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || cents > maxExactCents {
+		return 0, fmt.Errorf("amount %q is too large", s)
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+// isDigits reports whether s contains only ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cents returns the amount in whole cents
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return -m
+}
+
+// Prorate returns m × part / whole, rounded half away from zero to the nearest cent.
+// The product is computed exactly, so large amounts don't lose cents. whole must be non-zero.
+func (m Money) Prorate(part, whole Money) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(part)))
+	divisor := big.NewInt(int64(whole))
+
+	var remainder big.Int
+	quotient, _ := new(big.Int).QuoRem(product, divisor, &remainder)
+	// Round away from zero when the remainder is at least half the divisor
+	if new(big.Int).Mul(new(big.Int).Abs(&remainder), big.NewInt(2)).CmpAbs(divisor) >= 0 {
+		if product.Sign()*divisor.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return Money(quotient.Int64())
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+// Float64 returns the amount in dollars for pricing math and logging
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// Numeric returns the amount as a numeric with exactly two decimal places
+func (m Money) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -2, Valid: true}
+}
+
+// String formats the amount in dollars with two decimal places, e.g. "1234.56" or "-0.05"
+func (m Money) String() string {
+	cents := int64(m)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON encodes the amount as a JSON number of dollars with two decimal places
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number of dollars exactly, without rounding through float64.
+// A fractional cent is an error. JSON null leaves the amount unchanged.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,289 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TestMoneyFromFloat tests rounding to the nearest cent and rejection of unrepresentable amounts
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    float64
+		expected Money
+		wantErr  bool
+	}{
+		{"Whole dollars", 100, 10000, false},
+		{"Cents", 1234.56, 123456, false},
+		{"Float sum noise", 0.1 + 0.2, 30, false},
+		{"Rounds sub-cent up", 0.126, 13, false},
+		{"Exact half away from zero", 0.125, 13, false},
+		{"Negative", -50.255, -5026, false},
+		{"Negative zero", -0.001, 0, false},
+		{"NaN", math.NaN(), 0, true},
+		{"Positive infinity", math.Inf(1), 0, true},
+		{"Too large for exact cents", 1e17, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyFromFloat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MoneyFromFloat(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("MoneyFromFloat(%v) = %d cents, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMoneyFromNumeric tests exact conversion across scales and the error paths
+func TestMoneyFromNumeric(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    pgtype.Numeric
+		expected Money
+		wantErr  bool
+	}{
+		{"Two decimal places", scanNumeric(t, "1234.56"), 123456, false},
+		{"Whole dollars without cents", scanNumeric(t, "100"), 10000, false},
+		{"One decimal place", scanNumeric(t, "0.5"), 50, false},
+		{"Trailing zeros past cents", scanNumeric(t, "19.990"), 1999, false},
+		{"Negative", scanNumeric(t, "-50.25"), -5025, false},
+		{"Column maximum NUMERIC(12,2)", scanNumeric(t, "9999999999.99"), 999999999999, false},
+		{"Fractional cent", scanNumeric(t, "10.005"), 0, true},
+		{"Too large", scanNumeric(t, "100000000000000000"), 0, true},
+		{"Null", pgtype.Numeric{}, 0, true},
+		{"NaN", pgtype.Numeric{NaN: true, Valid: true}, 0, true},
+		{"Infinity", pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyFromNumeric(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MoneyFromNumeric() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("MoneyFromNumeric() = %d cents, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseMoney tests exact decimal parsing and the formats it refuses
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Money
+		wantErr  bool
+	}{
+		{"12.34", 1234, false},
+		{"12.3", 1230, false},
+		{"12", 1200, false},
+		{"0.01", 1, false},
+		{"-0.05", -5, false},
+		{"-0", 0, false},
+		{"1.500", 150, false},
+		{"9999999999.99", 999999999999, false},
+		{"1.005", 0, true},
+		{"1e3", 0, true},
+		{"1,000.00", 0, true},
+		{".50", 0, true},
+		{"5.", 0, true},
+		{"", 0, true},
+		{"-", 0, true},
+		{"+5", 0, true},
+		{"abc", 0, true},
+		{"99999999999999999999", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseMoney(%q) = %d cents, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseCents tests that client-supplied cents are accepted up to the exact range and rejected beyond it
+func TestParseCents(t *testing.T) {
+	tests := []struct {
+		cents   int64
+		wantErr bool
+	}{
+		{1234, false},
+		{0, false},
+		{-5, false},
+		{1 << 53, false},
+		{-(1 << 53), false},
+		{1<<53 + 1, true},
+		{-(1<<53 + 1), true},
+		{math.MaxInt64, true},
+		{math.MinInt64, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCents(tt.cents)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCents(%d) error = %v, wantErr %v", tt.cents, err, tt.wantErr)
+		}
+		if !tt.wantErr && got.Cents() != tt.cents {
+			t.Errorf("ParseCents(%d) = %d cents", tt.cents, got.Cents())
+		}
+	}
+}
+
+// TestMoneyArithmetic tests that sums and differences stay exact where float64 drifts
+func TestMoneyArithmetic(t *testing.T) {
+	tenCents := MoneyFromCents(10)
+	twentyCents := MoneyFromCents(20)
+
+	if sum := tenCents.Add(twentyCents); sum != MoneyFromCents(30) {
+		t.Errorf("0.10 + 0.20 = %s, want 0.30", sum)
+	}
+	if diff := tenCents.Sub(twentyCents); diff != MoneyFromCents(-10) {
+		t.Errorf("0.10 - 0.20 = %s, want -0.10", diff)
+	}
+	if neg := twentyCents.Neg(); neg.Cents() != -20 {
+		t.Errorf("Neg(0.20) = %d cents, want -20", neg.Cents())
+	}
+
+	// A thousand $0.10 deposits land on exactly $100.00
+	var total Money
+	for i := 0; i < 1000; i++ {
+		total = total.Add(tenCents)
+	}
+	if total != MoneyFromCents(10000) {
+		t.Errorf("1000 x 0.10 = %s, want 100.00", total)
+	}
+
+	if !tenCents.IsPositive() || MoneyFromCents(0).IsPositive() || tenCents.Neg().IsPositive() {
+		t.Error("IsPositive should hold only for amounts above zero")
+	}
+	if f := MoneyFromCents(123456).Float64(); f != 1234.56 {
+		t.Errorf("Float64() = %v, want 1234.56", f)
+	}
+}
+
+// TestMoneyProrate tests exact pro-rating with half-away-from-zero rounding, including products past int64
+func TestMoneyProrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Money
+		part     Money
+		whole    Money
+		expected Money
+	}{
+		{"Half of a purchase price", 977500, 500000, 1000000, 488750},
+		{"Whole amount of a $9,999,999.99 bill", 977499999, 999999999, 999999999, 977499999},
+		{"One cent of a $9,999,999.99 bill", 977499999, 1, 999999999, 1},
+		{"Product overflows int64", 999999999999, 999999999999, 999999999999, 999999999999},
+		{"Half a cent rounds up", 1, 1, 2, 1},
+		{"Negative half a cent rounds away from zero", -1, 1, 2, -1},
+		{"A third of a cent rounds down", 1, 1, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Prorate(tt.part, tt.whole); got != tt.expected {
+				t.Errorf("%s.Prorate(%s, %s) = %s, want %s", tt.amount, tt.part, tt.whole, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMoneyString tests two-decimal formatting, including negative amounts under a dollar
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		input    Money
+		expected string
+	}{
+		{0, "0.00"},
+		{1, "0.01"},
+		{123456, "1234.56"},
+		{10000, "100.00"},
+		{-5, "-0.05"},
+		{-5025, "-50.25"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.String(); got != tt.expected {
+			t.Errorf("String() of %d cents = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// TestMoneyNumeric tests that the numeric form keeps exactly two decimal places and round trips
+func TestMoneyNumeric(t *testing.T) {
+	for _, cents := range []int64{0, 1, 1999, 10000, -25075, 999999999999} {
+		m := MoneyFromCents(cents)
+		n := m.Numeric()
+		if n.Exp != -2 || !n.Valid {
+			t.Errorf("Numeric() of %s = %se%d, want scale 2", m, n.Int, n.Exp)
+		}
+		data, err := n.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON() failed: %v", err)
+		}
+		if string(data) != m.String() {
+			t.Errorf("Numeric() of %s serializes as %s", m, data)
+		}
+		back, err := MoneyFromNumeric(n)
+		if err != nil || back != m {
+			t.Errorf("Round trip of %s = %s, %v", m, back, err)
+		}
+	}
+}
+
+// TestMoneyJSON tests that Money encodes as a dollar number and decodes without float rounding
+func TestMoneyJSON(t *testing.T) {
+	type payload struct {
+		Amount Money `json:"amount"`
+	}
+
+	data, err := json.Marshal(payload{Amount: MoneyFromCents(1000050)})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"amount":10000.50}` {
+		t.Errorf("Marshal = %s, want {\"amount\":10000.50}", data)
+	}
+
+	tests := []struct {
+		body     string
+		expected Money
+		wantErr  bool
+	}{
+		{`{"amount": 10000.50}`, 1000050, false},
+		{`{"amount": 0.3}`, 30, false},
+		{`{"amount": 25}`, 2500, false},
+		{`{"amount": null}`, 0, false},
+		{`{}`, 0, false},
+		{`{"amount": 10.005}`, 0, true},
+		{`{"amount": 1e2}`, 0, true},
+		{`{"amount": "10.00"}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			var p payload
+			err := json.Unmarshal([]byte(tt.body), &p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+			}
+			if !tt.wantErr && p.Amount != tt.expected {
+				t.Errorf("Unmarshal(%s) = %s, want %s", tt.body, p.Amount, tt.expected)
+			}
+		})
+	}
+}
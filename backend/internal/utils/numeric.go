@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Largest cent count a float64 represents exactly (2^53); beyond it, cents can't be trusted.
+// Money accepts no larger magnitude either, so arithmetic on two amounts can't overflow.
+const maxExactCents = 1 << 53
+
+// FloatToNumeric converts a dollar amount to a numeric with exactly two decimal places.
+// The amount is rounded to the nearest cent as math.Round(f*100), halves away from zero.
+// f*100 is itself rounded, so amounts within float error of a half cent may land either way
+// (2.675 becomes 2.68, 1.005 becomes 1.00). Negative zero becomes 0.00.
+// NaN, infinities, and amounts too large to hold whole cents exactly are rejected.
+func FloatToNumeric(f float64) (pgtype.Numeric, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return pgtype.Numeric{}, fmt.Errorf("cannot convert %v to numeric", f)
+	}
+
+	cents := math.Round(f * 100)
+	if math.Abs(cents) > maxExactCents {
+		return pgtype.Numeric{}, fmt.Errorf("amount %.2f is too large to convert exactly", f)
+	}
+
+	return pgtype.Numeric{Int: big.NewInt(int64(cents)), Exp: -2, Valid: true}, nil
+}
+
+// NumericToFloat converts a numeric to float64 for pricing and comparisons.
+// NULL numerics are an error rather than silently reading as zero.
+func NumericToFloat(n pgtype.Numeric) (float64, error) {
+	if !n.Valid {
+		return 0, errors.New("numeric is null")
+	}
+
+	f, err := n.Float64Value()
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric: %w", err)
+	}
+	if !f.Valid || math.IsNaN(f.Float64) || math.IsInf(f.Float64, 0) {
+		return 0, errors.New("numeric is not a finite number")
+	}
+	return f.Float64, nil
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// These tests pin down how pgtype.Numeric and the "%.2f" formatting used to build numerics
+// behave for values that have caused trouble. Cases marked as precision loss are the
+// limits of routing money through float64.
+
+// scanNumeric parses s into a numeric, failing the test on error
+func scanNumeric(t *testing.T, s string) pgtype.Numeric {
+	t.Helper()
+	n := pgtype.Numeric{}
+	if err := n.Scan(s); err != nil {
+		t.Fatalf("Scan(%q) failed: %v", s, err)
+	}
+	return n
+}
+
+// TestNumericRoundTrip tests Scan, Float64Value, and JSON serialization for edge-case amounts
+func TestNumericRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedFloat float64
+		expectedFixed string // fmt.Sprintf("%.2f", Float64Value)
+		expectedJSON  string // MarshalJSON keeps the decimal digits as scanned
+	}{
+		{"Column maximum NUMERIC(12,2)", "9999999999.99", 9999999999.99, "9999999999.99", "9999999999.99"},
+		// Precision loss: 19 significant digits don't fit in a float64
+		{"Beyond float64 precision", "12345678901234567.89", 12345678901234568, "12345678901234568.00", "12345678901234567.89"},
+		{"Trailing zero cent", "100.10", 100.1, "100.10", "100.10"},
+		{"Whole dollars with cents", "100.00", 100, "100.00", "100.00"},
+		// Without a scale, the numeric stores 1e2 and serializes without cents
+		{"Whole dollars without cents", "100", 100, "100.00", "100"},
+		{"Smallest cent", "0.01", 0.01, "0.01", "0.01"},
+		{"Negative amount", "-50.25", -50.25, "-50.25", "-50.25"},
+		{"Negative zero", "-0.00", 0, "0.00", "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := scanNumeric(t, tt.input)
+
+			f, err := n.Float64Value()
+			if err != nil || !f.Valid {
+				t.Fatalf("Float64Value() = %v, %v", f, err)
+			}
+			if f.Float64 != tt.expectedFloat {
+				t.Errorf("Float64Value() = %v, want %v", f.Float64, tt.expectedFloat)
+			}
+			if got := fmt.Sprintf("%.2f", f.Float64); got != tt.expectedFixed {
+				t.Errorf("%%.2f = %s, want %s", got, tt.expectedFixed)
+			}
+
+			data, err := n.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() failed: %v", err)
+			}
+			if string(data) != tt.expectedJSON {
+				t.Errorf("MarshalJSON() = %s, want %s", data, tt.expectedJSON)
+			}
+
+			// Re-scanning the JSON form yields the same digits and scale
+			again := scanNumeric(t, string(data))
+			if again.Int.Cmp(n.Int) != 0 || again.Exp != n.Exp {
+				t.Errorf("Round trip changed value: %se%d -> %se%d", n.Int, n.Exp, again.Int, again.Exp)
+			}
+		})
+	}
+}
+
+// TestFixedFormattingOfHalves tests how "%.2f" rounds values halfway between cents.
+// Go rounds the exact binary value, so decimal halves round in either direction depending on
+// representation error, and exactly representable halves round to even. Neither matches
+// the half-up rounding a reader would expect from a decimal amount.
+func TestFixedFormattingOfHalves(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    float64
+		expected string
+	}{
+		// Stored just below the half, rounds down
+		{"2.675 rounds down", 2.675, "2.67"},
+		{"1.005 rounds down", 1.005, "1.00"},
+		// Exactly representable halves round to even
+		{"0.125 rounds to even (down)", 0.125, "0.12"},
+		{"0.375 rounds to even (up)", 0.375, "0.38"},
+		// Negative halves mirror positive ones; tiny negatives keep their sign
+		{"-0.005 rounds away from zero", -0.005, "-0.01"},
+		{"-0.001 formats as negative zero", -0.001, "-0.00"},
+		// Rounding can carry past the NUMERIC(12,2) column maximum
+		{"Half cent above column maximum", 9999999999.995, "10000000000.00"},
+		{"Float sum noise is hidden", 0.1 + 0.2, "0.30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprintf("%.2f", tt.input); got != tt.expected {
+				t.Errorf("%%.2f of %v = %s, want %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNumericScanRejectsNonDecimal tests strings Scan does not accept as numerics
+func TestNumericScanRejectsNonDecimal(t *testing.T) {
+	for _, input := range []string{"1e3", "abc", "", "1,000.00"} {
+		n := pgtype.Numeric{}
+		if err := n.Scan(input); err == nil {
+			t.Errorf("Scan(%q) = %v, expected error", input, n)
+		}
+	}
+}
+
+// TestFloatToNumeric tests rounding to cents and rejection of values that can't be represented
+func TestFloatToNumeric(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    float64
+		expected string
+		wantErr  bool
+	}{
+		{"Whole dollars", 100, "100.00", false},
+		{"Cents", 1234.56, "1234.56", false},
+		{"Rounds sub-cent up", 0.126, "0.13", false},
+		{"Rounds sub-cent down", 0.124, "0.12", false},
+		// Exact binary half rounds away from zero, unlike "%.2f"
+		{"Exact half away from zero", 0.125, "0.13", false},
+		// Scaling by 100 rounds 2.675 up to exactly 267.5, but 1.005 stays below 100.5
+		{"Inexact half scaled onto the half", 2.675, "2.68", false},
+		{"Inexact half scaled below the half", 1.005, "1.00", false},
+		{"Float sum noise", 0.1 + 0.2, "0.30", false},
+		{"Negative", -50.255, "-50.26", false},
+		{"Negative zero", -0.001, "0.00", false},
+		{"Column maximum", 9999999999.99, "9999999999.99", false},
+		{"NaN", math.NaN(), "", true},
+		{"Positive infinity", math.Inf(1), "", true},
+		{"Negative infinity", math.Inf(-1), "", true},
+		{"Too large for exact cents", 1e17, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FloatToNumeric(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FloatToNumeric(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := got.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() failed: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("FloatToNumeric(%v) = %s, want %s", tt.input, data, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNumericToFloat tests conversion and the null and non-finite error paths
+func TestNumericToFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    pgtype.Numeric
+		expected float64
+		wantErr  bool
+	}{
+		{"Decimal", scanNumeric(t, "1234.56"), 1234.56, false},
+		{"Negative", scanNumeric(t, "-50.25"), -50.25, false},
+		{"Zero", scanNumeric(t, "0.00"), 0, false},
+		{"Null", pgtype.Numeric{}, 0, true},
+		{"NaN", pgtype.Numeric{NaN: true, Valid: true}, 0, true},
+		{"Infinity", pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NumericToFloat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NumericToFloat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("NumericToFloat() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFloatToNumeric_RoundTrip tests that converting to numeric and back preserves cent amounts
+func TestFloatToNumeric_RoundTrip(t *testing.T) {
+	for _, amount := range []float64{0.01, 19.99, 100.10, 9876.54, -250.75} {
+		n, err := FloatToNumeric(amount)
+		if err != nil {
+			t.Fatalf("FloatToNumeric(%v) failed: %v", amount, err)
+		}
+		got, err := NumericToFloat(n)
+		if err != nil {
+			t.Fatalf("NumericToFloat() failed: %v", err)
+		}
+		if got != amount {
+			t.Errorf("Round trip of %v = %v", amount, got)
+		}
+	}
+}
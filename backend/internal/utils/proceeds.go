@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BillSaleProceeds values selling amount of face from a bill held daysHeld of its termDays days, returning the
+// proceeds and the discount earned on top of the amount's share of the purchase price.
+// The amount's share of the purchase price accretes toward face value over the term under accrual.
+// Legacy holdings without a recorded face value and purchase price are redeemed at face and report no discount earned.
+func BillSaleProceeds(faceValue, purchasePrice pgtype.Numeric, termDays int, amount Money, daysHeld int, accrual BillAccrual) (Money, Money, error) {
+	if !faceValue.Valid || !purchasePrice.Valid {
+		return amount, 0, nil
+	}
+
+	face, err := MoneyFromNumeric(faceValue)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid face value for bill holding: %w", err)
+	}
+	price, err := MoneyFromNumeric(purchasePrice)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid purchase price for bill holding: %w", err)
+	}
+	if !face.IsPositive() || !price.IsPositive() {
+		return amount, 0, nil
+	}
+
+	cost := price.Prorate(amount, face)
+	accreted, err := CalculateBillAccretedValueDays(amount.Float64(), cost.Float64(), termDays, daysHeld, accrual)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to calculate bill sale proceeds: %w", err)
+	}
+	proceeds, err := MoneyFromFloat(accreted)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create proceeds amount: %w", err)
+	}
+	return proceeds, proceeds.Sub(cost), nil
+}
+
+// NoteSaleProceeds values selling amount of a note or bond bought at yieldRate on purchased and sold on sold,
+// returning the proceeds and the interest earned. Simple interest accrues actual/actual, splitting the holding period by
+// calendar year so leap years accrue over 366 days; compound interest reinvests semiannual coupons.
+func NoteSaleProceeds(amount Money, yieldRate float64, purchased, sold time.Time, interest NoteInterest) (Money, Money, error) {
+	var interestFloat float64
+	switch interest {
+	case NoteInterestCompound:
+		daysHeld := int(sold.Sub(purchased).Hours() / 24)
+		value, err := CalculateNoteBondMaturityValueCompounded(amount.Float64(), yieldRate, daysHeld, NoteCouponsPerYear)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to calculate note/bond compounded value: %w", err)
+		}
+		interestFloat = value - amount.Float64()
+	default:
+		accruedInterest, err := CalculateAccruedInterestActualActual(amount.Float64(), yieldRate, purchased, sold)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to calculate note/bond accrued interest: %w", err)
+		}
+		interestFloat = accruedInterest
+	}
+
+	// Only the interest is rounded; the principal is added back exactly
+	interestEarned, err := MoneyFromFloat(interestFloat)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create interest amount: %w", err)
+	}
+	return amount.Add(interestEarned), interestEarned, nil
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TestBillSaleProceeds tests that bills sold before maturity return less than face, pro-rated by amount and days held
+func TestBillSaleProceeds(t *testing.T) {
+	// 6M bill: 10000.00 face bought at 9775.00
+	faceValue := MoneyFromCents(1000000).Numeric()
+	purchasePrice := MoneyFromCents(977500).Numeric()
+
+	tests := []struct {
+		name           string
+		faceValue      pgtype.Numeric
+		purchasePrice  pgtype.Numeric
+		amount         Money
+		daysHeld       int
+		expected       Money
+		expectedEarned Money
+	}{
+		{"Early full sale", faceValue, purchasePrice, 1000000, 90, 988750, 11250},
+		// Half the face carries half the cost (4887.50) and half the discount (112.50)
+		{"Early partial sale", faceValue, purchasePrice, 500000, 90, 494375, 5625},
+		{"Same-day sale returns the price paid", faceValue, purchasePrice, 1000000, 0, 977500, 0},
+		{"Held to maturity", faceValue, purchasePrice, 1000000, 180, 1000000, 22500},
+		{"Legacy holding redeemed at face", pgtype.Numeric{}, pgtype.Numeric{}, 500000, 90, 500000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proceeds, earned, err := BillSaleProceeds(tt.faceValue, tt.purchasePrice, 180, tt.amount, tt.daysHeld, BillAccrualLinear)
+			if err != nil {
+				t.Fatalf("BillSaleProceeds failed: %v", err)
+			}
+			if proceeds != tt.expected {
+				t.Errorf("Expected proceeds %s, got %s", tt.expected, proceeds)
+			}
+			if earned != tt.expectedEarned {
+				t.Errorf("Expected discount earned %s, got %s", tt.expectedEarned, earned)
+			}
+			if proceeds > tt.amount {
+				t.Errorf("Proceeds %s exceed face value sold %s", proceeds, tt.amount)
+			}
+		})
+	}
+
+	// Compound accrual earns slightly less by the midpoint: √(9775 × 10000) = 9886.86
+	proceeds, earned, err := BillSaleProceeds(faceValue, purchasePrice, 180, 1000000, 90, BillAccrualCompound)
+	if err != nil {
+		t.Fatalf("BillSaleProceeds failed: %v", err)
+	}
+	if proceeds != 988686 || earned != 11186 {
+		t.Errorf("Expected compound proceeds 9886.86 with 111.86 earned, got %s and %s", proceeds, earned)
+	}
+}
+
+// TestNoteSaleProceeds tests simple and compound interest on a note sold after a 10-year hold
+func TestNoteSaleProceeds(t *testing.T) {
+	// 2015-2025 spans 3,653 days including three leap days, so actual/actual simple interest is exactly ten years
+	purchased := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	sold := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	simple, simpleEarned, err := NoteSaleProceeds(1000000, 4.0, purchased, sold, NoteInterestSimple)
+	if err != nil {
+		t.Fatalf("NoteSaleProceeds (simple) failed: %v", err)
+	}
+	if simple != 1400000 || simpleEarned != 400000 {
+		t.Errorf("Expected simple proceeds 14000.00 with 4000.00 earned, got %s and %s", simple, simpleEarned)
+	}
+
+	// 10000 × (1 + 0.04/2)^(2 × 3653/365)
+	compound, compoundEarned, err := NoteSaleProceeds(1000000, 4.0, purchased, sold, NoteInterestCompound)
+	if err != nil {
+		t.Fatalf("NoteSaleProceeds (compound) failed: %v", err)
+	}
+	if compound != 1486431 || compoundEarned != 486431 {
+		t.Errorf("Expected compound proceeds 14864.31 with 4864.31 earned, got %s and %s", compound, compoundEarned)
+	}
+	if compound <= simple {
+		t.Errorf("Expected compound proceeds %s to exceed simple %s", compound, simple)
+	}
+}
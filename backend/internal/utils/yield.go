@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
+	"time"
 )
 
 // Security type constants for treasury securities
@@ -12,11 +16,28 @@ const (
 	SecurityTypeBond = "bond" // Treasury Bonds (30 years)
 )
 
+// SecurityTypeLabel returns the display name for a security type: "Treasury Bill", "Treasury Note", or "Treasury Bond".
+// Unknown types are returned unchanged.
+func SecurityTypeLabel(securityType string) string {
+	switch securityType {
+	case SecurityTypeBill:
+		return "Treasury Bill"
+	case SecurityTypeNote:
+		return "Treasury Note"
+	case SecurityTypeBond:
+		return "Treasury Bond"
+	default:
+		return securityType
+	}
+}
+
 // TermDurationDays maps treasury terms to their duration in days
 func TermDurationDays(term string) (int, error) {
 	termMap := map[string]int{
 		"1M":  30,
+		"2M":  60,
 		"3M":  90,
+		"4M":  120,
 		"6M":  180,
 		"1Y":  365,
 		"2Y":  730,
@@ -33,23 +54,86 @@ func TermDurationDays(term string) (int, error) {
 	return days, nil
 }
 
+// ValidTerms lists every term that can be bought, shortest first.
+// TermDurationDays and GetSecurityType must cover exactly these terms.
+var ValidTerms = []string{"1M", "2M", "3M", "4M", "6M", "1Y", "2Y", "5Y", "10Y", "30Y"}
+
+// ErrInvalidTerm is returned by NormalizeTerm for a term outside ValidTerms
+var ErrInvalidTerm = errors.New("invalid term: must be one of " + strings.Join(ValidTerms, ", "))
+
+// IsValidTerm reports whether term is one of ValidTerms, matching case exactly
+func IsValidTerm(term string) bool {
+	return slices.Contains(ValidTerms, term)
+}
+
+// NormalizeTerm trims and uppercases a client-supplied term ("6m" becomes "6M") and checks it is supported.
+// Returns the canonical term, or ErrInvalidTerm.
+func NormalizeTerm(input string) (string, error) {
+	term := strings.ToUpper(strings.TrimSpace(input))
+	if !IsValidTerm(term) {
+		return "", ErrInvalidTerm
+	}
+	return term, nil
+}
+
 // GetSecurityType classifies treasury securities by maturity: bill (≤1Y), note (2-10Y), or bond (30Y)
 func GetSecurityType(term string) (string, error) {
 	switch term {
-	case "1M", "3M", "6M", "1Y":
+	case "1M", "2M", "3M", "4M", "6M", "1Y":
 		return SecurityTypeBill, nil
 	case "2Y", "5Y", "10Y":
 		return SecurityTypeNote, nil
 	case "30Y":
 		return SecurityTypeBond, nil
 	default:
-		return "", fmt.Errorf("invalid term: %s (valid terms: 1M, 3M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y)", term)
+		return "", fmt.Errorf("invalid term: %s (valid terms: %s)", term, strings.Join(ValidTerms, ", "))
 	}
 }
 
 // CalculateBillPrice calculates discounted purchase price for Treasury Bills using 360-day convention.
 // Formula: price = faceValue × (1 - (yieldRate / 100 × days) / 360)
 func CalculateBillPrice(faceValue float64, yieldRate float64, term string) (float64, error) {
+	return CalculateBillPriceDayCount(faceValue, yieldRate, term, time.Time{}, DayCountThirty360)
+}
+
+// DayCountConvention selects how CalculateBillPriceDayCount measures a bill's term against a year
+type DayCountConvention string
+
+// Day count conventions
+const (
+	DayCountThirty360    DayCountConvention = "30/360"        // TermDurationDays (30-day months) over a 360-day year
+	DayCountActual360    DayCountConvention = "actual/360"    // Calendar days over a 360-day year, as treasury prices bills
+	DayCountActualActual DayCountConvention = "actual/actual" // Calendar days over each calendar year's length (365 or 366)
+)
+
+// ParseDayCountConvention validates a day count convention name
+func ParseDayCountConvention(name string) (DayCountConvention, error) {
+	switch DayCountConvention(name) {
+	case DayCountThirty360, DayCountActual360, DayCountActualActual:
+		return DayCountConvention(name), nil
+	default:
+		return "", fmt.Errorf("invalid day count convention: %s (valid: %s, %s, %s)", name, DayCountThirty360, DayCountActual360, DayCountActualActual)
+	}
+}
+
+// billTermMonths maps bill terms to their length in calendar months for the actual day counts
+var billTermMonths = map[string]int{
+	"1M": 1,
+	"2M": 2,
+	"3M": 3,
+	"4M": 4,
+	"6M": 6,
+	"1Y": 12,
+}
+
+// CalculateBillPriceDayCount calculates a Treasury Bill's discounted purchase price under convention
+// for a bill bought on purchaseDate.
+// Formula: price = faceValue × (1 - yieldRate / 100 × yearFraction), where yearFraction is days / 360
+// for 30/360 and actual/360, and actual days split by calendar year over 365 or 366 for actual/actual.
+// The actual conventions count calendar days from purchaseDate to the same date the term's months later
+// (the last day of that month if it is shorter), so 31-day months add days and February takes them away.
+// 30/360 ignores purchaseDate and matches CalculateBillPrice.
+func CalculateBillPriceDayCount(faceValue float64, yieldRate float64, term string, purchaseDate time.Time, convention DayCountConvention) (float64, error) {
 	securityType, err := GetSecurityType(term)
 	if err != nil {
 		return 0, err
@@ -67,24 +151,151 @@ func CalculateBillPrice(faceValue float64, yieldRate float64, term string) (floa
 		return 0, fmt.Errorf("yield rate must be between 0 and 100, got: %f", yieldRate)
 	}
 
-	days, err := TermDurationDays(term)
+	yearFraction, err := billYearFraction(term, purchaseDate, convention)
 	if err != nil {
 		return 0, err
 	}
 
-	discountFactor := (yieldRate / 100.0 * float64(days)) / 360.0
-	price := faceValue * (1.0 - discountFactor)
+	price := faceValue * (1.0 - yieldRate/100.0*yearFraction)
 	price = math.Round(price*100) / 100
 
 	return price, nil
 }
 
+// billYearFraction returns the share of a year a bill of term bought on purchaseDate is discounted over:
+// TermDurationDays / 360 for 30/360, and the calendar days of its billPeriod over 360 or split by calendar year
+// for the actual conventions.
+func billYearFraction(term string, purchaseDate time.Time, convention DayCountConvention) (float64, error) {
+	switch convention {
+	case DayCountThirty360:
+		days, err := TermDurationDays(term)
+		if err != nil {
+			return 0, err
+		}
+		return float64(days) / 360.0, nil
+	case DayCountActual360:
+		start, end := billPeriod(term, purchaseDate)
+		return end.Sub(start).Hours() / 24 / 360.0, nil
+	case DayCountActualActual:
+		start, end := billPeriod(term, purchaseDate)
+		return actualActualYearFraction(start, end), nil
+	default:
+		return 0, fmt.Errorf("invalid day count convention: %s", convention)
+	}
+}
+
+// billPeriod returns the calendar day a bill bought on purchaseDate starts and the same day of the month
+// its term later, clamped to the end of that month. term must be a bill term.
+func billPeriod(term string, purchaseDate time.Time) (time.Time, time.Time) {
+	start := time.Date(purchaseDate.Year(), purchaseDate.Month(), purchaseDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	// time.AddDate would roll Jan 31 + 1 month over to March, so clamp the day to the target month
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(billTermMonths[term]), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := min(start.Day(), lastDay)
+	return start, firstOfMonth.AddDate(0, 0, day-1)
+}
+
 // CalculateBillDiscount returns the discount amount (faceValue - purchasePrice)
 func CalculateBillDiscount(faceValue float64, purchasePrice float64) float64 {
 	discount := faceValue - purchasePrice
 	return math.Round(discount*100) / 100
 }
 
+// CalculatePricePer100 quotes a purchase price per $100 of face value, the convention treasury uses for bills
+// Formula: pricePer100 = purchasePrice / faceValue × 100, e.g. 9775.00 for 10000.00 face quotes 97.75
+// A non-positive face value has no meaningful quote and returns 0.
+func CalculatePricePer100(faceValue float64, purchasePrice float64) float64 {
+	if faceValue <= 0 {
+		return 0
+	}
+	// Six decimal places, as treasury publishes auction prices
+	return math.Round(purchasePrice/faceValue*100*1e6) / 1e6
+}
+
+// BillAccrual selects how CalculateBillAccretedValue earns a bill's discount over its term
+type BillAccrual string
+
+// Bill accrual models
+const (
+	BillAccrualLinear   BillAccrual = "linear"   // Equal share of the discount per day, matching the 360-day discount convention
+	BillAccrualCompound BillAccrual = "compound" // Constant daily growth rate from purchase price to face value
+)
+
+// ParseBillAccrual validates a bill accrual model name
+func ParseBillAccrual(model string) (BillAccrual, error) {
+	switch BillAccrual(model) {
+	case BillAccrualLinear, BillAccrualCompound:
+		return BillAccrual(model), nil
+	default:
+		return "", fmt.Errorf("invalid bill accrual: %s (valid: %s, %s)", model, BillAccrualLinear, BillAccrualCompound)
+	}
+}
+
+// CalculateBillAccretedValue returns what a bill is worth daysHeld days after purchase: the price paid plus
+// the share of the discount earned so far under accrual, reaching face value at maturity.
+// Formula (linear):   value = purchasePrice + (faceValue - purchasePrice) × t
+// Formula (compound): value = purchasePrice × (faceValue / purchasePrice)^t
+// where t = min(daysHeld, termDays) / termDays; the value is capped at faceValue.
+// Compound accretion earns less early in the term and catches up toward maturity.
+// termDays is TermDurationDays(term); use CalculateBillAccretedValueDays for a holding's own term length.
+func CalculateBillAccretedValue(faceValue float64, purchasePrice float64, term string, daysHeld int, accrual BillAccrual) (float64, error) {
+	days, err := TermDurationDays(term)
+	if err != nil {
+		return 0, err
+	}
+	return CalculateBillAccretedValueDays(faceValue, purchasePrice, days, daysHeld, accrual)
+}
+
+// CalculateBillAccretedValueDays is CalculateBillAccretedValue over a bill lasting termDays calendar days,
+// such as the days from a holding's purchase to its stored maturity date.
+func CalculateBillAccretedValueDays(faceValue float64, purchasePrice float64, termDays int, daysHeld int, accrual BillAccrual) (float64, error) {
+	if termDays <= 0 {
+		return 0, fmt.Errorf("term days must be greater than 0, got: %d", termDays)
+	}
+	if faceValue <= 0 {
+		return 0, fmt.Errorf("face value must be greater than 0, got: %f", faceValue)
+	}
+	if purchasePrice <= 0 {
+		return 0, fmt.Errorf("purchase price must be greater than 0, got: %f", purchasePrice)
+	}
+	if daysHeld < 0 {
+		return 0, fmt.Errorf("days held must be non-negative, got: %d", daysHeld)
+	}
+
+	earned := math.Min(float64(daysHeld)/float64(termDays), 1.0)
+	var value float64
+	switch accrual {
+	case BillAccrualLinear:
+		value = purchasePrice + (faceValue-purchasePrice)*earned
+	case BillAccrualCompound:
+		value = purchasePrice * math.Pow(faceValue/purchasePrice, earned)
+	default:
+		return 0, fmt.Errorf("invalid bill accrual: %s", accrual)
+	}
+	return math.Min(math.Round(value*100)/100, faceValue), nil
+}
+
+// CalculateInvestmentYield converts a bill's discount into its bond-equivalent (investment) yield.
+// Formula: yield = (faceValue - purchasePrice) / purchasePrice × 365 / days × 100
+// This puts bills on the same 365-day, price-based footing as note and bond yields.
+func CalculateInvestmentYield(faceValue float64, purchasePrice float64, term string) (float64, error) {
+	if faceValue <= 0 {
+		return 0, fmt.Errorf("face value must be greater than 0, got: %f", faceValue)
+	}
+	if purchasePrice <= 0 {
+		return 0, fmt.Errorf("purchase price must be greater than 0, got: %f", purchasePrice)
+	}
+
+	days, err := TermDurationDays(term)
+	if err != nil {
+		return 0, err
+	}
+
+	investmentYield := (faceValue - purchasePrice) / purchasePrice * (365.0 / float64(days)) * 100.0
+	return math.Round(investmentYield*100) / 100, nil
+}
+
 // CalculateNoteBondPrice returns par value for Treasury Notes and Bonds
 func CalculateNoteBondPrice(faceValue float64, yieldRate float64, term string) (float64, error) {
 	if faceValue <= 0 {
@@ -124,3 +335,301 @@ func CalculateNoteBondMaturityValue(principal float64, yieldRate float64, daysHe
 	maturityValue := principal + simpleInterest
 	return math.Round(maturityValue*100) / 100, nil
 }
+
+// CalculateNoteBondMaturityValueCompounded returns principal grown at yieldRate compounded compoundsPerYear times
+// a year over daysHeld on a 365-day basis: principal × (1 + rate/n)^(n × years).
+// With semiannual compounding this models reinvesting each coupon at the purchase yield.
+func CalculateNoteBondMaturityValueCompounded(principal float64, yieldRate float64, daysHeld int, compoundsPerYear int) (float64, error) {
+	if principal <= 0 {
+		return 0, fmt.Errorf("principal must be greater than 0, got: %f", principal)
+	}
+
+	if yieldRate < 0 || yieldRate > 100 {
+		return 0, fmt.Errorf("yield rate must be between 0 and 100, got: %f", yieldRate)
+	}
+
+	if daysHeld < 0 {
+		return 0, fmt.Errorf("days held must be non-negative, got: %d", daysHeld)
+	}
+
+	if compoundsPerYear <= 0 {
+		return 0, fmt.Errorf("compounds per year must be greater than 0, got: %d", compoundsPerYear)
+	}
+
+	n := float64(compoundsPerYear)
+	years := float64(daysHeld) / 365.0
+	maturityValue := principal * math.Pow(1+yieldRate/100.0/n, n*years)
+	return math.Round(maturityValue*100) / 100, nil
+}
+
+// NoteInterest selects how a note or bond sold before maturity earns interest
+type NoteInterest string
+
+// Note/bond interest models
+const (
+	NoteInterestSimple   NoteInterest = "simple"   // Simple interest accrued actual/actual
+	NoteInterestCompound NoteInterest = "compound" // Coupons reinvested at the purchase yield, compounded NoteCouponsPerYear times a year
+)
+
+// NoteCouponsPerYear is how often treasury notes and bonds pay coupons (semiannually)
+const NoteCouponsPerYear = 2
+
+// ParseNoteInterest validates a note/bond interest model name
+func ParseNoteInterest(model string) (NoteInterest, error) {
+	switch NoteInterest(model) {
+	case NoteInterestSimple, NoteInterestCompound:
+		return NoteInterest(model), nil
+	default:
+		return "", fmt.Errorf("invalid note interest: %s (valid: %s, %s)", model, NoteInterestSimple, NoteInterestCompound)
+	}
+}
+
+// CalculateAccruedInterestActualActual returns simple interest accrued between purchaseDate and saleDate
+// using the actual/actual day count: the holding period is split by calendar year and each
+// segment's days are divided by that year's actual length (365, or 366 in leap years).
+// Formula: interest = principal × yieldRate / 100 × Σ(daysInSegment / daysInYear)
+// Dates are compared by calendar day, so a same-day sale accrues nothing.
+func CalculateAccruedInterestActualActual(principal float64, yieldRate float64, purchaseDate time.Time, saleDate time.Time) (float64, error) {
+	if principal <= 0 {
+		return 0, fmt.Errorf("principal must be greater than 0, got: %f", principal)
+	}
+
+	if yieldRate < 0 || yieldRate > 100 {
+		return 0, fmt.Errorf("yield rate must be between 0 and 100, got: %f", yieldRate)
+	}
+
+	start := time.Date(purchaseDate.Year(), purchaseDate.Month(), purchaseDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(saleDate.Year(), saleDate.Month(), saleDate.Day(), 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		return 0, fmt.Errorf("sale date %s is before purchase date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	interest := principal * (yieldRate / 100.0) * actualActualYearFraction(start, end)
+	return math.Round(interest*100) / 100, nil
+}
+
+// actualActualYearFraction returns the years between two UTC midnights under actual/actual:
+// the period is split by calendar year and each segment's days are divided by that year's length
+func actualActualYearFraction(start, end time.Time) float64 {
+	yearFraction := 0.0
+	for segmentStart := start; segmentStart.Before(end); {
+		nextYear := time.Date(segmentStart.Year()+1, 1, 1, 0, 0, 0, 0, time.UTC)
+		segmentEnd := nextYear
+		if end.Before(segmentEnd) {
+			segmentEnd = end
+		}
+
+		daysInYear := nextYear.Sub(time.Date(segmentStart.Year(), 1, 1, 0, 0, 0, 0, time.UTC)).Hours() / 24
+		yearFraction += segmentEnd.Sub(segmentStart).Hours() / 24 / daysInYear
+		segmentStart = segmentEnd
+	}
+	return yearFraction
+}
+
+// CalculatePurchasePrice prices a purchase bought on purchaseDate for any term: discount pricing under
+// convention for bills, par for notes and bonds
+func CalculatePurchasePrice(faceValue float64, yieldRate float64, term string, purchaseDate time.Time, convention DayCountConvention) (float64, error) {
+	securityType, err := GetSecurityType(term)
+	if err != nil {
+		return 0, err
+	}
+
+	if securityType == SecurityTypeBill {
+		return CalculateBillPriceDayCount(faceValue, yieldRate, term, purchaseDate, convention)
+	}
+	return CalculateNoteBondPrice(faceValue, yieldRate, term)
+}
+
+// MaturityDate returns the date a security purchased on purchaseDate matures.
+// Bills priced under an actual day count mature at the end of the same billPeriod they were discounted over,
+// keeping the purchase's time of day; 30/360 bills, notes, and bonds mature TermDurationDays later.
+func MaturityDate(purchaseDate time.Time, term string, convention DayCountConvention) (time.Time, error) {
+	days, err := TermDurationDays(term)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	securityType, err := GetSecurityType(term)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if securityType == SecurityTypeBill && convention != DayCountThirty360 {
+		if _, err := ParseDayCountConvention(string(convention)); err != nil {
+			return time.Time{}, err
+		}
+		_, end := billPeriod(term, purchaseDate)
+		return time.Date(end.Year(), end.Month(), end.Day(), purchaseDate.Hour(), purchaseDate.Minute(),
+			purchaseDate.Second(), purchaseDate.Nanosecond(), purchaseDate.Location()), nil
+	}
+	return purchaseDate.AddDate(0, 0, days), nil
+}
+
+// DaysUntilMaturity returns the calendar days from now until a security purchased on purchaseDate under
+// convention matures. Zero means it matures today; negative values mean it has already matured.
+func DaysUntilMaturity(purchaseDate time.Time, term string, convention DayCountConvention, now time.Time) (int, error) {
+	maturity, err := MaturityDate(purchaseDate, term, convention)
+	if err != nil {
+		return 0, err
+	}
+	return DaysUntil(maturity, now), nil
+}
+
+// DaysUntil returns the calendar days from now until maturity, for holdings whose maturity date is stored.
+// Zero means it matures today; negative values mean it has already matured.
+func DaysUntil(maturity time.Time, now time.Time) int {
+	maturityDay := time.Date(maturity.Year(), maturity.Month(), maturity.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(maturityDay.Sub(today).Hours() / 24)
+}
+
+// CalculateMaturityProceeds returns the payout at maturity for a holding's remaining amount.
+// Bills redeem at face value; notes and bonds return principal plus simple interest over the full term.
+func CalculateMaturityProceeds(remaining float64, yieldRate float64, term string) (float64, error) {
+	securityType, err := GetSecurityType(term)
+	if err != nil {
+		return 0, err
+	}
+
+	if securityType == SecurityTypeBill {
+		return math.Round(remaining*100) / 100, nil
+	}
+
+	days, err := TermDurationDays(term)
+	if err != nil {
+		return 0, err
+	}
+	return CalculateNoteBondMaturityValue(remaining, yieldRate, days)
+}
+
+// CalculateMarketValue marks a holding's remaining face amount maturing on maturity to market at currentYield as of now.
+// Bills are discounted over the days remaining under convention: over a 360-day year for 30/360 and actual/360,
+// split by calendar year for actual/actual. Notes and bonds discount their full-term maturity proceeds
+// (principal plus interest at yieldAtPurchase) with simple interest on a 365-day basis.
+// Holdings at or past maturity are worth their maturity proceeds.
+func CalculateMarketValue(remaining float64, yieldAtPurchase float64, currentYield float64, term string,
+	now time.Time, maturity time.Time, convention DayCountConvention) (float64, error) {
+	if currentYield < 0 || currentYield > 100 {
+		return 0, fmt.Errorf("yield rate must be between 0 and 100, got: %f", currentYield)
+	}
+
+	securityType, err := GetSecurityType(term)
+	if err != nil {
+		return 0, err
+	}
+
+	proceeds, err := CalculateMaturityProceeds(remaining, yieldAtPurchase, term)
+	if err != nil {
+		return 0, err
+	}
+	daysRemaining := DaysUntil(maturity, now)
+	if daysRemaining <= 0 {
+		return proceeds, nil
+	}
+
+	var value float64
+	if securityType == SecurityTypeBill {
+		var yearFraction float64
+		switch convention {
+		case DayCountThirty360, DayCountActual360:
+			yearFraction = float64(daysRemaining) / 360.0
+		case DayCountActualActual:
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			yearFraction = actualActualYearFraction(today, today.AddDate(0, 0, daysRemaining))
+		default:
+			return 0, fmt.Errorf("invalid day count convention: %s", convention)
+		}
+		value = remaining * (1.0 - currentYield/100.0*yearFraction)
+	} else {
+		value = proceeds / (1.0 + currentYield/100.0*float64(daysRemaining)/365.0)
+	}
+	return math.Round(value*100) / 100, nil
+}
+
+// SpendRounding selects how FaceValueForSpend rounds the face value solved from a spend amount
+type SpendRounding string
+
+// Spend rounding policies
+const (
+	SpendRoundingFloor         SpendRounding = "floor_to_increment" // Round down to a whole increment
+	SpendRoundingNearest       SpendRounding = "nearest"            // Round to the nearest increment; price may exceed spend
+	SpendRoundingMaxAffordable SpendRounding = "max_affordable"     // Largest face value (to the cent) whose price fits the spend
+)
+
+// ParseSpendRounding validates a spend rounding policy name
+func ParseSpendRounding(policy string) (SpendRounding, error) {
+	switch SpendRounding(policy) {
+	case SpendRoundingFloor, SpendRoundingNearest, SpendRoundingMaxAffordable:
+		return SpendRounding(policy), nil
+	default:
+		return "", fmt.Errorf("invalid spend rounding: %s (valid: %s, %s, %s)",
+			policy, SpendRoundingFloor, SpendRoundingNearest, SpendRoundingMaxAffordable)
+	}
+}
+
+// FaceValueForSpend inverts purchase pricing to find the face value a spend amount buys on purchaseDate.
+// The exact solution (spend / (1 - discountFactor) for bills, with the discount measured under convention
+// over the same period CalculatePurchasePrice uses, and spend for notes and bonds)
+// is rounded according to policy; increment applies to the floor and nearest policies.
+// max_affordable guarantees the rounded purchase price never exceeds spend.
+func FaceValueForSpend(spend float64, yieldRate float64, term string, purchaseDate time.Time, convention DayCountConvention,
+	policy SpendRounding, increment float64) (float64, error) {
+	if spend <= 0 {
+		return 0, fmt.Errorf("spend must be greater than 0, got: %f", spend)
+	}
+	if increment <= 0 {
+		return 0, fmt.Errorf("increment must be greater than 0, got: %f", increment)
+	}
+
+	// Validates term and yield the same way pricing does
+	if _, err := CalculatePurchasePrice(spend, yieldRate, term, purchaseDate, convention); err != nil {
+		return 0, err
+	}
+
+	exact := spend
+	if securityType, _ := GetSecurityType(term); securityType == SecurityTypeBill {
+		yearFraction, _ := billYearFraction(term, purchaseDate, convention)
+		exact = spend / (1.0 - yieldRate/100.0*yearFraction)
+	}
+
+	switch policy {
+	case SpendRoundingFloor:
+		return math.Floor(exact/increment) * increment, nil
+	case SpendRoundingNearest:
+		return math.Round(exact/increment) * increment, nil
+	case SpendRoundingMaxAffordable:
+		spendCents := int64(math.Round(spend * 100))
+		fits := func(faceCents int64) (bool, error) {
+			price, err := CalculatePurchasePrice(float64(faceCents)/100, yieldRate, term, purchaseDate, convention)
+			if err != nil {
+				return false, err
+			}
+			return int64(math.Round(price*100)) <= spendCents, nil
+		}
+
+		// Start at the exact solution and nudge by cents to absorb price rounding
+		faceCents := int64(math.Floor(exact * 100))
+		for faceCents > 0 {
+			ok, err := fits(faceCents)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				break
+			}
+			faceCents--
+		}
+		for {
+			ok, err := fits(faceCents + 1)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				break
+			}
+			faceCents++
+		}
+		return float64(faceCents) / 100, nil
+	default:
+		return 0, fmt.Errorf("invalid spend rounding: %s", policy)
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"errors"
 	"math"
 	"testing"
+	"time"
 )
 
 // TestTermDurationDays tests the TermDurationDays function
@@ -14,7 +16,9 @@ func TestTermDurationDays(t *testing.T) {
 		wantErr  bool
 	}{
 		{"1 Month", "1M", 30, false},
+		{"2 Months", "2M", 60, false},
 		{"3 Months", "3M", 90, false},
+		{"4 Months", "4M", 120, false},
 		{"6 Months", "6M", 180, false},
 		{"1 Year", "1Y", 365, false},
 		{"2 Years", "2Y", 730, false},
@@ -90,6 +94,22 @@ func TestCalculateBillPrice(t *testing.T) {
 			expected:  9493.06, // 10000 × (1 - (5/100 × 365)/360) = 10000 × 0.9493056
 			wantErr:   false,
 		},
+		{
+			name:      "2M bill at 4.5% yield",
+			faceValue: 10000.0,
+			yieldRate: 4.5,
+			term:      "2M",
+			expected:  9925.0, // 10000 × (1 - (4.5/100 × 60)/360) = 10000 × 0.9925
+			wantErr:   false,
+		},
+		{
+			name:      "4M bill at 4.5% yield",
+			faceValue: 10000.0,
+			yieldRate: 4.5,
+			term:      "4M",
+			expected:  9850.0, // 10000 × (1 - (4.5/100 × 120)/360) = 10000 × 0.985
+			wantErr:   false,
+		},
 		{
 			name:      "Different face value: $50,000 at 4% for 3M",
 			faceValue: 50000.0,
@@ -118,7 +138,7 @@ func TestCalculateBillPrice(t *testing.T) {
 			name:      "Validation: invalid term should error",
 			faceValue: 10000.0,
 			yieldRate: 4.5,
-			term:      "2Y", // 2Y is not valid for T-Bills (only 1M, 3M, 6M, 1Y)
+			term:      "2Y", // 2Y is not valid for T-Bills (only 1M, 2M, 3M, 4M, 6M, 1Y)
 			expected:  0.0,
 			wantErr:   true,
 		},
@@ -215,13 +235,201 @@ func TestCalculateBillDiscount(t *testing.T) {
 	}
 }
 
+// TestCalculatePricePer100 tests quoting purchase prices per $100 of face value
+func TestCalculatePricePer100(t *testing.T) {
+	tests := []struct {
+		name          string
+		faceValue     float64
+		purchasePrice float64
+		expected      float64
+	}{
+		{"6M bill at 4.50%", 10000.0, 9775.0, 97.75},
+		{"Par purchase", 5000.0, 5000.0, 100.0},
+		{"Same quote at a different face value", 100.0, 97.75, 97.75},
+		{"Fractional quote", 1000.0, 988.125, 98.8125},
+		{"Zero face value", 0, 9775.0, 0},
+		{"Negative face value", -100.0, 97.75, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculatePricePer100(tt.faceValue, tt.purchasePrice)
+			if result != tt.expected {
+				t.Errorf("CalculatePricePer100() = %f, want %f", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCalculateBillAccretedValue tests linear accretion of a bill's discount toward face value
+func TestCalculateBillAccretedValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		faceValue     float64
+		purchasePrice float64
+		term          string
+		daysHeld      int
+		expected      float64
+		expectError   bool
+	}{
+		// 6M bill (180 days) bought at 9775.00 accretes 225.00 of discount
+		{"Same day", 10000.0, 9775.0, "6M", 0, 9775.00, false},
+		{"Halfway", 10000.0, 9775.0, "6M", 90, 9887.50, false},
+		{"One day before maturity", 10000.0, 9775.0, "6M", 179, 9998.75, false},
+		{"At maturity", 10000.0, 9775.0, "6M", 180, 10000.00, false},
+		{"Past maturity never exceeds face", 10000.0, 9775.0, "6M", 400, 10000.00, false},
+		{"Bought at par", 5000.0, 5000.0, "3M", 30, 5000.00, false},
+		{"Price above face is capped", 1000.0, 1005.0, "1M", 0, 1000.00, false},
+		{"Negative days held", 10000.0, 9775.0, "6M", -1, 0, true},
+		{"Zero face value", 0, 9775.0, "6M", 10, 0, true},
+		{"Zero purchase price", 10000.0, 0, "6M", 10, 0, true},
+		{"Unknown term", 10000.0, 9775.0, "7M", 10, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateBillAccretedValue(tt.faceValue, tt.purchasePrice, tt.term, tt.daysHeld, BillAccrualLinear)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %f", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("CalculateBillAccretedValue() = %f, want %f", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCalculateBillAccretedValue_Models tests linear and compound accretion at the midpoint and ends of a 6M bill's term
+func TestCalculateBillAccretedValue_Models(t *testing.T) {
+	tests := []struct {
+		name     string
+		accrual  BillAccrual
+		daysHeld int
+		expected float64
+	}{
+		// Linear earns half the 225.00 discount at the midpoint
+		{"Linear midpoint", BillAccrualLinear, 90, 9887.50},
+		// Compound: 9775 × (10000 / 9775)^0.5 = √(9775 × 10000) = 9886.86, slightly behind linear
+		{"Compound midpoint", BillAccrualCompound, 90, 9886.86},
+		{"Compound same day", BillAccrualCompound, 0, 9775.00},
+		{"Compound at maturity", BillAccrualCompound, 180, 10000.00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateBillAccretedValue(10000.0, 9775.0, "6M", tt.daysHeld, tt.accrual)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("CalculateBillAccretedValue() = %f, want %f", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := CalculateBillAccretedValue(10000.0, 9775.0, "6M", 90, "exponential"); err == nil {
+		t.Error("Expected error for an unknown accrual model")
+	}
+}
+
+// TestParseBillAccrual tests accepted and rejected accrual model names
+func TestParseBillAccrual(t *testing.T) {
+	for _, model := range []string{"linear", "compound"} {
+		if got, err := ParseBillAccrual(model); err != nil || string(got) != model {
+			t.Errorf("ParseBillAccrual(%q) = %q, %v", model, got, err)
+		}
+	}
+	for _, model := range []string{"", "Linear", "simple"} {
+		if _, err := ParseBillAccrual(model); err == nil {
+			t.Errorf("Expected error for %q", model)
+		}
+	}
+}
+
+// TestCalculateBillPriceDayCount tests bill pricing under each day count convention
+func TestCalculateBillPriceDayCount(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		term       string
+		purchase   time.Time
+		convention DayCountConvention
+		expected   float64
+		wantErr    bool
+	}{
+		// 6M bill from 2025-01-15 to 2025-07-15 crosses five 31-day months and February: 181 actual days
+		{"6M 30/360", "6M", date(2025, 1, 15), DayCountThirty360, 9775.00, false},
+		// 10000 × (1 - 0.045 × 181/360)
+		{"6M actual/360", "6M", date(2025, 1, 15), DayCountActual360, 9773.75, false},
+		// 10000 × (1 - 0.045 × 181/365)
+		{"6M actual/actual", "6M", date(2025, 1, 15), DayCountActualActual, 9776.85, false},
+		// 30/360 counts 180 days whatever the calendar says
+		{"6M 30/360 ignores purchase date", "6M", date(2025, 8, 1), DayCountThirty360, 9775.00, false},
+		// 2025-08-01 to 2026-02-01 is 184 days: 10000 × (1 - 0.045 × 184/360)
+		{"6M actual/360 over more 31-day months", "6M", date(2025, 8, 1), DayCountActual360, 9770.00, false},
+		// Jan 31 + 1 month clamps to Feb 28: 28 days
+		{"1M from month end clamps to February", "1M", date(2025, 1, 31), DayCountActual360, 9965.00, false},
+		// 2024-03-01 to 2025-03-01: 306/366 + 59/365 of a year
+		{"1Y actual/actual across leap year end", "1Y", date(2024, 3, 1), DayCountActualActual, 9551.03, false},
+		{"Unknown convention", "6M", date(2025, 1, 15), DayCountConvention("30/365"), 0, true},
+		{"Note term rejected", "2Y", date(2025, 1, 15), DayCountActual360, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateBillPriceDayCount(10000, 4.5, tt.term, tt.purchase, tt.convention)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateBillPriceDayCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("CalculateBillPriceDayCount() = %.2f, want %.2f", got, tt.expected)
+			}
+		})
+	}
+
+	// The default convention matches CalculateBillPrice for every bill term
+	for _, term := range []string{"1M", "2M", "3M", "4M", "6M", "1Y"} {
+		want, err := CalculateBillPrice(10000, 4.5, term)
+		if err != nil {
+			t.Fatalf("CalculateBillPrice(%s) failed: %v", term, err)
+		}
+		got, err := CalculateBillPriceDayCount(10000, 4.5, term, date(2025, 1, 15), DayCountThirty360)
+		if err != nil || got != want {
+			t.Errorf("CalculateBillPriceDayCount(%s, 30/360) = %.2f, %v; want %.2f", term, got, err, want)
+		}
+	}
+}
+
+// TestParseDayCountConvention tests that only the supported day count conventions are accepted
+func TestParseDayCountConvention(t *testing.T) {
+	for _, name := range []string{"30/360", "actual/360", "actual/actual"} {
+		if got, err := ParseDayCountConvention(name); err != nil || string(got) != name {
+			t.Errorf("ParseDayCountConvention(%q) = %q, %v", name, got, err)
+		}
+	}
+	for _, name := range []string{"", "Actual/360", "30/365"} {
+		if _, err := ParseDayCountConvention(name); err == nil {
+			t.Errorf("Expected error for %q", name)
+		}
+	}
+}
+
 // TestCalculateBillPriceAllTerms tests pricing calculation for all valid T-Bill terms
 func TestCalculateBillPriceAllTerms(t *testing.T) {
 	faceValue := 10000.0
 	yieldRate := 4.5
 
-	// Only T-Bill terms (1M, 3M, 6M, 1Y)
-	terms := []string{"1M", "3M", "6M", "1Y"}
+	// Only T-Bill terms (1M, 2M, 3M, 4M, 6M, 1Y)
+	terms := []string{"1M", "2M", "3M", "4M", "6M", "1Y"}
 
 	for _, term := range terms {
 		t.Run(term, func(t *testing.T) {
@@ -250,6 +458,27 @@ func TestCalculateBillPriceAllTerms(t *testing.T) {
 	}
 }
 
+// TestValidTerms tests that every valid term has a duration and security type, so the per-term tables cannot drift
+func TestValidTerms(t *testing.T) {
+	for _, term := range ValidTerms {
+		if !IsValidTerm(term) {
+			t.Errorf("IsValidTerm(%s) = false, want true", term)
+		}
+		if _, err := TermDurationDays(term); err != nil {
+			t.Errorf("TermDurationDays(%s) error: %v", term, err)
+		}
+		if _, err := GetSecurityType(term); err != nil {
+			t.Errorf("GetSecurityType(%s) error: %v", term, err)
+		}
+	}
+
+	for _, term := range []string{"6m", "1W", "7Y", ""} {
+		if IsValidTerm(term) {
+			t.Errorf("IsValidTerm(%q) = true, want false", term)
+		}
+	}
+}
+
 // TestGetSecurityType tests the GetSecurityType function for all valid terms
 func TestGetSecurityType(t *testing.T) {
 	tests := []struct {
@@ -260,7 +489,9 @@ func TestGetSecurityType(t *testing.T) {
 	}{
 		// Treasury Bills (1M - 1Y)
 		{"1 Month Bill", "1M", "bill", false},
+		{"2 Month Bill", "2M", "bill", false},
 		{"3 Month Bill", "3M", "bill", false},
+		{"4 Month Bill", "4M", "bill", false},
 		{"6 Month Bill", "6M", "bill", false},
 		{"1 Year Bill", "1Y", "bill", false},
 
@@ -295,6 +526,63 @@ func TestGetSecurityType(t *testing.T) {
 	}
 }
 
+// TestSecurityTypeLabel tests display names for each security type
+// TestNormalizeTerm tests trimming, uppercasing, and rejecting unsupported terms
+func TestNormalizeTerm(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"6M", "6M", false},
+		{"6m", "6M", false},
+		{" 10y ", "10Y", false},
+		{"1m", "1M", false},
+		{"30Y", "30Y", false},
+		{"7m", "", true},
+		{"", "", true},
+		{"6 M", "", true},
+		{"month", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeTerm(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTerm) {
+					t.Errorf("NormalizeTerm(%q) error = %v, want ErrInvalidTerm", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeTerm(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeTerm(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityTypeLabel(t *testing.T) {
+	tests := []struct {
+		securityType string
+		expected     string
+	}{
+		{SecurityTypeBill, "Treasury Bill"},
+		{SecurityTypeNote, "Treasury Note"},
+		{SecurityTypeBond, "Treasury Bond"},
+		{"strip", "strip"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := SecurityTypeLabel(tt.securityType); got != tt.expected {
+			t.Errorf("SecurityTypeLabel(%q) = %q, want %q", tt.securityType, got, tt.expected)
+		}
+	}
+}
+
 // TestCalculateNoteBondPrice tests the par pricing function for Treasury Notes and Bonds
 func TestCalculateNoteBondPrice(t *testing.T) {
 	tests := []struct {
@@ -568,3 +856,457 @@ func TestCalculateNoteBondMaturityValue(t *testing.T) {
 		})
 	}
 }
+
+// TestCalculateNoteBondMaturityValueCompounded tests compounding and that it beats simple interest over a 10Y hold
+func TestCalculateNoteBondMaturityValueCompounded(t *testing.T) {
+	tests := []struct {
+		name             string
+		compoundsPerYear int
+		daysHeld         int
+		expected         float64
+	}{
+		// 10000 × (1 + 0.04/2)^20 = 14859.47
+		{"10Y semiannual", 2, 3650, 14859.47},
+		// 10000 × 1.04^10 = 14802.44
+		{"10Y annual", 1, 3650, 14802.44},
+		// 182 days, just short of one coupon period: 10000 × 1.02^(2 × 182/365) = 10199.45
+		{"Half year semiannual", 2, 182, 10199.45},
+		{"Zero days", 2, 0, 10000.00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CalculateNoteBondMaturityValueCompounded(10000.0, 4.0, tt.daysHeld, tt.compoundsPerYear)
+			if err != nil {
+				t.Fatalf("CalculateNoteBondMaturityValueCompounded() error = %v", err)
+			}
+			if math.Abs(result-tt.expected) > 0.01 {
+				t.Errorf("CalculateNoteBondMaturityValueCompounded() = %f, want %f", result, tt.expected)
+			}
+		})
+	}
+
+	simple, err := CalculateNoteBondMaturityValue(10000.0, 4.0, 3650)
+	if err != nil {
+		t.Fatalf("CalculateNoteBondMaturityValue() error = %v", err)
+	}
+	compounded, err := CalculateNoteBondMaturityValueCompounded(10000.0, 4.0, 3650, NoteCouponsPerYear)
+	if err != nil {
+		t.Fatalf("CalculateNoteBondMaturityValueCompounded() error = %v", err)
+	}
+	if compounded <= simple {
+		t.Errorf("Expected compounded 10Y value %.2f to exceed simple %.2f", compounded, simple)
+	}
+
+	for _, bad := range []struct {
+		principal, yield float64
+		days, n          int
+	}{
+		{0, 4.0, 365, 2}, {10000, -1, 365, 2}, {10000, 4.0, -1, 2}, {10000, 4.0, 365, 0},
+	} {
+		if _, err := CalculateNoteBondMaturityValueCompounded(bad.principal, bad.yield, bad.days, bad.n); err == nil {
+			t.Errorf("Expected error for %+v", bad)
+		}
+	}
+}
+
+// TestParseNoteInterest tests accepted and rejected note interest model names
+func TestParseNoteInterest(t *testing.T) {
+	for _, model := range []string{"simple", "compound"} {
+		if got, err := ParseNoteInterest(model); err != nil || string(got) != model {
+			t.Errorf("ParseNoteInterest(%q) = %q, %v", model, got, err)
+		}
+	}
+	for _, model := range []string{"", "Simple", "linear"} {
+		if _, err := ParseNoteInterest(model); err == nil {
+			t.Errorf("Expected error for %q", model)
+		}
+	}
+}
+
+// TestCalculatePurchasePrice tests dispatch to bill discount pricing or note/bond par pricing
+func TestCalculatePurchasePrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		faceValue float64
+		yieldRate float64
+		term      string
+		expected  float64
+		wantErr   bool
+	}{
+		{"6M bill uses discount pricing", 10000.0, 4.5, "6M", 9775.0, false},
+		{"1M bill uses discount pricing", 10000.0, 3.6, "1M", 9970.0, false},
+		{"2Y note priced at par", 10000.0, 4.2, "2Y", 10000.0, false},
+		{"30Y bond priced at par", 5000.0, 4.8, "30Y", 5000.0, false},
+		{"Invalid term", 10000.0, 4.5, "7Y", 0, true},
+		{"Zero face value", 0, 4.5, "6M", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := CalculatePurchasePrice(tt.faceValue, tt.yieldRate, tt.term, time.Time{}, DayCountThirty360)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculatePurchasePrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(price-tt.expected) > 0.001 {
+				t.Errorf("CalculatePurchasePrice() = %f, want %f", price, tt.expected)
+			}
+		})
+	}
+
+	// Bills follow the day count from their purchase date: Jan 15 to Jul 15 is 181 days, 10000 × (1 - 0.045 × 181/360)
+	price, err := CalculatePurchasePrice(10000.0, 4.5, "6M", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), DayCountActual360)
+	if err != nil || price != 9773.75 {
+		t.Errorf("CalculatePurchasePrice(6M, actual/360) = %.2f, %v; want 9773.75", price, err)
+	}
+}
+
+// TestMaturityDate tests maturity date computation from purchase date and term
+func TestMaturityDate(t *testing.T) {
+	purchase := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		term       string
+		convention DayCountConvention
+		expected   string
+		wantErr    bool
+	}{
+		{"1M", DayCountThirty360, "2025-02-14", false},
+		{"6M", DayCountThirty360, "2025-07-14", false},
+		// Actual day counts discount bills to the same date the term's months later, and mature there
+		{"3M", DayCountActual360, "2025-04-15", false},
+		{"6M", DayCountActualActual, "2025-07-15", false},
+		{"2Y", DayCountThirty360, "2027-01-15", false},
+		{"2Y", DayCountActual360, "2027-01-15", false},
+		{"3M", DayCountConvention("30/365"), "", true},
+		{"7Y", DayCountThirty360, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term+" "+string(tt.convention), func(t *testing.T) {
+			maturity, err := MaturityDate(purchase, tt.term, tt.convention)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MaturityDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && maturity.Format("2006-01-02") != tt.expected {
+				t.Errorf("MaturityDate() = %s, want %s", maturity.Format("2006-01-02"), tt.expected)
+			}
+		})
+	}
+
+	// A bill bought on the 31st matures on the last day of a shorter month, keeping the time of day
+	maturity, err := MaturityDate(time.Date(2025, 1, 31, 10, 30, 0, 0, time.UTC), "1M", DayCountActual360)
+	if err != nil || !maturity.Equal(time.Date(2025, 2, 28, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("MaturityDate(Jan 31, 1M, actual/360) = %s, %v; want 2025-02-28 10:30", maturity, err)
+	}
+}
+
+// TestCalculateMaturityProceeds tests payout at maturity for bills, notes, and bonds
+func TestCalculateMaturityProceeds(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining float64
+		yieldRate float64
+		term      string
+		expected  float64
+		wantErr   bool
+	}{
+		{"Bill redeems at face", 10000.0, 4.5, "6M", 10000.0, false},
+		{"2Y note earns full-term interest", 10000.0, 4.0, "2Y", 10800.0, false}, // 10000 × 0.04 × 730/365
+		{"30Y bond earns full-term interest", 1000.0, 5.0, "30Y", 2500.0, false}, // 1000 × 0.05 × 30
+		{"Invalid term", 1000.0, 5.0, "7Y", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proceeds, err := CalculateMaturityProceeds(tt.remaining, tt.yieldRate, tt.term)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateMaturityProceeds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(proceeds-tt.expected) > 0.001 {
+				t.Errorf("CalculateMaturityProceeds() = %f, want %f", proceeds, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCalculateInvestmentYield tests discount-to-investment yield conversion for bills
+func TestCalculateInvestmentYield(t *testing.T) {
+	tests := []struct {
+		name          string
+		faceValue     float64
+		purchasePrice float64
+		term          string
+		expected      float64
+		wantErr       bool
+	}{
+		// 225 / 9775 × 365/180 × 100 = 4.6675
+		{"6M bill at 4.5% discount", 10000.0, 9775.0, "6M", 4.67, false},
+		// 30 / 9970 × 365/30 × 100 = 3.661
+		{"1M bill at 3.6% discount", 10000.0, 9970.0, "1M", 3.66, false},
+		// 420 / 9580 × 365/365 × 100 = 4.384
+		{"1Y bill at 4.2% discount", 10000.0, 9580.0, "1Y", 4.38, false},
+		{"Priced at par", 10000.0, 10000.0, "3M", 0, false},
+		{"Zero purchase price", 10000.0, 0, "6M", 0, true},
+		{"Zero face value", 0, 9775.0, "6M", 0, true},
+		{"Invalid term", 10000.0, 9775.0, "7M", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateInvestmentYield(tt.faceValue, tt.purchasePrice, tt.term)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateInvestmentYield() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("CalculateInvestmentYield() = %f, want %f", got, tt.expected)
+			}
+		})
+	}
+
+	// The investment yield of a discounted bill always exceeds its discount yield
+	price, _ := CalculateBillPrice(10000.0, 4.5, "6M")
+	investmentYield, _ := CalculateInvestmentYield(10000.0, price, "6M")
+	if investmentYield <= 4.5 {
+		t.Errorf("Expected investment yield above 4.5%% discount yield, got %f", investmentYield)
+	}
+}
+
+// TestFaceValueForSpend tests each rounding policy for a spend that doesn't divide evenly
+func TestFaceValueForSpend(t *testing.T) {
+	// 6M bill at 4.5%: $1 face costs 0.9775, so $10,000 spend buys exactly 10230.179... face
+	tests := []struct {
+		name      string
+		spend     float64
+		yieldRate float64
+		term      string
+		policy    SpendRounding
+		increment float64
+		expected  float64
+		wantErr   bool
+	}{
+		{"Floor to $100 increment", 10000.0, 4.5, "6M", SpendRoundingFloor, 100, 10200.00, false},
+		{"Nearest $100 increment", 10000.0, 4.5, "6M", SpendRoundingNearest, 100, 10200.00, false},
+		{"Nearest $1000 increment rounds down", 10000.0, 4.5, "6M", SpendRoundingNearest, 1000, 10000.00, false},
+		{"Nearest $50 increment rounds up past spend", 10000.0, 4.5, "6M", SpendRoundingNearest, 50, 10250.00, false},
+		{"Max affordable to the cent", 10000.0, 4.5, "6M", SpendRoundingMaxAffordable, 100, 10230.18, false},
+		{"Note at par floors", 2550.75, 4.1, "5Y", SpendRoundingFloor, 100, 2500.00, false},
+		{"Note at par max affordable", 2550.75, 4.1, "5Y", SpendRoundingMaxAffordable, 100, 2550.75, false},
+		{"Spend below one increment floors to zero", 50.0, 4.5, "6M", SpendRoundingFloor, 100, 0, false},
+		{"Zero spend", 0, 4.5, "6M", SpendRoundingFloor, 100, 0, true},
+		{"Zero increment", 10000.0, 4.5, "6M", SpendRoundingFloor, 0, 0, true},
+		{"Invalid term", 10000.0, 4.5, "7M", SpendRoundingFloor, 100, 0, true},
+		{"Invalid policy", 10000.0, 4.5, "6M", SpendRounding("ceil"), 100, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FaceValueForSpend(tt.spend, tt.yieldRate, tt.term, time.Time{}, DayCountThirty360, tt.policy, tt.increment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FaceValueForSpend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("FaceValueForSpend() = %.2f, want %.2f", got, tt.expected)
+			}
+		})
+	}
+
+	// max_affordable never exceeds spend, and one more cent of face would
+	for _, spend := range []float64{999.99, 10000.0, 12345.67, 97750.01} {
+		face, err := FaceValueForSpend(spend, 4.5, "6M", time.Time{}, DayCountThirty360, SpendRoundingMaxAffordable, 100)
+		if err != nil {
+			t.Fatalf("FaceValueForSpend(%.2f) failed: %v", spend, err)
+		}
+		price, _ := CalculateBillPrice(face, 4.5, "6M")
+		if price > spend {
+			t.Errorf("Spend %.2f: price %.2f for face %.2f exceeds spend", spend, price, face)
+		}
+		next, _ := CalculateBillPrice(face+0.01, 4.5, "6M")
+		if next <= spend {
+			t.Errorf("Spend %.2f: face %.2f is not maximal, %.2f also fits", spend, face, face+0.01)
+		}
+	}
+}
+
+// TestFaceValueForSpend_DayCount tests that spend inverts bill pricing exactly under the actual day counts,
+// so max_affordable never buys a face value priced above the spend
+func TestFaceValueForSpend_DayCount(t *testing.T) {
+	// Jan 15 to Apr 15 is 90 days; Jan 31 to Apr 30 is 89; Feb 28 to May 28 is 89; Aug 31 to Nov 30 is 91
+	dates := []time.Time{
+		time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 8, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for _, convention := range []DayCountConvention{DayCountActual360, DayCountActualActual} {
+		for _, date := range dates {
+			for _, spend := range []float64{999.99, 10000.0, 12345.67} {
+				face, err := FaceValueForSpend(spend, 4.5, "3M", date, convention, SpendRoundingMaxAffordable, 100)
+				if err != nil {
+					t.Fatalf("FaceValueForSpend(%.2f, %s) failed: %v", spend, convention, err)
+				}
+				price, _ := CalculateBillPriceDayCount(face, 4.5, "3M", date, convention)
+				if price > spend {
+					t.Errorf("%s %s spend %.2f: price %.2f for face %.2f exceeds spend", convention, date.Format("2006-01-02"), spend, price, face)
+				}
+				next, _ := CalculateBillPriceDayCount(face+0.01, 4.5, "3M", date, convention)
+				if next <= spend {
+					t.Errorf("%s %s spend %.2f: face %.2f is not maximal", convention, date.Format("2006-01-02"), spend, face)
+				}
+			}
+		}
+	}
+
+	// Nearest sizes from the actual period: 10000 / (1 - 0.045 × 91/360) = 10115.06 rounds to 10115.00,
+	// where 30/360's 90 days give 10113.78 and round to 10114.00
+	face, err := FaceValueForSpend(10000.0, 4.5, "3M", time.Date(2025, 8, 31, 0, 0, 0, 0, time.UTC), DayCountActual360, SpendRoundingNearest, 1)
+	if err != nil || face != 10115.00 {
+		t.Errorf("FaceValueForSpend(nearest, actual/360) = %.2f, %v; want 10115.00", face, err)
+	}
+}
+
+// TestParseSpendRounding tests spend rounding policy validation
+func TestParseSpendRounding(t *testing.T) {
+	for _, policy := range []string{"floor_to_increment", "nearest", "max_affordable"} {
+		if _, err := ParseSpendRounding(policy); err != nil {
+			t.Errorf("ParseSpendRounding(%q) unexpected error: %v", policy, err)
+		}
+	}
+	if _, err := ParseSpendRounding("round_up"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+// TestDaysUntilMaturity tests calendar days to maturity, ignoring time of day
+func TestDaysUntilMaturity(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		purchase time.Time
+		term     string
+		expected int
+		wantErr  bool
+	}{
+		{"Not yet matured", now.AddDate(0, 0, -20), "1M", 10, false},
+		// Bought late in the day 30 days ago: matures today even though 24h haven't elapsed
+		{"Matures today", time.Date(2025, 2, 12, 23, 0, 0, 0, time.UTC), "1M", 0, false},
+		{"Matured last week", now.AddDate(0, 0, -37), "1M", -7, false},
+		{"Note term", now.AddDate(0, 0, -30), "2Y", 700, false},
+		{"Invalid term", now, "7M", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DaysUntilMaturity(tt.purchase, tt.term, DayCountThirty360, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DaysUntilMaturity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("DaysUntilMaturity() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDaysUntil tests counting calendar days to a stored maturity date, ignoring the time of day
+func TestDaysUntil(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		maturity time.Time
+		expected int
+	}{
+		{time.Date(2025, 3, 24, 0, 0, 0, 0, time.UTC), 10},
+		{time.Date(2025, 3, 14, 23, 0, 0, 0, time.UTC), 0},
+		{time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC), -7},
+	}
+	for _, tt := range tests {
+		if got := DaysUntil(tt.maturity, now); got != tt.expected {
+			t.Errorf("DaysUntil(%s) = %d, want %d", tt.maturity, got, tt.expected)
+		}
+	}
+}
+
+// TestCalculateAccruedInterestActualActual tests actual/actual accrual, including Feb 29 boundaries
+func TestCalculateAccruedInterestActualActual(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		principal float64
+		yieldRate float64
+		purchase  time.Time
+		sale      time.Time
+		expected  float64
+		wantErr   bool
+	}{
+		{"Same day accrues nothing", 10000, 5.0, date(2024, 2, 29), date(2024, 2, 29), 0, false},
+		{"Full non-leap year", 20000, 4.0, date(2025, 1, 1), date(2026, 1, 1), 800.00, false},
+		{"Full leap year", 20000, 4.0, date(2024, 1, 1), date(2025, 1, 1), 800.00, false},
+		// 2 days over 366: 10000 × 5% × 2/366 = 2.73 (365-day basis would give 2.74)
+		{"Across Feb 29", 10000, 5.0, date(2024, 2, 28), date(2024, 3, 1), 2.73, false},
+		// 31/365 in 2023 + 60/366 in 2024 (Jan 31 + Feb 29)
+		{"Spans into leap year", 10000, 5.0, date(2023, 12, 1), date(2024, 3, 1), 124.43, false},
+		// 306/366 in 2024 + 59/365 in 2025: one calendar year, but short of a full 365-day year's interest
+		{"Spans out of leap year", 10000, 5.0, date(2024, 3, 1), date(2025, 3, 1), 498.85, false},
+		{"Time of day ignored", 10000, 5.0, time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC), time.Date(2025, 6, 2, 1, 0, 0, 0, time.UTC), 1.37, false},
+		{"Sale before purchase", 10000, 5.0, date(2025, 6, 2), date(2025, 6, 1), 0, true},
+		{"Zero principal", 0, 5.0, date(2025, 1, 1), date(2025, 6, 1), 0, true},
+		{"Negative yield", 10000, -1.0, date(2025, 1, 1), date(2025, 6, 1), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateAccruedInterestActualActual(tt.principal, tt.yieldRate, tt.purchase, tt.sale)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateAccruedInterestActualActual() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("CalculateAccruedInterestActualActual() = %.2f, want %.2f", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCalculateMarketValue tests marking bills and notes to market at the current yield
+func TestCalculateMarketValue(t *testing.T) {
+	tests := []struct {
+		name            string
+		remaining       float64
+		yieldAtPurchase float64
+		currentYield    float64
+		term            string
+		daysRemaining   int
+		convention      DayCountConvention
+		expected        float64
+		wantErr         bool
+	}{
+		// 10000 × (1 - 0.036 × 90/360)
+		{"Bill with 90 days left", 10000.00, 4.00, 3.60, "6M", 90, DayCountThirty360, 9910.00, false},
+		{"Bill with 90 days left on actual/360", 10000.00, 4.00, 3.60, "6M", 90, DayCountActual360, 9910.00, false},
+		// 10000 × (1 - 0.036 × 90/365), all within 2025
+		{"Bill with 90 days left on actual/actual", 10000.00, 4.00, 3.60, "6M", 90, DayCountActualActual, 9911.23, false},
+		{"Bill at maturity", 10000.00, 4.00, 3.60, "6M", 0, DayCountThirty360, 10000.00, false},
+		{"Bill with an unknown day count", 10000.00, 4.00, 3.60, "6M", 90, DayCountConvention("30/365"), 0, true},
+		// Maturity proceeds 10000 × (1 + 0.04 × 730/365) = 10800, discounted 10800 / (1 + 0.05 × 365/365)
+		{"Note with a year left", 10000.00, 4.00, 5.00, "2Y", 365, DayCountThirty360, 10285.71, false},
+		{"Note past maturity", 10000.00, 4.00, 5.00, "2Y", -3, DayCountThirty360, 10800.00, false},
+		{"Invalid term", 10000.00, 4.00, 5.00, "7Y", 30, DayCountThirty360, 0, true},
+		{"Negative current yield", 10000.00, 4.00, -1.00, "2Y", 30, DayCountThirty360, 0, true},
+	}
+
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateMarketValue(tt.remaining, tt.yieldAtPurchase, tt.currentYield, tt.term,
+				now, now.AddDate(0, 0, tt.daysRemaining), tt.convention)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateMarketValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("CalculateMarketValue() = %.2f, want %.2f", got, tt.expected)
+			}
+		})
+	}
+}
@@ -17,7 +17,9 @@ import {
 
 const TREASURY_TERMS = [
   { value: '1M', label: '1 Month' },
+  { value: '2M', label: '2 Months' },
   { value: '3M', label: '3 Months' },
+  { value: '4M', label: '4 Months' },
   { value: '6M', label: '6 Months' },
   { value: '1Y', label: '1 Year' },
   { value: '2Y', label: '2 Years' },
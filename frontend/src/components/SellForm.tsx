@@ -15,7 +15,9 @@ function daysBetween(startDate: Date, endDate: Date): number {
 function getTermDays(term: string): number {
   const termMap: Record<string, number> = {
     '1M': 30,
+    '2M': 60,
     '3M': 90,
+    '4M': 120,
     '6M': 180,
     '1Y': 365,
     '2Y': 730,
@@ -25,7 +25,7 @@ export const TransactionHistory = forwardRef<TransactionHistoryRef>((_, ref) =>
     try {
       setError(null);
       const data = await fetchUserTransactions(currentUser.id);
-      setTransactions(data);
+      setTransactions(data.transactions);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to fetch transactions');
     }
@@ -48,7 +48,7 @@ export const TransactionHistory = forwardRef<TransactionHistoryRef>((_, ref) =>
         setLoading(true);
         setError(null);
         const data = await fetchUserTransactions(currentUser.id);
-        setTransactions(data);
+        setTransactions(data.transactions);
       } catch (err) {
         setError(err instanceof Error ? err.message : 'Failed to fetch transactions');
       } finally {
@@ -91,6 +91,8 @@ export const TransactionHistory = forwardRef<TransactionHistoryRef>((_, ref) =>
       case 'buy':
         return 'blue';
       case 'sell':
+      case 'mature':
+      case 'cancel':
         return 'violet';
       default:
         return 'blue';
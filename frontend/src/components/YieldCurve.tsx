@@ -2,8 +2,23 @@ import { useState, useEffect } from 'react';
 import { LineChart, Card, Title, Select, SelectItem } from '@tremor/react';
 import { fetchHistoricalYields } from '../services/api';
 import type { HistoricalYieldData } from '../services/api';
-
-type SelectableTerm = "2Y" | "5Y" | "10Y";
+import type { TreasuryTerm } from '../types/treasury';
+
+type SelectableTerm = TreasuryTerm;
+
+// Fixed color per term so a line keeps its color as other terms are toggled
+const termColors: Record<SelectableTerm, string> = {
+  '1M': 'rose',
+  '2M': 'pink',
+  '3M': 'orange',
+  '4M': 'amber',
+  '6M': 'lime',
+  '1Y': 'cyan',
+  '2Y': 'violet',
+  '5Y': 'emerald',
+  '10Y': 'blue',
+  '30Y': 'slate'
+};
 
 export default function YieldCurve() {
   const [selectedPeriod, setSelectedPeriod] = useState<string>('3M');
@@ -107,7 +122,9 @@ export default function YieldCurve() {
   const periodOptions = [
     { value: '1W', label: '1 Week' },
     { value: '1M', label: '1 Month' },
+    { value: '2M', label: '2 Months' },
     { value: '3M', label: '3 Months' },
+    { value: '4M', label: '4 Months' },
     { value: '6M', label: '6 Months' },
     { value: '1Y', label: '1 Year' },
     { value: '5Y', label: '5 Years' },
@@ -116,9 +133,16 @@ export default function YieldCurve() {
   ];
 
   const availableTerms: { value: SelectableTerm; label: string; fullLabel: string }[] = [
+    { value: '1M', label: '1M', fullLabel: '1-Month Bill' },
+    { value: '2M', label: '2M', fullLabel: '2-Month Bill' },
+    { value: '3M', label: '3M', fullLabel: '3-Month Bill' },
+    { value: '4M', label: '4M', fullLabel: '4-Month Bill' },
+    { value: '6M', label: '6M', fullLabel: '6-Month Bill' },
+    { value: '1Y', label: '1Y', fullLabel: '1-Year Bill' },
     { value: '2Y', label: '2Y', fullLabel: '2-Year Note' },
     { value: '5Y', label: '5Y', fullLabel: '5-Year Note' },
-    { value: '10Y', label: '10Y', fullLabel: '10-Year Note' }
+    { value: '10Y', label: '10Y', fullLabel: '10-Year Note' },
+    { value: '30Y', label: '30Y', fullLabel: '30-Year Bond' }
   ];
 
   // Loading state
@@ -206,7 +230,7 @@ export default function YieldCurve() {
         data={chartData}
         index="displayDate"  // Use formatted dates (MM/DD/YY)
         categories={selectedTerms}  // Dynamic: only show selected terms
-        colors={["blue", "emerald", "violet"]}
+        colors={selectedTerms.map(term => termColors[term])}
         valueFormatter={(value) => `${value.toFixed(2)}%`}
         yAxisWidth={56}
         showLegend={true}
@@ -1,14 +1,15 @@
 import type { User } from '../types/user';
-import type { Transaction, TransactionRequest, TransactionResponse, BuyRequest } from '../types/transaction';
-import type { Holding, SellRequest } from '../types/holding';
+import type { TransactionPage, TransactionPageParams, TransactionRequest, TransactionResponse, BuyRequest, BuyQuote } from '../types/transaction';
+import type { Holding, MaturingHolding, SellRequest } from '../types/holding';
+import type { TreasuryTerm } from '../types/treasury';
 
 // Re-export types for convenience
-export type { Holding, SellRequest } from '../types/holding';
+export type { Holding, MaturingHolding, SellRequest } from '../types/holding';
 
 /**
  * Represents a single point on the treasury yield curve.
  *
- * @property {string} term - The maturity term (e.g., "1M", "3M", "6M", "1Y", "2Y", "5Y", "10Y", "30Y")
+ * @property {string} term - The maturity term (e.g., "1M", "2M", "3M", "4M", "6M", "1Y", "2Y", "5Y", "10Y", "30Y")
  * @property {number} rate - The yield rate as a percentage (e.g., 4.5 for 4.5%)
  */
 export interface YieldPoint {
@@ -16,32 +17,43 @@ export interface YieldPoint {
   rate: number;
 }
 
+/**
+ * Commonly watched yield curve spreads, in percentage points.
+ * A negative spread means the curve is inverted between those terms.
+ */
+export interface CurveSpreads {
+  '2s10s': number; // 10Y rate minus 2Y rate
+  '3m10y': number; // 10Y rate minus 3M rate
+  inverted: boolean; // 2s10s spread is negative
+  inverted_3m10y: boolean; // 3m10y spread is negative
+}
+
 /**
  * Contains the complete yield curve data for a specific date.
  *
  * @property {string} date - The date this yield data is from (ISO 8601 format)
  * @property {YieldPoint[]} yields - Array of yield points for all treasury terms
+ * @property {CurveSpreads} [spreads] - 2s10s and 3m10y spreads with inversion flags
+ * @property {boolean} [stale] - True when treasury.gov is unavailable and the last cached curve is served
+ * @property {string} [cached_at] - When the stale curve was fetched (ISO 8601, only set when stale)
  */
 export interface YieldData {
   date: string;
   yields: YieldPoint[];
+  spreads?: CurveSpreads;
+  stale?: boolean;
+  cached_at?: string;
 }
 
 /**
  * Represents a single data point in the historical yield time series.
  * This format is optimized for Tremor LineChart with flattened structure.
+ * Each term in the response's `terms` array is a key holding that term's yield (percentage),
+ * or null when treasury.gov published no rate for the term that day.
  *
  * @property {string} date - The date in YYYY-MM-DD format
- * @property {number} 10Y - 10-year Treasury Note yield (percentage)
- * @property {number} 5Y - 5-year Treasury Note yield (percentage)
- * @property {number} 2Y - 2-year Treasury Note yield (percentage)
  */
-export interface HistoricalDataPoint {
-  date: string;        // YYYY-MM-DD format
-  "10Y": number;       // 10-year yield (Treasury Note)
-  "5Y": number;        // 5-year yield (Treasury Note)
-  "2Y": number;        // 2-year yield (Treasury Note)
-}
+export type HistoricalDataPoint = { date: string } & Partial<Record<TreasuryTerm, number | null>>;
 
 /**
  * Contains historical yield data for a specific time period.
@@ -49,16 +61,18 @@ export interface HistoricalDataPoint {
  * The data format is optimized for Tremor LineChart - no transformation needed on frontend.
  *
  * @property {string} period - Time period ("1M", "3M", "6M", or "1Y")
+ * @property {string} resolution - Sampling of the data points: "daily", "weekly", or "monthly"
  * @property {string} startDate - Start date of the period (YYYY-MM-DD format)
  * @property {string} endDate - End date of the period (YYYY-MM-DD format)
- * @property {string[]} terms - Array of maturity terms included (e.g., ["10Y", "5Y", "2Y"])
+ * @property {TreasuryTerm[]} terms - Array of maturity terms included (all terms unless a subset was requested)
  * @property {HistoricalDataPoint[]} data - Array of historical data points
  */
 export interface HistoricalYieldData {
   period: string;      // "1M", "3M", "6M", or "1Y"
+  resolution: "daily" | "weekly" | "monthly"; // Sampling used for the data points
   startDate: string;   // YYYY-MM-DD format
   endDate: string;     // YYYY-MM-DD format
-  terms: string[];     // ["10Y", "5Y", "2Y"]
+  terms: TreasuryTerm[]; // ["1M", "2M", ..., "10Y", "30Y"]
   data: HistoricalDataPoint[];
 }
 
@@ -109,26 +123,30 @@ export async function fetchYields(): Promise<YieldData> {
 /**
  * Fetches historical Treasury yield data for a given time period.
  *
- * Returns time-series data for Treasury Note yields (10Y, 5Y, 2Y) over the specified period.
+ * Returns time-series data for every treasury term (1M through 30Y) over the specified period,
+ * or only the given terms when a subset is requested.
  * The backend caches historical data for 24 hours as it doesn't change retroactively.
  * Data is returned in a format optimized for Tremor LineChart - no frontend transformation needed.
  *
  * @param {string} period - Time frame: "1M", "3M", "6M", or "1Y" (defaults to "3M" if not provided)
+ * @param {TreasuryTerm[]} [terms] - Optional subset of terms to return (defaults to all terms)
  * @returns {Promise<HistoricalYieldData>} Promise resolving to historical yield data
  * @throws {Error} If fetch fails or period is invalid
  *
  * @example
  * ```tsx
  * const historicalData = await fetchHistoricalYields("3M");
- * console.log(historicalData.data); // [{date: "2025-07-26", "10Y": 4.25, "5Y": 4.10, "2Y": 4.05}, ...]
+ * console.log(historicalData.data); // [{date: "2025-07-26", "1M": 4.30, ..., "30Y": 4.60}, ...]
  * ```
  */
 export async function fetchHistoricalYields(
-  period: string
+  period: string,
+  terms?: TreasuryTerm[]
 ): Promise<HistoricalYieldData> {
   try {
+    const termsParam = terms && terms.length > 0 ? `&terms=${terms.join(',')}` : '';
     const response = await fetch(
-      `${API_BASE_URL}/api/yields/historical?period=${period}`,
+      `${API_BASE_URL}/api/yields/historical?period=${period}${termsParam}`,
       {
         method: 'GET',
         headers: {
@@ -190,6 +208,73 @@ export async function fetchUsers(): Promise<User[]> {
   }
 }
 
+/**
+ * Fetches a single user by ID.
+ *
+ * Lets the balance display refresh after an operation without re-listing every user.
+ *
+ * @param {number} userId - The ID of the user to fetch
+ * @returns {Promise<User>} Promise resolving to the user, in the same shape as fetchUsers entries
+ * @throws {Error} If the user does not exist or the API request fails
+ *
+ * @example
+ * ```tsx
+ * const user = await fetchUser(1);
+ * console.log(`${user.name} has $${user.balance}`);
+ * ```
+ */
+export async function fetchUser(userId: number): Promise<User> {
+  const response = await fetch(`${API_BASE_URL}/api/v1/users/${userId}`, {
+    headers: {
+      'Accept': 'application/json',
+    },
+  });
+
+  const data = await response.json();
+
+  if (!response.ok) {
+    throw new Error(data.error || `Failed to fetch user: HTTP ${response.status}`);
+  }
+
+  return data as User;
+}
+
+/**
+ * Creates a new user.
+ *
+ * The name is trimmed by the server and must be non-empty; the initial balance
+ * defaults to zero and must not be negative.
+ *
+ * @param {string} name - The display name of the new user
+ * @param {number} [balance] - Optional starting balance
+ * @returns {Promise<User>} Promise resolving to the created user
+ * @throws {Error} If the name or balance is invalid or the API request fails
+ *
+ * @example
+ * ```tsx
+ * const user = await createUser('Alice', 100000);
+ * console.log(`Created user ${user.id}`);
+ * ```
+ */
+export async function createUser(name: string, balance?: number): Promise<User> {
+  const response = await fetch(`${API_BASE_URL}/api/v1/users`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+      'Accept': 'application/json',
+    },
+    body: JSON.stringify({ name, balance }),
+  });
+
+  const data = await response.json();
+
+  if (!response.ok) {
+    throw new Error(data.error || `Failed to create user: HTTP ${response.status}`);
+  }
+
+  return data as User;
+}
+
 /**
  * Adds funds to a user's account.
  *
@@ -300,7 +385,7 @@ export async function withdrawAccount(userId: number, amount: number): Promise<U
  * together in a database transaction.
  *
  * @param {number} userId - The ID of the user making the purchase
- * @param {string} term - The treasury term (1M, 3M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y)
+ * @param {string} term - The treasury term (1M, 2M, 3M, 4M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y)
  * @param {number} faceValue - The face value amount (amount at maturity, not purchase cost)
  * @returns {Promise<User>} Promise resolving to the updated user with new balance
  * @throws {Error} If the API request fails, face value is invalid, insufficient balance for purchase cost, or server returns error
@@ -354,24 +439,69 @@ export async function buyTreasury(
 }
 
 /**
- * Fetches all transactions for a specific user.
+ * Quotes a treasury purchase without buying it.
+ *
+ * Runs the same term validation and pricing as buyTreasury at the current yield,
+ * but nothing is recorded and no balance is checked or changed.
+ *
+ * @param {string} term - The treasury term (1M, 2M, 3M, 4M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y)
+ * @param {number} faceValue - The face value amount (amount at maturity, not purchase cost)
+ * @returns {Promise<BuyQuote>} Promise resolving to the purchase price, discount, and yield
+ * @throws {Error} If the term or face value is invalid or yields are unavailable
+ *
+ * @example
+ * ```tsx
+ * const quote = await quoteBuy("6M", 100000);
+ * console.log(`This will cost $${quote.purchase_price} at a ${quote.yield}% yield`);
+ * ```
+ */
+export async function quoteBuy(term: string, faceValue: number): Promise<BuyQuote> {
+  const response = await fetch(`${API_BASE_URL}/api/v1/buy/quote`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ term: term, face_value: faceValue }),
+  });
+
+  const data = await response.json();
+
+  if (!response.ok || !data.success) {
+    throw new Error(data.error || 'Failed to quote purchase');
+  }
+
+  return data as BuyQuote;
+}
+
+/**
+ * Fetches a page of transactions for a specific user.
  *
- * Returns transactions ordered by timestamp descending (most recent first).
- * Includes fund, withdraw, and future buy/sell transactions.
+ * Returns transactions ordered by timestamp descending (most recent first),
+ * with the total count matching the date filter for pagination controls.
+ * Includes fund, withdraw, buy, sell, and mature transactions.
  *
  * @param {number} userId - The ID of the user whose transactions to fetch
- * @returns {Promise<Transaction[]>} Promise resolving to array of transactions (empty if no transactions)
+ * @param {TransactionPageParams} [params] - Optional limit, offset, from/to date, and type filters
+ * @returns {Promise<TransactionPage>} Promise resolving to the page (empty transactions array if none)
  * @throws {Error} If the API request fails or user doesn't exist
  *
  * @example
  * ```tsx
- * const transactions = await fetchUserTransactions(1);
- * console.log(`User has ${transactions.length} transactions`);
+ * const page = await fetchUserTransactions(1, { limit: 20 });
+ * console.log(`Showing ${page.transactions.length} of ${page.total_count} transactions`);
  * ```
  */
-export async function fetchUserTransactions(userId: number): Promise<Transaction[]> {
+export async function fetchUserTransactions(userId: number, params: TransactionPageParams = {}): Promise<TransactionPage> {
+  const query = new URLSearchParams();
+  if (params.limit !== undefined) query.set('limit', String(params.limit));
+  if (params.offset !== undefined) query.set('offset', String(params.offset));
+  if (params.from) query.set('from', params.from);
+  if (params.to) query.set('to', params.to);
+  if (params.type) query.set('type', params.type);
+  const queryString = query.toString();
+
   try {
-    const response = await fetch(`${API_BASE_URL}/api/v1/users/${userId}/transactions`, {
+    const response = await fetch(`${API_BASE_URL}/api/v1/users/${userId}/transactions${queryString ? `?${queryString}` : ''}`, {
       method: 'GET',
       headers: {
         'Accept': 'application/json',
@@ -382,7 +512,7 @@ export async function fetchUserTransactions(userId: number): Promise<Transaction
       throw new Error(`HTTP error! status: ${response.status}`);
     }
 
-    const data: Transaction[] = await response.json();
+    const data: TransactionPage = await response.json();
     return data;
   } catch (error) {
     if (error instanceof Error) {
@@ -431,6 +561,46 @@ export async function fetchUserHoldings(userId: number): Promise<Holding[]> {
   }
 }
 
+/**
+ * Fetches a user's active holdings maturing within the next `days` days, soonest first.
+ *
+ * Includes holdings already matured but not yet redeemed. Each carries its projected payout,
+ * so the UI can prompt the user to reinvest the proceeds.
+ *
+ * @param {number} userId - The ID of the user whose holdings to fetch
+ * @param {number} days - Window in days, 1-365 (default: 30)
+ * @returns {Promise<MaturingHolding[]>} Promise resolving to maturing holdings (empty if none)
+ * @throws {Error} If the API request fails or the window is out of range
+ *
+ * @example
+ * ```tsx
+ * const maturing = await fetchMaturingHoldings(1, 30);
+ * const proceeds = maturing.reduce((sum, h) => sum + h.projected_payout, 0);
+ * ```
+ */
+export async function fetchMaturingHoldings(userId: number, days: number = 30): Promise<MaturingHolding[]> {
+  try {
+    const response = await fetch(`${API_BASE_URL}/api/v1/users/${userId}/holdings/maturing?days=${days}`, {
+      method: 'GET',
+      headers: {
+        'Accept': 'application/json',
+      },
+    });
+
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+
+    const data: MaturingHolding[] = await response.json();
+    return data;
+  } catch (error) {
+    if (error instanceof Error) {
+      throw new Error(`Failed to fetch maturing holdings: ${error.message}`);
+    }
+    throw new Error('Failed to fetch maturing holdings: Unknown error');
+  }
+}
+
 /**
  * Sells a treasury security (full or partial).
  *
@@ -11,12 +11,29 @@ export interface Holding {
   amount: string; // Original purchase amount (decimal as string) - legacy field
   yield_at_purchase: string; // Yield rate at time of purchase
   purchase_date: string; // ISO 8601 format
+  maturity_date: string; // ISO 8601 format, fixed at purchase from purchase_date and term
   remaining_amount: string; // Amount not yet sold (decimal as string)
   // T-Bill discount pricing fields (added in Phase 1)
   face_value?: string; // Maturity amount - what user receives at maturity (null for legacy holdings)
   purchase_price?: string; // Actual cost - what user paid (null for legacy holdings)
   // Security type field (added in Phase 4.5 - Treasury Notes/Bonds implementation)
   security_type?: SecurityType | null; // SecurityType enum value (null for legacy holdings)
+  security_type_label: string; // Display name, e.g. "Treasury Bill" (inferred from term for legacy holdings)
+  investment_yield: number; // 365-day bond-equivalent yield, comparable across bills, notes, and bonds
+  discount: number; // Original face value minus purchase price (0 for notes/bonds bought at par)
+  price_per_100: number; // Purchase price per $100 of original face value, e.g. 97.75
+  days_held: number;
+  current_value: number | null; // Remaining amount valued at the latest yields (null when yields are unavailable)
+}
+
+/**
+ * An active holding due to mature within the requested window.
+ * Returned by GET /api/v1/users/{id}/holdings/maturing, soonest first, without the display fields of the holdings list.
+ */
+export interface MaturingHolding
+  extends Omit<Holding, 'security_type_label' | 'investment_yield' | 'discount' | 'price_per_100' | 'days_held' | 'current_value'> {
+  days_to_maturity: number; // Zero once matured
+  projected_payout: number; // Face value for bills; notes and bonds add full-term interest
 }
 
 /**
@@ -1,4 +1,4 @@
-export type TransactionType = 'fund' | 'withdraw' | 'buy' | 'sell';
+export type TransactionType = 'fund' | 'withdraw' | 'buy' | 'sell' | 'mature' | 'cancel';
 
 /**
  * Represents a complete transaction record from the database.
@@ -14,6 +14,30 @@ export interface Transaction {
   yield_at_transaction: string | null; // Only populated for buy/sell
   balance_after: string; // Decimal as string
   holding_id: number | null; // Only populated for sell
+  memo: string | null; // Only populated for admin adjustments
+}
+
+/**
+ * One page of a user's transaction history from GET /api/v1/users/{userId}/transactions.
+ * total_count is the number of transactions matching the date filter across all pages.
+ */
+export interface TransactionPage {
+  transactions: Transaction[];
+  total_count: number;
+  limit: number;
+  offset: number;
+}
+
+/**
+ * Optional pagination, date, and type filters for transaction history.
+ * from and to are inclusive YYYY-MM-DD dates; limit defaults to 50 and is capped at 500.
+ */
+export interface TransactionPageParams {
+  limit?: number;
+  offset?: number;
+  from?: string;
+  to?: string;
+  type?: TransactionType;
 }
 
 export interface TransactionRequest {
@@ -27,11 +51,20 @@ export interface TransactionRequest {
  */
 export interface BuyRequest {
   user_id: number;
-  term: string; // Treasury term: 1M, 3M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y
+  term: string; // Treasury term: 1M, 2M, 3M, 4M, 6M, 1Y, 2Y, 5Y, 10Y, 30Y
   amount?: number; // Deprecated: Use face_value instead (kept for backward compatibility)
   face_value: number; // Amount at maturity (for T-Bills, this is the face value)
 }
 
+export interface BuyQuote {
+  face_value: number;
+  purchase_price: number; // What the buy would cost now; equals face_value for notes and bonds
+  discount: number;
+  price_per_100: number;
+  yield: number; // Rate (%) the order was priced at
+  yield_date: string; // YYYY-MM-DD date of the curve used
+}
+
 export interface TransactionResponse {
   success: boolean;
   user?: {
@@ -41,4 +74,6 @@ export interface TransactionResponse {
     created_at: string;
   };
   error?: string;
+  // Machine-readable failure code, e.g. "insufficient_balance" or "holding_not_found"
+  code?: string;
 }
@@ -16,14 +16,16 @@ export type SecurityType = typeof SecurityType[keyof typeof SecurityType];
 /**
  * Valid treasury terms supported by the application
  */
-export type TreasuryTerm = '1M' | '3M' | '6M' | '1Y' | '2Y' | '5Y' | '10Y' | '30Y';
+export type TreasuryTerm = '1M' | '2M' | '3M' | '4M' | '6M' | '1Y' | '2Y' | '5Y' | '10Y' | '30Y';
 
 /**
  * Map treasury terms to their duration in days (matches backend TermDurationDays)
  */
 export const TERM_DAYS: Record<TreasuryTerm, number> = {
   '1M': 30,
+  '2M': 60,
   '3M': 90,
+  '4M': 120,
   '6M': 180,
   '1Y': 365,
   '2Y': 730,
@@ -41,7 +43,9 @@ export const TERM_DAYS: Record<TreasuryTerm, number> = {
 export function getSecurityType(term: string): SecurityType | null {
   switch (term) {
     case '1M':
+    case '2M':
     case '3M':
+    case '4M':
     case '6M':
     case '1Y':
       return SecurityType.Bill;